@@ -15,6 +15,8 @@ import (
 type AppServicer interface {
 	Create(ctx context.Context, r *CreateAppRequest, adt Audit) (*AppResponse, error)
 	Update(ctx context.Context, r *UpdateAppRequest, adt Audit) (*AppResponse, error)
+	Delete(ctx context.Context, extlID string, adt Audit) (DeleteResponse, error)
+	Restore(ctx context.Context, extlID string, adt Audit) (*AppResponse, error)
 }
 
 // APIKeyGenerator creates a random, 128 API key string
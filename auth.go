@@ -27,7 +27,7 @@ const (
 type PermissionServicer interface {
 	Create(ctx context.Context, r *CreatePermissionRequest, adt Audit) (*PermissionResponse, error)
 	FindAll(ctx context.Context) ([]*PermissionResponse, error)
-	Delete(ctx context.Context, extlID string) (DeleteResponse, error)
+	Delete(ctx context.Context, extlID string, adt Audit) (DeleteResponse, error)
 }
 
 // RoleServicer allows for creating, updating, reading and deleting a Role
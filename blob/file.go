@@ -0,0 +1,150 @@
+// Package blob has implementations of diygoapi.BlobStore. FileStore
+// keeps blobs on the local filesystem; stores backed by GCS or S3 can
+// be added alongside it.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+// FileStore is a diygoapi.BlobStore which keeps each blob as a file
+// below Dir. The key of a blob is its path relative to Dir.
+type FileStore struct {
+	Dir string
+}
+
+// NewFileStore initializes a FileStore, creating dir if it does not exist
+func NewFileStore(dir string) (*FileStore, error) {
+	const op errs.Op = "blob/NewFileStore"
+
+	if dir == "" {
+		return nil, errs.E(op, errs.Internal, "blob store directory is required")
+	}
+
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, errs.E(op, errs.IO, err)
+	}
+
+	return &FileStore{Dir: dir}, nil
+}
+
+// CheckHealth returns an error unless Dir is a directory
+func (s *FileStore) CheckHealth(ctx context.Context) error {
+	const op errs.Op = "blob/FileStore.CheckHealth"
+
+	fi, err := os.Stat(s.Dir)
+	if err != nil {
+		return errs.E(op, errs.IO, err)
+	}
+	if !fi.IsDir() {
+		return errs.E(op, errs.IO, "blob store path is not a directory: "+s.Dir)
+	}
+
+	return nil
+}
+
+// filename returns the file a key is stored in. Keys which are not
+// clean, relative slash separated paths are rejected so a key can
+// never refer to a file outside of Dir.
+func (s *FileStore) filename(key string) (string, error) {
+	const op errs.Op = "blob/FileStore.filename"
+
+	if key == "" || path.Clean(key) != key || path.IsAbs(key) || key == ".." || strings.HasPrefix(key, "../") || strings.Contains(key, "\\") {
+		return "", errs.E(op, errs.Invalid, "invalid blob key: "+key)
+	}
+
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes the blob to a temporary file and renames it into place,
+// so a reader never sees a partially written blob.
+func (s *FileStore) Put(ctx context.Context, key string, r io.Reader) (err error) {
+	const op errs.Op = "blob/FileStore.Put"
+
+	var name string
+	name, err = s.filename(key)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(name), 0o750)
+	if err != nil {
+		return errs.E(op, errs.IO, err)
+	}
+
+	var f *os.File
+	f, err = os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return errs.E(op, errs.IO, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	_, err = io.Copy(f, r)
+	if err != nil {
+		return errs.E(op, errs.IO, err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return errs.E(op, errs.IO, err)
+	}
+
+	err = os.Rename(f.Name(), name)
+	if err != nil {
+		return errs.E(op, errs.IO, err)
+	}
+
+	return nil
+}
+
+// Get opens the file of the blob stored at key
+func (s *FileStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	const op errs.Op = "blob/FileStore.Get"
+
+	name, err := s.filename(key)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, errs.E(op, errs.NotExist, "no blob exists for key: "+key)
+		}
+		return nil, errs.E(op, errs.IO, err)
+	}
+
+	return f, nil
+}
+
+// Delete removes the file of the blob stored at key
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	const op errs.Op = "blob/FileStore.Delete"
+
+	name, err := s.filename(key)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	err = os.Remove(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return errs.E(op, errs.IO, err)
+	}
+
+	return nil
+}
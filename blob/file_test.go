@@ -0,0 +1,76 @@
+package blob_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/blob"
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+// FileStore must satisfy the diygoapi.BlobStore interface
+var _ diygoapi.BlobStore = (*blob.FileStore)(nil)
+
+// FileStore must satisfy the diygoapi.HealthChecker interface
+var _ diygoapi.HealthChecker = (*blob.FileStore)(nil)
+
+func TestFileStore(t *testing.T) {
+	t.Run("put, get and delete", func(t *testing.T) {
+		c := qt.New(t)
+		ctx := context.Background()
+
+		s, err := blob.NewFileStore(t.TempDir())
+		c.Assert(err, qt.IsNil)
+
+		const key = "posters/abc/123"
+		err = s.Put(ctx, key, strings.NewReader("first"))
+		c.Assert(err, qt.IsNil)
+		err = s.Put(ctx, key, strings.NewReader("second"))
+		c.Assert(err, qt.IsNil)
+
+		rc, err := s.Get(ctx, key)
+		c.Assert(err, qt.IsNil)
+		b, err := io.ReadAll(rc)
+		c.Assert(err, qt.IsNil)
+		c.Assert(rc.Close(), qt.IsNil)
+		c.Assert(string(b), qt.Equals, "second")
+
+		c.Assert(s.Delete(ctx, key), qt.IsNil)
+		// deleting again is not an error
+		c.Assert(s.Delete(ctx, key), qt.IsNil)
+
+		_, err = s.Get(ctx, key)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+	})
+	t.Run("keys outside of the directory", func(t *testing.T) {
+		c := qt.New(t)
+		ctx := context.Background()
+
+		s, err := blob.NewFileStore(t.TempDir())
+		c.Assert(err, qt.IsNil)
+
+		for _, key := range []string{"", "../escape", "/etc/passwd", "posters/../../escape", "posters//x", `posters\x`} {
+			err = s.Put(ctx, key, strings.NewReader("x"))
+			c.Assert(errs.KindIs(errs.Invalid, err), qt.IsTrue, qt.Commentf("key %q", key))
+		}
+	})
+	t.Run("health", func(t *testing.T) {
+		c := qt.New(t)
+		ctx := context.Background()
+
+		dir := t.TempDir()
+		s, err := blob.NewFileStore(filepath.Join(dir, "blobs"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(s.CheckHealth(ctx), qt.IsNil)
+
+		c.Assert(os.Remove(s.Dir), qt.IsNil)
+		c.Assert(errs.KindIs(errs.IO, s.CheckHealth(ctx)), qt.IsTrue)
+	})
+}
@@ -5,21 +5,35 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/rs/zerolog"
 	"golang.org/x/text/language"
 
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/blob"
 	"github.com/gilcrest/diygoapi/errs"
 	"github.com/gilcrest/diygoapi/gateway"
 	"github.com/gilcrest/diygoapi/logger"
+	"github.com/gilcrest/diygoapi/ratelimit"
 	"github.com/gilcrest/diygoapi/secure"
 	"github.com/gilcrest/diygoapi/server"
 	"github.com/gilcrest/diygoapi/service"
 	"github.com/gilcrest/diygoapi/sqldb"
+	"github.com/gilcrest/diygoapi/tracing"
 )
 
+// traceFlushTimeout is how long spans not yet exported are given to be
+// sent once the server is shut down
+const traceFlushTimeout = 5 * time.Second
+
 // Run parses command line flags and starts the server
 func Run(args []string) (err error) {
 	const op errs.Op = "cmd/Run"
@@ -48,13 +62,12 @@ func Run(args []string) (err error) {
 	lgr := logger.NewWithGCPHook(os.Stdout, minlvl, true)
 
 	// logs will be written at the level set in NewLogger (which is
-	// also the minimum level). If the logs are to be written at a
-	// different level than the minimum, use SetGlobalLevel to set
-	// the global logging level to that. Minimum rules will still
-	// apply.
-	if minlvl != lvl {
-		zerolog.SetGlobalLevel(lvl)
-	}
+	// also the minimum level). The levels hook raises that to the
+	// logging level, globally or for a component, and can be updated
+	// while running through the LoggerService. Minimum rules will
+	// still apply.
+	lvls := logger.NewLevels(lvl)
+	lgr = lgr.Hook(lvls)
 
 	// set global logging time field format to Unix timestamp
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
@@ -71,6 +84,26 @@ func Run(args []string) (err error) {
 		lgr.Fatal().Err(err).Msg("flags.Validate() error")
 	}
 
+	// set up tracing, spans are flushed on the way out
+	var stopTracing func(context.Context) error
+	stopTracing, err = tracing.Start(context.Background(), tracing.Config{
+		Exporter:     flgs.traceExporter,
+		OTLPEndpoint: flgs.traceOTLPEndpoint,
+		SampleRatio:  flgs.traceSampleRatio,
+		Stdout:       os.Stdout,
+	})
+	if err != nil {
+		lgr.Fatal().Err(err).Msg("tracing.Start error")
+	}
+	lgr.Info().Msgf("trace exporter set to %s", flgs.traceExporter)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), traceFlushTimeout)
+		defer cancel()
+		if terr := stopTracing(ctx); terr != nil {
+			lgr.Error().Err(terr).Msg("trace spans not flushed")
+		}
+	}()
+
 	// decode and retrieve encryption key
 	var ek *[32]byte
 	ek, err = secure.ParseEncryptionKey(flgs.encryptkey)
@@ -80,12 +113,36 @@ func Run(args []string) (err error) {
 
 	// initialize Server enfolding a http.Server with default timeouts,
 	// a mux router and a zerolog.Logger
-	s := server.New(http.NewServeMux(), server.NewDriver(), lgr)
+	drv := server.NewDriver()
+	if flgs.h2c {
+		drv.EnableH2C()
+	}
+	s := server.New(http.NewServeMux(), drv, lgr)
+
+	// serve HTTPS if a certificate is given, otherwise plain HTTP
+	s.TLS = newTLS(flgs)
 
 	// set Server listener address
 	s.Addr = fmt.Sprintf(":%d", flgs.port)
 
-	ctx := context.Background()
+	// metrics are served on their own port if one is given
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	s.Metrics = server.NewMetrics(reg)
+	if flgs.metricsPort != 0 {
+		s.MetricsAddr = fmt.Sprintf(":%d", flgs.metricsPort)
+	}
+
+	// trap the signals Cloud Run and Kubernetes send to stop the
+	// container, so the server can be shut down gracefully
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	// ctx is canceled once the server is shut down, stopping the
+	// background processes started with it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	// initialize PostgreSQL database
 	var (
@@ -96,33 +153,56 @@ func Run(args []string) (err error) {
 	if err != nil {
 		lgr.Fatal().Err(err).Msg("sqldb.NewPgxPool error")
 	}
+	// the pool is closed as the last step of shutdown, this only
+	// covers returning early
 	defer cleanup()
 
 	// create a new DB using the pool and established connection
 	db := sqldb.NewDB(dbpool)
+	reg.MustRegister(sqldb.NewPoolCollector(db))
 
 	err = db.ValidatePool(ctx, lgr)
 	if err != nil {
 		lgr.Fatal().Err(err).Msg("db.ValidatePool error")
 	}
 
+	// initialize the blob store for uploaded files
+	var bs *blob.FileStore
+	bs, err = blob.NewFileStore(flgs.blobDir)
+	if err != nil {
+		lgr.Fatal().Err(err).Msg("blob.NewFileStore error")
+	}
+
 	var supportedLangs = []language.Tag{
 		language.AmericanEnglish,
 	}
 
 	matcher := language.NewMatcher(supportedLangs)
 
+	// a single connection listens for events written to the outbox
+	// and wakes the open event streams
+	broker := &service.EventBroker{Listener: db, Logger: lgr}
+	broker.Start(ctx)
+
+	webhookNetworks := newWebhookAllowedNetworks(flgs)
+
 	s.Services = server.Services{
 		OrgServicer: &service.OrgService{
 			Datastorer:      db,
 			APIKeyGenerator: secure.RandomGenerator{},
-			EncryptionKey:   ek},
+			EncryptionKey:   ek,
+			DeleteRetention: flgs.deleteRetention},
 		AppServicer: &service.AppService{
 			Datastorer:      db,
 			APIKeyGenerator: secure.RandomGenerator{},
-			EncryptionKey:   ek},
-		PingService:   &service.PingService{Datastorer: db},
-		LoggerService: &service.LoggerService{Logger: lgr},
+			EncryptionKey:   ek,
+			DeleteRetention: flgs.deleteRetention},
+		PingService: &service.PingService{Datastorer: db},
+		LoggerService: &service.LoggerService{
+			Logger:                lgr,
+			Levels:                lvls,
+			SecurityEventServicer: &service.SecurityEventService{Datastorer: db},
+		},
 		GenesisServicer: &service.GenesisService{
 			Datastorer:      db,
 			APIKeyGenerator: secure.RandomGenerator{},
@@ -131,18 +211,121 @@ func Run(args []string) (err error) {
 			LanguageMatcher: matcher,
 		},
 		AuthenticationServicer: service.DBAuthenticationService{
-			Datastorer:      db,
-			TokenExchanger:  gateway.Oauth2TokenExchange{},
-			EncryptionKey:   ek,
-			LanguageMatcher: matcher,
+			Datastorer:          db,
+			TokenExchanger:      gateway.Oauth2TokenExchange{},
+			EncryptionKey:       ek,
+			LanguageMatcher:     matcher,
+			FailureEventLimiter: ratelimit.NewMemoryLimiter(),
 		},
 		AuthorizationServicer: &service.DBAuthorizationService{Datastorer: db},
 		PermissionServicer:    &service.PermissionService{Datastorer: db},
 		RoleServicer:          &service.RoleService{Datastorer: db},
-		MovieServicer:         &service.MovieService{Datastorer: db},
+		MovieServicer:         &service.MovieService{Datastorer: db, DeleteRetention: flgs.deleteRetention},
+		ChangeHistoryServicer: &service.ChangeHistoryService{Datastorer: db},
+		ReviewServicer:        &service.ReviewService{Datastorer: db},
+		WatchlistServicer:     &service.WatchlistService{Datastorer: db},
+		PosterServicer:        &service.PosterService{Datastorer: db, BlobStore: bs},
+		JobServicer:           &service.JobService{Datastorer: db},
+		SecurityEventServicer: &service.SecurityEventService{Datastorer: db},
+		WebhookServicer: &service.WebhookService{
+			Datastorer:      db,
+			APIKeyGenerator: secure.RandomGenerator{},
+			EncryptionKey:   ek,
+			AllowedNetworks: webhookNetworks,
+		},
+		EventServicer: &service.EventService{Datastorer: db, Broker: broker},
+		RateLimitServicer: &service.RateLimitService{
+			Datastorer: db,
+			Defaults: diygoapi.AppRateLimits{
+				App:  diygoapi.RateLimit{RequestsPerMinute: flgs.rateLimitApp, Burst: flgs.rateLimitAppBurst},
+				User: diygoapi.RateLimit{RequestsPerMinute: flgs.rateLimitUser, Burst: flgs.rateLimitUserBurst},
+			},
+		},
+	}
+	// requests are counted in memory, so each instance allows the full rate
+	s.RateLimiter = ratelimit.NewMemoryLimiter()
+
+	s.MaxRequestBodyBytes = flgs.maxRequestBody
+
+	s.ShutdownDrainDelay = flgs.shutdownDrainDelay
+
+	s.CORS = newCORS(flgs)
+	s.CORSServicer = &service.CORSService{Datastorer: db, Logger: lgr}
+
+	// start the background job workers, which are drained when
+	// the server is shut down
+	if flgs.jobWorkers > 0 {
+		workers := &service.JobWorkers{
+			Datastorer: db,
+			Logger:     lgr,
+			Handlers: map[string]diygoapi.JobHandler{
+				diygoapi.WebhookDeliveryJobKind: &service.WebhookDeliverer{Datastorer: db, EncryptionKey: ek, AllowedNetworks: webhookNetworks},
+			},
+			Concurrency:  flgs.jobWorkers,
+			PollInterval: flgs.jobPollInterval,
+		}
+		workers.Start(ctx)
+		s.JobWorkers = workers
+	}
+
+	// dependencies the server must reach to be ready for traffic
+	s.HealthCheckers = map[string]diygoapi.HealthChecker{
+		"database":       diygoapi.HealthCheckerFunc(db.Ping),
+		"schema_version": diygoapi.HealthCheckerFunc(db.CheckSchemaVersion),
+		"blob_store":     bs,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serveErr:
+		// the server stopped without being shut down, e.g. the port
+		// is in use
+		return errs.E(op, err)
+	case sig := <-sigs:
+		lgr.Info().Msgf("%s signal received, shutting down", sig)
 	}
+	// a second signal stops the process without waiting
+	signal.Stop(sigs)
 
-	return s.ListenAndServe()
+	return shutdown(s, cancel, cleanup, flgs.shutdownGracePeriod, lgr)
+}
+
+// shutdown stops the server and the processes started with it, in
+// order, logging each phase. Once the Server's drain delay is over,
+// requests are drained and running jobs finished within the grace
+// period, then background processes are stopped and finally the
+// database pool is closed, once nothing is using it.
+func shutdown(s *server.Server, cancel context.CancelFunc, closePool func(), grace time.Duration, lgr zerolog.Logger) error {
+	const op errs.Op = "cmd/shutdown"
+
+	// the grace period starts once the drain delay is over
+	ctx, cancelGrace := context.WithTimeout(context.Background(), s.ShutdownDrainDelay+grace)
+	defer cancelGrace()
+
+	lgr.Info().Msgf("draining requests and jobs, grace period %s", grace)
+	err := s.Shutdown(ctx)
+	if err != nil {
+		// carry on, what is left is cut off when the process exits
+		lgr.Error().Err(err).Msg("server did not shut down cleanly")
+	}
+
+	cancel()
+	lgr.Info().Msg("background processes stopped")
+
+	closePool()
+	lgr.Info().Msg("database pool closed")
+
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	lgr.Info().Msg("shutdown complete")
+
+	return nil
 }
 
 // newPostgreSQLDSN initializes a sqldb.PostgreSQLDSN given a Flags struct
@@ -157,6 +340,49 @@ func newPostgreSQLDSN(flgs flags) sqldb.PostgreSQLDSN {
 	}
 }
 
+// newWebhookAllowedNetworks parses the webhook allowed networks of a
+// flags struct, which have been validated by flags.Validate
+func newWebhookAllowedNetworks(flgs flags) []netip.Prefix {
+	var networks []netip.Prefix
+	for _, n := range splitList(flgs.webhookAllowedNetworks) {
+		p, _ := netip.ParsePrefix(n)
+		networks = append(networks, p)
+	}
+	return networks
+}
+
+// newCORS initializes the server.CORS policy given a flags struct. The
+// allowed origins have been validated by flags.Validate.
+func newCORS(flgs flags) *server.CORS {
+	var origins []string
+	for _, o := range splitList(flgs.corsAllowedOrigins) {
+		origin, _ := diygoapi.ParseOrigin(o)
+		origins = append(origins, origin)
+	}
+
+	return &server.CORS{
+		AllowedOrigins: origins,
+		AllowedMethods: splitList(flgs.corsAllowedMethods),
+		AllowedHeaders: splitList(flgs.corsAllowedHeaders),
+		MaxAge:         flgs.corsMaxAge,
+	}
+}
+
+// newTLS initializes the server.TLS configuration given a flags
+// struct, or returns nil if no certificate is given and plain HTTP is
+// to be served
+func newTLS(flgs flags) *server.TLS {
+	if flgs.tlsCertFile == "" {
+		return nil
+	}
+
+	return &server.TLS{
+		CertFile:     flgs.tlsCertFile,
+		KeyFile:      flgs.tlsKeyFile,
+		ClientCAFile: flgs.tlsClientCAFile,
+	}
+}
+
 // portRange validates the port be in an acceptable range
 func portRange(port int) error {
 	const op errs.Op = "cmd/portRange"
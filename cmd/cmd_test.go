@@ -1,12 +1,23 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	qt "github.com/frankban/quicktest"
 	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"net/netip"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
 	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/server"
+	"github.com/gilcrest/diygoapi/service"
 	"github.com/gilcrest/diygoapi/sqldb"
 )
 
@@ -46,6 +57,7 @@ func Test_newFlags(t *testing.T) {
 		c.Setenv(logLevelMinFlagEnvVarName, "debug")
 		c.Setenv(logErrorStackFlagEnvVarName, "false")
 		c.Setenv(listenPortFlagEnvVarName, "8081")
+		c.Setenv(metricsPortFlagEnvVarName, "9091")
 		c.Setenv(sqldb.DBHostEnv, "hostwiththemost")
 		c.Setenv(sqldb.DBPortEnv, "5150")
 		c.Setenv(sqldb.DBNameEnv, "whatisinaname")
@@ -53,6 +65,24 @@ func Test_newFlags(t *testing.T) {
 		c.Setenv(sqldb.DBPasswordEnv, "yeet")
 		c.Setenv(sqldb.DBSearchPathEnv, "u2")
 		c.Setenv(encryptKeyFlagEnvVarName, "reallyGoodKey")
+		c.Setenv(deleteRetentionFlagEnvVarName, "72h")
+		c.Setenv(blobDirFlagEnvVarName, "/var/lib/diygoapi/blobs")
+		c.Setenv(jobWorkersFlagEnvVarName, "8")
+		c.Setenv(shutdownGracePeriodFlagEnvVarName, "20s")
+		c.Setenv(shutdownDrainDelayFlagEnvVarName, "10s")
+		c.Setenv(traceExporterFlagEnvVarName, "otlp")
+		c.Setenv(traceOTLPEndpointFlagEnvVarName, "http://collector:4318")
+		c.Setenv(traceSampleRatioFlagEnvVarName, "0.25")
+		c.Setenv(rateLimitAppFlagEnvVarName, "600")
+		c.Setenv(rateLimitAppBurstFlagEnvVarName, "50")
+		c.Setenv(rateLimitUserFlagEnvVarName, "60")
+		c.Setenv(rateLimitUserBurstFlagEnvVarName, "10")
+		c.Setenv(corsAllowedOriginsFlagEnvVarName, "https://app.example.com")
+		c.Setenv(corsAllowedMethodsFlagEnvVarName, "GET")
+		c.Setenv(corsAllowedHeadersFlagEnvVarName, "X-APP-ID")
+		c.Setenv(corsMaxAgeFlagEnvVarName, "1h")
+		c.Setenv(maxRequestBodyFlagEnvVarName, "65536")
+		c.Setenv(h2cFlagEnvVarName, "true")
 		c.Log("Environment setup completed")
 	}
 
@@ -62,6 +92,7 @@ func Test_newFlags(t *testing.T) {
 		c.Setenv(logLevelMinFlagEnvVarName, "")
 		c.Setenv(logErrorStackFlagEnvVarName, "")
 		c.Setenv(listenPortFlagEnvVarName, "")
+		c.Setenv(metricsPortFlagEnvVarName, "")
 		c.Setenv(sqldb.DBHostEnv, "")
 		c.Setenv(sqldb.DBPortEnv, "")
 		c.Setenv(sqldb.DBNameEnv, "")
@@ -69,55 +100,137 @@ func Test_newFlags(t *testing.T) {
 		c.Setenv(sqldb.DBPasswordEnv, "")
 		c.Setenv(sqldb.DBSearchPathEnv, "")
 		c.Setenv(encryptKeyFlagEnvVarName, "")
+		c.Setenv(deleteRetentionFlagEnvVarName, "")
+		c.Setenv(blobDirFlagEnvVarName, "")
+		c.Setenv(jobWorkersFlagEnvVarName, "")
+		c.Setenv(shutdownGracePeriodFlagEnvVarName, "")
+		c.Setenv(shutdownDrainDelayFlagEnvVarName, "")
+		c.Setenv(traceExporterFlagEnvVarName, "")
+		c.Setenv(traceOTLPEndpointFlagEnvVarName, "")
+		c.Setenv(traceSampleRatioFlagEnvVarName, "")
+		c.Setenv(rateLimitAppFlagEnvVarName, "")
+		c.Setenv(rateLimitAppBurstFlagEnvVarName, "")
+		c.Setenv(rateLimitUserFlagEnvVarName, "")
+		c.Setenv(rateLimitUserBurstFlagEnvVarName, "")
+		c.Setenv(corsAllowedOriginsFlagEnvVarName, "")
+		c.Setenv(corsAllowedMethodsFlagEnvVarName, "")
+		c.Setenv(corsAllowedHeadersFlagEnvVarName, "")
+		c.Setenv(corsMaxAgeFlagEnvVarName, "")
+		c.Setenv(maxRequestBodyFlagEnvVarName, "")
+		c.Setenv(tlsCertFileFlagEnvVarName, "")
+		c.Setenv(tlsKeyFileFlagEnvVarName, "")
+		c.Setenv(tlsClientCAFileFlagEnvVarName, "")
+		c.Setenv(h2cFlagEnvVarName, "")
 		c.Log("Environment setup completed")
 	}
 
-	a1 := args{args: []string{"server", "-log-level=info", "-log-level-min=debug", "-log-error-stack", "-port=8080", "-db-host=localhost", "-db-port=5432", "-db-name=go_api_basic", "-db-user=postgres", "-db-password=sosecret", "-db-search-path=demo", "-encrypt-key=reallyGoodKey"}}
+	a1 := args{args: []string{"server", "-log-level=info", "-log-level-min=debug", "-log-error-stack", "-port=8080", "-metrics-port=9090", "-db-host=localhost", "-db-port=5432", "-db-name=go_api_basic", "-db-user=postgres", "-db-password=sosecret", "-db-search-path=demo", "-encrypt-key=reallyGoodKey", "-delete-retention=48h", "-blob-dir=/tmp/blobs", "-job-workers=0", "-job-poll-interval=1s", "-shutdown-grace-period=30s", "-shutdown-drain-delay=5s", "-trace-exporter=stdout", "-trace-sample-ratio=0.5", "-rate-limit-app=0", "-rate-limit-app-burst=1", "-rate-limit-user=30", "-rate-limit-user-burst=5", "-cors-allowed-origins=*", "-cors-max-age=0s", "-max-request-body=1024", "-tls-cert-file=/etc/tls/cert.pem", "-tls-key-file=/etc/tls/key.pem", "-tls-client-ca-file=/etc/tls/ca.pem"}}
 	f1 := flags{
-		target:        "dev",
-		loglvl:        "info",
-		logLvlMin:     "debug",
-		logErrorStack: true,
-		port:          8080,
-		dbhost:        "localhost",
-		dbport:        5432,
-		dbname:        "go_api_basic",
-		dbuser:        "postgres",
-		dbpassword:    "sosecret",
-		dbsearchpath:  "demo",
-		encryptkey:    "reallyGoodKey",
+		target:              "dev",
+		loglvl:              "info",
+		logLvlMin:           "debug",
+		logErrorStack:       true,
+		port:                8080,
+		metricsPort:         9090,
+		dbhost:              "localhost",
+		dbport:              5432,
+		dbname:              "go_api_basic",
+		dbuser:              "postgres",
+		dbpassword:          "sosecret",
+		dbsearchpath:        "demo",
+		encryptkey:          "reallyGoodKey",
+		deleteRetention:     48 * time.Hour,
+		blobDir:             "/tmp/blobs",
+		jobWorkers:          0,
+		jobPollInterval:     time.Second,
+		shutdownGracePeriod: 30 * time.Second,
+		shutdownDrainDelay:  5 * time.Second,
+		traceExporter:       "stdout",
+		traceSampleRatio:    0.5,
+		rateLimitApp:        0,
+		rateLimitAppBurst:   1,
+		rateLimitUser:       30,
+		rateLimitUserBurst:  5,
+		corsAllowedOrigins:  "*",
+		corsAllowedMethods:  corsAllowedMethodsFlagDefault,
+		corsAllowedHeaders:  corsAllowedHeadersFlagDefault,
+		corsMaxAge:          0,
+		maxRequestBody:      1024,
+		tlsCertFile:         "/etc/tls/cert.pem",
+		tlsKeyFile:          "/etc/tls/key.pem",
+		tlsClientCAFile:     "/etc/tls/ca.pem",
 	}
 
 	a2 := args{args: []string{"server"}}
 	f2 := flags{
-		target:        "dev",
-		loglvl:        "warn",
-		logLvlMin:     "debug",
-		logErrorStack: false,
-		port:          8081,
-		dbhost:        "hostwiththemost",
-		dbport:        5150,
-		dbname:        "whatisinaname",
-		dbuser:        "usersarelosers",
-		dbpassword:    "yeet",
-		dbsearchpath:  "u2",
-		encryptkey:    "reallyGoodKey",
+		target:              "dev",
+		loglvl:              "warn",
+		logLvlMin:           "debug",
+		logErrorStack:       false,
+		port:                8081,
+		metricsPort:         9091,
+		dbhost:              "hostwiththemost",
+		dbport:              5150,
+		dbname:              "whatisinaname",
+		dbuser:              "usersarelosers",
+		dbpassword:          "yeet",
+		dbsearchpath:        "u2",
+		encryptkey:          "reallyGoodKey",
+		deleteRetention:     72 * time.Hour,
+		blobDir:             "/var/lib/diygoapi/blobs",
+		jobWorkers:          8,
+		jobPollInterval:     service.DefaultJobPollInterval,
+		shutdownGracePeriod: 20 * time.Second,
+		shutdownDrainDelay:  10 * time.Second,
+		traceExporter:       "otlp",
+		traceOTLPEndpoint:   "http://collector:4318",
+		traceSampleRatio:    0.25,
+		rateLimitApp:        600,
+		rateLimitAppBurst:   50,
+		rateLimitUser:       60,
+		rateLimitUserBurst:  10,
+		corsAllowedOrigins:  "https://app.example.com",
+		corsAllowedMethods:  "GET",
+		corsAllowedHeaders:  "X-APP-ID",
+		corsMaxAge:          time.Hour,
+		maxRequestBody:      65536,
+		h2c:                 true,
 	}
 
 	a3 := args{args: []string{"server", "-log-level=error"}}
 	f3 := flags{
-		target:        "dev",
-		loglvl:        "error",
-		logLvlMin:     "debug",
-		logErrorStack: false,
-		port:          8081,
-		dbhost:        "hostwiththemost",
-		dbport:        5150,
-		dbname:        "whatisinaname",
-		dbuser:        "usersarelosers",
-		dbpassword:    "yeet",
-		dbsearchpath:  "u2",
-		encryptkey:    "reallyGoodKey",
+		target:              "dev",
+		loglvl:              "error",
+		logLvlMin:           "debug",
+		logErrorStack:       false,
+		port:                8081,
+		metricsPort:         9091,
+		dbhost:              "hostwiththemost",
+		dbport:              5150,
+		dbname:              "whatisinaname",
+		dbuser:              "usersarelosers",
+		dbpassword:          "yeet",
+		dbsearchpath:        "u2",
+		encryptkey:          "reallyGoodKey",
+		deleteRetention:     72 * time.Hour,
+		blobDir:             "/var/lib/diygoapi/blobs",
+		jobWorkers:          8,
+		jobPollInterval:     service.DefaultJobPollInterval,
+		shutdownGracePeriod: 20 * time.Second,
+		shutdownDrainDelay:  10 * time.Second,
+		traceExporter:       "otlp",
+		traceOTLPEndpoint:   "http://collector:4318",
+		traceSampleRatio:    0.25,
+		rateLimitApp:        600,
+		rateLimitAppBurst:   50,
+		rateLimitUser:       60,
+		rateLimitUserBurst:  10,
+		corsAllowedOrigins:  "https://app.example.com",
+		corsAllowedMethods:  "GET",
+		corsAllowedHeaders:  "X-APP-ID",
+		corsMaxAge:          time.Hour,
+		maxRequestBody:      65536,
+		h2c:                 true,
 	}
 
 	a4 := args{args: []string{"server", "-badflag=true"}}
@@ -125,16 +238,31 @@ func Test_newFlags(t *testing.T) {
 
 	a5 := args{args: []string{"server", "-log-level=debug", "-log-level-min=debug", "-log-error-stack", "-port=8080", "-db-host=localhost", "-db-port=5432", "-db-name=go_api_basic", "-db-user=postgres", "-db-password=sosecret"}}
 	f5 := flags{
-		target:        "dev",
-		loglvl:        "debug",
-		logLvlMin:     "debug",
-		logErrorStack: true,
-		port:          8080,
-		dbhost:        "localhost",
-		dbport:        5432,
-		dbname:        "go_api_basic",
-		dbuser:        "postgres",
-		dbpassword:    "sosecret",
+		target:              "dev",
+		loglvl:              "debug",
+		logLvlMin:           "debug",
+		logErrorStack:       true,
+		port:                8080,
+		dbhost:              "localhost",
+		dbport:              5432,
+		dbname:              "go_api_basic",
+		dbuser:              "postgres",
+		dbpassword:          "sosecret",
+		deleteRetention:     diygoapi.DefaultDeleteRetention,
+		blobDir:             blobDirFlagDefault,
+		jobWorkers:          service.DefaultJobConcurrency,
+		jobPollInterval:     service.DefaultJobPollInterval,
+		shutdownGracePeriod: shutdownGracePeriodFlagDefault,
+		traceExporter:       traceExporterFlagDefault,
+		traceSampleRatio:    traceSampleRatioFlagDefault,
+		rateLimitApp:        rateLimitAppFlagDefault,
+		rateLimitAppBurst:   rateLimitAppBurstFlagDefault,
+		rateLimitUser:       rateLimitUserFlagDefault,
+		rateLimitUserBurst:  rateLimitUserBurstFlagDefault,
+		corsAllowedMethods:  corsAllowedMethodsFlagDefault,
+		corsAllowedHeaders:  corsAllowedHeadersFlagDefault,
+		corsMaxAge:          corsMaxAgeFlagDefault,
+		maxRequestBody:      maxRequestBodyFlagDefault,
 	}
 
 	tests := []struct {
@@ -161,3 +289,175 @@ func Test_newFlags(t *testing.T) {
 		})
 	}
 }
+
+func Test_configJSONParser(t *testing.T) {
+	c := qt.New(t)
+	c.Setenv(targetFlagEnvVarName, "")
+
+	parse := func(config string) map[string]string {
+		got := make(map[string]string)
+		err := configJSONParser(strings.NewReader(config), func(name, value string) error {
+			got[name] = value
+			return nil
+		})
+		c.Assert(err, qt.IsNil)
+		return got
+	}
+
+	got := parse(`{"default_target": "local", "targets": [{"target": "local", "jobs": {"workers": 0, "poll_interval": "5s"}}]}`)
+	c.Assert(got[jobWorkersFlagName], qt.Equals, "0")
+	c.Assert(got[jobPollIntervalFlagName], qt.Equals, "5s")
+
+	got = parse(`{"default_target": "local", "targets": [{"target": "local"}]}`)
+	_, ok := got[jobWorkersFlagName]
+	c.Assert(ok, qt.IsFalse)
+	_, ok = got[traceSampleRatioFlagName]
+	c.Assert(ok, qt.IsFalse)
+
+	got = parse(`{"default_target": "local", "targets": [{"target": "local", "tracing": {"exporter": "otlp", "otlp_endpoint": "http://localhost:4318", "sample_ratio": 0}}]}`)
+	c.Assert(got[traceExporterFlagName], qt.Equals, "otlp")
+	c.Assert(got[traceOTLPEndpointFlagName], qt.Equals, "http://localhost:4318")
+	c.Assert(got[traceSampleRatioFlagName], qt.Equals, "0")
+
+	got = parse(`{"default_target": "local", "targets": [{"target": "local", "rate_limit": {"app": {"requests_per_minute": 0}, "user": {"requests_per_minute": 60, "burst": 10}}}]}`)
+	c.Assert(got[rateLimitAppFlagName], qt.Equals, "0")
+	_, ok = got[rateLimitAppBurstFlagName]
+	c.Assert(ok, qt.IsFalse)
+	c.Assert(got[rateLimitUserFlagName], qt.Equals, "60")
+	c.Assert(got[rateLimitUserBurstFlagName], qt.Equals, "10")
+
+	got = parse(`{"default_target": "local", "targets": [{"target": "local", "cors": {"allowed_origins": ["https://app.example.com", "http://localhost:3000"], "max_age": "1m"}}]}`)
+	c.Assert(got[corsAllowedOriginsFlagName], qt.Equals, "https://app.example.com,http://localhost:3000")
+	c.Assert(got[corsMaxAgeFlagName], qt.Equals, "1m")
+	_, ok = got[corsAllowedMethodsFlagName]
+	c.Assert(ok, qt.IsFalse)
+	_, ok = got[maxRequestBodyFlagName]
+	c.Assert(ok, qt.IsFalse)
+
+	got = parse(`{"default_target": "local", "targets": [{"target": "local", "max_request_body": 4194304}]}`)
+	c.Assert(got[maxRequestBodyFlagName], qt.Equals, "4194304")
+
+	got = parse(`{"default_target": "local", "targets": [{"target": "local", "tls": {"cert_file": "/etc/tls/cert.pem", "key_file": "/etc/tls/key.pem"}}]}`)
+	c.Assert(got[tlsCertFileFlagName], qt.Equals, "/etc/tls/cert.pem")
+	c.Assert(got[tlsKeyFileFlagName], qt.Equals, "/etc/tls/key.pem")
+	_, ok = got[tlsClientCAFileFlagName]
+	c.Assert(ok, qt.IsFalse)
+	c.Assert(got[h2cFlagName], qt.Equals, "false")
+}
+
+// recordingDriver is a driver.Server which records when it is shut down
+type recordingDriver struct {
+	steps *[]string
+	err   error
+}
+
+func (d recordingDriver) ListenAndServe(addr string, h http.Handler) error { return nil }
+
+func (d recordingDriver) Shutdown(ctx context.Context) error {
+	*d.steps = append(*d.steps, "server")
+	return d.err
+}
+
+func Test_shutdown(t *testing.T) {
+	t.Run("in order", func(t *testing.T) {
+		c := qt.New(t)
+
+		var steps []string
+		s := server.New(http.NewServeMux(), recordingDriver{steps: &steps}, zerolog.Nop())
+
+		err := shutdown(s,
+			func() { steps = append(steps, "background") },
+			func() { steps = append(steps, "pool") },
+			time.Second, zerolog.Nop())
+		c.Assert(err, qt.IsNil)
+		c.Assert(steps, qt.DeepEquals, []string{"server", "background", "pool"})
+	})
+	t.Run("grace period exceeded", func(t *testing.T) {
+		c := qt.New(t)
+
+		var steps []string
+		s := server.New(http.NewServeMux(), recordingDriver{steps: &steps, err: context.DeadlineExceeded}, zerolog.Nop())
+
+		err := shutdown(s,
+			func() { steps = append(steps, "background") },
+			func() { steps = append(steps, "pool") },
+			time.Second, zerolog.Nop())
+		c.Assert(errors.Is(err, context.DeadlineExceeded), qt.IsTrue)
+		// the pool is still closed
+		c.Assert(steps, qt.DeepEquals, []string{"server", "background", "pool"})
+	})
+}
+
+func Test_schemaVersion(t *testing.T) {
+	c := qt.New(t)
+
+	// sqldb.SchemaVersion must be bumped along with each up migration,
+	// or the server is never ready
+	ddlFiles, err := readDDLFiles("../scripts/db/migrations/up")
+	c.Assert(err, qt.IsNil)
+	c.Assert(int32(ddlFiles[len(ddlFiles)-1].fileNumber), qt.Equals, sqldb.SchemaVersion)
+}
+
+func Test_ddlArgs(t *testing.T) {
+	const dir = "../scripts/db/migrations/up"
+
+	c := qt.New(t)
+
+	ddlFiles, err := readDDLFiles(dir)
+	c.Assert(err, qt.IsNil)
+
+	conn := []string{"-w", "-d", "postgresql://demo_user@localhost:5432/dga_local"}
+	got, err := ddlArgs(conn, dir, true)
+	c.Assert(err, qt.IsNil)
+
+	// psql stops at the first failed file, before the version is stamped
+	c.Assert(got[:7], qt.DeepEquals, []string{"-w", "-d", conn[2], "-v", "ON_ERROR_STOP=1", "-q", "-c"})
+	c.Assert(got[8], qt.Equals, "-f")
+	c.Assert(got[9], qt.Equals, dir+"/"+ddlFiles[0].filename)
+	c.Assert(got[len(got)-2], qt.Equals, "-c")
+	c.Assert(got[len(got)-1], qt.Equals, fmt.Sprintf(stampSchemaVersionSQL, ddlFiles[len(ddlFiles)-1].fileNumber))
+	c.Assert(got, qt.HasLen, 8+2*len(ddlFiles)+2)
+}
+
+func Test_newCORS(t *testing.T) {
+	c := qt.New(t)
+
+	cors := newCORS(flags{
+		corsAllowedOrigins: "HTTPS://App.Example.com/, http://localhost:3000",
+		corsAllowedMethods: "GET,POST",
+		corsAllowedHeaders: "X-APP-ID, X-API-KEY",
+		corsMaxAge:         time.Minute,
+	})
+	c.Assert(cors, qt.DeepEquals, &server.CORS{
+		AllowedOrigins: []string{"https://app.example.com", "http://localhost:3000"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"X-APP-ID", "X-API-KEY"},
+		MaxAge:         time.Minute,
+	})
+}
+
+func Test_newWebhookAllowedNetworks(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(newWebhookAllowedNetworks(flags{}), qt.IsNil)
+
+	networks := newWebhookAllowedNetworks(flags{webhookAllowedNetworks: "127.0.0.0/8, ::1/128"})
+	c.Assert(networks, qt.HasLen, 2)
+	c.Assert(networks[0], qt.Equals, netip.MustParsePrefix("127.0.0.0/8"))
+	c.Assert(networks[1], qt.Equals, netip.MustParsePrefix("::1/128"))
+}
+
+func Test_newTLS(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(newTLS(flags{h2c: true}), qt.IsNil)
+	c.Assert(newTLS(flags{
+		tlsCertFile:     "/etc/tls/cert.pem",
+		tlsKeyFile:      "/etc/tls/key.pem",
+		tlsClientCAFile: "/etc/tls/ca.pem",
+	}), qt.DeepEquals, &server.TLS{
+		CertFile:     "/etc/tls/cert.pem",
+		KeyFile:      "/etc/tls/key.pem",
+		ClientCAFile: "/etc/tls/ca.pem",
+	})
+}
@@ -106,9 +106,17 @@ func PSQLConnectionArgs(args []string) ([]string, error) {
 	return psqlArgs, nil
 }
 
+// stampSchemaVersionSQL sets the schema version to the number of the
+// latest up migration file
+const stampSchemaVersionSQL = `insert into schema_version (version, update_timestamp)
+values (%d, now())
+on conflict (schema_version_id) do update set version          = excluded.version,
+                                              update_timestamp = excluded.update_timestamp`
+
 // PSQLArgs builds command line arguments for psql to execute DDL migration
 // files. It delegates connection logic to PSQLConnectionArgs and appends
-// a connectivity check followed by -f flags for each DDL file.
+// a connectivity check followed by -f flags for each DDL file. Up migrations
+// end by stamping the schema version.
 func PSQLArgs(up bool, args []string) ([]string, error) {
 	const op errs.Op = "cmd/PSQLArgs"
 
@@ -119,20 +127,36 @@ func PSQLArgs(up bool, args []string) ([]string, error) {
 		dir += "/down"
 	}
 
-	ddlFiles, err := readDDLFiles(dir)
+	psqlArgs, err := PSQLConnectionArgs(args)
 	if err != nil {
 		return nil, errs.E(op, err)
 	}
 
-	if len(ddlFiles) == 0 {
-		return nil, errs.E(op, fmt.Sprintf("there are no DDL files to process in %s", dir))
+	psqlArgs, err = ddlArgs(psqlArgs, dir, up)
+	if err != nil {
+		return nil, errs.E(op, err)
 	}
 
-	psqlArgs, err := PSQLConnectionArgs(args)
+	return psqlArgs, nil
+}
+
+// ddlArgs appends the psql arguments to run the DDL files in dir to the
+// connection arguments. psql is set to stop at the first error, so a
+// failed file leaves the rest unrun and the schema version unstamped.
+func ddlArgs(psqlArgs []string, dir string, up bool) ([]string, error) {
+	const op errs.Op = "cmd/ddlArgs"
+
+	ddlFiles, err := readDDLFiles(dir)
 	if err != nil {
 		return nil, errs.E(op, err)
 	}
 
+	if len(ddlFiles) == 0 {
+		return nil, errs.E(op, fmt.Sprintf("there are no DDL files to process in %s", dir))
+	}
+
+	psqlArgs = append(psqlArgs, "-v", "ON_ERROR_STOP=1")
+
 	// verify connectivity before running DDL files
 	psqlArgs = append(psqlArgs, "-q", "-c", "select current_database(), current_user, version()")
 
@@ -140,5 +164,11 @@ func PSQLArgs(up bool, args []string) ([]string, error) {
 		psqlArgs = append(psqlArgs, "-f", dir+"/"+file.filename)
 	}
 
+	// record the latest migration run, which the readiness check
+	// compares against sqldb.SchemaVersion
+	if up {
+		psqlArgs = append(psqlArgs, "-c", fmt.Sprintf(stampSchemaVersionSQL, ddlFiles[len(ddlFiles)-1].fileNumber))
+	}
+
 	return psqlArgs, nil
 }
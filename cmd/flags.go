@@ -5,13 +5,20 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"net/netip"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/peterbourgon/ff/v3"
 
+	"github.com/gilcrest/diygoapi"
 	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/server"
+	"github.com/gilcrest/diygoapi/service"
 	"github.com/gilcrest/diygoapi/sqldb"
+	"github.com/gilcrest/diygoapi/tracing"
 )
 
 const (
@@ -39,6 +46,11 @@ const (
 	listenPorFlagDefault     = 8080
 	listenPortFlagEnvVarName = "PORT"
 
+	// zero serves /metrics on the listen port along with the API
+	metricsPortFlagName       = "metrics-port"
+	metricsPortFlagDefault    = 0
+	metricsPortFlagEnvVarName = "METRICS_PORT"
+
 	dbHostFlagName    = "db-host"
 	dbHostFlagDefault = "localhost"
 
@@ -60,6 +72,109 @@ const (
 	encryptKeyFlagName       = "encrypt-key"
 	encryptKeyFlagDefault    = ""
 	encryptKeyFlagEnvVarName = "ENCRYPT_KEY"
+
+	deleteRetentionFlagName       = "delete-retention"
+	deleteRetentionFlagDefault    = diygoapi.DefaultDeleteRetention
+	deleteRetentionFlagEnvVarName = "DELETE_RETENTION"
+
+	blobDirFlagName       = "blob-dir"
+	blobDirFlagDefault    = "./data/blobs"
+	blobDirFlagEnvVarName = "BLOB_DIR"
+
+	jobWorkersFlagName       = "job-workers"
+	jobWorkersFlagDefault    = service.DefaultJobConcurrency
+	jobWorkersFlagEnvVarName = "JOB_WORKERS"
+
+	jobPollIntervalFlagName       = "job-poll-interval"
+	jobPollIntervalFlagDefault    = service.DefaultJobPollInterval
+	jobPollIntervalFlagEnvVarName = "JOB_POLL_INTERVAL"
+
+	// the default leaves time to close the database pool within the
+	// 10 seconds Cloud Run allows after sending SIGTERM
+	shutdownGracePeriodFlagName       = "shutdown-grace-period"
+	shutdownGracePeriodFlagDefault    = 8 * time.Second
+	shutdownGracePeriodFlagEnvVarName = "SHUTDOWN_GRACE_PERIOD"
+
+	shutdownDrainDelayFlagName       = "shutdown-drain-delay"
+	shutdownDrainDelayFlagDefault    = time.Duration(0)
+	shutdownDrainDelayFlagEnvVarName = "SHUTDOWN_DRAIN_DELAY"
+
+	traceExporterFlagName       = "trace-exporter"
+	traceExporterFlagDefault    = tracing.NoExporter
+	traceExporterFlagEnvVarName = "TRACE_EXPORTER"
+
+	// empty uses OTEL_EXPORTER_OTLP_ENDPOINT or the OpenTelemetry default
+	traceOTLPEndpointFlagName       = "trace-otlp-endpoint"
+	traceOTLPEndpointFlagDefault    = ""
+	traceOTLPEndpointFlagEnvVarName = "TRACE_OTLP_ENDPOINT"
+
+	traceSampleRatioFlagName       = "trace-sample-ratio"
+	traceSampleRatioFlagDefault    = 1.0
+	traceSampleRatioFlagEnvVarName = "TRACE_SAMPLE_RATIO"
+
+	// default rate limits, which can be overridden for an app in the
+	// app_rate_limit table. Zero requests per minute is no limit.
+	rateLimitAppFlagName       = "rate-limit-app"
+	rateLimitAppFlagDefault    = 1200
+	rateLimitAppFlagEnvVarName = "RATE_LIMIT_APP"
+
+	rateLimitAppBurstFlagName       = "rate-limit-app-burst"
+	rateLimitAppBurstFlagDefault    = 100
+	rateLimitAppBurstFlagEnvVarName = "RATE_LIMIT_APP_BURST"
+
+	rateLimitUserFlagName       = "rate-limit-user"
+	rateLimitUserFlagDefault    = 0
+	rateLimitUserFlagEnvVarName = "RATE_LIMIT_USER"
+
+	rateLimitUserBurstFlagName       = "rate-limit-user-burst"
+	rateLimitUserBurstFlagDefault    = 20
+	rateLimitUserBurstFlagEnvVarName = "RATE_LIMIT_USER_BURST"
+
+	// CORS lists are comma separated. With no allowed origins, only
+	// origins allowed by an app in the app_allowed_origin table are.
+	corsAllowedOriginsFlagName       = "cors-allowed-origins"
+	corsAllowedOriginsFlagDefault    = ""
+	corsAllowedOriginsFlagEnvVarName = "CORS_ALLOWED_ORIGINS"
+
+	corsAllowedMethodsFlagName       = "cors-allowed-methods"
+	corsAllowedMethodsFlagDefault    = "GET,POST,PUT,DELETE"
+	corsAllowedMethodsFlagEnvVarName = "CORS_ALLOWED_METHODS"
+
+	corsAllowedHeadersFlagName       = "cors-allowed-headers"
+	corsAllowedHeadersFlagDefault    = "Authorization,Content-Type,If-None-Match," + diygoapi.AppIDHeaderKey + "," + diygoapi.ApiKeyHeaderKey + "," + diygoapi.AuthProviderHeaderKey
+	corsAllowedHeadersFlagEnvVarName = "CORS_ALLOWED_HEADERS"
+
+	corsMaxAgeFlagName       = "cors-max-age"
+	corsMaxAgeFlagDefault    = 10 * time.Minute
+	corsMaxAgeFlagEnvVarName = "CORS_MAX_AGE"
+
+	// webhooks are only sent to public addresses, unless in one of
+	// the comma separated networks allowed, e.g. for local development
+	webhookAllowedNetworksFlagName       = "webhook-allowed-networks"
+	webhookAllowedNetworksFlagDefault    = ""
+	webhookAllowedNetworksFlagEnvVarName = "WEBHOOK_ALLOWED_NETWORKS"
+
+	maxRequestBodyFlagName       = "max-request-body"
+	maxRequestBodyFlagDefault    = server.DefaultMaxRequestBodyBytes
+	maxRequestBodyFlagEnvVarName = "MAX_REQUEST_BODY"
+
+	// The server is in TLS mode if a certificate and key are given,
+	// otherwise it serves plain HTTP.
+	tlsCertFileFlagName       = "tls-cert-file"
+	tlsCertFileFlagDefault    = ""
+	tlsCertFileFlagEnvVarName = "TLS_CERT_FILE"
+
+	tlsKeyFileFlagName       = "tls-key-file"
+	tlsKeyFileFlagDefault    = ""
+	tlsKeyFileFlagEnvVarName = "TLS_KEY_FILE"
+
+	tlsClientCAFileFlagName       = "tls-client-ca-file"
+	tlsClientCAFileFlagDefault    = ""
+	tlsClientCAFileFlagEnvVarName = "TLS_CLIENT_CA_FILE"
+
+	h2cFlagName       = "h2c"
+	h2cFlagDefault    = false
+	h2cFlagEnvVarName = "H2C"
 )
 
 type flags struct {
@@ -87,6 +202,10 @@ type flags struct {
 	// port flag is what http.ListenAndServe will listen on. default is 8080 if not set
 	port int
 
+	// metricsPort is the port /metrics is served on, if it is not
+	// served on port with the API
+	metricsPort int
+
 	// dbhost is the database host
 	dbhost string
 
@@ -107,6 +226,92 @@ type flags struct {
 
 	// encryptkey is the encryption key
 	encryptkey string
+
+	// deleteRetention is how long deleted records can be restored
+	// before they are eligible to be purged
+	deleteRetention time.Duration
+
+	// blobDir is the directory the local blob store keeps
+	// uploaded files, e.g. movie posters, in
+	blobDir string
+
+	// jobWorkers is the number of background jobs run at once. Zero
+	// runs no job workers in this process.
+	jobWorkers int
+
+	// jobPollInterval is how long an idle job worker waits before
+	// looking for a job again
+	jobPollInterval time.Duration
+
+	// shutdownGracePeriod is how long in-flight requests and running
+	// jobs are given to finish once a shutdown signal is received
+	shutdownGracePeriod time.Duration
+
+	// shutdownDrainDelay is how long readiness fails before the
+	// server stops accepting connections on shutdown
+	shutdownDrainDelay time.Duration
+
+	// traceExporter is where spans are sent: none, stdout or otlp
+	traceExporter string
+
+	// traceOTLPEndpoint is the URL of the OpenTelemetry collector
+	// spans are sent to by the otlp exporter
+	traceOTLPEndpoint string
+
+	// traceSampleRatio is the fraction of new traces which are sampled
+	traceSampleRatio float64
+
+	// rateLimitApp is the default number of requests a minute allowed
+	// for each app, zero for no limit
+	rateLimitApp int
+
+	// rateLimitAppBurst is the default number of requests allowed at
+	// once for each app
+	rateLimitAppBurst int
+
+	// rateLimitUser is the default number of requests a minute allowed
+	// for each user of an app, zero for no limit
+	rateLimitUser int
+
+	// rateLimitUserBurst is the default number of requests allowed at
+	// once for each user of an app
+	rateLimitUserBurst int
+
+	// corsAllowedOrigins are the comma separated origins browser
+	// clients may call the API from, unless an app allows its own
+	corsAllowedOrigins string
+
+	// corsAllowedMethods are the comma separated methods browser
+	// clients may use
+	corsAllowedMethods string
+
+	// corsAllowedHeaders are the comma separated request headers
+	// browser clients may send
+	corsAllowedHeaders string
+
+	// corsMaxAge is how long browsers may cache a preflight response
+	corsMaxAge time.Duration
+
+	// webhookAllowedNetworks are the comma separated CIDR networks
+	// webhooks may be sent to, which would otherwise not be allowed
+	// as they are not public
+	webhookAllowedNetworks string
+
+	// maxRequestBody is the size in bytes JSON request bodies are
+	// limited to, on routes without a limit of their own
+	maxRequestBody int64
+
+	// tlsCertFile and tlsKeyFile are the certificate and key files
+	// served in TLS mode
+	tlsCertFile string
+	tlsKeyFile  string
+
+	// tlsClientCAFile is the CA certificates file client certificates
+	// are verified against in TLS mode, if any
+	tlsClientCAFile string
+
+	// h2c serves unencrypted HTTP/2 in plain mode
+	h2c bool
 }
 
 // validateDBConnection validates only the fields required for a database connection.
@@ -156,6 +361,15 @@ func (f *flags) Validate() error {
 		return err
 	}
 
+	// validate metrics port in acceptable range and not the API port
+	err = portRange(f.metricsPort)
+	if err != nil {
+		return err
+	}
+	if f.metricsPort != 0 && f.metricsPort == f.port {
+		return errs.E(op, "metrics port must be zero or differ from the listen port")
+	}
+
 	// validate encryption key is not empty
 	if f.encryptkey == "" {
 		return errs.E(op, "encryption key is required")
@@ -171,6 +385,85 @@ func (f *flags) Validate() error {
 		return errs.E(op, "minimum log level is required")
 	}
 
+	// validate delete retention is positive
+	if f.deleteRetention <= 0 {
+		return errs.E(op, "delete retention must be greater than zero")
+	}
+
+	// validate blob store directory is not empty
+	if f.blobDir == "" {
+		return errs.E(op, "blob store directory is required")
+	}
+
+	// validate job worker settings
+	if f.jobWorkers < 0 {
+		return errs.E(op, "job workers cannot be negative")
+	}
+	if f.jobPollInterval <= 0 {
+		return errs.E(op, "job poll interval must be greater than zero")
+	}
+
+	// validate shutdown grace period is positive
+	if f.shutdownGracePeriod <= 0 {
+		return errs.E(op, "shutdown grace period must be greater than zero")
+	}
+
+	if f.shutdownDrainDelay < 0 {
+		return errs.E(op, "shutdown drain delay must not be negative")
+	}
+
+	// validate tracing settings
+	switch f.traceExporter {
+	case tracing.NoExporter, tracing.StdoutExporter, tracing.OTLPExporter:
+	default:
+		return errs.E(op, fmt.Sprintf("trace exporter must be one of %s, %s or %s", tracing.NoExporter, tracing.StdoutExporter, tracing.OTLPExporter))
+	}
+	if f.traceSampleRatio < 0 || f.traceSampleRatio > 1 {
+		return errs.E(op, "trace sample ratio must be between 0 and 1")
+	}
+
+	// validate default rate limits
+	if f.rateLimitApp < 0 || f.rateLimitUser < 0 {
+		return errs.E(op, "rate limits cannot be negative")
+	}
+	if f.rateLimitAppBurst < 1 || f.rateLimitUserBurst < 1 {
+		return errs.E(op, "rate limit bursts must be at least 1")
+	}
+
+	// validate CORS settings
+	for _, origin := range splitList(f.corsAllowedOrigins) {
+		_, err = diygoapi.ParseOrigin(origin)
+		if err != nil {
+			return errs.E(op, fmt.Sprintf("invalid CORS allowed origin %q, must be a scheme and host, e.g. https://app.example.com, or *", origin))
+		}
+	}
+	if f.corsMaxAge < 0 {
+		return errs.E(op, "CORS max age cannot be negative")
+	}
+
+	// validate webhook allowed networks
+	for _, n := range splitList(f.webhookAllowedNetworks) {
+		_, err = netip.ParsePrefix(n)
+		if err != nil {
+			return errs.E(op, fmt.Sprintf("invalid webhook allowed network %q, must be in CIDR notation, e.g. 127.0.0.0/8", n))
+		}
+	}
+
+	if f.maxRequestBody < 1 {
+		return errs.E(op, "max request body must be at least 1 byte")
+	}
+
+	// validate TLS settings
+	if (f.tlsCertFile == "") != (f.tlsKeyFile == "") {
+		return errs.E(op, "TLS certificate and key files must be given together")
+	}
+	if f.tlsCertFile == "" && f.tlsClientCAFile != "" {
+		return errs.E(op, "TLS client CA file requires a TLS certificate and key")
+	}
+	if f.tlsCertFile != "" && f.h2c {
+		return errs.E(op, "h2c cannot be served in TLS mode, where HTTP/2 is negotiated")
+	}
+
 	// validate database connection fields
 	err = f.validateDBConnection()
 	if err != nil {
@@ -188,19 +481,43 @@ func newFlags(args []string) (flags, error) {
 	// as the name of the FlagSet
 	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
 	var (
-		target        = fs.String(targetFlagName, targetFlagDefault, fmt.Sprintf("target to run (also via %s)", targetFlagEnvVarName))
-		logLvlMin     = fs.String(logLevelMinFlagName, logLevelMinFlagDefault, fmt.Sprintf("sets minimum log level (trace, debug, info, warn, error, fatal, panic, disabled), (also via %s)", logLevelMinFlagEnvVarName))
-		loglvl        = fs.String(loglevelFlagName, loglevelFlagDefault, fmt.Sprintf("sets log level (trace, debug, info, warn, error, fatal, panic, disabled), (also via %s)", loglevelFlagEnvVarName))
-		logErrorStack = fs.Bool(logErrorStackFlagName, logErrorStackFlagDefault, fmt.Sprintf("if true, log full error stacktrace using github.com/pkg/errors, else just log error, (also via %s)", logErrorStackFlagEnvVarName))
-		port          = fs.Int(listenPortFlagName, listenPorFlagDefault, fmt.Sprintf("listen port for server (also via %s)", listenPortFlagEnvVarName))
-		dbhost        = fs.String(dbHostFlagName, dbHostFlagDefault, fmt.Sprintf("postgresql database host (also via %s)", sqldb.DBHostEnv))
-		dbport        = fs.Int(dbPortFlagName, dbPortFlagDefault, fmt.Sprintf("postgresql database port (also via %s)", sqldb.DBPortEnv))
-		dbname        = fs.String(dbNameFlagName, dbNameFlagDefault, fmt.Sprintf("postgresql database name (also via %s)", sqldb.DBNameEnv))
-		dbuser        = fs.String(dbUserFlagName, dbUserFlagDefault, fmt.Sprintf("postgresql database user (also via %s)", sqldb.DBUserEnv))
-		dbpassword    = fs.String(dbPasswordFlagName, dbPasswordFlagDefault, fmt.Sprintf("postgresql database password (also via %s)", sqldb.DBPasswordEnv))
-		dbsearchpath  = fs.String(dbSearchPathFlagName, dbSearchPathFlagDefault, fmt.Sprintf("postgresql database search path (also via %s)", sqldb.DBSearchPathEnv))
-		encryptkey    = fs.String(encryptKeyFlagName, encryptKeyFlagDefault, fmt.Sprintf("encryption key (also via %s)", encryptKeyFlagEnvVarName))
-		_             = fs.String(configFileFlagName, configFileFlagNameDefault, fmt.Sprintf("JSON configuration file (also via %s)", configFileFlagNameEnvVar))
+		target                 = fs.String(targetFlagName, targetFlagDefault, fmt.Sprintf("target to run (also via %s)", targetFlagEnvVarName))
+		logLvlMin              = fs.String(logLevelMinFlagName, logLevelMinFlagDefault, fmt.Sprintf("sets minimum log level (trace, debug, info, warn, error, fatal, panic, disabled), (also via %s)", logLevelMinFlagEnvVarName))
+		loglvl                 = fs.String(loglevelFlagName, loglevelFlagDefault, fmt.Sprintf("sets log level (trace, debug, info, warn, error, fatal, panic, disabled), (also via %s)", loglevelFlagEnvVarName))
+		logErrorStack          = fs.Bool(logErrorStackFlagName, logErrorStackFlagDefault, fmt.Sprintf("if true, log full error stacktrace using github.com/pkg/errors, else just log error, (also via %s)", logErrorStackFlagEnvVarName))
+		port                   = fs.Int(listenPortFlagName, listenPorFlagDefault, fmt.Sprintf("listen port for server (also via %s)", listenPortFlagEnvVarName))
+		metricsPort            = fs.Int(metricsPortFlagName, metricsPortFlagDefault, fmt.Sprintf("port to serve /metrics on, 0 serves it on the listen port (also via %s)", metricsPortFlagEnvVarName))
+		dbhost                 = fs.String(dbHostFlagName, dbHostFlagDefault, fmt.Sprintf("postgresql database host (also via %s)", sqldb.DBHostEnv))
+		dbport                 = fs.Int(dbPortFlagName, dbPortFlagDefault, fmt.Sprintf("postgresql database port (also via %s)", sqldb.DBPortEnv))
+		dbname                 = fs.String(dbNameFlagName, dbNameFlagDefault, fmt.Sprintf("postgresql database name (also via %s)", sqldb.DBNameEnv))
+		dbuser                 = fs.String(dbUserFlagName, dbUserFlagDefault, fmt.Sprintf("postgresql database user (also via %s)", sqldb.DBUserEnv))
+		dbpassword             = fs.String(dbPasswordFlagName, dbPasswordFlagDefault, fmt.Sprintf("postgresql database password (also via %s)", sqldb.DBPasswordEnv))
+		dbsearchpath           = fs.String(dbSearchPathFlagName, dbSearchPathFlagDefault, fmt.Sprintf("postgresql database search path (also via %s)", sqldb.DBSearchPathEnv))
+		encryptkey             = fs.String(encryptKeyFlagName, encryptKeyFlagDefault, fmt.Sprintf("encryption key (also via %s)", encryptKeyFlagEnvVarName))
+		deleteRetention        = fs.Duration(deleteRetentionFlagName, deleteRetentionFlagDefault, fmt.Sprintf("how long deleted records can be restored before they are purged (also via %s)", deleteRetentionFlagEnvVarName))
+		blobDir                = fs.String(blobDirFlagName, blobDirFlagDefault, fmt.Sprintf("directory uploaded files are stored in (also via %s)", blobDirFlagEnvVarName))
+		jobWorkers             = fs.Int(jobWorkersFlagName, jobWorkersFlagDefault, fmt.Sprintf("number of background jobs run at once, 0 runs no job workers (also via %s)", jobWorkersFlagEnvVarName))
+		jobPollInterval        = fs.Duration(jobPollIntervalFlagName, jobPollIntervalFlagDefault, fmt.Sprintf("how long an idle job worker waits before looking for a job again (also via %s)", jobPollIntervalFlagEnvVarName))
+		shutdownGracePeriod    = fs.Duration(shutdownGracePeriodFlagName, shutdownGracePeriodFlagDefault, fmt.Sprintf("how long in-flight requests and running jobs are given to finish on shutdown (also via %s)", shutdownGracePeriodFlagEnvVarName))
+		shutdownDrainDelay     = fs.Duration(shutdownDrainDelayFlagName, shutdownDrainDelayFlagDefault, fmt.Sprintf("how long readiness fails on shutdown before connections stop being accepted (also via %s)", shutdownDrainDelayFlagEnvVarName))
+		traceExporter          = fs.String(traceExporterFlagName, traceExporterFlagDefault, fmt.Sprintf("where trace spans are sent (none, stdout, otlp) (also via %s)", traceExporterFlagEnvVarName))
+		traceOTLPEndpoint      = fs.String(traceOTLPEndpointFlagName, traceOTLPEndpointFlagDefault, fmt.Sprintf("URL of the OpenTelemetry collector for the otlp trace exporter (also via %s)", traceOTLPEndpointFlagEnvVarName))
+		traceSampleRatio       = fs.Float64(traceSampleRatioFlagName, traceSampleRatioFlagDefault, fmt.Sprintf("fraction of new traces sampled, from 0 to 1 (also via %s)", traceSampleRatioFlagEnvVarName))
+		rateLimitApp           = fs.Int(rateLimitAppFlagName, rateLimitAppFlagDefault, fmt.Sprintf("default requests a minute allowed for each app, 0 for no limit (also via %s)", rateLimitAppFlagEnvVarName))
+		rateLimitAppBurst      = fs.Int(rateLimitAppBurstFlagName, rateLimitAppBurstFlagDefault, fmt.Sprintf("default requests allowed at once for each app (also via %s)", rateLimitAppBurstFlagEnvVarName))
+		rateLimitUser          = fs.Int(rateLimitUserFlagName, rateLimitUserFlagDefault, fmt.Sprintf("default requests a minute allowed for each user of an app, 0 for no limit (also via %s)", rateLimitUserFlagEnvVarName))
+		rateLimitUserBurst     = fs.Int(rateLimitUserBurstFlagName, rateLimitUserBurstFlagDefault, fmt.Sprintf("default requests allowed at once for each user of an app (also via %s)", rateLimitUserBurstFlagEnvVarName))
+		corsAllowedOrigins     = fs.String(corsAllowedOriginsFlagName, corsAllowedOriginsFlagDefault, fmt.Sprintf("comma separated origins browser clients may call the API from, * for any (also via %s)", corsAllowedOriginsFlagEnvVarName))
+		corsAllowedMethods     = fs.String(corsAllowedMethodsFlagName, corsAllowedMethodsFlagDefault, fmt.Sprintf("comma separated methods browser clients may use (also via %s)", corsAllowedMethodsFlagEnvVarName))
+		corsAllowedHeaders     = fs.String(corsAllowedHeadersFlagName, corsAllowedHeadersFlagDefault, fmt.Sprintf("comma separated request headers browser clients may send (also via %s)", corsAllowedHeadersFlagEnvVarName))
+		corsMaxAge             = fs.Duration(corsMaxAgeFlagName, corsMaxAgeFlagDefault, fmt.Sprintf("how long browsers may cache a CORS preflight response (also via %s)", corsMaxAgeFlagEnvVarName))
+		webhookAllowedNetworks = fs.String(webhookAllowedNetworksFlagName, webhookAllowedNetworksFlagDefault, fmt.Sprintf("comma separated CIDR networks webhooks may be sent to, though not public (also via %s)", webhookAllowedNetworksFlagEnvVarName))
+		maxRequestBody         = fs.Int64(maxRequestBodyFlagName, maxRequestBodyFlagDefault, fmt.Sprintf("size in bytes JSON request bodies are limited to (also via %s)", maxRequestBodyFlagEnvVarName))
+		tlsCertFile            = fs.String(tlsCertFileFlagName, tlsCertFileFlagDefault, fmt.Sprintf("PEM certificate file, serves HTTPS if given with the key file (also via %s)", tlsCertFileFlagEnvVarName))
+		tlsKeyFile             = fs.String(tlsKeyFileFlagName, tlsKeyFileFlagDefault, fmt.Sprintf("PEM private key file of the TLS certificate (also via %s)", tlsKeyFileFlagEnvVarName))
+		tlsClientCAFile        = fs.String(tlsClientCAFileFlagName, tlsClientCAFileFlagDefault, fmt.Sprintf("PEM CA certificates file, requires client certificates issued by them (also via %s)", tlsClientCAFileFlagEnvVarName))
+		h2c                    = fs.Bool(h2cFlagName, h2cFlagDefault, fmt.Sprintf("serve unencrypted HTTP/2 when not serving TLS (also via %s)", h2cFlagEnvVarName))
+		_                      = fs.String(configFileFlagName, configFileFlagNameDefault, fmt.Sprintf("JSON configuration file (also via %s)", configFileFlagNameEnvVar))
 	)
 
 	// Parse the command line flags from above
@@ -214,18 +531,42 @@ func newFlags(args []string) (flags, error) {
 	}
 
 	return flags{
-		target:        *target,
-		loglvl:        *loglvl,
-		logLvlMin:     *logLvlMin,
-		logErrorStack: *logErrorStack,
-		port:          *port,
-		dbhost:        *dbhost,
-		dbport:        *dbport,
-		dbname:        *dbname,
-		dbuser:        *dbuser,
-		dbpassword:    *dbpassword,
-		dbsearchpath:  *dbsearchpath,
-		encryptkey:    *encryptkey,
+		target:                 *target,
+		loglvl:                 *loglvl,
+		logLvlMin:              *logLvlMin,
+		logErrorStack:          *logErrorStack,
+		port:                   *port,
+		metricsPort:            *metricsPort,
+		dbhost:                 *dbhost,
+		dbport:                 *dbport,
+		dbname:                 *dbname,
+		dbuser:                 *dbuser,
+		dbpassword:             *dbpassword,
+		dbsearchpath:           *dbsearchpath,
+		encryptkey:             *encryptkey,
+		deleteRetention:        *deleteRetention,
+		blobDir:                *blobDir,
+		jobWorkers:             *jobWorkers,
+		jobPollInterval:        *jobPollInterval,
+		shutdownGracePeriod:    *shutdownGracePeriod,
+		shutdownDrainDelay:     *shutdownDrainDelay,
+		traceExporter:          *traceExporter,
+		traceOTLPEndpoint:      *traceOTLPEndpoint,
+		traceSampleRatio:       *traceSampleRatio,
+		rateLimitApp:           *rateLimitApp,
+		rateLimitAppBurst:      *rateLimitAppBurst,
+		rateLimitUser:          *rateLimitUser,
+		rateLimitUserBurst:     *rateLimitUserBurst,
+		corsAllowedOrigins:     *corsAllowedOrigins,
+		corsAllowedMethods:     *corsAllowedMethods,
+		corsAllowedHeaders:     *corsAllowedHeaders,
+		corsMaxAge:             *corsMaxAge,
+		webhookAllowedNetworks: *webhookAllowedNetworks,
+		maxRequestBody:         *maxRequestBody,
+		tlsCertFile:            *tlsCertFile,
+		tlsKeyFile:             *tlsKeyFile,
+		tlsClientCAFile:        *tlsClientCAFile,
+		h2c:                    *h2c,
 	}, nil
 }
 
@@ -234,13 +575,47 @@ type ConfigFile struct {
 	Targets       []struct {
 		Target             string `json:"target"`
 		ServerListenerPort int    `json:"server_listener_port"`
+		MetricsPort        int    `json:"metrics_port"`
 		Logger             struct {
 			MinLogLevel   string `json:"min_log_level"`
 			LogLevel      string `json:"log_level"`
 			LogErrorStack bool   `json:"log_error_stack"`
 		} `json:"logger"`
-		EncryptionKey string `json:"encryption_key"`
-		Database      struct {
+		EncryptionKey       string `json:"encryption_key"`
+		DeleteRetention     string `json:"delete_retention"`
+		BlobDir             string `json:"blob_dir"`
+		ShutdownGracePeriod string `json:"shutdown_grace_period"`
+		ShutdownDrainDelay  string `json:"shutdown_drain_delay"`
+		MaxRequestBody      int64  `json:"max_request_body"`
+		H2C                 bool   `json:"h2c"`
+		TLS                 struct {
+			CertFile     string `json:"cert_file"`
+			KeyFile      string `json:"key_file"`
+			ClientCAFile string `json:"client_ca_file"`
+		} `json:"tls"`
+		Jobs struct {
+			Workers      *int   `json:"workers"`
+			PollInterval string `json:"poll_interval"`
+		} `json:"jobs"`
+		Tracing struct {
+			Exporter     string   `json:"exporter"`
+			OTLPEndpoint string   `json:"otlp_endpoint"`
+			SampleRatio  *float64 `json:"sample_ratio"`
+		} `json:"tracing"`
+		RateLimit struct {
+			App  rateLimitConfig `json:"app"`
+			User rateLimitConfig `json:"user"`
+		} `json:"rate_limit"`
+		CORS struct {
+			AllowedOrigins []string `json:"allowed_origins"`
+			AllowedMethods []string `json:"allowed_methods"`
+			AllowedHeaders []string `json:"allowed_headers"`
+			MaxAge         string   `json:"max_age"`
+		} `json:"cors"`
+		Webhooks struct {
+			AllowedNetworks []string `json:"allowed_networks"`
+		} `json:"webhooks"`
+		Database struct {
 			Host       string `json:"host"`
 			Port       int    `json:"port"`
 			Name       string `json:"name"`
@@ -251,6 +626,14 @@ type ConfigFile struct {
 	} `json:"targets"`
 }
 
+// rateLimitConfig is a default rate limit in the config file. Fields
+// are pointers as zero requests per minute (no limit) is a valid
+// setting.
+type rateLimitConfig struct {
+	RequestsPerMinute *int `json:"requests_per_minute"`
+	Burst             *int `json:"burst"`
+}
+
 // configJSONParser is a custom ff config file parser that reads a
 // multi-target JSON config file and sets flag values from the selected target.
 // Target selection priority: --target CLI arg > TARGET env var > default_target in JSON.
@@ -290,16 +673,59 @@ func configJSONParser(r io.Reader, set func(name, value string) error) error {
 	}{
 		{targetFlagName, t.Target},
 		{listenPortFlagName, strconv.Itoa(t.ServerListenerPort)},
+		{metricsPortFlagName, strconv.Itoa(t.MetricsPort)},
 		{logLevelMinFlagName, t.Logger.MinLogLevel},
 		{loglevelFlagName, t.Logger.LogLevel},
 		{logErrorStackFlagName, strconv.FormatBool(t.Logger.LogErrorStack)},
 		{encryptKeyFlagName, t.EncryptionKey},
+		{deleteRetentionFlagName, t.DeleteRetention},
+		{blobDirFlagName, t.BlobDir},
+		{shutdownGracePeriodFlagName, t.ShutdownGracePeriod},
+		{shutdownDrainDelayFlagName, t.ShutdownDrainDelay},
 		{dbHostFlagName, t.Database.Host},
 		{dbPortFlagName, strconv.Itoa(t.Database.Port)},
 		{dbNameFlagName, t.Database.Name},
 		{dbUserFlagName, t.Database.User},
 		{dbPasswordFlagName, t.Database.Password},
 		{dbSearchPathFlagName, t.Database.SearchPath},
+		{jobPollIntervalFlagName, t.Jobs.PollInterval},
+		{traceExporterFlagName, t.Tracing.Exporter},
+		{traceOTLPEndpointFlagName, t.Tracing.OTLPEndpoint},
+		{corsAllowedOriginsFlagName, strings.Join(t.CORS.AllowedOrigins, ",")},
+		{corsAllowedMethodsFlagName, strings.Join(t.CORS.AllowedMethods, ",")},
+		{corsAllowedHeadersFlagName, strings.Join(t.CORS.AllowedHeaders, ",")},
+		{corsMaxAgeFlagName, t.CORS.MaxAge},
+		{webhookAllowedNetworksFlagName, strings.Join(t.Webhooks.AllowedNetworks, ",")},
+		{tlsCertFileFlagName, t.TLS.CertFile},
+		{tlsKeyFileFlagName, t.TLS.KeyFile},
+		{tlsClientCAFileFlagName, t.TLS.ClientCAFile},
+		{h2cFlagName, strconv.FormatBool(t.H2C)},
+	}
+	if t.MaxRequestBody != 0 {
+		pairs = append(pairs, struct{ name, value string }{maxRequestBodyFlagName, strconv.FormatInt(t.MaxRequestBody, 10)})
+	}
+	// zero job workers is a valid setting, so workers is only set if
+	// it is in the config file
+	if t.Jobs.Workers != nil {
+		pairs = append(pairs, struct{ name, value string }{jobWorkersFlagName, strconv.Itoa(*t.Jobs.Workers)})
+	}
+	// likewise, a zero sample ratio is valid
+	if t.Tracing.SampleRatio != nil {
+		pairs = append(pairs, struct{ name, value string }{traceSampleRatioFlagName, strconv.FormatFloat(*t.Tracing.SampleRatio, 'g', -1, 64)})
+	}
+	// and zero requests per minute is no rate limit
+	for _, p := range []struct {
+		name  string
+		value *int
+	}{
+		{rateLimitAppFlagName, t.RateLimit.App.RequestsPerMinute},
+		{rateLimitAppBurstFlagName, t.RateLimit.App.Burst},
+		{rateLimitUserFlagName, t.RateLimit.User.RequestsPerMinute},
+		{rateLimitUserBurstFlagName, t.RateLimit.User.Burst},
+	} {
+		if p.value != nil {
+			pairs = append(pairs, struct{ name, value string }{p.name, strconv.Itoa(*p.value)})
+		}
 	}
 
 	for _, p := range pairs {
@@ -314,6 +740,18 @@ func configJSONParser(r io.Reader, set func(name, value string) error) error {
 	return nil
 }
 
+// splitList splits a comma separated flag value, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // scanArgsForTarget scans os.Args for --target or -target and returns the value.
 func scanArgsForTarget() string {
 	for i, arg := range os.Args {
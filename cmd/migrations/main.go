@@ -19,9 +19,9 @@ func main() {
 // DBUp executes DDL scripts which create all required DB objects,
 // example: mage -v dbup local.
 //
-// All files will be executed, regardless of errors within an individual
-// file. Check output to determine if any errors occurred. Eventually,
-// I will write this to stop on errors, but for now it is what it is.
+// Files are executed in order until one fails, in which case the rest
+// are not run, the schema version is not stamped and an error is
+// returned.
 func DBUp(args []string) (err error) {
 	const op errs.Op = "main/DBUp"
 
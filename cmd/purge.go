@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/logger"
+	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb"
+)
+
+// Purge command purges movies, orgs and apps which were deleted
+// longer ago than the delete retention period.
+func Purge(args []string) (err error) {
+	const op errs.Op = "cmd/Purge"
+
+	var flgs flags
+	flgs, err = newFlags(args)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// determine logging level based on flag input
+	var lvl zerolog.Level
+	lvl, err = zerolog.ParseLevel(flgs.loglvl)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	lgr := logger.NewWithGCPHook(os.Stdout, lvl, true)
+
+	// set global logging time field format to Unix timestamp
+	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+
+	err = flgs.validateDBConnection()
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	if flgs.deleteRetention <= 0 {
+		return errs.E(op, "delete retention must be greater than zero")
+	}
+
+	ctx := context.Background()
+
+	// initialize PostgreSQL database
+	var (
+		dbpool  *pgxpool.Pool
+		cleanup func()
+	)
+	dbpool, cleanup, err = sqldb.NewPgxPool(ctx, lgr, newPostgreSQLDSN(flgs))
+	if err != nil {
+		return errs.E(op, err)
+	}
+	defer cleanup()
+
+	s := service.PurgeService{Datastorer: sqldb.NewDB(dbpool)}
+
+	before := time.Now().Add(-flgs.deleteRetention)
+
+	var response diygoapi.PurgeResponse
+	response, err = s.Purge(ctx, before)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	lgr.Info().
+		Time("before", response.Before).
+		Int64("movies", response.Movies).
+		Int64("orgs", response.Orgs).
+		Int64("apps", response.Apps).
+		Int64("app_api_keys", response.AppAPIKeys).
+		Msg("purged deleted records")
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gilcrest/diygoapi/cmd"
+)
+
+func main() {
+	if err := cmd.Purge(os.Args); err != nil {
+		fmt.Fprintf(os.Stderr, "error from cmd.Purge(): %s\n", err)
+		os.Exit(1)
+	}
+}
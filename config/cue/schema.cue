@@ -8,8 +8,34 @@ package config
 #Target: {
 	target:               string
 	server_listener_port: >=8080 & <=10080
+	// port to serve /metrics on, e.g. an admin port not exposed
+	// publicly. If omitted, /metrics is served on server_listener_port.
+	metrics_port?: >=1024 & <=65535
 	logger:               #Logger
 	encryption_key:       !="" // must be specified and non-empty
+	// how long deleted records can be restored before they are purged, e.g. "720h"
+	delete_retention?: string
+	// directory the local blob store keeps uploaded files in, e.g. "./data/blobs"
+	blob_dir?: string
+	jobs?:     #Jobs
+	// how long in-flight requests and running jobs are given to finish on shutdown, e.g. "8s"
+	shutdown_grace_period?: string
+	// how long readiness fails on shutdown before connections stop being accepted, e.g. "5s"
+	shutdown_drain_delay?: string
+	// size in bytes JSON request bodies are limited to, e.g. 1048576
+	max_request_body?: >=1
+	tracing?:          #Tracing
+	cors?:             #CORS
+	// serves HTTPS in place of plain HTTP
+	tls?: #TLS
+	// serves unencrypted HTTP/2 when not serving TLS, e.g. behind a
+	// proxy speaking HTTP/2 to the server
+	h2c?: bool
+	// default rate limits, which can be overridden for an app in the app_rate_limit table
+	rate_limit?: {
+		app?:  #RateLimit
+		user?: #RateLimit
+	}
 	database:             #Database
 	_gcp:                 #GCP
 }
@@ -25,6 +51,49 @@ package config
 	log_error_stack: bool
 }
 
+#Jobs: {
+	// number of background jobs run at once, 0 runs no job workers
+	workers?: >=0
+	// how long an idle job worker waits before looking for a job again, e.g. "5s"
+	poll_interval?: string
+}
+
+#Tracing: {
+	// where trace spans are sent, "stdout" is meant for local use
+	exporter?: "none" | "stdout" | "otlp"
+	// URL of the OpenTelemetry collector for the otlp exporter, e.g. "http://localhost:4318"
+	otlp_endpoint?: string
+	// fraction of new traces sampled
+	sample_ratio?: >=0 & <=1
+}
+
+#CORS: {
+	// origins browser clients may call the API from, e.g. "https://app.example.com", or "*" for any
+	allowed_origins?: [...string]
+	// methods browser clients may use
+	allowed_methods?: [...string]
+	// request headers browser clients may send
+	allowed_headers?: [...string]
+	// how long browsers may cache a preflight response, e.g. "10m"
+	max_age?: string
+}
+
+#TLS: {
+	// PEM certificate file, followed by any intermediates, reloaded when changed
+	cert_file: !="" // must be specified and non-empty
+	// PEM private key file of the certificate
+	key_file: !="" // must be specified and non-empty
+	// PEM CA certificates file. If given, clients must present a certificate issued by one of them.
+	client_ca_file?: string
+}
+
+#RateLimit: {
+	// requests a minute allowed, 0 for no limit
+	requests_per_minute?: >=0
+	// requests allowed at once
+	burst?: >=1
+}
+
 #Database: {
 	host:        !="" // must be specified and non-empty
 	port:        !=0  // must be specified and non-empty
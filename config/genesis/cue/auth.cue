@@ -126,18 +126,306 @@ _moviesV1FindAll: #Permission & {
 	active:      true
 }
 
+_orgsV1HistoryByExtlID: #Permission & {
+	resource:    "/api/v1/orgs/{extlID}/history"
+	operation:   "GET"
+	description: "allows for reading the change history of an organization"
+	active:      true
+}
+
+_appsV1HistoryByExtlID: #Permission & {
+	resource:    "/api/v1/apps/{extlID}/history"
+	operation:   "GET"
+	description: "allows for reading the change history of an app"
+	active:      true
+}
+
+_permissionsV1HistoryByExtlID: #Permission & {
+	resource:    "/api/v1/permissions/{extlID}/history"
+	operation:   "GET"
+	description: "allows for reading the change history of a permission"
+	active:      true
+}
+
+_rolesV1HistoryByExtlID: #Permission & {
+	resource:    "/api/v1/roles/{extlID}/history"
+	operation:   "GET"
+	description: "allows for reading the change history of a role"
+	active:      true
+}
+
+_moviesV1HistoryByExtlID: #Permission & {
+	resource:    "/api/v1/movies/{extlID}/history"
+	operation:   "GET"
+	description: "allows for reading the change history of a movie"
+	active:      true
+}
+
+_orgsV1RestoreByExtlID: #Permission & {
+	resource:    "/api/v1/orgs/{extlID}/restore"
+	operation:   "POST"
+	description: "allows for restoring a deleted organization"
+	active:      true
+}
+
+_appsV1DeleteByExtlID: #Permission & {
+	resource:    "/api/v1/apps/{extlID}"
+	operation:   "DELETE"
+	description: "allows for deleting an app"
+	active:      true
+}
+
+_appsV1RestoreByExtlID: #Permission & {
+	resource:    "/api/v1/apps/{extlID}/restore"
+	operation:   "POST"
+	description: "allows for restoring a deleted app"
+	active:      true
+}
+
+_moviesV1RestoreByExtlID: #Permission & {
+	resource:    "/api/v1/movies/{extlID}/restore"
+	operation:   "POST"
+	description: "allows for restoring a deleted movie"
+	active:      true
+}
+
+_moviesV1Import: #Permission & {
+	resource:    "/api/v1/movies:import"
+	operation:   "POST"
+	description: "allows for importing movies in bulk"
+	active:      true
+}
+
+_moviesV1Export: #Permission & {
+	resource:    "/api/v1/movies:export"
+	operation:   "GET"
+	description: "allows for exporting all movies"
+	active:      true
+}
+
+_orgsV1Export: #Permission & {
+	resource:    "/api/v1/orgs:export"
+	operation:   "GET"
+	description: "allows for exporting all organizations"
+	active:      true
+}
+
+_moviesV1Search: #Permission & {
+	resource:    "/api/v1/movies/search"
+	operation:   "GET"
+	description: "allows for searching movies"
+	active:      true
+}
+
+_moviesV1FindByCredit: #Permission & {
+	resource:    "/api/v1/movies/credits"
+	operation:   "GET"
+	description: "allows for finding the movies a person is credited on"
+	active:      true
+}
+
+_genresV1FindAll: #Permission & {
+	resource:    "/api/v1/genres"
+	operation:   "GET"
+	description: "allows for listing the genre taxonomy"
+	active:      true
+}
+
+_genresV1FindMovies: #Permission & {
+	resource:    "/api/v1/genres/{genre}/movies"
+	operation:   "GET"
+	description: "allows for finding the movies in a genre"
+	active:      true
+}
+
+_ratingsV1FindAll: #Permission & {
+	resource:    "/api/v1/ratings"
+	operation:   "GET"
+	description: "allows for listing the ratings of each rating system"
+	active:      true
+}
+
+_moviesV1PosterPut: #Permission & {
+	resource:    "/api/v1/movies/{extlID}/poster"
+	operation:   "PUT"
+	description: "allows for uploading the poster image of a movie"
+	active:      true
+}
+
+_moviesV1PosterGet: #Permission & {
+	resource:    "/api/v1/movies/{extlID}/poster"
+	operation:   "GET"
+	description: "allows for reading the poster image of a movie"
+	active:      true
+}
+
+_moviesV1ReviewsPost: #Permission & {
+	resource:    "/api/v1/movies/{extlID}/reviews"
+	operation:   "POST"
+	description: "allows for reviewing a movie"
+	active:      true
+}
+
+_moviesV1ReviewsGet: #Permission & {
+	resource:    "/api/v1/movies/{extlID}/reviews"
+	operation:   "GET"
+	description: "allows for reading the reviews of a movie"
+	active:      true
+}
+
+_moviesV1ReviewsUpdateByExtlID: #Permission & {
+	resource:    "/api/v1/movies/{extlID}/reviews/{reviewExtlID}"
+	operation:   "PUT"
+	description: "allows for editing a review of a movie. Only the author of a review can edit it."
+	active:      true
+}
+
+_usersV1WatchlistsPost: #Permission & {
+	resource:    "/api/v1/users/me/watchlists"
+	operation:   "POST"
+	description: "allows for creating a watchlist owned by the authenticated user"
+	active:      true
+}
+
+_usersV1WatchlistsGet: #Permission & {
+	resource:    "/api/v1/users/me/watchlists"
+	operation:   "GET"
+	description: "allows for listing the watchlists of the authenticated user"
+	active:      true
+}
+
+_usersV1WatchlistsUpdateByExtlID: #Permission & {
+	resource:    "/api/v1/users/me/watchlists/{extlID}"
+	operation:   "PUT"
+	description: "allows for renaming or sharing a watchlist. Only the owner of a watchlist can change it."
+	active:      true
+}
+
+_usersV1WatchlistsDeleteByExtlID: #Permission & {
+	resource:    "/api/v1/users/me/watchlists/{extlID}"
+	operation:   "DELETE"
+	description: "allows for deleting a watchlist. Only the owner of a watchlist can delete it."
+	active:      true
+}
+
+_usersV1WatchlistsMoviesPost: #Permission & {
+	resource:    "/api/v1/users/me/watchlists/{extlID}/movies"
+	operation:   "POST"
+	description: "allows for adding a movie to a watchlist"
+	active:      true
+}
+
+_usersV1WatchlistsMoviesPut: #Permission & {
+	resource:    "/api/v1/users/me/watchlists/{extlID}/movies"
+	operation:   "PUT"
+	description: "allows for reordering the movies of a watchlist"
+	active:      true
+}
+
+_usersV1WatchlistsMoviesDeleteByExtlID: #Permission & {
+	resource:    "/api/v1/users/me/watchlists/{extlID}/movies/{movieExtlID}"
+	operation:   "DELETE"
+	description: "allows for removing a movie from a watchlist"
+	active:      true
+}
+
+_watchlistsV1FindByExtlID: #Permission & {
+	resource:    "/api/v1/watchlists/{extlID}"
+	operation:   "GET"
+	description: "allows for reading a watchlist of the authenticated user or one shared within their org"
+	active:      true
+}
+
+_jobsV1FindAll: #Permission & {
+	resource:    "/api/v1/jobs"
+	operation:   "GET"
+	description: "allows for listing background jobs"
+	active:      true
+}
+
+_jobsV1FindByExtlID: #Permission & {
+	resource:    "/api/v1/jobs/{extlID}"
+	operation:   "GET"
+	description: "allows for reading a background job"
+	active:      true
+}
+
+_jobsV1RetryByExtlID: #Permission & {
+	resource:    "/api/v1/jobs/{extlID}/retry"
+	operation:   "POST"
+	description: "allows for retrying a dead background job"
+	active:      true
+}
+
+_webhooksV1Post: #Permission & {
+	resource:    "/api/v1/webhooks"
+	operation:   "POST"
+	description: "allows for registering a webhook for the app"
+	active:      true
+}
+
+_webhooksV1FindAll: #Permission & {
+	resource:    "/api/v1/webhooks"
+	operation:   "GET"
+	description: "allows for listing the webhooks of the app"
+	active:      true
+}
+
+_webhooksV1DeleteByExtlID: #Permission & {
+	resource:    "/api/v1/webhooks/{extlID}"
+	operation:   "DELETE"
+	description: "allows for deleting a webhook of the app"
+	active:      true
+}
+
+_webhooksV1DeliveriesByExtlID: #Permission & {
+	resource:    "/api/v1/webhooks/{extlID}/deliveries"
+	operation:   "GET"
+	description: "allows for listing the deliveries to a webhook of the app"
+	active:      true
+}
+
+_eventsV1Get: #Permission & {
+	resource:    "/api/v1/events"
+	operation:   "GET"
+	description: "allows for streaming the events of the org the user is permitted to read"
+	active:      true
+}
+
+_securityEventsV1FindAll: #Permission & {
+	resource:    "/api/v1/security-events"
+	operation:   "GET"
+	description: "allows for listing security events"
+	active:      true
+}
+
 _sysAdmin: #Role & {
 	role_cd:          "sysAdmin"
 	role_description: "System administrator role."
 	active:           true
-	permissions: [_pingV1Get, _loggerV1Get, _loggerV1Put, _orgsV1Post, _orgsV1Put, _orgsV1Delete, _orgsV1Get, _orgsV1GetByExtlID, _appsV1Post,
+	_permissions: [_pingV1Get, _loggerV1Get, _loggerV1Put, _orgsV1Post, _orgsV1Put, _orgsV1Delete, _orgsV1Get, _orgsV1GetByExtlID, _appsV1Post,
 		_permissionsV1Post, _permissionsV1Get, _permissionsV1Delete, _moviesV1Post, _moviesV1UpdateByExtlID, _moviesV1DeleteByExtlID,
-		_moviesV1FindByExtlID, _moviesV1FindAll]
+		_moviesV1FindByExtlID, _moviesV1FindAll, _orgsV1HistoryByExtlID, _appsV1HistoryByExtlID, _permissionsV1HistoryByExtlID,
+		_rolesV1HistoryByExtlID, _moviesV1HistoryByExtlID, _orgsV1RestoreByExtlID, _appsV1DeleteByExtlID, _appsV1RestoreByExtlID,
+		_moviesV1RestoreByExtlID, _moviesV1Import, _moviesV1Export, _orgsV1Export,
+		_moviesV1Search, _moviesV1FindByCredit, _genresV1FindAll, _genresV1FindMovies,
+		_ratingsV1FindAll, _moviesV1ReviewsPost, _moviesV1ReviewsGet, _moviesV1ReviewsUpdateByExtlID,
+		_usersV1WatchlistsPost, _usersV1WatchlistsGet, _usersV1WatchlistsUpdateByExtlID, _usersV1WatchlistsDeleteByExtlID,
+		_usersV1WatchlistsMoviesPost, _usersV1WatchlistsMoviesPut, _usersV1WatchlistsMoviesDeleteByExtlID, _watchlistsV1FindByExtlID,
+		_moviesV1PosterPut, _moviesV1PosterGet, _jobsV1FindAll, _jobsV1FindByExtlID, _jobsV1RetryByExtlID,
+		_webhooksV1Post, _webhooksV1FindAll, _webhooksV1DeleteByExtlID, _webhooksV1DeliveriesByExtlID, _eventsV1Get,
+		_securityEventsV1FindAll]
 }
 
 _movieAdmin: #Role & {
 	role_cd:          "movieAdmin"
 	role_description: "Users can create, update, delete and read the movie database"
 	active:           true
-	permissions: [_moviesV1Post, _moviesV1UpdateByExtlID, _moviesV1DeleteByExtlID, _moviesV1FindByExtlID, _moviesV1FindAll]
+	_permissions: [_moviesV1Post, _moviesV1UpdateByExtlID, _moviesV1DeleteByExtlID, _moviesV1FindByExtlID, _moviesV1FindAll,
+		_moviesV1HistoryByExtlID, _moviesV1RestoreByExtlID, _moviesV1Import, _moviesV1Export,
+		_moviesV1Search, _moviesV1FindByCredit, _genresV1FindAll, _genresV1FindMovies,
+		_ratingsV1FindAll, _moviesV1ReviewsPost, _moviesV1ReviewsGet, _moviesV1ReviewsUpdateByExtlID,
+		_usersV1WatchlistsPost, _usersV1WatchlistsGet, _usersV1WatchlistsUpdateByExtlID, _usersV1WatchlistsDeleteByExtlID,
+		_usersV1WatchlistsMoviesPost, _usersV1WatchlistsMoviesPut, _usersV1WatchlistsMoviesDeleteByExtlID, _watchlistsV1FindByExtlID,
+		_moviesV1PosterPut, _moviesV1PosterGet, _eventsV1Get]
 }
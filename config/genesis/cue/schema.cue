@@ -6,7 +6,17 @@ user: #User
 org:  #Org
 permissions: [_pingV1Get, _loggerV1Get, _loggerV1Put, _orgsV1Post, _orgsV1Put, _orgsV1Delete, _orgsV1Get,
 	_orgsV1GetByExtlID, _appsV1Post, _permissionsV1Post, _permissionsV1Get, _permissionsV1Delete,
-	_moviesV1Post, _moviesV1UpdateByExtlID, _moviesV1DeleteByExtlID, _moviesV1FindByExtlID, _moviesV1FindAll]
+	_moviesV1Post, _moviesV1UpdateByExtlID, _moviesV1DeleteByExtlID, _moviesV1FindByExtlID, _moviesV1FindAll,
+	_orgsV1HistoryByExtlID, _appsV1HistoryByExtlID, _permissionsV1HistoryByExtlID, _rolesV1HistoryByExtlID,
+	_moviesV1HistoryByExtlID, _orgsV1RestoreByExtlID, _appsV1DeleteByExtlID, _appsV1RestoreByExtlID,
+	_moviesV1RestoreByExtlID, _moviesV1Import, _moviesV1Export, _orgsV1Export,
+	_moviesV1Search, _moviesV1FindByCredit, _genresV1FindAll, _genresV1FindMovies,
+	_ratingsV1FindAll, _moviesV1ReviewsPost, _moviesV1ReviewsGet, _moviesV1ReviewsUpdateByExtlID,
+	_usersV1WatchlistsPost, _usersV1WatchlistsGet, _usersV1WatchlistsUpdateByExtlID, _usersV1WatchlistsDeleteByExtlID,
+	_usersV1WatchlistsMoviesPost, _usersV1WatchlistsMoviesPut, _usersV1WatchlistsMoviesDeleteByExtlID, _watchlistsV1FindByExtlID,
+	_moviesV1PosterPut, _moviesV1PosterGet, _jobsV1FindAll, _jobsV1FindByExtlID, _jobsV1RetryByExtlID,
+	_webhooksV1Post, _webhooksV1FindAll, _webhooksV1DeleteByExtlID, _webhooksV1DeliveriesByExtlID, _eventsV1Get,
+	_securityEventsV1FindAll]
 roles: [_sysAdmin, _movieAdmin]
 
 #User: {
@@ -46,8 +56,10 @@ roles: [_sysAdmin, _movieAdmin]
 	role_description: !="" // must be specified and non-empty
 	// A boolean denoting whether the role is active (true) or not (false).
 	active: bool
-	// A list of permissions that the role allows
-	permissions: [...#Permission]
+	// The permissions that the role allows
+	_permissions: [...#Permission]
+	// The permissions that the role allows, by resource and operation
+	permissions: [for p in _permissions {resource: p.resource, operation: p.operation}]
 }
 
 // Permission stores an approval of a mode of access to a resource.
@@ -15,6 +15,8 @@ const (
 	appContextKey        contextKey = "app"
 	contextKeyUser       contextKey = "user"
 	authParamsContextKey contextKey = "authParams"
+	rowSecurityBypassKey contextKey = "rowSecurityBypass"
+	authProbeContextKey  contextKey = "authorizationProbe"
 )
 
 // NewContextWithRequestHandlerPattern returns a new context with the given Handler pattern
@@ -148,3 +150,32 @@ func AuthParamsFromContext(ctx context.Context) (*AuthenticationParams, error) {
 	}
 	return a, nil
 }
+
+// NewContextWithRowSecurityBypass returns a new context which marks
+// database transactions begun with it as exempt from row level
+// security. It is meant for system operations which work across
+// orgs and have no App, such as purging deleted records.
+func NewContextWithRowSecurityBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rowSecurityBypassKey, true)
+}
+
+// RowSecurityBypassFromContext reports whether the given context was
+// marked with NewContextWithRowSecurityBypass
+func RowSecurityBypassFromContext(ctx context.Context) bool {
+	bypass, _ := ctx.Value(rowSecurityBypassKey).(bool)
+	return bypass
+}
+
+// NewContextWithAuthorizationProbe returns a new context which marks
+// an authorization check made with it as a probe: the check only finds
+// out what the user may do, so a denial is not a security event.
+func NewContextWithAuthorizationProbe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, authProbeContextKey, true)
+}
+
+// AuthorizationProbeFromContext reports whether the given context was
+// marked with NewContextWithAuthorizationProbe
+func AuthorizationProbeFromContext(ctx context.Context) bool {
+	probe, _ := ctx.Value(authProbeContextKey).(bool)
+	return probe
+}
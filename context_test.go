@@ -65,3 +65,21 @@ func TestUserFromRequest(t *testing.T) {
 		c.Assert(u, qt.IsNil)
 	})
 }
+
+func TestRowSecurityBypassFromContext(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(RowSecurityBypassFromContext(context.Background()), qt.IsFalse)
+
+	ctx := NewContextWithRowSecurityBypass(context.Background())
+	c.Assert(RowSecurityBypassFromContext(ctx), qt.IsTrue)
+}
+
+func TestAuthorizationProbeFromContext(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(AuthorizationProbeFromContext(context.Background()), qt.IsFalse)
+
+	ctx := NewContextWithAuthorizationProbe(context.Background())
+	c.Assert(AuthorizationProbeFromContext(ctx), qt.IsTrue)
+}
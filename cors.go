@@ -0,0 +1,57 @@
+package diygoapi
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+// AnyOrigin is the allowed origin which allows requests from any origin
+const AnyOrigin = "*"
+
+// CORSServicer finds the origins Apps allow browser requests from, in
+// place of the configured ones
+type CORSServicer interface {
+	// AnyAppAllowsOrigin reports whether any App allows requests from
+	// origin. It is used for preflight requests, which do not identify
+	// the App.
+	AnyAppAllowsOrigin(ctx context.Context, origin string) (bool, error)
+	// FindAppAllowedOrigins returns the origins the App allows, or nil
+	// if the App has none of its own and the configured ones apply.
+	FindAppAllowedOrigins(ctx context.Context, a *App) ([]string, error)
+}
+
+// ParseOrigin validates an origin, e.g. https://app.example.com:8443,
+// and returns it in the lower case form browsers send in the Origin
+// header. AnyOrigin is returned as is.
+func ParseOrigin(origin string) (string, error) {
+	const op errs.Op = "diygoapi/ParseOrigin"
+
+	if origin == AnyOrigin {
+		return origin, nil
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(origin, "/"))
+	if err != nil {
+		return "", errs.E(op, errs.Validation, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", errs.E(op, errs.Validation, "origin must be a scheme and host, e.g. https://app.example.com: "+origin)
+	}
+
+	return strings.ToLower(u.Scheme + "://" + u.Host), nil
+}
+
+// OriginAllowed reports whether origin is one of allowed, or allowed
+// has AnyOrigin. Both are expected to be in the form returned by
+// ParseOrigin.
+func OriginAllowed(allowed []string, origin string) bool {
+	for _, a := range allowed {
+		if a == AnyOrigin || a == origin {
+			return true
+		}
+	}
+	return false
+}
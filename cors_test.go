@@ -0,0 +1,45 @@
+package diygoapi_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/gilcrest/diygoapi"
+)
+
+func TestParseOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		origin  string
+		want    string
+		wantErr bool
+	}{
+		{"https", "https://app.example.com", "https://app.example.com", false},
+		{"port and case", "HTTP://Localhost:3000", "http://localhost:3000", false},
+		{"trailing slash", "https://app.example.com/", "https://app.example.com", false},
+		{"any", "*", "*", false},
+		{"path", "https://app.example.com/app", "", true},
+		{"no scheme", "app.example.com", "", true},
+		{"other scheme", "ftp://app.example.com", "", true},
+		{"empty", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			got, err := diygoapi.ParseOrigin(tt.origin)
+			c.Assert(err != nil, qt.Equals, tt.wantErr)
+			c.Assert(got, qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestOriginAllowed(t *testing.T) {
+	c := qt.New(t)
+
+	allowed := []string{"https://app.example.com", "http://localhost:3000"}
+	c.Assert(diygoapi.OriginAllowed(allowed, "http://localhost:3000"), qt.IsTrue)
+	c.Assert(diygoapi.OriginAllowed(allowed, "https://evil.example.com"), qt.IsFalse)
+	c.Assert(diygoapi.OriginAllowed(nil, "https://app.example.com"), qt.IsFalse)
+	c.Assert(diygoapi.OriginAllowed([]string{diygoapi.AnyOrigin}, "https://evil.example.com"), qt.IsTrue)
+}
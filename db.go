@@ -22,6 +22,13 @@ type Datastorer interface {
 	CommitTx(ctx context.Context, tx pgx.Tx) error
 }
 
+// Listener listens for PostgreSQL notifications
+type Listener interface {
+	// Listen calls notify with the payload of each notification on the
+	// channel until the context is done or the connection fails
+	Listen(ctx context.Context, channel string, notify func(payload string)) error
+}
+
 // DBTX interface mirrors the interface generated by https://github.com/kyleconroy/sqlc
 // to allow passing a Pool or a Tx
 type DBTX interface {
@@ -3,13 +3,16 @@ package diygoapi
 
 import (
 	"context"
+	"strings"
 	"time"
+
+	"github.com/gilcrest/diygoapi/errs"
 )
 
 // LoggerServicer reads and updates the logger state
 type LoggerServicer interface {
 	Read() *LoggerResponse
-	Update(r *LoggerRequest) (*LoggerResponse, error)
+	Update(ctx context.Context, r *LoggerRequest, adt Audit) (*LoggerResponse, error)
 }
 
 // GenesisServicer initializes the database with dependent data
@@ -43,18 +46,97 @@ type DeleteResponse struct {
 	Deleted    bool   `json:"deleted"`
 }
 
+// DefaultDeleteRetention is how long a deleted record can be
+// restored before it is eligible to be purged, unless
+// configured otherwise.
+const DefaultDeleteRetention = 30 * 24 * time.Hour
+
+// PurgeResponse is the response struct for purging deleted
+// records which have aged beyond the retention period
+type PurgeResponse struct {
+	Before     time.Time `json:"before"`
+	Movies     int64     `json:"movies"`
+	Orgs       int64     `json:"orgs"`
+	Apps       int64     `json:"apps"`
+	AppAPIKeys int64     `json:"app_api_keys"`
+}
+
+// ExportFormat is the encoding of a streaming export
+type ExportFormat string
+
+// Supported streaming export formats
+const (
+	CSVExportFormat    ExportFormat = "csv"
+	NDJSONExportFormat ExportFormat = "ndjson"
+)
+
+// ParseExportFormat initializes an ExportFormat given a case-insensitive string
+func ParseExportFormat(s string) (ExportFormat, error) {
+	const op errs.Op = "diygoapi/ParseExportFormat"
+
+	switch ExportFormat(strings.ToLower(s)) {
+	case CSVExportFormat:
+		return CSVExportFormat, nil
+	case NDJSONExportFormat:
+		return NDJSONExportFormat, nil
+	}
+
+	return "", errs.E(op, errs.Validation, errs.Parameter("format"),
+		"format must be one of csv or ndjson")
+}
+
+// ContentType returns the media type of the export format
+func (f ExportFormat) ContentType() string {
+	if f == CSVExportFormat {
+		return "text/csv"
+	}
+	return "application/x-ndjson"
+}
+
 // LoggerRequest is the request struct for the app logger
 type LoggerRequest struct {
 	GlobalLogLevel string `json:"global_log_level"`
-	LogErrorStack  string `json:"log_error_stack"`
+	// GlobalLogLevelTTL: How long global_log_level is set for, as a
+	// duration (e.g. "30m"), after which the level set before is
+	// restored. If empty, the level is kept until changed.
+	GlobalLogLevelTTL string `json:"global_log_level_ttl"`
+	LogErrorStack     string `json:"log_error_stack"`
+	// ComponentLogLevels: Levels to set for components, which take
+	// precedence over the global level.
+	ComponentLogLevels []ComponentLogLevelRequest `json:"component_log_levels"`
+}
+
+// ComponentLogLevelRequest is the request struct for the log level
+// of a component
+type ComponentLogLevelRequest struct {
+	// Component: An errs.Op or a prefix of one at a "/" or "."
+	// (e.g. "service" or "service/MovieService"), or a route handler
+	// pattern (e.g. "GET /api/v1/movies/{extlID}").
+	Component string `json:"component"`
+	// LogLevel: The level to log at. If empty, the level set for the
+	// component is removed.
+	LogLevel string `json:"log_level"`
+	// TTL: How long the level is set for, as a duration (e.g. "30m").
+	// If empty, the level is kept until changed.
+	TTL string `json:"ttl"`
 }
 
 // LoggerResponse is the response struct for the current
 // state of the app logger
 type LoggerResponse struct {
-	LoggerMinimumLevel string `json:"logger_minimum_level"`
-	GlobalLogLevel     string `json:"global_log_level"`
-	LogErrorStack      bool   `json:"log_error_stack"`
+	LoggerMinimumLevel      string                      `json:"logger_minimum_level"`
+	GlobalLogLevel          string                      `json:"global_log_level"`
+	GlobalLogLevelRevertsAt string                      `json:"global_log_level_reverts_at,omitempty"`
+	LogErrorStack           bool                        `json:"log_error_stack"`
+	ComponentLogLevels      []ComponentLogLevelResponse `json:"component_log_levels"`
+}
+
+// ComponentLogLevelResponse is the response struct for the log level
+// set for a component
+type ComponentLogLevelResponse struct {
+	Component string `json:"component"`
+	LogLevel  string `json:"log_level"`
+	ExpiresAt string `json:"expires_at,omitempty"`
 }
 
 // GenesisRequest is the request struct for the genesis service
@@ -0,0 +1,26 @@
+package diygoapi
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+func TestParseExportFormat(t *testing.T) {
+	c := qt.New(t)
+
+	f, err := ParseExportFormat("CSV")
+	c.Assert(err, qt.IsNil)
+	c.Assert(f, qt.Equals, CSVExportFormat)
+	c.Assert(f.ContentType(), qt.Equals, "text/csv")
+
+	f, err = ParseExportFormat("ndjson")
+	c.Assert(err, qt.IsNil)
+	c.Assert(f, qt.Equals, NDJSONExportFormat)
+	c.Assert(f.ContentType(), qt.Equals, "application/x-ndjson")
+
+	_, err = ParseExportFormat("")
+	c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+}
@@ -154,6 +154,12 @@ const (
 	// The error is logged and http.StatusForbidden (403) is sent.
 	Unauthorized
 	UnsupportedMediaType // Unsupported Media Type
+	// RateLimited is used when a caller has made more requests than
+	// its rate limit allows.
+	//
+	// http.StatusTooManyRequests (429) is sent. The Retry-After and
+	// RateLimit-* headers should be set before the error is sent.
+	RateLimited
 )
 
 func (k Kind) String() string {
@@ -188,6 +194,8 @@ func (k Kind) String() string {
 		return "unauthorized request"
 	case UnsupportedMediaType:
 		return "unsupported media type"
+	case RateLimited:
+		return "rate limit exceeded"
 	default:
 		return "unknown error kind"
 	}
@@ -39,6 +39,7 @@ func HTTPErrorResponse(w http.ResponseWriter, lgr zerolog.Logger, err error) {
 
 	var e *Error
 	if errors.As(err, &e) {
+		recordErrorKind(w, e.Kind)
 		switch e.Kind {
 		case Unauthenticated:
 			unauthenticatedErrorResponse(w, lgr, e)
@@ -52,9 +53,23 @@ func HTTPErrorResponse(w http.ResponseWriter, lgr zerolog.Logger, err error) {
 		}
 	}
 
+	recordErrorKind(w, Unanticipated)
 	unknownErrorResponse(w, lgr, err)
 }
 
+// ErrorKindRecorder is implemented by an http.ResponseWriter which
+// wants to know the Kind of the error sent by HTTPErrorResponse, e.g.
+// to count errors by Kind
+type ErrorKindRecorder interface {
+	RecordErrorKind(k Kind)
+}
+
+func recordErrorKind(w http.ResponseWriter, k Kind) {
+	if kr, ok := w.(ErrorKindRecorder); ok {
+		kr.RecordErrorKind(k)
+	}
+}
+
 // typicalErrorResponse replies to the request with the specified error
 // message and HTTP code. It does not otherwise end the request; the
 // caller should ensure no further writes are done to w.
@@ -270,6 +285,8 @@ func httpErrorStatusCode(k Kind) int {
 		return http.StatusConflict
 	case UnsupportedMediaType:
 		return http.StatusUnsupportedMediaType
+	case RateLimited:
+		return http.StatusTooManyRequests
 	// the zero value of Kind is Other, so if no Kind is present
 	// in the error, Other is used. Errors should always have a
 	// Kind set, otherwise, a 500 will be returned and no
@@ -3,9 +3,11 @@ package errs
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -35,6 +37,7 @@ func Test_httpErrorStatusCode(t *testing.T) {
 		{"Internal", args{k: Internal}, http.StatusInternalServerError},
 		{"Database", args{k: Database}, http.StatusInternalServerError},
 		{"Unanticipated", args{k: Unanticipated}, http.StatusInternalServerError},
+		{"RateLimited", args{k: RateLimited}, http.StatusTooManyRequests},
 		{"Default", args{k: 99}, http.StatusInternalServerError},
 	}
 	for _, tt := range tests {
@@ -68,6 +71,7 @@ func TestHTTPErrorResponse_StatusCode(t *testing.T) {
 		{"empty *Error", args{httptest.NewRecorder(), l, &Error{}}, http.StatusInternalServerError},
 		{"unauthenticated", args{httptest.NewRecorder(), l, unauthenticatedErr}, http.StatusUnauthorized},
 		{"unauthorized", args{httptest.NewRecorder(), l, unauthorizedErr}, http.StatusForbidden},
+		{"rate limited", args{httptest.NewRecorder(), l, E(RateLimited, "rate limit exceeded")}, http.StatusTooManyRequests},
 	}
 
 	for _, tt := range tests {
@@ -113,3 +117,38 @@ func TestHTTPErrorResponse_Body(t *testing.T) {
 		})
 	}
 }
+
+// kindRecorder is a ResponseRecorder which records the error Kind sent
+type kindRecorder struct {
+	*httptest.ResponseRecorder
+	kinds []Kind
+}
+
+func (r *kindRecorder) RecordErrorKind(k Kind) {
+	r.kinds = append(r.kinds, k)
+}
+
+func TestHTTPErrorResponse_RecordErrorKind(t *testing.T) {
+	l := zerolog.Nop()
+
+	tests := []struct {
+		name string
+		err  error
+		want []Kind
+	}{
+		{"nil error", nil, nil},
+		{"validation", E(Validation, "title is required"), []Kind{Validation}},
+		{"unauthorized", E(Unauthorized, "some authorization error"), []Kind{Unauthorized}},
+		{"unknown error", fmt.Errorf("not an *Error"), []Kind{Unanticipated}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &kindRecorder{ResponseRecorder: httptest.NewRecorder()}
+			HTTPErrorResponse(w, l, tt.err)
+			if !reflect.DeepEqual(w.kinds, tt.want) {
+				t.Errorf("recorded kinds = %v, want %v", w.kinds, tt.want)
+			}
+		})
+	}
+}
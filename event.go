@@ -0,0 +1,83 @@
+package diygoapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+// EventType is the type of a domain event, named for the entity and
+// what happened to it, e.g. movie.created
+type EventType string
+
+// Event types
+const (
+	MovieCreatedEvent  EventType = "movie.created"
+	MovieUpdatedEvent  EventType = "movie.updated"
+	MovieDeletedEvent  EventType = "movie.deleted"
+	MovieRestoredEvent EventType = "movie.restored"
+	OrgCreatedEvent    EventType = "org.created"
+	OrgUpdatedEvent    EventType = "org.updated"
+	OrgDeletedEvent    EventType = "org.deleted"
+	OrgRestoredEvent   EventType = "org.restored"
+	AppCreatedEvent    EventType = "app.created"
+	AppUpdatedEvent    EventType = "app.updated"
+	AppDeletedEvent    EventType = "app.deleted"
+	AppRestoredEvent   EventType = "app.restored"
+)
+
+// EventTypes lists every EventType
+var EventTypes = []EventType{
+	MovieCreatedEvent, MovieUpdatedEvent, MovieDeletedEvent, MovieRestoredEvent,
+	OrgCreatedEvent, OrgUpdatedEvent, OrgDeletedEvent, OrgRestoredEvent,
+	AppCreatedEvent, AppUpdatedEvent, AppDeletedEvent, AppRestoredEvent,
+}
+
+// ParseEventType initializes an EventType given a string
+func ParseEventType(s string) (EventType, error) {
+	const op errs.Op = "diygoapi/ParseEventType"
+
+	for _, et := range EventTypes {
+		if string(et) == s {
+			return et, nil
+		}
+	}
+
+	return "", errs.E(op, errs.Validation, errs.Parameter("event_types"), fmt.Sprintf("unknown event type %q", s))
+}
+
+// EventServicer streams the domain events of an Org
+type EventServicer interface {
+	Stream(ctx context.Context, r *StreamEventsRequest, adt Audit, sender EventSender) error
+}
+
+// StreamEventsRequest is the request struct for streaming events.
+// LastEventID is the Sequence of the last event received, to resume a
+// stream after it. If empty, only events written from now on are sent.
+type StreamEventsRequest struct {
+	LastEventID string
+}
+
+// EventSender sends the events of a stream to a client. Heartbeat is
+// called once the stream is set up and whenever it has been idle for
+// a while, to tell the client it is still open.
+type EventSender interface {
+	Send(e *EventResponse) error
+	Heartbeat() error
+}
+
+// EventResponse is the response struct for a domain event. Data is the
+// entity the event is about, as it was after the change, or before it
+// for a delete. Sequence orders the events of a stream and is sent as
+// the event ID of a Server-Sent Events stream, it is not part of the
+// JSON.
+type EventResponse struct {
+	Sequence      int64           `json:"-"`
+	ExternalID    string          `json:"id"`
+	Type          EventType       `json:"type"`
+	SubjectExtlID string          `json:"subject_extl_id"`
+	CreateTime    string          `json:"create_date_time"`
+	Data          json.RawMessage `json:"data"`
+}
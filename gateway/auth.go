@@ -6,22 +6,28 @@ import (
 	"context"
 	"time"
 
+	"go.opentelemetry.io/otel"
 	"golang.org/x/oauth2"
 	googleoauth "google.golang.org/api/oauth2/v2"
 	"google.golang.org/api/option"
 
 	"github.com/gilcrest/diygoapi"
 	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/tracing"
 )
 
+var tracer = otel.Tracer("github.com/gilcrest/diygoapi/gateway")
+
 // Oauth2TokenExchange is used to convert an oauth2.Token to a ProviderInfo
 // struct from details returned from a provider API
 type Oauth2TokenExchange struct{}
 
 // Exchange calls the Google Userinfo API with the access token and converts
 // the Userinfo struct to a User struct
-func (e Oauth2TokenExchange) Exchange(ctx context.Context, realm string, provider diygoapi.Provider, token *oauth2.Token) (*diygoapi.ProviderInfo, error) {
+func (e Oauth2TokenExchange) Exchange(ctx context.Context, realm string, provider diygoapi.Provider, token *oauth2.Token) (pi *diygoapi.ProviderInfo, err error) {
 	const op errs.Op = "gateway/Oauth2TokenExchange.Exchange"
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
 
 	switch provider {
 	case diygoapi.Google:
@@ -32,7 +38,8 @@ func (e Oauth2TokenExchange) Exchange(ctx context.Context, realm string, provide
 }
 
 // googleTokenExchange makes a request to Google's OAuth2 API and
-// populates ProviderInfo based on the response.
+// populates ProviderInfo based on the response. The Google API client
+// propagates the trace context of ctx to Google.
 func googleTokenExchange(ctx context.Context, realm string, token *oauth2.Token) (*diygoapi.ProviderInfo, error) {
 	const op errs.Op = "gateway/googleTokenExchange"
 
@@ -48,7 +55,7 @@ func googleTokenExchange(ctx context.Context, realm string, token *oauth2.Token)
 
 	// make api call to get metadata about the token
 	var tokenInfo *googleoauth.Tokeninfo
-	tokenInfo, err = oauthService.Tokeninfo().Do()
+	tokenInfo, err = oauthService.Tokeninfo().Context(ctx).Do()
 	if err != nil {
 		return nil, errs.E(op, errs.Unauthenticated, errs.Realm(realm), err)
 	}
@@ -65,7 +72,7 @@ func googleTokenExchange(ctx context.Context, realm string, token *oauth2.Token)
 	pti.Token.Expiry = time.Now().Add(time.Duration(tokenInfo.ExpiresIn) * time.Second)
 
 	var userinfo *googleoauth.Userinfo
-	userinfo, err = oauthService.Userinfo.Get().Do()
+	userinfo, err = oauthService.Userinfo.Get().Context(ctx).Do()
 	if err != nil {
 		// "In summary, a 401 Unauthorized response should be used for missing or
 		// bad authentication, and a 403 Forbidden response should be used afterward,
@@ -0,0 +1,28 @@
+package diygoapi
+
+import "context"
+
+// HealthChecker checks a dependency the server needs to serve
+// requests, e.g. the database. Checkers are registered with the server
+// and run on each readiness check.
+type HealthChecker interface {
+	// CheckHealth returns an error if the dependency is unhealthy
+	CheckHealth(ctx context.Context) error
+}
+
+// HealthCheckerFunc allows a function to be used as a HealthChecker,
+// e.g. HealthCheckerFunc(db.Ping)
+type HealthCheckerFunc func(ctx context.Context) error
+
+// CheckHealth calls f(ctx)
+func (f HealthCheckerFunc) CheckHealth(ctx context.Context) error {
+	return f(ctx)
+}
+
+// HealthResponse is the response struct for the liveness and
+// readiness endpoints. Checks holds the outcome of each readiness
+// check by name, either "ok" or "unavailable".
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
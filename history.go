@@ -0,0 +1,50 @@
+package diygoapi
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// EntityType identifies a kind of domain entity which has its
+// changes recorded in the change history.
+type EntityType string
+
+// Entity types which have their change history recorded
+const (
+	MovieEntityType      EntityType = "movie"
+	OrgEntityType        EntityType = "org"
+	AppEntityType        EntityType = "app"
+	RoleEntityType       EntityType = "role"
+	PermissionEntityType EntityType = "permission"
+)
+
+// ChangeAction is the action taken against a domain entity
+type ChangeAction string
+
+// Change actions recorded in the change history
+const (
+	CreateChangeAction  ChangeAction = "create"
+	UpdateChangeAction  ChangeAction = "update"
+	DeleteChangeAction  ChangeAction = "delete"
+	RestoreChangeAction ChangeAction = "restore"
+)
+
+// ChangeHistoryServicer reads the append-only change history of a domain entity.
+type ChangeHistoryServicer interface {
+	FindByExternalID(ctx context.Context, et EntityType, extlID string, adt Audit) ([]*ChangeHistoryResponse, error)
+}
+
+// ChangeHistoryResponse is the response struct for a single change
+// made to a domain entity. Before is null for a create or restore and
+// After is null for a delete.
+type ChangeHistoryResponse struct {
+	EntityType          string          `json:"entity_type"`
+	ExternalID          string          `json:"external_id"`
+	Action              string          `json:"action"`
+	Before              json.RawMessage `json:"before"`
+	After               json.RawMessage `json:"after"`
+	ChangeAppExtlID     string          `json:"change_app_extl_id"`
+	ChangeUserFirstName string          `json:"change_user_first_name"`
+	ChangeUserLastName  string          `json:"change_user_last_name"`
+	ChangeDateTime      string          `json:"change_date_time"`
+}
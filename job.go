@@ -0,0 +1,178 @@
+package diygoapi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+// DefaultJobMaxAttempts is the number of attempts a job is given
+// before it is dead-lettered, unless set otherwise
+const DefaultJobMaxAttempts = 5
+
+// Retry backoff for failed jobs. The delay doubles with each attempt,
+// starting at JobBackoffBase and never exceeding JobBackoffMax.
+const (
+	JobBackoffBase = 10 * time.Second
+	JobBackoffMax  = time.Hour
+)
+
+// Job list limits
+const (
+	DefaultJobListLimit = 50
+	MaxJobListLimit     = 500
+)
+
+// JobState is the state of a background job
+type JobState string
+
+// Job states. A failed job is queued again until it runs out of
+// attempts, after which it is dead and only runs again if retried.
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobDead      JobState = "dead"
+)
+
+// ParseJobState initializes a JobState given a case-insensitive string
+func ParseJobState(s string) (JobState, error) {
+	const op errs.Op = "diygoapi/ParseJobState"
+
+	switch js := JobState(strings.ToLower(s)); js {
+	case JobQueued, JobRunning, JobSucceeded, JobDead:
+		return js, nil
+	}
+
+	return "", errs.E(op, errs.Validation, errs.Parameter("state"),
+		"state must be one of queued, running, succeeded or dead")
+}
+
+// Job is a unit of work run in the background by a JobHandler
+// registered for its Kind. Payload is the input of the job as JSON.
+type Job struct {
+	ID          uuid.UUID
+	ExternalID  secure.Identifier
+	Kind        string
+	Payload     json.RawMessage
+	State       JobState
+	Attempts    int
+	MaxAttempts int
+	RunAt       time.Time
+	LastError   string
+}
+
+// NewJob initializes a Job of the given kind to run as soon as
+// possible, marshaling payload to JSON
+func NewJob(kind string, payload any) (Job, error) {
+	const op errs.Op = "diygoapi/NewJob"
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, errs.E(op, errs.Internal, err)
+	}
+
+	j := Job{
+		ID:          uuid.New(),
+		ExternalID:  secure.NewID(),
+		Kind:        kind,
+		Payload:     b,
+		State:       JobQueued,
+		MaxAttempts: DefaultJobMaxAttempts,
+	}
+
+	err = j.IsValid()
+	if err != nil {
+		return Job{}, errs.E(op, err)
+	}
+
+	return j, nil
+}
+
+// IsValid performs validation of the struct
+func (j *Job) IsValid() error {
+	const op errs.Op = "diygoapi/Job.IsValid"
+
+	switch {
+	case j.ExternalID.String() == "":
+		return errs.E(op, errs.Validation, errs.Parameter("extlID"), errs.MissingField("extlID"))
+	case j.Kind == "":
+		return errs.E(op, errs.Validation, errs.Parameter("kind"), errs.MissingField("kind"))
+	case len(j.Kind) > 100:
+		return errs.E(op, errs.Validation, errs.Parameter("kind"), "kind must be 100 characters or less")
+	case j.MaxAttempts < 1:
+		return errs.E(op, errs.Validation, errs.Parameter("max_attempts"), "max_attempts must be at least 1")
+	}
+
+	return nil
+}
+
+// JobBackoff returns how long to wait before running a job again after
+// its given attempt failed
+func JobBackoff(attempt int) time.Duration {
+	d := JobBackoffBase
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= JobBackoffMax {
+			return JobBackoffMax
+		}
+	}
+	return d
+}
+
+// JobHandler runs jobs of a kind. A returned error fails the attempt
+// and the job is retried with backoff until it runs out of attempts.
+type JobHandler interface {
+	HandleJob(ctx context.Context, j Job) error
+}
+
+// JobHandlerFunc is an adapter to allow the use of an ordinary
+// function as a JobHandler
+type JobHandlerFunc func(ctx context.Context, j Job) error
+
+// HandleJob calls f(ctx, j)
+func (f JobHandlerFunc) HandleJob(ctx context.Context, j Job) error {
+	return f(ctx, j)
+}
+
+// JobWorkers claim queued jobs and run them in the background.
+// Shutdown stops claiming jobs and waits for the running ones to
+// finish, or for ctx to be done.
+type JobWorkers interface {
+	Start(ctx context.Context)
+	Shutdown(ctx context.Context) error
+}
+
+// JobServicer is used to inspect and retry background jobs
+type JobServicer interface {
+	FindJobs(ctx context.Context, r *FindJobsRequest) ([]*JobResponse, error)
+	FindJobByExternalID(ctx context.Context, extlID string) (*JobResponse, error)
+	Retry(ctx context.Context, extlID string) (*JobResponse, error)
+}
+
+// FindJobsRequest is the request struct for listing jobs. An empty
+// State lists jobs in every state. If Limit is zero,
+// DefaultJobListLimit is used.
+type FindJobsRequest struct {
+	State JobState
+	Limit int
+}
+
+// JobResponse is the response struct for a Job
+type JobResponse struct {
+	ExternalID     string          `json:"external_id"`
+	Kind           string          `json:"kind"`
+	Payload        json.RawMessage `json:"payload"`
+	State          JobState        `json:"state"`
+	Attempts       int             `json:"attempts"`
+	MaxAttempts    int             `json:"max_attempts"`
+	RunAt          string          `json:"run_at"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreateDateTime string          `json:"create_date_time"`
+	UpdateDateTime string          `json:"update_date_time"`
+}
@@ -0,0 +1,55 @@
+package diygoapi_test
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+func TestParseJobState(t *testing.T) {
+	c := qt.New(t)
+
+	js, err := diygoapi.ParseJobState("DEAD")
+	c.Assert(err, qt.IsNil)
+	c.Assert(js, qt.Equals, diygoapi.JobDead)
+
+	_, err = diygoapi.ParseJobState("failed")
+	c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+}
+
+func TestNewJob(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c := qt.New(t)
+
+		j, err := diygoapi.NewJob("email.send", map[string]string{"to": "otto@example.com"})
+		c.Assert(err, qt.IsNil)
+		c.Assert(j.State, qt.Equals, diygoapi.JobQueued)
+		c.Assert(j.MaxAttempts, qt.Equals, diygoapi.DefaultJobMaxAttempts)
+		c.Assert(string(j.Payload), qt.Equals, `{"to":"otto@example.com"}`)
+	})
+	t.Run("missing kind", func(t *testing.T) {
+		c := qt.New(t)
+
+		_, err := diygoapi.NewJob("", nil)
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+	})
+	t.Run("unmarshalable payload", func(t *testing.T) {
+		c := qt.New(t)
+
+		_, err := diygoapi.NewJob("email.send", make(chan int))
+		c.Assert(errs.KindIs(errs.Internal, err), qt.IsTrue)
+	})
+}
+
+func TestJobBackoff(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(diygoapi.JobBackoff(1), qt.Equals, diygoapi.JobBackoffBase)
+	c.Assert(diygoapi.JobBackoff(2), qt.Equals, 2*diygoapi.JobBackoffBase)
+	c.Assert(diygoapi.JobBackoff(3), qt.Equals, 4*diygoapi.JobBackoffBase)
+	c.Assert(diygoapi.JobBackoff(100), qt.Equals, time.Hour)
+}
@@ -0,0 +1,236 @@
+package logger
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Levels is a zerolog.Hook which discards events below the level set
+// for the component writing them, or below the default level if none
+// is set.
+//
+// A component is an errs.Op (e.g. "service/MovieService.Create") or a
+// handler pattern (e.g. "GET /api/v1/movies/{extlID}"), added to the
+// context given to the logger with NewContextWithComponent. A level
+// set for a component also applies to the components it prefixes at a
+// path or method boundary, so "service" covers every service Op and
+// "service/MovieService" every MovieService method. The longest
+// matching key wins, and the component added last (the innermost) is
+// matched first.
+//
+// Levels given a TTL revert once it has passed: a component level is
+// removed and a default level returns to the one set before. A level
+// set for a single request with NewContextWithRequestLevel applies if
+// it is lower than the one otherwise resolved.
+//
+// Levels can only discard events, a logger's own level (the minimum
+// accepted level) and the zerolog global level still apply.
+type Levels struct {
+	now func() time.Time
+
+	mu         sync.RWMutex
+	base       zerolog.Level
+	raised     *expiringLevel
+	components map[string]expiringLevel
+}
+
+// expiringLevel is a level which expires at expires, or never if it
+// is zero
+type expiringLevel struct {
+	level   zerolog.Level
+	expires time.Time
+}
+
+// expired reports if the level has expired at now
+func (el expiringLevel) expired(now time.Time) bool {
+	return !el.expires.IsZero() && !now.Before(el.expires)
+}
+
+// ComponentLevel is the level set for a component
+type ComponentLevel struct {
+	Component string
+	Level     zerolog.Level
+	// Expires is when the level is removed, zero if never
+	Expires time.Time
+}
+
+// NewLevels returns Levels with lvl as the default level
+func NewLevels(lvl zerolog.Level) *Levels {
+	return &Levels{
+		now:        time.Now,
+		base:       lvl,
+		components: make(map[string]expiringLevel),
+	}
+}
+
+// expiry returns when a level set with ttl expires, zero if ttl is
+// not positive
+func (l *Levels) expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return l.now().Add(ttl)
+}
+
+// SetDefault sets the default level. If ttl is positive, the level
+// reverts to the one set before once ttl has passed.
+func (l *Levels) SetDefault(lvl zerolog.Level, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if ttl <= 0 {
+		l.base, l.raised = lvl, nil
+		return
+	}
+	l.raised = &expiringLevel{level: lvl, expires: l.expiry(ttl)}
+}
+
+// Default returns the default level and when it reverts, zero if it
+// does not
+func (l *Levels) Default() (zerolog.Level, time.Time) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.raised != nil && !l.raised.expired(l.now()) {
+		return l.raised.level, l.raised.expires
+	}
+	return l.base, time.Time{}
+}
+
+// SetComponent sets the level for component. If ttl is positive, the
+// level is removed once ttl has passed.
+func (l *Levels) SetComponent(component string, lvl zerolog.Level, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.components[component] = expiringLevel{level: lvl, expires: l.expiry(ttl)}
+}
+
+// RemoveComponent removes the level set for component, if any
+func (l *Levels) RemoveComponent(component string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.components, component)
+}
+
+// Components returns the levels set for components, sorted by
+// component. Expired levels are removed.
+func (l *Levels) Components() []ComponentLevel {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	cls := make([]ComponentLevel, 0, len(l.components))
+	for c, el := range l.components {
+		if el.expired(now) {
+			delete(l.components, c)
+			continue
+		}
+		cls = append(cls, ComponentLevel{Component: c, Level: el.level, Expires: el.expires})
+	}
+	sort.Slice(cls, func(i, j int) bool { return cls[i].Component < cls[j].Component })
+
+	return cls
+}
+
+// Run satisfies the zerolog.Hook interface and discards the event if
+// its level is below the level resolved from the event's context
+func (l *Levels) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if level == zerolog.NoLevel {
+		return
+	}
+	if level < l.level(e.GetCtx()) {
+		e.Discard()
+	}
+}
+
+// level resolves the level for events written with ctx
+func (l *Levels) level(ctx context.Context) zerolog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	now := l.now()
+	lvl := l.base
+	if l.raised != nil && !l.raised.expired(now) {
+		lvl = l.raised.level
+	}
+
+	cn, _ := ctx.Value(componentKey{}).(*componentNode)
+	for ; cn != nil && len(l.components) > 0; cn = cn.parent {
+		if el, ok := l.match(cn.component, now); ok {
+			lvl = el.level
+			break
+		}
+	}
+
+	if rlvl, ok := ctx.Value(requestLevelKey{}).(zerolog.Level); ok && rlvl < lvl {
+		lvl = rlvl
+	}
+
+	return lvl
+}
+
+// match returns the unexpired level set for the longest key matching
+// component
+func (l *Levels) match(component string, now time.Time) (expiringLevel, bool) {
+	var (
+		found  expiringLevel
+		keyLen = -1
+	)
+	for key, el := range l.components {
+		if len(key) <= keyLen || el.expired(now) || !componentMatches(key, component) {
+			continue
+		}
+		found, keyLen = el, len(key)
+	}
+	return found, keyLen >= 0
+}
+
+// componentMatches reports if key is component, or prefixes it at a
+// path ("/") or method (".") boundary
+func componentMatches(key, component string) bool {
+	rest, ok := strings.CutPrefix(component, key)
+	if !ok {
+		return false
+	}
+	return rest == "" || rest[0] == '/' || rest[0] == '.'
+}
+
+// componentKey is the context key for the components of a logger
+type componentKey struct{}
+
+// componentNode is a component added to a context, linked to the
+// one added before it
+type componentNode struct {
+	component string
+	parent    *componentNode
+}
+
+// requestLevelKey is the context key for the level set for a request
+type requestLevelKey struct{}
+
+// NewContextWithComponent returns a new context with component added,
+// for a logger given the context to write at the level set for it
+func NewContextWithComponent(ctx context.Context, component string) context.Context {
+	parent, _ := ctx.Value(componentKey{}).(*componentNode)
+	return context.WithValue(ctx, componentKey{}, &componentNode{component: component, parent: parent})
+}
+
+// NewContextWithRequestLevel returns a new context with lvl, for a
+// logger given the context to write at lvl, if lower than the level
+// set otherwise
+func NewContextWithRequestLevel(ctx context.Context, lvl zerolog.Level) context.Context {
+	return context.WithValue(ctx, requestLevelKey{}, lvl)
+}
+
+// ForComponent returns lgr writing at the level set for component,
+// along with any components or request level already in ctx
+func ForComponent(ctx context.Context, lgr zerolog.Logger, component string) zerolog.Logger {
+	return lgr.With().Ctx(NewContextWithComponent(ctx, component)).Logger()
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/rs/zerolog"
+)
+
+func TestLevels(t *testing.T) {
+	c := qt.New(t)
+
+	now := time.Now()
+	lvls := NewLevels(zerolog.InfoLevel)
+	lvls.now = func() time.Time { return now }
+
+	var b bytes.Buffer
+	lgr := zerolog.New(&b).Level(zerolog.TraceLevel).Hook(lvls)
+
+	// writtenAt reports if an event at lvl is written by lgr given ctx
+	writtenAt := func(ctx context.Context, lvl zerolog.Level) bool {
+		c.Helper()
+		b.Reset()
+		l := lgr.With().Ctx(ctx).Logger()
+		l.WithLevel(lvl).Msg("")
+		return b.Len() > 0
+	}
+	// written reports if a debug event is written by lgr given ctx
+	written := func(ctx context.Context) bool {
+		c.Helper()
+		return writtenAt(ctx, zerolog.DebugLevel)
+	}
+
+	ctx := context.Background()
+	movieCtx := NewContextWithComponent(NewContextWithComponent(ctx, "POST /api/v1/movies"), "service/MovieService.Create")
+	orgCtx := NewContextWithComponent(NewContextWithComponent(ctx, "POST /api/v1/orgs"), "service/OrgService.Create")
+
+	c.Assert(written(ctx), qt.IsFalse)
+	c.Assert(written(movieCtx), qt.IsFalse)
+
+	// a package prefix covers all its Ops
+	lvls.SetComponent("service", zerolog.DebugLevel, 0)
+	c.Assert(written(movieCtx), qt.IsTrue)
+	c.Assert(written(orgCtx), qt.IsTrue)
+	c.Assert(written(ctx), qt.IsFalse)
+
+	// the longest matching key wins
+	lvls.SetComponent("service/MovieService", zerolog.WarnLevel, 0)
+	c.Assert(written(movieCtx), qt.IsFalse)
+	c.Assert(written(orgCtx), qt.IsTrue)
+
+	// keys match only at a boundary
+	lvls.RemoveComponent("service")
+	lvls.RemoveComponent("service/MovieService")
+	lvls.SetComponent("serv", zerolog.DebugLevel, 0)
+	c.Assert(written(movieCtx), qt.IsFalse)
+	lvls.RemoveComponent("serv")
+
+	// the innermost component is matched first
+	lvls.SetComponent("POST /api/v1/movies", zerolog.DebugLevel, 0)
+	c.Assert(written(movieCtx), qt.IsTrue)
+	lvls.SetComponent("service/MovieService.Create", zerolog.InfoLevel, 0)
+	c.Assert(written(movieCtx), qt.IsFalse)
+	lvls.RemoveComponent("service/MovieService.Create")
+
+	// a component level set with a TTL is removed after it
+	lvls.SetComponent("POST /api/v1/orgs", zerolog.DebugLevel, time.Minute)
+	c.Assert(written(orgCtx), qt.IsTrue)
+	c.Assert(lvls.Components(), qt.DeepEquals, []ComponentLevel{
+		{Component: "POST /api/v1/movies", Level: zerolog.DebugLevel},
+		{Component: "POST /api/v1/orgs", Level: zerolog.DebugLevel, Expires: now.Add(time.Minute)},
+	})
+	now = now.Add(time.Minute)
+	c.Assert(written(orgCtx), qt.IsFalse)
+	c.Assert(lvls.Components(), qt.DeepEquals, []ComponentLevel{
+		{Component: "POST /api/v1/movies", Level: zerolog.DebugLevel},
+	})
+
+	// a default level set with a TTL reverts after it
+	lvls.SetDefault(zerolog.DebugLevel, time.Minute)
+	c.Assert(written(ctx), qt.IsTrue)
+	lvl, expires := lvls.Default()
+	c.Assert(lvl, qt.Equals, zerolog.DebugLevel)
+	c.Assert(expires, qt.Equals, now.Add(time.Minute))
+	now = now.Add(time.Minute)
+	c.Assert(written(ctx), qt.IsFalse)
+	lvl, expires = lvls.Default()
+	c.Assert(lvl, qt.Equals, zerolog.InfoLevel)
+	c.Assert(expires.IsZero(), qt.IsTrue)
+
+	// a request level applies only if lower
+	c.Assert(written(NewContextWithRequestLevel(ctx, zerolog.DebugLevel)), qt.IsTrue)
+	lvls.SetDefault(zerolog.ErrorLevel, 0)
+	c.Assert(writtenAt(NewContextWithRequestLevel(ctx, zerolog.WarnLevel), zerolog.WarnLevel), qt.IsTrue)
+	c.Assert(writtenAt(NewContextWithRequestLevel(ctx, zerolog.FatalLevel), zerolog.ErrorLevel), qt.IsTrue)
+
+	// ForComponent adds to the components already in the context
+	lvls.SetComponent("service/MovieService.Create", zerolog.DebugLevel, 0)
+	b.Reset()
+	l := ForComponent(NewContextWithComponent(ctx, "POST /api/v1/movies"), lgr, "service/MovieService.Create")
+	l.Debug().Msg("")
+	c.Assert(b.Len() > 0, qt.IsTrue)
+}
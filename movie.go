@@ -2,7 +2,12 @@ package diygoapi
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/gilcrest/diygoapi/errs"
 	"github.com/gilcrest/diygoapi/secure"
@@ -13,25 +18,47 @@ import (
 type MovieServicer interface {
 	Create(ctx context.Context, r *CreateMovieRequest, adt Audit) (*MovieResponse, error)
 	Update(ctx context.Context, r *UpdateMovieRequest, adt Audit) (*MovieResponse, error)
-	Delete(ctx context.Context, extlID string) (DeleteResponse, error)
-	FindMovieByExternalID(ctx context.Context, extlID string) (*MovieResponse, error)
-	FindAllMovies(ctx context.Context) ([]*MovieResponse, error)
+	Delete(ctx context.Context, extlID string, adt Audit) (DeleteResponse, error)
+	Restore(ctx context.Context, extlID string, adt Audit) (*MovieResponse, error)
+	Import(ctx context.Context, r *ImportMoviesRequest, adt Audit) (*ImportMoviesResponse, error)
+	Export(ctx context.Context, f ExportFormat, w io.Writer, adt Audit) error
+	FindMovieByExternalID(ctx context.Context, extlID string, adt Audit) (*MovieResponse, error)
+	FindAllMovies(ctx context.Context, adt Audit) ([]*MovieResponse, error)
+	FindMoviesByCredit(ctx context.Context, r *FindMoviesByCreditRequest, adt Audit) ([]*MovieResponse, error)
+	FindMoviesByGenre(ctx context.Context, genre string, adt Audit) ([]*MovieResponse, error)
+	FindAllGenres(ctx context.Context) ([]*GenreResponse, error)
+	FindAllRatings(ctx context.Context) ([]*RatingResponse, error)
+	Search(ctx context.Context, r *SearchMoviesRequest, adt Audit) ([]*MovieSearchResponse, error)
 }
 
-// Movie holds details of a movie
+// Movie holds details of a movie.
+//
+// Director and Writer summarize the director and writer Credits as a
+// comma separated list of names. Rated is a rating from the rating
+// system of RatingCountry. ReviewCount and ReviewScoreTotal are the
+// number of reviews of the movie and the sum of their scores. Poster
+// is nil until a poster image is uploaded.
 type Movie struct {
-	ID         uuid.UUID
-	ExternalID secure.Identifier
-	Title      string
-	Rated      string
-	Released   time.Time
-	RunTime    int64
-	Director   string
-	Writer     string
-}
-
-// IsValid performs validation of the struct
-func (m *Movie) IsValid() error {
+	ID            uuid.UUID
+	ExternalID    secure.Identifier
+	Title         string
+	Rated         string
+	RatingCountry string
+	Released      time.Time
+	RunTime       int64
+	Director      string
+	Writer        string
+	Credits       []Credit
+	Genres        []Genre
+	Poster        *Poster
+
+	ReviewCount      int64
+	ReviewScoreTotal int64
+}
+
+// IsValid performs validation of the struct. Rated must be one of the
+// given ratings for the movie's RatingCountry.
+func (m *Movie) IsValid(ratings Ratings) error {
 	const op errs.Op = "diygoapi/Movie.IsValid"
 
 	switch {
@@ -41,6 +68,8 @@ func (m *Movie) IsValid() error {
 		return errs.E(op, errs.Validation, errs.Parameter("title"), errs.MissingField("title"))
 	case m.Rated == "":
 		return errs.E(op, errs.Validation, errs.Parameter("rated"), errs.MissingField("rated"))
+	case m.RatingCountry == "":
+		return errs.E(op, errs.Validation, errs.Parameter("rating_country"), errs.MissingField("rating_country"))
 	case m.Released.IsZero():
 		return errs.E(op, errs.Validation, errs.Parameter("release_date"), "release_date must have a value")
 	case m.RunTime <= 0:
@@ -51,45 +80,328 @@ func (m *Movie) IsValid() error {
 		return errs.E(op, errs.Validation, errs.Parameter("writer"), errs.MissingField("writer"))
 	}
 
+	codes := ratings.Codes(m.RatingCountry)
+	if len(codes) == 0 {
+		return errs.E(op, errs.Validation, errs.Parameter("rating_country"), errs.Code("invalid_rating_country"),
+			fmt.Sprintf("there is no rating system for rating_country %s", m.RatingCountry))
+	}
+	if !slices.Contains(codes, m.Rated) {
+		return errs.E(op, errs.Validation, errs.Parameter("rated"), errs.Code("invalid_rating"),
+			fmt.Sprintf("rated must be one of %s", strings.Join(codes, ", ")))
+	}
+
+	for _, c := range m.Credits {
+		if c.PersonName == "" {
+			return errs.E(op, errs.Validation, errs.Parameter("credits"), fmt.Sprintf("%s credit must have a name", c.Role))
+		}
+	}
+
 	return nil
 }
 
-// CreateMovieRequest is the request struct for Creating a Movie
+// DefaultRatingCountry is the country of the rating system a movie is
+// rated in when no country is given
+const DefaultRatingCountry = "US"
+
+// Rating is a rating from a country's rating system, e.g. the MPAA's
+// PG-13 in the US
+type Rating struct {
+	ID          uuid.UUID
+	System      string
+	Country     string
+	Code        string
+	Description string
+}
+
+// Ratings is the controlled vocabulary of ratings a movie can be
+// given, for every rating system, in display order
+type Ratings []Rating
+
+// Codes returns the rating codes of the country's rating system. The
+// country is matched case-insensitively.
+func (rs Ratings) Codes(country string) []string {
+	var codes []string
+	for _, r := range rs {
+		if strings.EqualFold(r.Country, country) {
+			codes = append(codes, r.Code)
+		}
+	}
+	return codes
+}
+
+// Normalize returns the rating of the country's rating system which
+// matches s ignoring case, spaces and punctuation, e.g. pg13 or
+// "PG 13" is PG-13. If no rating matches, s is returned unchanged.
+func (rs Ratings) Normalize(country, s string) string {
+	key := ratingKey(s)
+	for _, r := range rs {
+		if strings.EqualFold(r.Country, country) && ratingKey(r.Code) == key {
+			return r.Code
+		}
+	}
+	return s
+}
+
+// ratingKey reduces a rating to its upper case letters and digits
+func ratingKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, s)
+}
+
+// RatingResponse is the response struct for a Rating
+type RatingResponse struct {
+	System      string `json:"system"`
+	Country     string `json:"country"`
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// CreditRole is the part a person had in making a movie
+type CreditRole string
+
+// Movie credit roles
+const (
+	DirectorCreditRole CreditRole = "director"
+	WriterCreditRole   CreditRole = "writer"
+	ActorCreditRole    CreditRole = "actor"
+	ProducerCreditRole CreditRole = "producer"
+)
+
+// ParseCreditRole initializes a CreditRole given a case-insensitive string
+func ParseCreditRole(s string) (CreditRole, error) {
+	const op errs.Op = "diygoapi/ParseCreditRole"
+
+	switch r := CreditRole(strings.ToLower(s)); r {
+	case DirectorCreditRole, WriterCreditRole, ActorCreditRole, ProducerCreditRole:
+		return r, nil
+	}
+
+	return "", errs.E(op, errs.Validation, errs.Parameter("role"),
+		"role must be one of director, writer, actor or producer")
+}
+
+// Credit is a person's credit for their part in a movie. Order is the
+// billing order of the credit among the movie's credits with the same
+// role, starting at 1. Character is only given for actors.
+type Credit struct {
+	Role       CreditRole
+	PersonName string
+	Character  string
+	Order      int
+}
+
+// Genre is a category from the genre taxonomy, e.g. horror
+type Genre struct {
+	ID   uuid.UUID
+	Code string
+	Name string
+}
+
+// CreditRequest is the request struct for a movie credit. Credits are
+// billed in the order given within each role.
+type CreditRequest struct {
+	Role      string `json:"role"`
+	Name      string `json:"name"`
+	Character string `json:"character,omitempty"`
+}
+
+// CreditResponse is the response struct for a movie credit
+type CreditResponse struct {
+	Role      string `json:"role"`
+	Name      string `json:"name"`
+	Character string `json:"character,omitempty"`
+	Order     int    `json:"order"`
+}
+
+// GenreResponse is the response struct for a Genre
+type GenreResponse struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// FindMoviesByCreditRequest is the request struct for finding the
+// movies a person is credited on. Name is matched case-insensitively.
+// If Role is empty, credits in any role are matched.
+type FindMoviesByCreditRequest struct {
+	Name string
+	Role string
+}
+
+// CreateMovieRequest is the request struct for Creating a Movie.
+//
+// Director and Writer can be left empty when Credits includes at
+// least one director and writer, otherwise a director or writer
+// credit is added for them. Genres are genre codes, e.g. horror.
+// Rated is a rating of the RatingCountry's rating system, or of the
+// DefaultRatingCountry if RatingCountry is empty.
 type CreateMovieRequest struct {
-	Title    string `json:"title"`
-	Rated    string `json:"rated"`
-	Released string `json:"release_date"`
-	RunTime  int64  `json:"run_time"`
-	Director string `json:"director"`
-	Writer   string `json:"writer"`
+	Title         string          `json:"title"`
+	Rated         string          `json:"rated"`
+	RatingCountry string          `json:"rating_country,omitempty"`
+	Released      string          `json:"release_date"`
+	RunTime       int64           `json:"run_time"`
+	Director      string          `json:"director"`
+	Writer        string          `json:"writer"`
+	Credits       []CreditRequest `json:"credits,omitempty"`
+	Genres        []string        `json:"genres,omitempty"`
 }
 
-// UpdateMovieRequest is the request struct for updating a Movie
+// UpdateMovieRequest is the request struct for updating a Movie.
+//
+// Credits and Genres replace the movie's existing credits and genres.
+// If either is omitted (nil), the existing ones are kept, except that
+// a changed Director or Writer replaces the director or writer credits.
+// If RatingCountry is empty, the movie's existing rating country is kept.
 type UpdateMovieRequest struct {
-	ExternalID string
-	Title      string `json:"title"`
-	Rated      string `json:"rated"`
-	Released   string `json:"release_date"`
-	RunTime    int64  `json:"run_time"`
-	Director   string `json:"director"`
-	Writer     string `json:"writer"`
+	ExternalID    string
+	Title         string          `json:"title"`
+	Rated         string          `json:"rated"`
+	RatingCountry string          `json:"rating_country,omitempty"`
+	Released      string          `json:"release_date"`
+	RunTime       int64           `json:"run_time"`
+	Director      string          `json:"director"`
+	Writer        string          `json:"writer"`
+	Credits       []CreditRequest `json:"credits,omitempty"`
+	Genres        []string        `json:"genres,omitempty"`
 }
 
 // MovieResponse is the response struct for a Movie
 type MovieResponse struct {
-	ExternalID          string `json:"external_id"`
-	Title               string `json:"title"`
-	Rated               string `json:"rated"`
-	Released            string `json:"release_date"`
-	RunTime             int64  `json:"run_time"`
-	Director            string `json:"director"`
-	Writer              string `json:"writer"`
-	CreateAppExtlID     string `json:"create_app_extl_id"`
-	CreateUserFirstName string `json:"create_user_first_name"`
-	CreateUserLastName  string `json:"create_user_last_name"`
-	CreateDateTime      string `json:"create_date_time"`
-	UpdateAppExtlID     string `json:"update_app_extl_id"`
-	UpdateUserFirstName string `json:"update_user_first_name"`
-	UpdateUserLastName  string `json:"update_user_last_name"`
-	UpdateDateTime      string `json:"update_date_time"`
+	ExternalID          string           `json:"external_id"`
+	Title               string           `json:"title"`
+	Rated               string           `json:"rated"`
+	RatingCountry       string           `json:"rating_country"`
+	Released            string           `json:"release_date"`
+	RunTime             int64            `json:"run_time"`
+	Director            string           `json:"director"`
+	Writer              string           `json:"writer"`
+	Credits             []CreditResponse `json:"credits"`
+	Genres              []GenreResponse  `json:"genres"`
+	AverageScore        float64          `json:"average_score"`
+	ReviewCount         int64            `json:"review_count"`
+	PosterURL           string           `json:"poster_url,omitempty"`
+	CreateAppExtlID     string           `json:"create_app_extl_id"`
+	CreateUserFirstName string           `json:"create_user_first_name"`
+	CreateUserLastName  string           `json:"create_user_last_name"`
+	CreateDateTime      string           `json:"create_date_time"`
+	UpdateAppExtlID     string           `json:"update_app_extl_id"`
+	UpdateUserFirstName string           `json:"update_user_first_name"`
+	UpdateUserLastName  string           `json:"update_user_last_name"`
+	UpdateDateTime      string           `json:"update_date_time"`
+}
+
+// Movie search result limits
+const (
+	DefaultMovieSearchLimit = 20
+	MaxMovieSearchLimit     = 100
+)
+
+// SearchMoviesRequest is the request struct for searching movies.
+// Query uses web search syntax (quoted phrases, or, -word). If Limit
+// is zero, DefaultMovieSearchLimit is used.
+type SearchMoviesRequest struct {
+	Query string
+	Limit int
+}
+
+// MovieSearchResponse is the response struct for a movie search
+// result. Results with a higher Rank are more relevant. Snippet is an
+// HTML fragment of the title, director and writer, escaped, with the
+// matched words wrapped in <mark> tags.
+type MovieSearchResponse struct {
+	ExternalID string  `json:"external_id"`
+	Title      string  `json:"title"`
+	Rated      string  `json:"rated"`
+	Released   string  `json:"release_date"`
+	RunTime    int64   `json:"run_time"`
+	Director   string  `json:"director"`
+	Writer     string  `json:"writer"`
+	Rank       float32 `json:"rank"`
+	Snippet    string  `json:"snippet"`
+}
+
+// ImportFormat is the encoding of a bulk movie import stream
+type ImportFormat string
+
+// Supported bulk movie import formats
+const (
+	// CSVImportFormat is comma-separated values with a header row naming
+	// the columns using the CreateMovieRequest JSON field names.
+	CSVImportFormat ImportFormat = "csv"
+	// NDJSONImportFormat is newline delimited JSON, one CreateMovieRequest per line.
+	NDJSONImportFormat ImportFormat = "ndjson"
+)
+
+// MaxImportBytes is the size a bulk movie import request body is
+// limited to, well above the JSON request body limit as an import
+// carries many movies. A single NDJSON line may be as long as the body.
+const MaxImportBytes int64 = 32 << 20
+
+// ImportMode determines how a bulk movie import handles rows which fail validation
+type ImportMode string
+
+// Bulk movie import modes
+const (
+	// AllOrNothingImportMode imports nothing if any row is invalid.
+	AllOrNothingImportMode ImportMode = "all_or_nothing"
+	// BestEffortImportMode imports every valid row and skips the rest.
+	BestEffortImportMode ImportMode = "best_effort"
+)
+
+// ParseImportMode initializes an ImportMode given a case-insensitive
+// string. An empty string results in AllOrNothingImportMode.
+func ParseImportMode(s string) (ImportMode, error) {
+	const op errs.Op = "diygoapi/ParseImportMode"
+
+	switch ImportMode(strings.ToLower(s)) {
+	case "", AllOrNothingImportMode:
+		return AllOrNothingImportMode, nil
+	case BestEffortImportMode:
+		return BestEffortImportMode, nil
+	}
+
+	return "", errs.E(op, errs.Validation, errs.Parameter("mode"),
+		"mode must be one of all_or_nothing or best_effort")
+}
+
+// ImportMoviesRequest is the request struct for a bulk movie import
+type ImportMoviesRequest struct {
+	Format ImportFormat
+	Mode   ImportMode
+	Data   io.Reader
+}
+
+// ImportMoviesResponse is the response struct for a bulk movie import
+type ImportMoviesResponse struct {
+	Mode     ImportMode          `json:"mode"`
+	Rows     int                 `json:"rows"`
+	Imported int                 `json:"imported"`
+	Failed   int                 `json:"failed"`
+	Results  []ImportMovieResult `json:"results"`
+}
+
+// ImportMovieResult is the outcome of importing a single row. Line is
+// the line number of the row in the request body. ExternalID is set
+// when the movie was imported, otherwise Error explains why it was not.
+type ImportMovieResult struct {
+	Line       int    `json:"line"`
+	ExternalID string `json:"external_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// MovieExport is a single movie as written by a streaming export
+type MovieExport struct {
+	ExternalID     string `json:"external_id"`
+	Title          string `json:"title"`
+	Rated          string `json:"rated"`
+	Released       string `json:"release_date"`
+	RunTime        int64  `json:"run_time"`
+	Director       string `json:"director"`
+	Writer         string `json:"writer"`
+	CreateDateTime string `json:"create_date_time"`
+	UpdateDateTime string `json:"update_date_time"`
 }
@@ -12,6 +12,17 @@ import (
 	"github.com/gilcrest/diygoapi/uuid"
 )
 
+// testRatings is a subset of the ratings seeded by the database migration
+var testRatings = Ratings{
+	{System: "MPAA", Country: "US", Code: "G"},
+	{System: "MPAA", Country: "US", Code: "PG"},
+	{System: "MPAA", Country: "US", Code: "PG-13"},
+	{System: "MPAA", Country: "US", Code: "R"},
+	{System: "BBFC", Country: "GB", Code: "PG"},
+	{System: "BBFC", Country: "GB", Code: "12A"},
+	{System: "BBFC", Country: "GB", Code: "15"},
+}
+
 func TestMovie_IsValid(t *testing.T) {
 	c := qt.New(t)
 
@@ -19,14 +30,15 @@ func TestMovie_IsValid(t *testing.T) {
 
 	movieFunc := func() *Movie {
 		return &Movie{
-			ID:         uuid.New(),
-			ExternalID: secure.NewID(),
-			Title:      "The Return of the Living Dead",
-			Rated:      "R",
-			Released:   rd,
-			RunTime:    91,
-			Director:   "Dan O'Bannon",
-			Writer:     "Russell Streiner",
+			ID:            uuid.New(),
+			ExternalID:    secure.NewID(),
+			Title:         "The Return of the Living Dead",
+			Rated:         "R",
+			RatingCountry: "US",
+			Released:      rd,
+			RunTime:       91,
+			Director:      "Dan O'Bannon",
+			Writer:        "Russell Streiner",
 		}
 	}
 
@@ -47,6 +59,17 @@ func TestMovie_IsValid(t *testing.T) {
 	m7.Director = ""
 	m8 := movieFunc()
 	m8.Writer = ""
+	m9 := movieFunc()
+	m9.Rated = "PG13"
+	m10 := movieFunc()
+	m10.Rated = "15"
+	m11 := movieFunc()
+	m11.RatingCountry = "GB"
+	m11.Rated = "15"
+	m12 := movieFunc()
+	m12.RatingCountry = ""
+	m13 := movieFunc()
+	m13.RatingCountry = "XX"
 
 	tests := []struct {
 		name    string
@@ -62,10 +85,15 @@ func TestMovie_IsValid(t *testing.T) {
 		{"zero RunTime", m6, errs.E(errs.Validation, errs.Parameter("run_time"), "run_time must be greater than zero")},
 		{"empty Director", m7, errs.E(errs.Validation, errs.Parameter("director"), errs.MissingField("director"))},
 		{"empty Writer", m8, errs.E(errs.Validation, errs.Parameter("writer"), errs.MissingField("writer"))},
+		{"unknown Rated", m9, errs.E(errs.Validation, errs.Parameter("rated"), errs.Code("invalid_rating"), "rated must be one of G, PG, PG-13, R")},
+		{"Rated from another country", m10, errs.E(errs.Validation, errs.Parameter("rated"), errs.Code("invalid_rating"), "rated must be one of G, PG, PG-13, R")},
+		{"GB Rated", m11, nil},
+		{"empty RatingCountry", m12, errs.E(errs.Validation, errs.Parameter("rating_country"), errs.MissingField("rating_country"))},
+		{"unknown RatingCountry", m13, errs.E(errs.Validation, errs.Parameter("rating_country"), errs.Code("invalid_rating_country"), "there is no rating system for rating_country XX")},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			isValidErr := tt.m.IsValid()
+			isValidErr := tt.m.IsValid(testRatings)
 			if (isValidErr != nil) && (tt.wantErr == nil) {
 				t.Errorf("IsValid() error = %v; nil expected", isValidErr)
 				return
@@ -74,3 +102,78 @@ func TestMovie_IsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestParseImportMode(t *testing.T) {
+	c := qt.New(t)
+
+	tests := []struct {
+		name string
+		s    string
+		want ImportMode
+	}{
+		{"empty defaults to all or nothing", "", AllOrNothingImportMode},
+		{"all or nothing", "all_or_nothing", AllOrNothingImportMode},
+		{"best effort", "best_effort", BestEffortImportMode},
+		{"case insensitive", "Best_Effort", BestEffortImportMode},
+	}
+	for _, tt := range tests {
+		c.Run(tt.name, func(c *qt.C) {
+			got, err := ParseImportMode(tt.s)
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.Equals, tt.want)
+		})
+	}
+
+	_, err := ParseImportMode("sometimes")
+	c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+}
+
+func TestParseCreditRole(t *testing.T) {
+	c := qt.New(t)
+
+	tests := []struct {
+		name string
+		s    string
+		want CreditRole
+	}{
+		{"director", "director", DirectorCreditRole},
+		{"writer", "writer", WriterCreditRole},
+		{"actor", "actor", ActorCreditRole},
+		{"producer", "producer", ProducerCreditRole},
+		{"case insensitive", "Director", DirectorCreditRole},
+	}
+	for _, tt := range tests {
+		c.Run(tt.name, func(c *qt.C) {
+			got, err := ParseCreditRole(tt.s)
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.Equals, tt.want)
+		})
+	}
+
+	_, err := ParseCreditRole("grip")
+	c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+}
+
+func TestRatings_Normalize(t *testing.T) {
+	c := qt.New(t)
+
+	tests := []struct {
+		name    string
+		country string
+		s       string
+		want    string
+	}{
+		{"exact", "US", "PG-13", "PG-13"},
+		{"missing punctuation", "US", "PG13", "PG-13"},
+		{"lower case", "US", "pg-13", "PG-13"},
+		{"space", "US", "PG 13", "PG-13"},
+		{"country case insensitive", "gb", "12a", "12A"},
+		{"other country unchanged", "GB", "PG13", "PG13"},
+		{"unknown unchanged", "US", "X", "X"},
+	}
+	for _, tt := range tests {
+		c.Run(tt.name, func(c *qt.C) {
+			c.Assert(testRatings.Normalize(tt.country, tt.s), qt.Equals, tt.want)
+		})
+	}
+}
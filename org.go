@@ -2,6 +2,7 @@ package diygoapi
 
 import (
 	"context"
+	"io"
 
 	"github.com/gilcrest/diygoapi/errs"
 	"github.com/gilcrest/diygoapi/secure"
@@ -13,11 +14,17 @@ type OrgServicer interface {
 	// Create manages the creation of an Org (and optional app)
 	Create(ctx context.Context, r *CreateOrgRequest, adt Audit) (*OrgResponse, error)
 	Update(ctx context.Context, r *UpdateOrgRequest, adt Audit) (*OrgResponse, error)
-	Delete(ctx context.Context, extlID string) (DeleteResponse, error)
+	Delete(ctx context.Context, extlID string, adt Audit) (DeleteResponse, error)
+	Restore(ctx context.Context, extlID string, adt Audit) (*OrgResponse, error)
 	FindAll(ctx context.Context) ([]*OrgResponse, error)
 	FindByExternalID(ctx context.Context, extlID string) (*OrgResponse, error)
+	Export(ctx context.Context, f ExportFormat, w io.Writer) error
 }
 
+// PrincipalOrgKind is the external ID of the OrgKind given to the
+// Principal org during the Genesis event
+const PrincipalOrgKind string = "principal"
+
 // OrgKind is a way of classifying an organization. Examples are Genesis, Test, Standard
 type OrgKind struct {
 	// ID: The unique identifier
@@ -131,3 +138,13 @@ type OrgResponse struct {
 	UpdateDateTime      string       `json:"update_date_time"`
 	App                 *AppResponse `json:"app,omitempty"`
 }
+
+// OrgExport is a single org as written by a streaming export
+type OrgExport struct {
+	ExternalID     string `json:"external_id"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	Kind           string `json:"kind"`
+	CreateDateTime string `json:"create_date_time"`
+	UpdateDateTime string `json:"update_date_time"`
+}
@@ -0,0 +1,176 @@
+package diygoapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/jpeg" // register the JPEG decoder
+	_ "image/png"  // register the PNG decoder
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+// Poster image limits. A compressed image can be far larger once
+// decoded (up to 4 bytes a pixel), so the dimensions and pixel count
+// are checked from the image header before it is decoded, which bounds
+// the memory a decode takes to around 100 MB.
+const (
+	MaxPosterSize      = 5 << 20    // bytes
+	MaxPosterDimension = 6000       // pixels, for both width and height
+	MaxPosterPixels    = 25_000_000 // width times height
+)
+
+// PosterContentTypes are the media types accepted for a poster image
+var PosterContentTypes = []string{"image/jpeg", "image/png"}
+
+// BlobStore stores opaque blobs of bytes by key. Keys are slash
+// separated paths, e.g. posters/<movie id>/<hash>.
+type BlobStore interface {
+	// Put stores the bytes read from r at key, replacing any blob
+	// already there
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Get opens the blob stored at key. An error of kind errs.NotExist
+	// is returned if there is none.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored at key. Deleting a key which does
+	// not exist is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// PosterServicer is used to upload and serve the poster image of a movie
+type PosterServicer interface {
+	Upload(ctx context.Context, r *UploadPosterRequest, adt Audit) (*PosterResponse, error)
+	Find(ctx context.Context, movieExtlID string, adt Audit) (*Poster, io.ReadCloser, error)
+}
+
+// Poster is the metadata of the poster image of a movie. The image
+// itself is kept in a BlobStore at BlobKey.
+type Poster struct {
+	MovieID     uuid.UUID
+	BlobKey     string
+	ContentType string
+	Size        int64
+	SHA256      string
+	Width       int
+	Height      int
+	UpdateTime  time.Time
+}
+
+// NewPoster validates a poster image and initializes its metadata.
+// The content type declared by the client must be one of
+// PosterContentTypes and must match the content of the image.
+func NewPoster(movieID uuid.UUID, contentType string, data []byte) (Poster, error) {
+	const op errs.Op = "diygoapi/NewPoster"
+
+	if !isPosterContentType(contentType) {
+		return Poster{}, errs.E(op, errs.UnsupportedMediaType,
+			fmt.Sprintf("poster must be one of %s", strings.Join(PosterContentTypes, ", ")))
+	}
+
+	switch {
+	case len(data) == 0:
+		return Poster{}, errs.E(op, errs.Validation, errs.Parameter("poster"), "poster image is empty")
+	case len(data) > MaxPosterSize:
+		return Poster{}, errs.E(op, errs.Validation, errs.Parameter("poster"),
+			fmt.Sprintf("poster must be %d MB or less", MaxPosterSize>>20))
+	}
+
+	if sniffed := http.DetectContentType(data); sniffed != contentType {
+		return Poster{}, errs.E(op, errs.UnsupportedMediaType,
+			fmt.Sprintf("poster content is %s, not %s", sniffed, contentType))
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Poster{}, errs.E(op, errs.Validation, errs.Parameter("poster"), "poster is not a readable image")
+	}
+	if cfg.Width > MaxPosterDimension || cfg.Height > MaxPosterDimension {
+		return Poster{}, errs.E(op, errs.Validation, errs.Parameter("poster"),
+			fmt.Sprintf("poster must be %d pixels or less wide and high", MaxPosterDimension))
+	}
+	if cfg.Width*cfg.Height > MaxPosterPixels {
+		return Poster{}, errs.E(op, errs.Validation, errs.Parameter("poster"),
+			fmt.Sprintf("poster must be %d megapixels or less", MaxPosterPixels/1_000_000))
+	}
+
+	// the header alone does not show the image is intact
+	_, _, err = image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Poster{}, errs.E(op, errs.Validation, errs.Parameter("poster"), "poster is not a readable image")
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	return Poster{
+		MovieID:     movieID,
+		BlobKey:     PosterBlobKey(movieID, hash),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SHA256:      hash,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}, nil
+}
+
+func isPosterContentType(contentType string) bool {
+	for _, ct := range PosterContentTypes {
+		if ct == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// PosterBlobKey returns the BlobStore key of a poster image. The key
+// includes the hash of the image, so a replaced poster never
+// overwrites the blob of the one before it.
+func PosterBlobKey(movieID uuid.UUID, hash string) string {
+	return "posters/" + hex.EncodeToString(movieID[:]) + "/" + hash
+}
+
+// ETag returns the quoted entity tag of the poster image
+func (p Poster) ETag() string {
+	return `"` + p.SHA256 + `"`
+}
+
+// Version returns the part of the image hash used as the v query
+// parameter of the poster's URL
+func (p Poster) Version() string {
+	return p.SHA256[:12]
+}
+
+// URL returns the path the poster image of the movie is served at.
+// The path includes the Version so that a replaced poster gets a new
+// URL and the old one can be cached for a long time.
+func (p Poster) URL(movieExtlID string) string {
+	return fmt.Sprintf("/api/v1/movies/%s/poster?v=%s", movieExtlID, p.Version())
+}
+
+// UploadPosterRequest is the request struct for uploading the poster
+// image of a movie
+type UploadPosterRequest struct {
+	MovieExternalID string
+	ContentType     string
+	Data            io.Reader
+}
+
+// PosterResponse is the response struct for the poster of a movie
+type PosterResponse struct {
+	MovieExtlID    string `json:"movie_extl_id"`
+	URL            string `json:"url"`
+	ContentType    string `json:"content_type"`
+	Size           int64  `json:"size"`
+	SHA256         string `json:"sha256"`
+	Width          int    `json:"width"`
+	Height         int    `json:"height"`
+	UpdateDateTime string `json:"update_date_time"`
+}
@@ -0,0 +1,65 @@
+package diygoapi
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+// testPNG returns a PNG encoded image of the given size
+func testPNG(c *qt.C, width, height int) []byte {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height)))
+	c.Assert(err, qt.IsNil)
+	return buf.Bytes()
+}
+
+func TestNewPoster(t *testing.T) {
+	c := qt.New(t)
+
+	movieID := uuid.New()
+	img := testPNG(c, 27, 40)
+
+	p, err := NewPoster(movieID, "image/png", img)
+	c.Assert(err, qt.IsNil)
+	c.Assert(p.MovieID, qt.Equals, movieID)
+	c.Assert(p.Size, qt.Equals, int64(len(img)))
+	c.Assert(p.Width, qt.Equals, 27)
+	c.Assert(p.Height, qt.Equals, 40)
+	c.Assert(p.SHA256, qt.HasLen, 64)
+	c.Assert(p.BlobKey, qt.Equals, PosterBlobKey(movieID, p.SHA256))
+	c.Assert(p.ETag(), qt.Equals, `"`+p.SHA256+`"`)
+	c.Assert(p.Version(), qt.Equals, p.SHA256[:12])
+	c.Assert(p.URL("abc"), qt.Equals, "/api/v1/movies/abc/poster?v="+p.Version())
+
+	truncated := img[:len(img)/2]
+	tooBig := append(append([]byte{}, img...), make([]byte, MaxPosterSize)...)
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+		wantErr     error
+	}{
+		{"unsupported content type", "image/gif", img, errs.E(errs.UnsupportedMediaType, "poster must be one of image/jpeg, image/png")},
+		{"empty", "image/png", nil, errs.E(errs.Validation, errs.Parameter("poster"), "poster image is empty")},
+		{"too big", "image/png", tooBig, errs.E(errs.Validation, errs.Parameter("poster"), "poster must be 5 MB or less")},
+		{"content does not match content type", "image/jpeg", img, errs.E(errs.UnsupportedMediaType, "poster content is image/png, not image/jpeg")},
+		{"not a readable image", "image/png", truncated, errs.E(errs.Validation, errs.Parameter("poster"), "poster is not a readable image")},
+		{"too wide", "image/png", testPNG(c, MaxPosterDimension+1, 1), errs.E(errs.Validation, errs.Parameter("poster"), "poster must be 6000 pixels or less wide and high")},
+		{"too many pixels", "image/png", testPNG(c, MaxPosterDimension, MaxPosterPixels/MaxPosterDimension+1), errs.E(errs.Validation, errs.Parameter("poster"), "poster must be 25 megapixels or less")},
+	}
+	for _, tt := range tests {
+		c.Run(tt.name, func(c *qt.C) {
+			_, err := NewPoster(movieID, tt.contentType, tt.data)
+			c.Assert(err, qt.CmpEquals(cmp.Comparer(errs.Match)), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package diygoapi
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimit is the rate requests are allowed at. Requests are let
+// through at RequestsPerMinute on average, with up to Burst allowed at
+// once. A zero RequestsPerMinute means requests are not limited.
+type RateLimit struct {
+	RequestsPerMinute int
+	Burst             int
+}
+
+// Unlimited reports whether requests are not limited
+func (rl RateLimit) Unlimited() bool {
+	return rl.RequestsPerMinute <= 0
+}
+
+// AppRateLimits are the limits applied to requests made through an
+// App: one shared by all of the App's requests and one for each of its
+// authenticated users.
+type AppRateLimits struct {
+	App  RateLimit
+	User RateLimit
+}
+
+// RateLimitResult is the outcome of counting a request against a
+// RateLimit
+type RateLimitResult struct {
+	// Allowed is true if the request is within the limit
+	Allowed bool
+	// Limit is the number of requests allowed at once (the burst)
+	Limit int
+	// Remaining is the number of requests which can be made now
+	Remaining int
+	// Reset is how long until the full Limit can be made again
+	Reset time.Duration
+	// RetryAfter is how long until a request will be allowed, zero if
+	// the request was allowed
+	RetryAfter time.Duration
+}
+
+// RateLimiter counts requests against a RateLimit by key, e.g. an App
+// or a User of an App. An in-memory RateLimiter only limits the
+// requests of one process, a shared store can be used to limit all of
+// them.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// RateLimitServicer finds the rate limits of an App
+type RateLimitServicer interface {
+	FindAppRateLimits(ctx context.Context, a *App) (AppRateLimits, error)
+}
@@ -0,0 +1,108 @@
+// Package ratelimit has implementations of the diygoapi.RateLimiter
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gilcrest/diygoapi"
+)
+
+// sweepInterval is how often buckets which have filled up are removed
+const sweepInterval = time.Minute
+
+// MemoryLimiter is a diygoapi.RateLimiter keeping a token bucket for
+// each key in memory. Limits only apply to the requests of this
+// process, so with more than one instance running, each allows the
+// full rate.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewMemoryLimiter initializes a MemoryLimiter
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// bucket holds tokens which are taken for each request and refilled
+// at rate per second, up to burst
+type bucket struct {
+	tokens float64
+	rate   float64
+	burst  float64
+	last   time.Time
+}
+
+// refill adds the tokens accrued since the bucket was last used
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// Allow takes a token from the bucket for key, if there is one. A
+// change to the limit of a key applies from the next request.
+func (l *MemoryLimiter) Allow(_ context.Context, key string, limit diygoapi.RateLimit) (diygoapi.RateLimitResult, error) {
+	if limit.Unlimited() {
+		return diygoapi.RateLimitResult{Allowed: true}, nil
+	}
+
+	now := l.now()
+	rate := float64(limit.RequestsPerMinute) / 60
+	burst := float64(max(limit.Burst, 1))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+	b.rate, b.burst = rate, burst
+	b.refill(now)
+
+	res := diygoapi.RateLimitResult{Limit: int(burst)}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((burst - b.tokens) / rate)
+
+	return res, nil
+}
+
+// sweep removes buckets which have filled up since they were last
+// used, as a full bucket is no different to a new one. The caller
+// must hold l.mu.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= b.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/gilcrest/diygoapi"
+)
+
+func TestMemoryLimiter_Allow(t *testing.T) {
+	c := qt.New(t)
+	ctx := context.Background()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewMemoryLimiter()
+	l.now = func() time.Time { return now }
+
+	// 60 a minute is a token a second
+	limit := diygoapi.RateLimit{RequestsPerMinute: 60, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		res, err := l.Allow(ctx, "app", limit)
+		c.Assert(err, qt.IsNil)
+		c.Assert(res.Allowed, qt.IsTrue)
+		c.Assert(res.Limit, qt.Equals, 3)
+		c.Assert(res.Remaining, qt.Equals, i)
+	}
+
+	res, err := l.Allow(ctx, "app", limit)
+	c.Assert(err, qt.IsNil)
+	c.Assert(res.Allowed, qt.IsFalse)
+	c.Assert(res.RetryAfter, qt.Equals, time.Second)
+	c.Assert(res.Reset, qt.Equals, 3*time.Second)
+
+	// other keys have their own bucket
+	res, err = l.Allow(ctx, "other", limit)
+	c.Assert(err, qt.IsNil)
+	c.Assert(res.Allowed, qt.IsTrue)
+
+	// a token is added each second
+	now = now.Add(time.Second)
+	res, err = l.Allow(ctx, "app", limit)
+	c.Assert(err, qt.IsNil)
+	c.Assert(res.Allowed, qt.IsTrue)
+	c.Assert(res.Remaining, qt.Equals, 0)
+
+	// no more than the burst is kept
+	now = now.Add(time.Hour)
+	res, err = l.Allow(ctx, "app", limit)
+	c.Assert(err, qt.IsNil)
+	c.Assert(res.Remaining, qt.Equals, 2)
+
+	// unlimited requests are not counted
+	res, err = l.Allow(ctx, "unlimited", diygoapi.RateLimit{})
+	c.Assert(err, qt.IsNil)
+	c.Assert(res.Allowed, qt.IsTrue)
+	_, ok := l.buckets["unlimited"]
+	c.Assert(ok, qt.IsFalse)
+}
+
+func TestMemoryLimiter_sweep(t *testing.T) {
+	c := qt.New(t)
+	ctx := context.Background()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewMemoryLimiter()
+	l.now = func() time.Time { return now }
+
+	limit := diygoapi.RateLimit{RequestsPerMinute: 60, Burst: 10}
+	_, _ = l.Allow(ctx, "idle", limit)
+	c.Assert(l.buckets, qt.HasLen, 1)
+
+	// the idle bucket has filled up by the next sweep
+	now = now.Add(sweepInterval)
+	_, _ = l.Allow(ctx, "busy", limit)
+	c.Assert(l.buckets, qt.HasLen, 1)
+	_, ok := l.buckets["busy"]
+	c.Assert(ok, qt.IsTrue)
+}
@@ -0,0 +1,90 @@
+package diygoapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+// Review score bounds
+const (
+	MinReviewScore = 1
+	MaxReviewScore = 5
+)
+
+// ReviewServicer is used to create, update and read the reviews of a movie.
+type ReviewServicer interface {
+	Create(ctx context.Context, r *CreateReviewRequest, adt Audit) (*ReviewResponse, error)
+	Update(ctx context.Context, r *UpdateReviewRequest, adt Audit) (*ReviewResponse, error)
+	FindByMovie(ctx context.Context, movieExtlID string, adt Audit) ([]*ReviewResponse, error)
+}
+
+// Review is a user's score and review of a movie. A user can review a
+// movie once, and only the author (User) can edit the review.
+type Review struct {
+	ID         uuid.UUID
+	ExternalID secure.Identifier
+	MovieID    uuid.UUID
+	User       *User
+	Score      int
+	Body       string
+}
+
+// IsValid performs validation of the struct
+func (r *Review) IsValid() error {
+	const op errs.Op = "diygoapi/Review.IsValid"
+
+	switch {
+	case r.ExternalID.String() == "":
+		return errs.E(op, errs.Validation, errs.Parameter("extlID"), errs.MissingField("extlID"))
+	case r.User == nil:
+		return errs.E(op, errs.Validation, errs.Parameter("user"), "a review must have an author")
+	case r.Score < MinReviewScore || r.Score > MaxReviewScore:
+		return errs.E(op, errs.Validation, errs.Parameter("score"),
+			fmt.Sprintf("score must be between %d and %d", MinReviewScore, MaxReviewScore))
+	}
+
+	return nil
+}
+
+// AverageScore returns the average of a movie's review scores given
+// the number of reviews and the sum of their scores. A movie without
+// reviews has an average score of zero.
+func AverageScore(count, total int64) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(total) / float64(count)
+}
+
+// CreateReviewRequest is the request struct for reviewing a movie
+type CreateReviewRequest struct {
+	MovieExternalID string
+	Score           int    `json:"score"`
+	Body            string `json:"body"`
+}
+
+// UpdateReviewRequest is the request struct for editing a review
+type UpdateReviewRequest struct {
+	MovieExternalID string
+	ExternalID      string
+	Score           int    `json:"score"`
+	Body            string `json:"body"`
+}
+
+// ReviewResponse is the response struct for a Review
+type ReviewResponse struct {
+	ExternalID      string `json:"external_id"`
+	MovieExtlID     string `json:"movie_extl_id"`
+	Score           int    `json:"score"`
+	Body            string `json:"body"`
+	UserExtlID      string `json:"user_extl_id"`
+	UserFirstName   string `json:"user_first_name"`
+	UserLastName    string `json:"user_last_name"`
+	CreateAppExtlID string `json:"create_app_extl_id"`
+	CreateDateTime  string `json:"create_date_time"`
+	UpdateDateTime  string `json:"update_date_time"`
+}
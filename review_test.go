@@ -0,0 +1,66 @@
+package diygoapi
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+func TestReview_IsValid(t *testing.T) {
+	c := qt.New(t)
+
+	reviewFunc := func() *Review {
+		return &Review{
+			ID:         uuid.New(),
+			ExternalID: secure.NewID(),
+			MovieID:    uuid.New(),
+			User:       &User{ID: uuid.New()},
+			Score:      4,
+			Body:       "Gleefully gruesome.",
+		}
+	}
+
+	r1 := reviewFunc()
+	r2 := reviewFunc()
+	r2.ExternalID = nil
+	r3 := reviewFunc()
+	r3.User = nil
+	r4 := reviewFunc()
+	r4.Score = MinReviewScore - 1
+	r5 := reviewFunc()
+	r5.Score = MaxReviewScore + 1
+	r6 := reviewFunc()
+	r6.Body = ""
+
+	scoreErr := errs.E(errs.Validation, errs.Parameter("score"), "score must be between 1 and 5")
+
+	tests := []struct {
+		name    string
+		r       *Review
+		wantErr error
+	}{
+		{"typical no error", r1, nil},
+		{"nil ExternalID", r2, errs.E(errs.Validation, errs.Parameter("extlID"), errs.MissingField("extlID"))},
+		{"nil User", r3, errs.E(errs.Validation, errs.Parameter("user"), "a review must have an author")},
+		{"score too low", r4, scoreErr},
+		{"score too high", r5, scoreErr},
+		{"score without review", r6, nil},
+	}
+	for _, tt := range tests {
+		c.Run(tt.name, func(c *qt.C) {
+			c.Assert(tt.r.IsValid(), qt.CmpEquals(cmp.Comparer(errs.Match)), tt.wantErr)
+		})
+	}
+}
+
+func TestAverageScore(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(AverageScore(0, 0), qt.Equals, float64(0))
+	c.Assert(AverageScore(2, 7), qt.Equals, 3.5)
+}
@@ -0,0 +1,3 @@
+drop table if exists change_history cascade;
+
+drop function if exists change_history_append_only();
\ No newline at end of file
@@ -0,0 +1 @@
+drop function if exists movie_search_document(varchar, varchar, varchar) cascade;
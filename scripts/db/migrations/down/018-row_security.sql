@@ -0,0 +1,2 @@
+drop function if exists current_org_id() cascade;
+drop function if exists row_security_bypassed() cascade;
@@ -0,0 +1,3 @@
+drop table if exists movie_credit cascade;
+drop table if exists movie_genre cascade;
+drop table if exists genre cascade;
@@ -0,0 +1,2 @@
+drop table if exists watchlist_entry cascade;
+drop table if exists watchlist cascade;
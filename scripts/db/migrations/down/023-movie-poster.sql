@@ -0,0 +1 @@
+drop table if exists movie_poster cascade;
@@ -0,0 +1,4 @@
+drop table if exists webhook_delivery_attempt cascade;
+drop table if exists webhook_delivery cascade;
+drop table if exists webhook cascade;
+drop table if exists event_outbox cascade;
@@ -0,0 +1,6 @@
+drop trigger if exists event_outbox_notify_trg on event_outbox;
+
+drop function if exists event_outbox_notify();
+
+alter table event_outbox
+    drop column if exists transaction_id;
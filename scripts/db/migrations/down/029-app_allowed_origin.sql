@@ -0,0 +1 @@
+drop table if exists app_allowed_origin cascade;
@@ -0,0 +1,3 @@
+drop table if exists security_event cascade;
+
+drop function if exists security_event_append_only();
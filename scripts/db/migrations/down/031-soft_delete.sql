@@ -0,0 +1,23 @@
+alter table if exists movie
+    drop column if exists delete_app_id,
+    drop column if exists delete_user_id,
+    drop column if exists delete_timestamp;
+
+alter table if exists org
+    drop column if exists delete_app_id,
+    drop column if exists delete_user_id,
+    drop column if exists delete_timestamp,
+    drop column if exists purge_timestamp;
+
+alter table if exists app
+    drop column if exists delete_app_id,
+    drop column if exists delete_user_id,
+    drop column if exists delete_timestamp,
+    drop column if exists purge_timestamp;
+
+alter table if exists change_history
+    drop constraint if exists change_history_change_action_ck;
+
+alter table if exists change_history
+    add constraint change_history_change_action_ck
+        check (change_action in ('create', 'update', 'delete'));
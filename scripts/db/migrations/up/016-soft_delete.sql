@@ -0,0 +1,47 @@
+alter table movie
+    add column if not exists delete_app_id    uuid,
+    add column if not exists delete_user_id   uuid,
+    add column if not exists delete_timestamp timestamp with time zone;
+
+comment on column movie.delete_app_id is 'The application which deleted this record. Null unless the record is deleted.';
+
+comment on column movie.delete_user_id is 'The user which deleted this record. Null unless the record is deleted.';
+
+comment on column movie.delete_timestamp is 'The timestamp when this record was deleted. A record with a delete_timestamp is hidden from callers and is physically removed by the purge command once it is older than the retention period.';
+
+alter table org
+    add column if not exists delete_app_id    uuid,
+    add column if not exists delete_user_id   uuid,
+    add column if not exists delete_timestamp timestamp with time zone,
+    add column if not exists purge_timestamp  timestamp with time zone;
+
+comment on column org.delete_app_id is 'The application which deleted this record. Null unless the record is deleted.';
+
+comment on column org.delete_user_id is 'The user which deleted this record. Null unless the record is deleted.';
+
+comment on column org.delete_timestamp is 'The timestamp when this record was deleted. A record with a delete_timestamp is hidden from callers and is purged by the purge command once it is older than the retention period.';
+
+comment on column org.purge_timestamp is 'The timestamp when this deleted record was purged. A purged org is kept, as other records refer to it, but its name and description are scrubbed and it can no longer be restored.';
+
+alter table app
+    add column if not exists delete_app_id    uuid,
+    add column if not exists delete_user_id   uuid,
+    add column if not exists delete_timestamp timestamp with time zone,
+    add column if not exists purge_timestamp  timestamp with time zone;
+
+comment on column app.delete_app_id is 'The application which deleted this record. Null unless the record is deleted.';
+
+comment on column app.delete_user_id is 'The user which deleted this record. Null unless the record is deleted.';
+
+comment on column app.delete_timestamp is 'The timestamp when this record was deleted. A record with a delete_timestamp is hidden from callers and is purged by the purge command once it is older than the retention period.';
+
+comment on column app.purge_timestamp is 'The timestamp when this deleted record was purged. A purged app is kept, as other records refer to it, but its name, description and API keys are removed and it can no longer be restored.';
+
+alter table change_history
+    drop constraint if exists change_history_change_action_ck;
+
+alter table change_history
+    add constraint change_history_change_action_ck
+        check (change_action in ('create', 'update', 'delete', 'restore'));
+
+comment on column change_history.change_action is 'The action taken against the entity (create, update, delete, restore).';
@@ -0,0 +1,27 @@
+create extension if not exists pg_trgm;
+
+create or replace function movie_search_document(title varchar, director varchar, writer varchar)
+    returns tsvector
+    language sql
+    immutable
+    parallel safe
+as
+$$
+select setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+       setweight(to_tsvector('simple', coalesce(director, '')), 'B') ||
+       setweight(to_tsvector('simple', coalesce(writer, '')), 'B')
+$$;
+
+comment on function movie_search_document(varchar, varchar, varchar) is 'Builds the full-text search document for a movie. Title matches are weighted above director and writer matches. Names are not stemmed.';
+
+create index if not exists movie_search_ix
+    on movie using gin (movie_search_document(title, director, writer));
+
+create index if not exists movie_title_trgm_ix
+    on movie using gin (title gin_trgm_ops);
+
+create index if not exists movie_director_trgm_ix
+    on movie using gin (director gin_trgm_ops);
+
+create index if not exists movie_writer_trgm_ix
+    on movie using gin (writer gin_trgm_ops);
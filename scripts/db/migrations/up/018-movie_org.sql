@@ -0,0 +1,26 @@
+alter table movie
+    add column if not exists org_id uuid;
+
+comment on column movie.org_id is 'The organization which owns the movie. Movies are only visible to apps of the owning organization.';
+
+-- existing movies are owned by the org of the app which created them
+update movie m
+set org_id = a.org_id
+from app a
+where a.app_id = m.create_app_id
+  and m.org_id is null;
+
+alter table movie
+    alter column org_id set not null;
+
+alter table movie
+    drop constraint if exists movie_org_fk;
+
+alter table movie
+    add constraint movie_org_fk
+        foreign key (org_id) references org
+            on delete cascade
+            deferrable initially deferred;
+
+create index if not exists movie_org_ix
+    on movie (org_id);
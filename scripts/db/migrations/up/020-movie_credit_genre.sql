@@ -0,0 +1,198 @@
+create table if not exists genre
+(
+    genre_id   uuid    not null
+        constraint genre_pk
+            primary key,
+    genre_cd   varchar not null,
+    genre_name varchar not null
+);
+
+comment on table genre is 'The genre table is the taxonomy of genres a movie can be categorized in.';
+
+comment on column genre.genre_id is 'The unique ID given to the genre.';
+
+comment on column genre.genre_cd is 'The unique code of the genre, e.g. horror. Callers refer to a genre by its code.';
+
+comment on column genre.genre_name is 'The display name of the genre.';
+
+create unique index if not exists genre_genre_cd_uindex
+    on genre (genre_cd);
+
+insert into genre (genre_id, genre_cd, genre_name)
+values (gen_random_uuid(), 'action', 'Action'),
+       (gen_random_uuid(), 'adventure', 'Adventure'),
+       (gen_random_uuid(), 'animation', 'Animation'),
+       (gen_random_uuid(), 'comedy', 'Comedy'),
+       (gen_random_uuid(), 'crime', 'Crime'),
+       (gen_random_uuid(), 'documentary', 'Documentary'),
+       (gen_random_uuid(), 'drama', 'Drama'),
+       (gen_random_uuid(), 'family', 'Family'),
+       (gen_random_uuid(), 'fantasy', 'Fantasy'),
+       (gen_random_uuid(), 'horror', 'Horror'),
+       (gen_random_uuid(), 'musical', 'Musical'),
+       (gen_random_uuid(), 'mystery', 'Mystery'),
+       (gen_random_uuid(), 'romance', 'Romance'),
+       (gen_random_uuid(), 'science_fiction', 'Science Fiction'),
+       (gen_random_uuid(), 'thriller', 'Thriller'),
+       (gen_random_uuid(), 'war', 'War'),
+       (gen_random_uuid(), 'western', 'Western')
+on conflict (genre_cd) do nothing;
+
+create table if not exists movie_genre
+(
+    movie_id         uuid                     not null,
+    genre_id         uuid                     not null,
+    create_app_id    uuid                     not null,
+    create_user_id   uuid,
+    create_timestamp timestamp with time zone not null,
+    update_app_id    uuid                     not null,
+    update_user_id   uuid,
+    update_timestamp timestamp with time zone not null,
+    constraint movie_genre_pk
+        primary key (movie_id, genre_id),
+    constraint movie_genre_movie_fk
+        foreign key (movie_id) references movie
+            on delete cascade,
+    constraint movie_genre_genre_fk
+        foreign key (genre_id) references genre,
+    constraint movie_genre_create_app_fk
+        foreign key (create_app_id) references app
+            deferrable initially deferred,
+    constraint movie_genre_update_app_fk
+        foreign key (update_app_id) references app
+            deferrable initially deferred,
+    constraint movie_genre_create_user_fk
+        foreign key (create_user_id) references users
+            deferrable initially deferred,
+    constraint movie_genre_update_user_fk
+        foreign key (update_user_id) references users
+            deferrable initially deferred
+);
+
+comment on table movie_genre is 'The movie_genre table stores which genres a movie is categorized in.';
+
+comment on column movie_genre.movie_id is 'The movie which is categorized in the genre.';
+
+comment on column movie_genre.genre_id is 'The genre the movie is categorized in.';
+
+comment on column movie_genre.create_app_id is 'The application which created this record.';
+
+comment on column movie_genre.create_user_id is 'The user which created this record.';
+
+comment on column movie_genre.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column movie_genre.update_app_id is 'The application which performed the most recent update to this record.';
+
+comment on column movie_genre.update_user_id is 'The user which performed the most recent update to this record.';
+
+comment on column movie_genre.update_timestamp is 'The timestamp when the record was updated most recently.';
+
+create index if not exists movie_genre_genre_ix
+    on movie_genre (genre_id);
+
+create table if not exists movie_credit
+(
+    movie_credit_id  uuid                     not null
+        constraint movie_credit_pk
+            primary key,
+    movie_id         uuid                     not null,
+    credit_role      varchar                  not null,
+    person_name      varchar                  not null,
+    character_name   varchar,
+    billing_order    integer                  not null,
+    create_app_id    uuid                     not null,
+    create_user_id   uuid,
+    create_timestamp timestamp with time zone not null,
+    update_app_id    uuid                     not null,
+    update_user_id   uuid,
+    update_timestamp timestamp with time zone not null,
+    constraint movie_credit_movie_fk
+        foreign key (movie_id) references movie
+            on delete cascade,
+    constraint movie_credit_role_ck
+        check (credit_role in ('director', 'writer', 'actor', 'producer')),
+    constraint movie_credit_order_uk
+        unique (movie_id, credit_role, billing_order),
+    constraint movie_credit_create_app_fk
+        foreign key (create_app_id) references app
+            deferrable initially deferred,
+    constraint movie_credit_update_app_fk
+        foreign key (update_app_id) references app
+            deferrable initially deferred,
+    constraint movie_credit_create_user_fk
+        foreign key (create_user_id) references users
+            deferrable initially deferred,
+    constraint movie_credit_update_user_fk
+        foreign key (update_user_id) references users
+            deferrable initially deferred
+);
+
+comment on table movie_credit is 'The movie_credit table stores the cast and crew credited on a movie.';
+
+comment on column movie_credit.movie_credit_id is 'The unique ID given to the credit.';
+
+comment on column movie_credit.movie_id is 'The movie the person is credited on.';
+
+comment on column movie_credit.credit_role is 'The part the person had in making the movie: director, writer, actor or producer.';
+
+comment on column movie_credit.person_name is 'The name of the credited person.';
+
+comment on column movie_credit.character_name is 'The character played, for actors.';
+
+comment on column movie_credit.billing_order is 'The billing order of the credit among the credits of the movie with the same role, starting at 1.';
+
+comment on column movie_credit.create_app_id is 'The application which created this record.';
+
+comment on column movie_credit.create_user_id is 'The user which created this record.';
+
+comment on column movie_credit.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column movie_credit.update_app_id is 'The application which performed the most recent update to this record.';
+
+comment on column movie_credit.update_user_id is 'The user which performed the most recent update to this record.';
+
+comment on column movie_credit.update_timestamp is 'The timestamp when the record was updated most recently.';
+
+create index if not exists movie_credit_person_name_ix
+    on movie_credit (lower(person_name));
+
+-- credits and genres are visible when their movie is visible under the movie_org_isolation policy
+alter table movie_genre
+    enable row level security;
+
+alter table movie_genre
+    force row level security;
+
+drop policy if exists movie_genre_org_isolation on movie_genre;
+
+create policy movie_genre_org_isolation on movie_genre
+    using (exists(select 1 from movie m where m.movie_id = movie_genre.movie_id))
+    with check (exists(select 1 from movie m where m.movie_id = movie_genre.movie_id));
+
+alter table movie_credit
+    enable row level security;
+
+alter table movie_credit
+    force row level security;
+
+drop policy if exists movie_credit_org_isolation on movie_credit;
+
+create policy movie_credit_org_isolation on movie_credit
+    using (exists(select 1 from movie m where m.movie_id = movie_credit.movie_id))
+    with check (exists(select 1 from movie m where m.movie_id = movie_credit.movie_id));
+
+-- existing directors and writers become each movie's first director and writer credit
+insert into movie_credit (movie_credit_id, movie_id, credit_role, person_name, billing_order,
+                          create_app_id, create_user_id, create_timestamp,
+                          update_app_id, update_user_id, update_timestamp)
+select gen_random_uuid(), m.movie_id, c.credit_role, c.person_name, 1,
+       m.create_app_id, m.create_user_id, m.create_timestamp,
+       m.update_app_id, m.update_user_id, m.update_timestamp
+from movie m
+         cross join lateral (values ('director', m.director), ('writer', m.writer)) c(credit_role, person_name)
+where c.person_name is not null
+  and c.person_name <> ''
+  and not exists(select 1
+                 from movie_credit mc
+                 where mc.movie_id = m.movie_id
+                   and mc.credit_role = c.credit_role);
@@ -0,0 +1,57 @@
+create table if not exists rating
+(
+    rating_id     uuid    not null
+        constraint rating_pk
+            primary key,
+    rating_system varchar not null,
+    country_cd    char(2) not null,
+    rating_cd     varchar not null,
+    rating_desc   varchar not null,
+    display_order integer not null
+);
+
+comment on table rating is 'The rating table is the controlled vocabulary of ratings a movie can be given, for each rating system (MPAA, BBFC, etc.).';
+
+comment on column rating.rating_id is 'The unique ID given to the rating.';
+
+comment on column rating.rating_system is 'The rating system the rating belongs to, e.g. MPAA.';
+
+comment on column rating.country_cd is 'The ISO 3166-1 alpha-2 code of the country the rating system is used in. Each country has a single rating system.';
+
+comment on column rating.rating_cd is 'The rating as it is written by the rating system, e.g. PG-13.';
+
+comment on column rating.rating_desc is 'A description of the rating.';
+
+comment on column rating.display_order is 'The order of the rating within its rating system, from least to most restrictive.';
+
+create unique index if not exists rating_country_cd_rating_cd_uindex
+    on rating (country_cd, rating_cd);
+
+insert into rating (rating_id, rating_system, country_cd, rating_cd, rating_desc, display_order)
+values (gen_random_uuid(), 'MPAA', 'US', 'G', 'General Audiences', 1),
+       (gen_random_uuid(), 'MPAA', 'US', 'PG', 'Parental Guidance Suggested', 2),
+       (gen_random_uuid(), 'MPAA', 'US', 'PG-13', 'Parents Strongly Cautioned', 3),
+       (gen_random_uuid(), 'MPAA', 'US', 'R', 'Restricted', 4),
+       (gen_random_uuid(), 'MPAA', 'US', 'NC-17', 'Adults Only', 5),
+       (gen_random_uuid(), 'MPAA', 'US', 'NR', 'Not Rated', 6),
+       (gen_random_uuid(), 'BBFC', 'GB', 'U', 'Universal', 1),
+       (gen_random_uuid(), 'BBFC', 'GB', 'PG', 'Parental Guidance', 2),
+       (gen_random_uuid(), 'BBFC', 'GB', '12A', 'Cinema release suitable for 12 years and over', 3),
+       (gen_random_uuid(), 'BBFC', 'GB', '12', 'Video release suitable for 12 years and over', 4),
+       (gen_random_uuid(), 'BBFC', 'GB', '15', 'Suitable only for 15 years and over', 5),
+       (gen_random_uuid(), 'BBFC', 'GB', '18', 'Suitable only for adults', 6),
+       (gen_random_uuid(), 'BBFC', 'GB', 'R18', 'Restricted 18', 7)
+on conflict (country_cd, rating_cd) do nothing;
+
+alter table movie
+    add column if not exists rating_country char(2) default 'US' not null;
+
+comment on column movie.rating_country is 'The country of the rating system the movie is rated in. The rated column must be a rating of this country.';
+
+-- normalize existing ratings (e.g. PG13 or pg-13 to PG-13), ignoring case and punctuation
+update movie m
+set rated = r.rating_cd
+from rating r
+where r.country_cd = m.rating_country
+  and upper(regexp_replace(m.rated, '[^[:alnum:]]', '', 'g')) = upper(regexp_replace(r.rating_cd, '[^[:alnum:]]', '', 'g'))
+  and m.rated <> r.rating_cd;
@@ -0,0 +1,90 @@
+create table if not exists review
+(
+    review_id        uuid                     not null
+        constraint review_pk
+            primary key,
+    extl_id          varchar                  not null,
+    movie_id         uuid                     not null,
+    user_id          uuid                     not null,
+    score            smallint                 not null,
+    body             varchar(10000),
+    create_app_id    uuid                     not null,
+    create_user_id   uuid,
+    create_timestamp timestamp with time zone not null,
+    update_app_id    uuid                     not null,
+    update_user_id   uuid,
+    update_timestamp timestamp with time zone not null,
+    constraint review_movie_fk
+        foreign key (movie_id) references movie
+            on delete cascade,
+    constraint review_user_fk
+        foreign key (user_id) references users
+            on delete cascade,
+    constraint review_score_ck
+        check (score between 1 and 5),
+    constraint review_movie_user_uk
+        unique (movie_id, user_id),
+    constraint review_create_app_fk
+        foreign key (create_app_id) references app
+            deferrable initially deferred,
+    constraint review_update_app_fk
+        foreign key (update_app_id) references app
+            deferrable initially deferred,
+    constraint review_create_user_fk
+        foreign key (create_user_id) references users
+            deferrable initially deferred,
+    constraint review_update_user_fk
+        foreign key (update_user_id) references users
+            deferrable initially deferred
+);
+
+comment on table review is 'The review table stores the score and review a user has given a movie. A user can review a movie once.';
+
+comment on column review.review_id is 'The unique ID given to the review.';
+
+comment on column review.extl_id is 'A unique ID given to the review which can be used externally.';
+
+comment on column review.movie_id is 'The movie being reviewed.';
+
+comment on column review.user_id is 'The user who wrote the review. Only the author can edit a review.';
+
+comment on column review.score is 'The score given to the movie, from 1 to 5.';
+
+comment on column review.body is 'The text of the review.';
+
+comment on column review.create_app_id is 'The application which created this record.';
+
+comment on column review.create_user_id is 'The user which created this record.';
+
+comment on column review.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column review.update_app_id is 'The application which performed the most recent update to this record.';
+
+comment on column review.update_user_id is 'The user which performed the most recent update to this record.';
+
+comment on column review.update_timestamp is 'The timestamp when the record was updated most recently.';
+
+create unique index if not exists review_extl_id_uindex
+    on review (extl_id);
+
+alter table review
+    enable row level security;
+
+alter table review
+    force row level security;
+
+drop policy if exists review_org_isolation on review;
+
+create policy review_org_isolation on review
+    using (exists(select 1 from movie m where m.movie_id = review.movie_id))
+    with check (exists(select 1 from movie m where m.movie_id = review.movie_id));
+
+alter table movie
+    add column if not exists review_count integer default 0 not null;
+
+alter table movie
+    add column if not exists review_score_total bigint default 0 not null;
+
+comment on column movie.review_count is 'The number of reviews of the movie. Maintained in the same transaction as the reviews.';
+
+comment on column movie.review_score_total is 'The sum of the scores of the reviews of the movie. The average score is review_score_total / review_count.';
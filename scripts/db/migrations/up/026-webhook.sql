@@ -0,0 +1,256 @@
+create table if not exists event_outbox
+(
+    event_id         uuid                     not null
+        constraint event_outbox_pk
+            primary key,
+    event_seq        bigint generated always as identity,
+    extl_id          varchar                  not null,
+    event_type       varchar(100)             not null,
+    org_id           uuid                     not null,
+    subject_extl_id  varchar                  not null,
+    payload          jsonb                    not null,
+    create_timestamp timestamp with time zone not null,
+    constraint event_outbox_org_fk
+        foreign key (org_id) references org
+            on delete cascade
+);
+
+comment on table event_outbox is 'The event_outbox table stores domain events, e.g. movie.created, written in the same transaction as the change they describe. Webhook deliveries are created from it in that transaction too, so an event is only published if the change is committed.';
+
+comment on column event_outbox.event_id is 'The unique ID given to the event.';
+
+comment on column event_outbox.event_seq is 'The order in which events were written.';
+
+comment on column event_outbox.extl_id is 'A unique ID given to the event which can be used externally.';
+
+comment on column event_outbox.event_type is 'The type of event, e.g. movie.created or org.deleted.';
+
+comment on column event_outbox.org_id is 'The organization the event belongs to. Only webhooks of this organization receive it.';
+
+comment on column event_outbox.subject_extl_id is 'The external ID of the entity the event is about.';
+
+comment on column event_outbox.payload is 'The entity the event is about as JSON, as it was after the change, or before it for a delete.';
+
+comment on column event_outbox.create_timestamp is 'The timestamp when the change the event describes was made.';
+
+create unique index if not exists event_outbox_extl_id_uindex
+    on event_outbox (extl_id);
+
+create unique index if not exists event_outbox_event_seq_uindex
+    on event_outbox (event_seq);
+
+create index if not exists event_outbox_org_event_seq_ix
+    on event_outbox (org_id, event_seq);
+
+alter table event_outbox
+    enable row level security;
+
+alter table event_outbox
+    force row level security;
+
+drop policy if exists event_outbox_org_isolation on event_outbox;
+
+create policy event_outbox_org_isolation on event_outbox
+    using (row_security_bypassed() or org_id = current_org_id())
+    with check (row_security_bypassed() or org_id = current_org_id());
+
+create table if not exists webhook
+(
+    webhook_id        uuid                     not null
+        constraint webhook_pk
+            primary key,
+    extl_id           varchar                  not null,
+    org_id            uuid                     not null,
+    app_id            uuid                     not null,
+    endpoint_url      varchar(2000)            not null,
+    secret_ciphertext varchar                  not null,
+    event_types       varchar[]                not null,
+    active            boolean default true     not null,
+    create_app_id     uuid                     not null,
+    create_user_id    uuid,
+    create_timestamp  timestamp with time zone not null,
+    update_app_id     uuid                     not null,
+    update_user_id    uuid,
+    update_timestamp  timestamp with time zone not null,
+    constraint webhook_org_fk
+        foreign key (org_id) references org
+            on delete cascade,
+    constraint webhook_app_fk
+        foreign key (app_id) references app
+            on delete cascade,
+    constraint webhook_event_types_ck
+        check (cardinality(event_types) > 0),
+    constraint webhook_create_app_fk
+        foreign key (create_app_id) references app
+            deferrable initially deferred,
+    constraint webhook_update_app_fk
+        foreign key (update_app_id) references app
+            deferrable initially deferred,
+    constraint webhook_create_user_fk
+        foreign key (create_user_id) references users
+            deferrable initially deferred,
+    constraint webhook_update_user_fk
+        foreign key (update_user_id) references users
+            deferrable initially deferred
+);
+
+comment on table webhook is 'The webhook table stores the endpoints an app has registered to be sent the events of its organization.';
+
+comment on column webhook.webhook_id is 'The unique ID given to the webhook.';
+
+comment on column webhook.extl_id is 'A unique ID given to the webhook which can be used externally.';
+
+comment on column webhook.org_id is 'The organization whose events are sent to the webhook.';
+
+comment on column webhook.app_id is 'The app which registered, and alone can manage, the webhook.';
+
+comment on column webhook.endpoint_url is 'The URL events are posted to.';
+
+comment on column webhook.secret_ciphertext is 'The secret deliveries are signed with, encrypted with the encryption key and hex encoded.';
+
+comment on column webhook.event_types is 'The types of event sent to the webhook.';
+
+comment on column webhook.active is 'Whether events are sent to the webhook.';
+
+comment on column webhook.create_app_id is 'The application which created this record.';
+
+comment on column webhook.create_user_id is 'The user which created this record.';
+
+comment on column webhook.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column webhook.update_app_id is 'The application which performed the most recent update to this record.';
+
+comment on column webhook.update_user_id is 'The user which performed the most recent update to this record.';
+
+comment on column webhook.update_timestamp is 'The timestamp when the record was updated most recently.';
+
+create unique index if not exists webhook_extl_id_uindex
+    on webhook (extl_id);
+
+create index if not exists webhook_org_ix
+    on webhook (org_id);
+
+create index if not exists webhook_app_ix
+    on webhook (app_id);
+
+alter table webhook
+    enable row level security;
+
+alter table webhook
+    force row level security;
+
+drop policy if exists webhook_org_isolation on webhook;
+
+create policy webhook_org_isolation on webhook
+    using (row_security_bypassed() or org_id = current_org_id())
+    with check (row_security_bypassed() or org_id = current_org_id());
+
+create table if not exists webhook_delivery
+(
+    delivery_id      uuid                     not null
+        constraint webhook_delivery_pk
+            primary key,
+    extl_id          varchar                  not null,
+    webhook_id       uuid                     not null,
+    event_id         uuid                     not null,
+    delivery_state   varchar(20)              not null,
+    attempts         integer default 0        not null,
+    last_status_code integer,
+    last_error       text,
+    create_timestamp timestamp with time zone not null,
+    update_timestamp timestamp with time zone not null,
+    constraint webhook_delivery_webhook_fk
+        foreign key (webhook_id) references webhook
+            on delete cascade,
+    constraint webhook_delivery_event_fk
+        foreign key (event_id) references event_outbox
+            on delete cascade,
+    constraint webhook_delivery_webhook_event_uk
+        unique (webhook_id, event_id),
+    constraint webhook_delivery_state_ck
+        check (delivery_state in ('pending', 'succeeded', 'failed'))
+);
+
+comment on table webhook_delivery is 'The webhook_delivery table stores the sending of an event to a webhook. Each delivery is run as a background job, which retries it with backoff.';
+
+comment on column webhook_delivery.delivery_id is 'The unique ID given to the delivery.';
+
+comment on column webhook_delivery.extl_id is 'A unique ID given to the delivery which can be used externally. Sent with each attempt, so a receiver can ignore a delivery it has already seen.';
+
+comment on column webhook_delivery.webhook_id is 'The webhook the event is sent to.';
+
+comment on column webhook_delivery.event_id is 'The event being sent.';
+
+comment on column webhook_delivery.delivery_state is 'The state of the delivery: pending, succeeded or failed. A failed delivery ran out of attempts.';
+
+comment on column webhook_delivery.attempts is 'The number of times sending the event has been attempted.';
+
+comment on column webhook_delivery.last_status_code is 'The HTTP status code of the most recent attempt. Null if no response was received.';
+
+comment on column webhook_delivery.last_error is 'The error from the most recent failed attempt.';
+
+comment on column webhook_delivery.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column webhook_delivery.update_timestamp is 'The timestamp when the record was updated most recently.';
+
+create unique index if not exists webhook_delivery_extl_id_uindex
+    on webhook_delivery (extl_id);
+
+create index if not exists webhook_delivery_webhook_create_timestamp_ix
+    on webhook_delivery (webhook_id, create_timestamp);
+
+create index if not exists webhook_delivery_event_ix
+    on webhook_delivery (event_id);
+
+alter table webhook_delivery
+    enable row level security;
+
+alter table webhook_delivery
+    force row level security;
+
+drop policy if exists webhook_delivery_org_isolation on webhook_delivery;
+
+create policy webhook_delivery_org_isolation on webhook_delivery
+    using (exists(select 1 from webhook w where w.webhook_id = webhook_delivery.webhook_id))
+    with check (exists(select 1 from webhook w where w.webhook_id = webhook_delivery.webhook_id));
+
+create table if not exists webhook_delivery_attempt
+(
+    delivery_id       uuid                     not null,
+    attempt           integer                  not null,
+    status_code       integer,
+    error_message     text,
+    duration_ms       integer                  not null,
+    attempt_timestamp timestamp with time zone not null,
+    constraint webhook_delivery_attempt_pk
+        primary key (delivery_id, attempt),
+    constraint webhook_delivery_attempt_delivery_fk
+        foreign key (delivery_id) references webhook_delivery
+            on delete cascade
+);
+
+comment on table webhook_delivery_attempt is 'The webhook_delivery_attempt table stores the history of attempts to send an event to a webhook.';
+
+comment on column webhook_delivery_attempt.delivery_id is 'The delivery the attempt was made for.';
+
+comment on column webhook_delivery_attempt.attempt is 'The number of the attempt, starting at 1.';
+
+comment on column webhook_delivery_attempt.status_code is 'The HTTP status code of the response. Null if no response was received.';
+
+comment on column webhook_delivery_attempt.error_message is 'Why the attempt failed. Null if it succeeded.';
+
+comment on column webhook_delivery_attempt.duration_ms is 'How long the attempt took, in milliseconds.';
+
+comment on column webhook_delivery_attempt.attempt_timestamp is 'The timestamp when the attempt was made.';
+
+alter table webhook_delivery_attempt
+    enable row level security;
+
+alter table webhook_delivery_attempt
+    force row level security;
+
+drop policy if exists webhook_delivery_attempt_org_isolation on webhook_delivery_attempt;
+
+create policy webhook_delivery_attempt_org_isolation on webhook_delivery_attempt
+    using (exists(select 1 from webhook_delivery d where d.delivery_id = webhook_delivery_attempt.delivery_id))
+    with check (exists(select 1 from webhook_delivery d where d.delivery_id = webhook_delivery_attempt.delivery_id));
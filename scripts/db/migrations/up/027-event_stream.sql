@@ -0,0 +1,21 @@
+alter table event_outbox
+    add column if not exists transaction_id xid8 default pg_current_xact_id() not null;
+
+comment on column event_outbox.transaction_id is 'The ID of the transaction which wrote the event. Event streams only read events of transactions older than any still running, so events committed out of sequence order are not skipped.';
+
+create or replace function event_outbox_notify()
+    returns trigger
+    language plpgsql
+as
+$$
+begin
+    perform pg_notify('event_outbox', '');
+    return null;
+end;
+$$;
+
+create or replace trigger event_outbox_notify_trg
+    after insert
+    on event_outbox
+    for each statement
+execute function event_outbox_notify();
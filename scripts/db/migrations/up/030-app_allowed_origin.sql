@@ -0,0 +1,16 @@
+create table if not exists app_allowed_origin
+(
+    app_id uuid    not null
+        constraint app_allowed_origin_app_fk
+            references app
+            on delete cascade,
+    origin varchar not null,
+    constraint app_allowed_origin_pk
+        primary key (app_id, origin)
+);
+
+comment on table app_allowed_origin is 'The app_allowed_origin table holds the origins browsers may call the API from for an app, in place of the configured CORS allowed origins. An app with no rows uses the configured origins.';
+
+comment on column app_allowed_origin.app_id is 'The app which allows the origin.';
+
+comment on column app_allowed_origin.origin is 'The scheme and host of the origin, e.g. https://app.example.com, or * for any origin.';
@@ -13,6 +13,10 @@ create table if not exists app
     update_app_id           uuid                     not null,
     update_user_id          uuid,
     update_timestamp        timestamp with time zone not null,
+    delete_app_id           uuid,
+    delete_user_id          uuid,
+    delete_timestamp        timestamp with time zone,
+    purge_timestamp         timestamp with time zone,
     constraint app_pk
         primary key (app_id),
     constraint app_self_ref1
@@ -54,6 +58,14 @@ comment on column app.update_user_id is 'The user which performed the most recen
 
 comment on column app.update_timestamp is 'The timestamp when the record was updated most recently.';
 
+comment on column app.delete_app_id is 'The application which deleted this record. Null unless the record is deleted.';
+
+comment on column app.delete_user_id is 'The user which deleted this record. Null unless the record is deleted.';
+
+comment on column app.delete_timestamp is 'The timestamp when this record was deleted. A record with a delete_timestamp is hidden from callers and is purged by the purge command once it is older than the retention period.';
+
+comment on column app.purge_timestamp is 'The timestamp when this deleted record was purged. A purged app is kept, as other records refer to it, but its name, description and API keys are removed and it can no longer be restored.';
+
 comment on constraint app_auth_provider_null_fk on app is 'Not every app has an associated auth provider, thus this field can be null.';
 
 alter table app
@@ -0,0 +1,36 @@
+create table if not exists app_rate_limit
+(
+    app_id                   uuid not null
+        constraint app_rate_limit_pk
+            primary key
+        constraint app_rate_limit_app_fk
+            references app
+            on delete cascade,
+    requests_per_minute      integer
+        constraint app_rate_limit_requests_per_minute_ck
+            check (requests_per_minute >= 0),
+    burst                    integer
+        constraint app_rate_limit_burst_ck
+            check (burst > 0),
+    user_requests_per_minute integer
+        constraint app_rate_limit_user_requests_per_minute_ck
+            check (user_requests_per_minute >= 0),
+    user_burst               integer
+        constraint app_rate_limit_user_burst_ck
+            check (user_burst > 0)
+);
+
+comment on table app_rate_limit is 'The app_rate_limit table overrides the default rate limits for an app. A null column leaves the default in place.';
+
+comment on column app_rate_limit.app_id is 'The app the rate limits apply to.';
+
+comment on column app_rate_limit.requests_per_minute is 'The number of requests a minute allowed for all of the app''s requests together. Zero means the app''s requests are not limited.';
+
+comment on column app_rate_limit.burst is 'The number of the app''s requests allowed at once.';
+
+comment on column app_rate_limit.user_requests_per_minute is 'The number of requests a minute allowed for each user of the app. Zero means users'' requests are not limited.';
+
+comment on column app_rate_limit.user_burst is 'The number of requests allowed at once for each user of the app.';
+
+alter table app_rate_limit
+    owner to demo_user;
@@ -0,0 +1,67 @@
+create table if not exists change_history
+(
+    change_history_id uuid                     not null,
+    change_seq        bigint generated always as identity,
+    entity_type       varchar                  not null,
+    entity_id         uuid                     not null,
+    entity_extl_id    varchar                  not null,
+    change_action     varchar                  not null,
+    before_image      jsonb,
+    after_image       jsonb,
+    change_app_id     uuid                     not null,
+    change_user_id    uuid,
+    change_timestamp  timestamp with time zone not null,
+    constraint change_history_pk
+        primary key (change_history_id),
+    constraint change_history_entity_type_ck
+        check (entity_type in ('movie', 'org', 'app', 'role', 'permission')),
+    constraint change_history_change_action_ck
+        check (change_action in ('create', 'update', 'delete', 'restore'))
+);
+
+comment on table change_history is 'The change_history table is an append-only log of every change made to a domain entity (movie, org, app, role, permission). There are intentionally no foreign keys so that history outlives the entities and apps/users it references.';
+
+comment on column change_history.change_history_id is 'The unique ID given to the change history entry.';
+
+comment on column change_history.change_seq is 'A monotonically increasing sequence used to order changes written within the same moment.';
+
+comment on column change_history.entity_type is 'The type of domain entity which was changed (movie, org, app, role, permission).';
+
+comment on column change_history.entity_id is 'The unique ID of the entity which was changed.';
+
+comment on column change_history.entity_extl_id is 'The external ID of the entity which was changed.';
+
+comment on column change_history.change_action is 'The action taken against the entity (create, update, delete, restore).';
+
+comment on column change_history.before_image is 'The JSON representation of the entity before the change. Null for create.';
+
+comment on column change_history.after_image is 'The JSON representation of the entity after the change. Null for delete.';
+
+comment on column change_history.change_app_id is 'The application which performed the change.';
+
+comment on column change_history.change_user_id is 'The user which performed the change.';
+
+comment on column change_history.change_timestamp is 'The timestamp when the change was made.';
+
+create index if not exists change_history_entity_ix
+    on change_history (entity_type, entity_extl_id, change_seq);
+
+create or replace function change_history_append_only()
+    returns trigger
+    language plpgsql
+as
+$$
+begin
+    raise exception 'change_history is append-only, % is not allowed', tg_op;
+end;
+$$;
+
+create or replace trigger change_history_append_only_trg
+    before update or delete
+    on change_history
+    for each row
+execute function change_history_append_only();
+
+alter table change_history
+    owner to demo_user;
+
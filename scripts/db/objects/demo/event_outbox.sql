@@ -0,0 +1,78 @@
+create table if not exists event_outbox
+(
+    event_id         uuid                     not null
+        constraint event_outbox_pk
+            primary key,
+    event_seq        bigint generated always as identity,
+    extl_id          varchar                  not null,
+    event_type       varchar(100)             not null,
+    org_id           uuid                     not null,
+    subject_extl_id  varchar                  not null,
+    payload          jsonb                    not null,
+    create_timestamp timestamp with time zone not null,
+    transaction_id   xid8 default pg_current_xact_id() not null,
+    constraint event_outbox_org_fk
+        foreign key (org_id) references org
+            on delete cascade
+);
+
+comment on table event_outbox is 'The event_outbox table stores domain events, e.g. movie.created, written in the same transaction as the change they describe. Webhook deliveries are created from it in that transaction too, so an event is only published if the change is committed.';
+
+comment on column event_outbox.event_id is 'The unique ID given to the event.';
+
+comment on column event_outbox.event_seq is 'The order in which events were written.';
+
+comment on column event_outbox.extl_id is 'A unique ID given to the event which can be used externally.';
+
+comment on column event_outbox.event_type is 'The type of event, e.g. movie.created or org.deleted.';
+
+comment on column event_outbox.org_id is 'The organization the event belongs to. Only webhooks of this organization receive it.';
+
+comment on column event_outbox.subject_extl_id is 'The external ID of the entity the event is about.';
+
+comment on column event_outbox.payload is 'The entity the event is about as JSON, as it was after the change, or before it for a delete.';
+
+comment on column event_outbox.create_timestamp is 'The timestamp when the change the event describes was made.';
+
+comment on column event_outbox.transaction_id is 'The ID of the transaction which wrote the event. Event streams only read events of transactions older than any still running, so events committed out of sequence order are not skipped.';
+
+create unique index if not exists event_outbox_extl_id_uindex
+    on event_outbox (extl_id);
+
+create unique index if not exists event_outbox_event_seq_uindex
+    on event_outbox (event_seq);
+
+create index if not exists event_outbox_org_event_seq_ix
+    on event_outbox (org_id, event_seq);
+
+create or replace function event_outbox_notify()
+    returns trigger
+    language plpgsql
+as
+$$
+begin
+    perform pg_notify('event_outbox', '');
+    return null;
+end;
+$$;
+
+create or replace trigger event_outbox_notify_trg
+    after insert
+    on event_outbox
+    for each statement
+execute function event_outbox_notify();
+
+alter table event_outbox
+    owner to demo_user;
+
+alter table event_outbox
+    enable row level security;
+
+alter table event_outbox
+    force row level security;
+
+drop policy if exists event_outbox_org_isolation on event_outbox;
+
+create policy event_outbox_org_isolation on event_outbox
+    using (row_security_bypassed() or org_id = current_org_id())
+    with check (row_security_bypassed() or org_id = current_org_id());
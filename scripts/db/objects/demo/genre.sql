@@ -0,0 +1,42 @@
+create table if not exists genre
+(
+    genre_id   uuid    not null
+        constraint genre_pk
+            primary key,
+    genre_cd   varchar not null,
+    genre_name varchar not null
+);
+
+comment on table genre is 'The genre table is the taxonomy of genres a movie can be categorized in.';
+
+comment on column genre.genre_id is 'The unique ID given to the genre.';
+
+comment on column genre.genre_cd is 'The unique code of the genre, e.g. horror. Callers refer to a genre by its code.';
+
+comment on column genre.genre_name is 'The display name of the genre.';
+
+create unique index if not exists genre_genre_cd_uindex
+    on genre (genre_cd);
+
+alter table genre
+    owner to demo_user;
+
+insert into genre (genre_id, genre_cd, genre_name)
+values (gen_random_uuid(), 'action', 'Action'),
+       (gen_random_uuid(), 'adventure', 'Adventure'),
+       (gen_random_uuid(), 'animation', 'Animation'),
+       (gen_random_uuid(), 'comedy', 'Comedy'),
+       (gen_random_uuid(), 'crime', 'Crime'),
+       (gen_random_uuid(), 'documentary', 'Documentary'),
+       (gen_random_uuid(), 'drama', 'Drama'),
+       (gen_random_uuid(), 'family', 'Family'),
+       (gen_random_uuid(), 'fantasy', 'Fantasy'),
+       (gen_random_uuid(), 'horror', 'Horror'),
+       (gen_random_uuid(), 'musical', 'Musical'),
+       (gen_random_uuid(), 'mystery', 'Mystery'),
+       (gen_random_uuid(), 'romance', 'Romance'),
+       (gen_random_uuid(), 'science_fiction', 'Science Fiction'),
+       (gen_random_uuid(), 'thriller', 'Thriller'),
+       (gen_random_uuid(), 'war', 'War'),
+       (gen_random_uuid(), 'western', 'Western')
+on conflict (genre_cd) do nothing;
@@ -0,0 +1,64 @@
+create table if not exists job
+(
+    job_id           uuid                     not null
+        constraint job_pk
+            primary key,
+    extl_id          varchar                  not null,
+    job_kind         varchar(100)             not null,
+    payload          jsonb                    not null,
+    job_state        varchar(20)              not null,
+    attempts         integer default 0        not null,
+    max_attempts     integer                  not null,
+    run_at           timestamp with time zone not null,
+    locked_at        timestamp with time zone,
+    last_error       text,
+    create_timestamp timestamp with time zone not null,
+    update_timestamp timestamp with time zone not null,
+    constraint job_state_ck
+        check (job_state in ('queued', 'running', 'succeeded', 'dead')),
+    constraint job_max_attempts_ck
+        check (max_attempts > 0)
+);
+
+comment on table job is 'The job table is the queue of background jobs. Workers claim queued jobs with select ... for update skip locked, so each job is run by one worker at a time.';
+
+comment on column job.job_id is 'The unique ID given to the job.';
+
+comment on column job.extl_id is 'A unique ID given to the job which can be used externally.';
+
+comment on column job.job_kind is 'The kind of job, which determines the handler that runs it.';
+
+comment on column job.payload is 'The input of the job, as JSON.';
+
+comment on column job.job_state is 'The state of the job: queued, running, succeeded or dead. A dead job failed on every attempt and is only run again if retried.';
+
+comment on column job.attempts is 'The number of times the job has been claimed by a worker.';
+
+comment on column job.max_attempts is 'The number of attempts after which a failing job is dead-lettered.';
+
+comment on column job.run_at is 'The earliest time the job may next be run. Pushed back after each failed attempt.';
+
+comment on column job.locked_at is 'When the job was claimed by a worker. A running job whose lease has expired is claimed again.';
+
+comment on column job.last_error is 'The error from the most recent failed attempt.';
+
+comment on column job.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column job.update_timestamp is 'The timestamp when the record was updated most recently.';
+
+create unique index if not exists job_extl_id_uindex
+    on job (extl_id);
+
+create index if not exists job_queued_run_at_ix
+    on job (run_at)
+    where job_state = 'queued';
+
+create index if not exists job_running_locked_at_ix
+    on job (locked_at)
+    where job_state = 'running';
+
+create index if not exists job_state_create_timestamp_ix
+    on job (job_state, create_timestamp);
+
+alter table job
+    owner to demo_user;
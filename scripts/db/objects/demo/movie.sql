@@ -1,19 +1,26 @@
 create table if not exists movie
 (
-    movie_id         uuid                     not null,
-    extl_id          varchar                  not null,
-    title            varchar(1000)            not null,
-    rated            varchar,
-    released         date,
-    run_time         integer,
-    director         varchar(1000),
-    writer           varchar(1000),
-    create_app_id    uuid                     not null,
-    create_user_id   uuid,
-    create_timestamp timestamp with time zone not null,
-    update_app_id    uuid                     not null,
-    update_user_id   uuid,
-    update_timestamp timestamp with time zone not null,
+    movie_id           uuid                     not null,
+    extl_id            varchar                  not null,
+    title              varchar(1000)            not null,
+    rated              varchar,
+    released           date,
+    run_time           integer,
+    director           varchar(1000),
+    writer             varchar(1000),
+    create_app_id      uuid                     not null,
+    create_user_id     uuid,
+    create_timestamp   timestamp with time zone not null,
+    update_app_id      uuid                     not null,
+    update_user_id     uuid,
+    update_timestamp   timestamp with time zone not null,
+    delete_app_id      uuid,
+    delete_user_id     uuid,
+    delete_timestamp   timestamp with time zone,
+    org_id             uuid                     not null,
+    rating_country     char(2) default 'US'     not null,
+    review_count       integer default 0        not null,
+    review_score_total bigint default 0         not null,
     constraint movie_pk
         primary key (movie_id),
     constraint movie_create_app_fk
@@ -27,6 +34,10 @@ create table if not exists movie
             deferrable initially deferred,
     constraint movie_update_user_fk
         foreign key (update_user_id) references users
+            deferrable initially deferred,
+    constraint movie_org_fk
+        foreign key (org_id) references org
+            on delete cascade
             deferrable initially deferred
 );
 
@@ -60,9 +71,66 @@ comment on column movie.update_user_id is 'The user which performed the most rec
 
 comment on column movie.update_timestamp is 'The timestamp when the record was updated most recently.';
 
+comment on column movie.delete_app_id is 'The application which deleted this record. Null unless the record is deleted.';
+
+comment on column movie.delete_user_id is 'The user which deleted this record. Null unless the record is deleted.';
+
+comment on column movie.delete_timestamp is 'The timestamp when this record was deleted. A record with a delete_timestamp is hidden from callers and is physically removed by the purge command once it is older than the retention period.';
+
+comment on column movie.org_id is 'The organization which owns the movie. Movies are only visible to apps of the owning organization.';
+
+comment on column movie.rating_country is 'The country of the rating system the movie is rated in. The rated column must be a rating of this country.';
+
+comment on column movie.review_count is 'The number of reviews of the movie. Maintained in the same transaction as the reviews.';
+
+comment on column movie.review_score_total is 'The sum of the scores of the reviews of the movie. The average score is review_score_total / review_count.';
+
 alter table movie
     owner to demo_user;
 
 create unique index if not exists movie_extl_id_uindex
     on movie (extl_id);
 
+create index if not exists movie_org_ix
+    on movie (org_id);
+
+create extension if not exists pg_trgm;
+
+create or replace function movie_search_document(title varchar, director varchar, writer varchar)
+    returns tsvector
+    language sql
+    immutable
+    parallel safe
+as
+$$
+select setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+       setweight(to_tsvector('simple', coalesce(director, '')), 'B') ||
+       setweight(to_tsvector('simple', coalesce(writer, '')), 'B')
+$$;
+
+comment on function movie_search_document(varchar, varchar, varchar) is 'Builds the full-text search document for a movie. Title matches are weighted above director and writer matches. Names are not stemmed.';
+
+create index if not exists movie_search_ix
+    on movie using gin (movie_search_document(title, director, writer));
+
+create index if not exists movie_title_trgm_ix
+    on movie using gin (title gin_trgm_ops);
+
+create index if not exists movie_director_trgm_ix
+    on movie using gin (director gin_trgm_ops);
+
+create index if not exists movie_writer_trgm_ix
+    on movie using gin (writer gin_trgm_ops);
+
+alter table movie
+    enable row level security;
+
+-- the app connects as the table owner, which is otherwise exempt from row level security
+alter table movie
+    force row level security;
+
+drop policy if exists movie_org_isolation on movie;
+
+create policy movie_org_isolation on movie
+    using (row_security_bypassed() or org_id = current_org_id())
+    with check (row_security_bypassed() or org_id = current_org_id());
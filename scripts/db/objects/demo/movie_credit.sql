@@ -0,0 +1,80 @@
+create table if not exists movie_credit
+(
+    movie_credit_id  uuid                     not null
+        constraint movie_credit_pk
+            primary key,
+    movie_id         uuid                     not null,
+    credit_role      varchar                  not null,
+    person_name      varchar                  not null,
+    character_name   varchar,
+    billing_order    integer                  not null,
+    create_app_id    uuid                     not null,
+    create_user_id   uuid,
+    create_timestamp timestamp with time zone not null,
+    update_app_id    uuid                     not null,
+    update_user_id   uuid,
+    update_timestamp timestamp with time zone not null,
+    constraint movie_credit_movie_fk
+        foreign key (movie_id) references movie
+            on delete cascade,
+    constraint movie_credit_role_ck
+        check (credit_role in ('director', 'writer', 'actor', 'producer')),
+    constraint movie_credit_order_uk
+        unique (movie_id, credit_role, billing_order),
+    constraint movie_credit_create_app_fk
+        foreign key (create_app_id) references app
+            deferrable initially deferred,
+    constraint movie_credit_update_app_fk
+        foreign key (update_app_id) references app
+            deferrable initially deferred,
+    constraint movie_credit_create_user_fk
+        foreign key (create_user_id) references users
+            deferrable initially deferred,
+    constraint movie_credit_update_user_fk
+        foreign key (update_user_id) references users
+            deferrable initially deferred
+);
+
+comment on table movie_credit is 'The movie_credit table stores the cast and crew credited on a movie.';
+
+comment on column movie_credit.movie_credit_id is 'The unique ID given to the credit.';
+
+comment on column movie_credit.movie_id is 'The movie the person is credited on.';
+
+comment on column movie_credit.credit_role is 'The part the person had in making the movie: director, writer, actor or producer.';
+
+comment on column movie_credit.person_name is 'The name of the credited person.';
+
+comment on column movie_credit.character_name is 'The character played, for actors.';
+
+comment on column movie_credit.billing_order is 'The billing order of the credit among the credits of the movie with the same role, starting at 1.';
+
+comment on column movie_credit.create_app_id is 'The application which created this record.';
+
+comment on column movie_credit.create_user_id is 'The user which created this record.';
+
+comment on column movie_credit.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column movie_credit.update_app_id is 'The application which performed the most recent update to this record.';
+
+comment on column movie_credit.update_user_id is 'The user which performed the most recent update to this record.';
+
+comment on column movie_credit.update_timestamp is 'The timestamp when the record was updated most recently.';
+
+create index if not exists movie_credit_person_name_ix
+    on movie_credit (lower(person_name));
+
+alter table movie_credit
+    owner to demo_user;
+
+alter table movie_credit
+    enable row level security;
+
+alter table movie_credit
+    force row level security;
+
+drop policy if exists movie_credit_org_isolation on movie_credit;
+
+create policy movie_credit_org_isolation on movie_credit
+    using (exists(select 1 from movie m where m.movie_id = movie_credit.movie_id))
+    with check (exists(select 1 from movie m where m.movie_id = movie_credit.movie_id));
@@ -0,0 +1,66 @@
+create table if not exists movie_genre
+(
+    movie_id         uuid                     not null,
+    genre_id         uuid                     not null,
+    create_app_id    uuid                     not null,
+    create_user_id   uuid,
+    create_timestamp timestamp with time zone not null,
+    update_app_id    uuid                     not null,
+    update_user_id   uuid,
+    update_timestamp timestamp with time zone not null,
+    constraint movie_genre_pk
+        primary key (movie_id, genre_id),
+    constraint movie_genre_movie_fk
+        foreign key (movie_id) references movie
+            on delete cascade,
+    constraint movie_genre_genre_fk
+        foreign key (genre_id) references genre,
+    constraint movie_genre_create_app_fk
+        foreign key (create_app_id) references app
+            deferrable initially deferred,
+    constraint movie_genre_update_app_fk
+        foreign key (update_app_id) references app
+            deferrable initially deferred,
+    constraint movie_genre_create_user_fk
+        foreign key (create_user_id) references users
+            deferrable initially deferred,
+    constraint movie_genre_update_user_fk
+        foreign key (update_user_id) references users
+            deferrable initially deferred
+);
+
+comment on table movie_genre is 'The movie_genre table stores which genres a movie is categorized in.';
+
+comment on column movie_genre.movie_id is 'The movie which is categorized in the genre.';
+
+comment on column movie_genre.genre_id is 'The genre the movie is categorized in.';
+
+comment on column movie_genre.create_app_id is 'The application which created this record.';
+
+comment on column movie_genre.create_user_id is 'The user which created this record.';
+
+comment on column movie_genre.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column movie_genre.update_app_id is 'The application which performed the most recent update to this record.';
+
+comment on column movie_genre.update_user_id is 'The user which performed the most recent update to this record.';
+
+comment on column movie_genre.update_timestamp is 'The timestamp when the record was updated most recently.';
+
+create index if not exists movie_genre_genre_ix
+    on movie_genre (genre_id);
+
+alter table movie_genre
+    owner to demo_user;
+
+alter table movie_genre
+    enable row level security;
+
+alter table movie_genre
+    force row level security;
+
+drop policy if exists movie_genre_org_isolation on movie_genre;
+
+create policy movie_genre_org_isolation on movie_genre
+    using (exists(select 1 from movie m where m.movie_id = movie_genre.movie_id))
+    with check (exists(select 1 from movie m where m.movie_id = movie_genre.movie_id));
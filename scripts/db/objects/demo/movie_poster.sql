@@ -0,0 +1,76 @@
+create table if not exists movie_poster
+(
+    movie_id         uuid                     not null
+        constraint movie_poster_pk
+            primary key,
+    blob_key         varchar                  not null,
+    content_type     varchar(100)             not null,
+    byte_size        bigint                   not null,
+    sha256           char(64)                 not null,
+    width            integer                  not null,
+    height           integer                  not null,
+    create_app_id    uuid                     not null,
+    create_user_id   uuid,
+    create_timestamp timestamp with time zone not null,
+    update_app_id    uuid                     not null,
+    update_user_id   uuid,
+    update_timestamp timestamp with time zone not null,
+    constraint movie_poster_movie_fk
+        foreign key (movie_id) references movie
+            on delete cascade,
+    constraint movie_poster_create_app_fk
+        foreign key (create_app_id) references app
+            deferrable initially deferred,
+    constraint movie_poster_update_app_fk
+        foreign key (update_app_id) references app
+            deferrable initially deferred,
+    constraint movie_poster_create_user_fk
+        foreign key (create_user_id) references users
+            deferrable initially deferred,
+    constraint movie_poster_update_user_fk
+        foreign key (update_user_id) references users
+            deferrable initially deferred
+);
+
+comment on table movie_poster is 'The movie_poster table stores the metadata of the poster image of a movie. The image itself is kept in the blob store.';
+
+comment on column movie_poster.movie_id is 'The movie the poster is for. A movie has at most one poster.';
+
+comment on column movie_poster.blob_key is 'The key of the image in the blob store.';
+
+comment on column movie_poster.content_type is 'The media type of the image, e.g. image/png.';
+
+comment on column movie_poster.byte_size is 'The size of the image in bytes.';
+
+comment on column movie_poster.sha256 is 'The hex encoded SHA-256 hash of the image. Used as the ETag when serving the image.';
+
+comment on column movie_poster.width is 'The width of the image in pixels.';
+
+comment on column movie_poster.height is 'The height of the image in pixels.';
+
+comment on column movie_poster.create_app_id is 'The application which created this record.';
+
+comment on column movie_poster.create_user_id is 'The user which created this record.';
+
+comment on column movie_poster.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column movie_poster.update_app_id is 'The application which performed the most recent update to this record.';
+
+comment on column movie_poster.update_user_id is 'The user which performed the most recent update to this record.';
+
+comment on column movie_poster.update_timestamp is 'The timestamp when the record was updated most recently.';
+
+alter table movie_poster
+    owner to demo_user;
+
+alter table movie_poster
+    enable row level security;
+
+alter table movie_poster
+    force row level security;
+
+drop policy if exists movie_poster_org_isolation on movie_poster;
+
+create policy movie_poster_org_isolation on movie_poster
+    using (exists(select 1 from movie m where m.movie_id = movie_poster.movie_id))
+    with check (exists(select 1 from movie m where m.movie_id = movie_poster.movie_id));
@@ -11,6 +11,10 @@ create table if not exists org
     update_app_id    uuid                     not null,
     update_user_id   uuid,
     update_timestamp timestamp with time zone not null,
+    delete_app_id    uuid,
+    delete_user_id   uuid,
+    delete_timestamp timestamp with time zone,
+    purge_timestamp  timestamp with time zone,
     constraint org_pk
         primary key (org_id),
     constraint org_create_user_fk
@@ -52,6 +56,14 @@ comment on column org.update_user_id is 'The user which performed the most recen
 
 comment on column org.update_timestamp is 'The timestamp when the record was updated most recently.';
 
+comment on column org.delete_app_id is 'The application which deleted this record. Null unless the record is deleted.';
+
+comment on column org.delete_user_id is 'The user which deleted this record. Null unless the record is deleted.';
+
+comment on column org.delete_timestamp is 'The timestamp when this record was deleted. A record with a delete_timestamp is hidden from callers and is purged by the purge command once it is older than the retention period.';
+
+comment on column org.purge_timestamp is 'The timestamp when this deleted record was purged. A purged org is kept, as other records refer to it, but its name and description are scrubbed and it can no longer be restored.';
+
 alter table org
     owner to demo_user;
 
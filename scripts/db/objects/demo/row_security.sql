@@ -0,0 +1,23 @@
+create or replace function current_org_id()
+    returns uuid
+    language sql
+    stable
+    parallel safe
+as
+$$
+select nullif(current_setting('app.org_id', true), '')::uuid
+$$;
+
+comment on function current_org_id() is 'The org acting in the current transaction, set from the calling app when the transaction begins. Null when no org is set, in which case row level security policies expose no org owned rows.';
+
+create or replace function row_security_bypassed()
+    returns boolean
+    language sql
+    stable
+    parallel safe
+as
+$$
+select coalesce(current_setting('app.rls_bypass', true), '') = 'on'
+$$;
+
+comment on function row_security_bypassed() is 'True when the current transaction is exempt from the org isolation policies. Set for apps of the Principal org and for system operations, such as purge, which work across orgs.';
@@ -0,0 +1,18 @@
+create table if not exists schema_version
+(
+    schema_version_id boolean default true     not null
+        constraint schema_version_pk
+            primary key
+        constraint schema_version_single_row_ck
+            check (schema_version_id),
+    version           integer                  not null,
+    update_timestamp  timestamp with time zone not null
+);
+
+comment on table schema_version is 'The schema_version table has a single row holding the number of the latest up migration file run against the database. It is stamped after all up migrations have run, and checked by the readiness endpoint.';
+
+comment on column schema_version.schema_version_id is 'Always true, to allow only a single row.';
+
+comment on column schema_version.version is 'The number of the latest up migration file run, e.g. 28 for 028-schema_version.sql.';
+
+comment on column schema_version.update_timestamp is 'The timestamp when the migrations were last run.';
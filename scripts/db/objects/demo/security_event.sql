@@ -0,0 +1,97 @@
+create table if not exists security_event
+(
+    security_event_id uuid                     not null,
+    event_seq         bigint generated always as identity,
+    event_action      varchar                  not null,
+    event_outcome     varchar                  not null,
+    actor_extl_id     varchar,
+    app_extl_id       varchar,
+    org_id            uuid,
+    org_extl_id       varchar,
+    target            varchar,
+    detail            varchar,
+    request_id        varchar,
+    event_timestamp   timestamp with time zone not null,
+    constraint security_event_pk
+        primary key (security_event_id),
+    constraint security_event_action_ck
+        check (event_action in ('authenticate_app', 'authenticate_user', 'authorize', 'genesis', 'create_api_key',
+                                'create_role', 'grant_role', 'update_logger')),
+    constraint security_event_outcome_ck
+        check (event_outcome in ('success', 'failure', 'denied'))
+);
+
+comment on table security_event is 'The security_event table is an append-only audit log of security relevant events: authentication failures, authorization denials, genesis, API key creation, role creation and grants and logger changes. There are intentionally no foreign keys so that events outlive the apps, orgs and users they reference.';
+
+comment on column security_event.security_event_id is 'The unique ID given to the security event.';
+
+comment on column security_event.event_seq is 'A monotonically increasing sequence used to order events and page through them.';
+
+comment on column security_event.event_action is 'The action taken (authenticate_app, authenticate_user, authorize, genesis, create_api_key, create_role, grant_role, update_logger).';
+
+comment on column security_event.event_outcome is 'The outcome of the action (success, failure, denied).';
+
+comment on column security_event.actor_extl_id is 'The external ID of the user who took the action. Null when the user is not known, e.g. for a failed authentication.';
+
+comment on column security_event.app_extl_id is 'The external ID of the app the action was taken through. Null when the app is not known.';
+
+comment on column security_event.org_id is 'The org the event belongs to, which row level security limits reads by. Null when the org is not known.';
+
+comment on column security_event.org_extl_id is 'The external ID of the org the event belongs to.';
+
+comment on column security_event.target is 'What the action was taken on, e.g. the route of an authorization or the user granted a role.';
+
+comment on column security_event.detail is 'Further detail of the event, e.g. why an authentication failed.';
+
+comment on column security_event.request_id is 'The ID of the request the event happened in, as sent in the Request-Id response header.';
+
+comment on column security_event.event_timestamp is 'The timestamp when the event happened.';
+
+create index if not exists security_event_org_ix
+    on security_event (org_id, event_seq);
+
+create or replace function security_event_append_only()
+    returns trigger
+    language plpgsql
+as
+$$
+begin
+    raise exception 'security_event is append-only, % is not allowed', tg_op;
+end;
+$$;
+
+create or replace trigger security_event_append_only_trg
+    before update or delete
+    on security_event
+    for each row
+execute function security_event_append_only();
+
+create or replace trigger security_event_no_truncate_trg
+    before truncate
+    on security_event
+    for each statement
+execute function security_event_append_only();
+
+alter table security_event
+    owner to demo_user;
+
+alter table security_event
+    enable row level security;
+
+-- the app connects as the table owner, which is otherwise exempt from row level security
+alter table security_event
+    force row level security;
+
+drop policy if exists security_event_org_isolation on security_event;
+
+drop policy if exists security_event_append on security_event;
+
+-- events are read only within the org they belong to, though any
+-- transaction may append one, as failed authentications have no org
+create policy security_event_org_isolation on security_event
+    for select
+    using (row_security_bypassed() or org_id = current_org_id());
+
+create policy security_event_append on security_event
+    for insert
+    with check (true);
@@ -0,0 +1,84 @@
+create table if not exists watchlist
+(
+    watchlist_id     uuid                     not null
+        constraint watchlist_pk
+            primary key,
+    extl_id          varchar                  not null,
+    org_id           uuid                     not null,
+    user_id          uuid                     not null,
+    watchlist_name   varchar(200)             not null,
+    shared           boolean default false    not null,
+    create_app_id    uuid                     not null,
+    create_user_id   uuid,
+    create_timestamp timestamp with time zone not null,
+    update_app_id    uuid                     not null,
+    update_user_id   uuid,
+    update_timestamp timestamp with time zone not null,
+    constraint watchlist_org_fk
+        foreign key (org_id) references org
+            on delete cascade,
+    constraint watchlist_user_fk
+        foreign key (user_id) references users
+            on delete cascade,
+    constraint watchlist_user_name_uk
+        unique (user_id, watchlist_name),
+    constraint watchlist_create_app_fk
+        foreign key (create_app_id) references app
+            deferrable initially deferred,
+    constraint watchlist_update_app_fk
+        foreign key (update_app_id) references app
+            deferrable initially deferred,
+    constraint watchlist_create_user_fk
+        foreign key (create_user_id) references users
+            deferrable initially deferred,
+    constraint watchlist_update_user_fk
+        foreign key (update_user_id) references users
+            deferrable initially deferred
+);
+
+comment on table watchlist is 'The watchlist table stores the personal lists of movies kept by a user. Only the owner can change a watchlist.';
+
+comment on column watchlist.watchlist_id is 'The unique ID given to the watchlist.';
+
+comment on column watchlist.extl_id is 'A unique ID given to the watchlist which can be used externally.';
+
+comment on column watchlist.org_id is 'The organization the watchlist belongs to. A shared watchlist can be read by the users of this organization.';
+
+comment on column watchlist.user_id is 'The user who owns the watchlist.';
+
+comment on column watchlist.watchlist_name is 'The name of the watchlist, unique for the owner.';
+
+comment on column watchlist.shared is 'Whether the watchlist can be read by the other users of the organization.';
+
+comment on column watchlist.create_app_id is 'The application which created this record.';
+
+comment on column watchlist.create_user_id is 'The user which created this record.';
+
+comment on column watchlist.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column watchlist.update_app_id is 'The application which performed the most recent update to this record.';
+
+comment on column watchlist.update_user_id is 'The user which performed the most recent update to this record.';
+
+comment on column watchlist.update_timestamp is 'The timestamp when the record was updated most recently.';
+
+create unique index if not exists watchlist_extl_id_uindex
+    on watchlist (extl_id);
+
+create index if not exists watchlist_org_ix
+    on watchlist (org_id);
+
+alter table watchlist
+    owner to demo_user;
+
+alter table watchlist
+    enable row level security;
+
+alter table watchlist
+    force row level security;
+
+drop policy if exists watchlist_org_isolation on watchlist;
+
+create policy watchlist_org_isolation on watchlist
+    using (row_security_bypassed() or org_id = current_org_id())
+    with check (row_security_bypassed() or org_id = current_org_id());
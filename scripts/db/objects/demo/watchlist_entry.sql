@@ -0,0 +1,70 @@
+create table if not exists watchlist_entry
+(
+    watchlist_id     uuid                     not null,
+    movie_id         uuid                     not null,
+    position         integer                  not null,
+    create_app_id    uuid                     not null,
+    create_user_id   uuid,
+    create_timestamp timestamp with time zone not null,
+    update_app_id    uuid                     not null,
+    update_user_id   uuid,
+    update_timestamp timestamp with time zone not null,
+    constraint watchlist_entry_pk
+        primary key (watchlist_id, movie_id),
+    constraint watchlist_entry_watchlist_fk
+        foreign key (watchlist_id) references watchlist
+            on delete cascade,
+    constraint watchlist_entry_movie_fk
+        foreign key (movie_id) references movie
+            on delete cascade,
+    constraint watchlist_entry_create_app_fk
+        foreign key (create_app_id) references app
+            deferrable initially deferred,
+    constraint watchlist_entry_update_app_fk
+        foreign key (update_app_id) references app
+            deferrable initially deferred,
+    constraint watchlist_entry_create_user_fk
+        foreign key (create_user_id) references users
+            deferrable initially deferred,
+    constraint watchlist_entry_update_user_fk
+        foreign key (update_user_id) references users
+            deferrable initially deferred
+);
+
+comment on table watchlist_entry is 'The watchlist_entry table stores the movies on a watchlist.';
+
+comment on column watchlist_entry.watchlist_id is 'The watchlist the movie is on.';
+
+comment on column watchlist_entry.movie_id is 'The movie on the watchlist.';
+
+comment on column watchlist_entry.position is 'The position of the movie on the watchlist, starting at 1.';
+
+comment on column watchlist_entry.create_app_id is 'The application which created this record.';
+
+comment on column watchlist_entry.create_user_id is 'The user which created this record.';
+
+comment on column watchlist_entry.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column watchlist_entry.update_app_id is 'The application which performed the most recent update to this record.';
+
+comment on column watchlist_entry.update_user_id is 'The user which performed the most recent update to this record.';
+
+comment on column watchlist_entry.update_timestamp is 'The timestamp when the record was updated most recently.';
+
+create index if not exists watchlist_entry_movie_ix
+    on watchlist_entry (movie_id);
+
+alter table watchlist_entry
+    owner to demo_user;
+
+alter table watchlist_entry
+    enable row level security;
+
+alter table watchlist_entry
+    force row level security;
+
+drop policy if exists watchlist_entry_org_isolation on watchlist_entry;
+
+create policy watchlist_entry_org_isolation on watchlist_entry
+    using (exists(select 1 from watchlist w where w.watchlist_id = watchlist_entry.watchlist_id))
+    with check (exists(select 1 from watchlist w where w.watchlist_id = watchlist_entry.watchlist_id));
@@ -0,0 +1,93 @@
+create table if not exists webhook
+(
+    webhook_id        uuid                     not null
+        constraint webhook_pk
+            primary key,
+    extl_id           varchar                  not null,
+    org_id            uuid                     not null,
+    app_id            uuid                     not null,
+    endpoint_url      varchar(2000)            not null,
+    secret_ciphertext varchar                  not null,
+    event_types       varchar[]                not null,
+    active            boolean default true     not null,
+    create_app_id     uuid                     not null,
+    create_user_id    uuid,
+    create_timestamp  timestamp with time zone not null,
+    update_app_id     uuid                     not null,
+    update_user_id    uuid,
+    update_timestamp  timestamp with time zone not null,
+    constraint webhook_org_fk
+        foreign key (org_id) references org
+            on delete cascade,
+    constraint webhook_app_fk
+        foreign key (app_id) references app
+            on delete cascade,
+    constraint webhook_event_types_ck
+        check (cardinality(event_types) > 0),
+    constraint webhook_create_app_fk
+        foreign key (create_app_id) references app
+            deferrable initially deferred,
+    constraint webhook_update_app_fk
+        foreign key (update_app_id) references app
+            deferrable initially deferred,
+    constraint webhook_create_user_fk
+        foreign key (create_user_id) references users
+            deferrable initially deferred,
+    constraint webhook_update_user_fk
+        foreign key (update_user_id) references users
+            deferrable initially deferred
+);
+
+comment on table webhook is 'The webhook table stores the endpoints an app has registered to be sent the events of its organization.';
+
+comment on column webhook.webhook_id is 'The unique ID given to the webhook.';
+
+comment on column webhook.extl_id is 'A unique ID given to the webhook which can be used externally.';
+
+comment on column webhook.org_id is 'The organization whose events are sent to the webhook.';
+
+comment on column webhook.app_id is 'The app which registered, and alone can manage, the webhook.';
+
+comment on column webhook.endpoint_url is 'The URL events are posted to.';
+
+comment on column webhook.secret_ciphertext is 'The secret deliveries are signed with, encrypted with the encryption key and hex encoded.';
+
+comment on column webhook.event_types is 'The types of event sent to the webhook.';
+
+comment on column webhook.active is 'Whether events are sent to the webhook.';
+
+comment on column webhook.create_app_id is 'The application which created this record.';
+
+comment on column webhook.create_user_id is 'The user which created this record.';
+
+comment on column webhook.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column webhook.update_app_id is 'The application which performed the most recent update to this record.';
+
+comment on column webhook.update_user_id is 'The user which performed the most recent update to this record.';
+
+comment on column webhook.update_timestamp is 'The timestamp when the record was updated most recently.';
+
+create unique index if not exists webhook_extl_id_uindex
+    on webhook (extl_id);
+
+create index if not exists webhook_org_ix
+    on webhook (org_id);
+
+create index if not exists webhook_app_ix
+    on webhook (app_id);
+
+alter table webhook
+    owner to demo_user;
+
+alter table webhook
+    enable row level security;
+
+alter table webhook
+    force row level security;
+
+drop policy if exists webhook_org_isolation on webhook;
+
+create policy webhook_org_isolation on webhook
+    using (row_security_bypassed() or org_id = current_org_id())
+    with check (row_security_bypassed() or org_id = current_org_id());
@@ -0,0 +1,71 @@
+create table if not exists webhook_delivery
+(
+    delivery_id      uuid                     not null
+        constraint webhook_delivery_pk
+            primary key,
+    extl_id          varchar                  not null,
+    webhook_id       uuid                     not null,
+    event_id         uuid                     not null,
+    delivery_state   varchar(20)              not null,
+    attempts         integer default 0        not null,
+    last_status_code integer,
+    last_error       text,
+    create_timestamp timestamp with time zone not null,
+    update_timestamp timestamp with time zone not null,
+    constraint webhook_delivery_webhook_fk
+        foreign key (webhook_id) references webhook
+            on delete cascade,
+    constraint webhook_delivery_event_fk
+        foreign key (event_id) references event_outbox
+            on delete cascade,
+    constraint webhook_delivery_webhook_event_uk
+        unique (webhook_id, event_id),
+    constraint webhook_delivery_state_ck
+        check (delivery_state in ('pending', 'succeeded', 'failed'))
+);
+
+comment on table webhook_delivery is 'The webhook_delivery table stores the sending of an event to a webhook. Each delivery is run as a background job, which retries it with backoff.';
+
+comment on column webhook_delivery.delivery_id is 'The unique ID given to the delivery.';
+
+comment on column webhook_delivery.extl_id is 'A unique ID given to the delivery which can be used externally. Sent with each attempt, so a receiver can ignore a delivery it has already seen.';
+
+comment on column webhook_delivery.webhook_id is 'The webhook the event is sent to.';
+
+comment on column webhook_delivery.event_id is 'The event being sent.';
+
+comment on column webhook_delivery.delivery_state is 'The state of the delivery: pending, succeeded or failed. A failed delivery ran out of attempts.';
+
+comment on column webhook_delivery.attempts is 'The number of times sending the event has been attempted.';
+
+comment on column webhook_delivery.last_status_code is 'The HTTP status code of the most recent attempt. Null if no response was received.';
+
+comment on column webhook_delivery.last_error is 'The error from the most recent failed attempt.';
+
+comment on column webhook_delivery.create_timestamp is 'The timestamp when this record was created.';
+
+comment on column webhook_delivery.update_timestamp is 'The timestamp when the record was updated most recently.';
+
+create unique index if not exists webhook_delivery_extl_id_uindex
+    on webhook_delivery (extl_id);
+
+create index if not exists webhook_delivery_webhook_create_timestamp_ix
+    on webhook_delivery (webhook_id, create_timestamp);
+
+create index if not exists webhook_delivery_event_ix
+    on webhook_delivery (event_id);
+
+alter table webhook_delivery
+    owner to demo_user;
+
+alter table webhook_delivery
+    enable row level security;
+
+alter table webhook_delivery
+    force row level security;
+
+drop policy if exists webhook_delivery_org_isolation on webhook_delivery;
+
+create policy webhook_delivery_org_isolation on webhook_delivery
+    using (exists(select 1 from webhook w where w.webhook_id = webhook_delivery.webhook_id))
+    with check (exists(select 1 from webhook w where w.webhook_id = webhook_delivery.webhook_id));
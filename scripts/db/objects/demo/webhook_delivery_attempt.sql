@@ -0,0 +1,43 @@
+create table if not exists webhook_delivery_attempt
+(
+    delivery_id       uuid                     not null,
+    attempt           integer                  not null,
+    status_code       integer,
+    error_message     text,
+    duration_ms       integer                  not null,
+    attempt_timestamp timestamp with time zone not null,
+    constraint webhook_delivery_attempt_pk
+        primary key (delivery_id, attempt),
+    constraint webhook_delivery_attempt_delivery_fk
+        foreign key (delivery_id) references webhook_delivery
+            on delete cascade
+);
+
+comment on table webhook_delivery_attempt is 'The webhook_delivery_attempt table stores the history of attempts to send an event to a webhook.';
+
+comment on column webhook_delivery_attempt.delivery_id is 'The delivery the attempt was made for.';
+
+comment on column webhook_delivery_attempt.attempt is 'The number of the attempt, starting at 1.';
+
+comment on column webhook_delivery_attempt.status_code is 'The HTTP status code of the response. Null if no response was received.';
+
+comment on column webhook_delivery_attempt.error_message is 'Why the attempt failed. Null if it succeeded.';
+
+comment on column webhook_delivery_attempt.duration_ms is 'How long the attempt took, in milliseconds.';
+
+comment on column webhook_delivery_attempt.attempt_timestamp is 'The timestamp when the attempt was made.';
+
+alter table webhook_delivery_attempt
+    owner to demo_user;
+
+alter table webhook_delivery_attempt
+    enable row level security;
+
+alter table webhook_delivery_attempt
+    force row level security;
+
+drop policy if exists webhook_delivery_attempt_org_isolation on webhook_delivery_attempt;
+
+create policy webhook_delivery_attempt_org_isolation on webhook_delivery_attempt
+    using (exists(select 1 from webhook_delivery d where d.delivery_id = webhook_delivery_attempt.delivery_id))
+    with check (exists(select 1 from webhook_delivery d where d.delivery_id = webhook_delivery_attempt.delivery_id));
@@ -0,0 +1,151 @@
+package diygoapi
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+// Security event list limits
+const (
+	DefaultSecurityEventListLimit = 50
+	MaxSecurityEventListLimit     = 500
+)
+
+// SecurityAction is a security relevant action recorded as a
+// SecurityEvent
+type SecurityAction string
+
+// Security actions recorded in the security event log
+const (
+	AuthenticateAppSecurityAction  SecurityAction = "authenticate_app"
+	AuthenticateUserSecurityAction SecurityAction = "authenticate_user"
+	AuthorizeSecurityAction        SecurityAction = "authorize"
+	GenesisSecurityAction          SecurityAction = "genesis"
+	CreateAPIKeySecurityAction     SecurityAction = "create_api_key"
+	CreateRoleSecurityAction       SecurityAction = "create_role"
+	GrantRoleSecurityAction        SecurityAction = "grant_role"
+	UpdateLoggerSecurityAction     SecurityAction = "update_logger"
+)
+
+// ParseSecurityAction initializes a SecurityAction given a
+// case-insensitive string
+func ParseSecurityAction(s string) (SecurityAction, error) {
+	const op errs.Op = "diygoapi/ParseSecurityAction"
+
+	switch sa := SecurityAction(strings.ToLower(s)); sa {
+	case AuthenticateAppSecurityAction, AuthenticateUserSecurityAction, AuthorizeSecurityAction,
+		GenesisSecurityAction, CreateAPIKeySecurityAction, CreateRoleSecurityAction,
+		GrantRoleSecurityAction, UpdateLoggerSecurityAction:
+		return sa, nil
+	}
+
+	return "", errs.E(op, errs.Validation, errs.Parameter("action"),
+		"action must be one of authenticate_app, authenticate_user, authorize, genesis, create_api_key, create_role, grant_role or update_logger")
+}
+
+// SecurityOutcome is the outcome of a SecurityAction
+type SecurityOutcome string
+
+// Security outcomes. Failure is used for authentication and denied
+// for authorization.
+const (
+	SuccessSecurityOutcome SecurityOutcome = "success"
+	FailureSecurityOutcome SecurityOutcome = "failure"
+	DeniedSecurityOutcome  SecurityOutcome = "denied"
+)
+
+// ParseSecurityOutcome initializes a SecurityOutcome given a
+// case-insensitive string
+func ParseSecurityOutcome(s string) (SecurityOutcome, error) {
+	const op errs.Op = "diygoapi/ParseSecurityOutcome"
+
+	switch so := SecurityOutcome(strings.ToLower(s)); so {
+	case SuccessSecurityOutcome, FailureSecurityOutcome, DeniedSecurityOutcome:
+		return so, nil
+	}
+
+	return "", errs.E(op, errs.Validation, errs.Parameter("outcome"),
+		"outcome must be one of success, failure or denied")
+}
+
+// SecurityEvent is a security relevant event, appended to the
+// security event log. Actor, App and Org are nil when not known, as
+// for a failed authentication.
+type SecurityEvent struct {
+	Action  SecurityAction
+	Outcome SecurityOutcome
+	// Actor is the User who took the action
+	Actor *User
+	// App is the App the action was taken through
+	App *App
+	// Org is the Org the event belongs to, only users of which (or of
+	// the Principal org) can read it
+	Org *Org
+	// Target is what the action was taken on, e.g. the route of an
+	// authorization or the user granted a role
+	Target string
+	// Detail is further detail, e.g. why an authentication failed
+	Detail string
+	Moment time.Time
+}
+
+// NewSecurityEvent initializes a SecurityEvent for an action taken by
+// the User of the Audit through its App, belonging to the App's Org
+func NewSecurityEvent(action SecurityAction, outcome SecurityOutcome, adt Audit) SecurityEvent {
+	e := SecurityEvent{
+		Action:  action,
+		Outcome: outcome,
+		Actor:   adt.User,
+		App:     adt.App,
+		Moment:  adt.Moment,
+	}
+	if adt.App != nil {
+		e.Org = adt.App.Org
+	}
+	if e.Moment.IsZero() {
+		e.Moment = time.Now()
+	}
+	return e
+}
+
+// SecurityEventServicer records security events and finds them
+type SecurityEventServicer interface {
+	// Record appends the event to the log in a transaction of its
+	// own, for events which happen as a request fails.
+	Record(ctx context.Context, e SecurityEvent) error
+	FindSecurityEvents(ctx context.Context, r *FindSecurityEventsRequest) ([]*SecurityEventResponse, error)
+}
+
+// FindSecurityEventsRequest is the request struct for listing
+// security events, newest first. Empty fields do not filter. If
+// Before is set, only events with a lower Sequence are listed, to page
+// back from the last event of a previous list. If Limit is zero,
+// DefaultSecurityEventListLimit is used.
+type FindSecurityEventsRequest struct {
+	Action      SecurityAction
+	Outcome     SecurityOutcome
+	ActorExtlID string
+	AppExtlID   string
+	OrgExtlID   string
+	Since       time.Time
+	Until       time.Time
+	Before      int64
+	Limit       int
+}
+
+// SecurityEventResponse is the response struct for a SecurityEvent
+type SecurityEventResponse struct {
+	Sequence      int64           `json:"sequence"`
+	Action        SecurityAction  `json:"action"`
+	Outcome       SecurityOutcome `json:"outcome"`
+	ActorExtlID   string          `json:"actor_extl_id,omitempty"`
+	AppExtlID     string          `json:"app_extl_id,omitempty"`
+	OrgExtlID     string          `json:"org_extl_id,omitempty"`
+	Target        string          `json:"target,omitempty"`
+	Detail        string          `json:"detail,omitempty"`
+	RequestID     string          `json:"request_id,omitempty"`
+	EventDateTime string          `json:"event_date_time"`
+}
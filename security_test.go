@@ -0,0 +1,51 @@
+package diygoapi_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+func TestParseSecurityAction(t *testing.T) {
+	c := qt.New(t)
+
+	sa, err := diygoapi.ParseSecurityAction("Grant_Role")
+	c.Assert(err, qt.IsNil)
+	c.Assert(sa, qt.Equals, diygoapi.GrantRoleSecurityAction)
+
+	_, err = diygoapi.ParseSecurityAction("login")
+	c.Assert(errs.Match(errs.E(errs.Validation, errs.Parameter("action")), err), qt.IsTrue)
+}
+
+func TestParseSecurityOutcome(t *testing.T) {
+	c := qt.New(t)
+
+	so, err := diygoapi.ParseSecurityOutcome("DENIED")
+	c.Assert(err, qt.IsNil)
+	c.Assert(so, qt.Equals, diygoapi.DeniedSecurityOutcome)
+
+	_, err = diygoapi.ParseSecurityOutcome("blocked")
+	c.Assert(errs.Match(errs.E(errs.Validation, errs.Parameter("outcome")), err), qt.IsTrue)
+}
+
+func TestNewSecurityEvent(t *testing.T) {
+	c := qt.New(t)
+
+	org := &diygoapi.Org{Name: "org"}
+	app := &diygoapi.App{Name: "app", Org: org}
+	user := &diygoapi.User{FirstName: "Otto"}
+
+	e := diygoapi.NewSecurityEvent(diygoapi.AuthorizeSecurityAction, diygoapi.DeniedSecurityOutcome, diygoapi.Audit{App: app, User: user})
+	c.Assert(e.Actor, qt.Equals, user)
+	c.Assert(e.App, qt.Equals, app)
+	c.Assert(e.Org, qt.Equals, org)
+	c.Assert(e.Moment.IsZero(), qt.IsFalse)
+
+	// an event of an unauthenticated request has no app, nor org
+	e = diygoapi.NewSecurityEvent(diygoapi.AuthenticateUserSecurityAction, diygoapi.FailureSecurityOutcome, diygoapi.Audit{})
+	c.Assert(e.App, qt.IsNil)
+	c.Assert(e.Org, qt.IsNil)
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/hlog"
+
+	"github.com/gilcrest/diygoapi"
+)
+
+// corsExposedHeaders are the response headers browser clients are
+// allowed to read, beyond the CORS-safelisted ones
+var corsExposedHeaders = strings.Join([]string{
+	"ETag",
+	"Request-Id",
+	"Retry-After",
+	"RateLimit-Limit",
+	"RateLimit-Remaining",
+	"RateLimit-Reset",
+}, ", ")
+
+// CORS is the Cross-Origin Resource Sharing policy which lets browser
+// clients call the API from other origins
+type CORS struct {
+	// AllowedOrigins are the origins, in the form returned by
+	// diygoapi.ParseOrigin, browser requests are allowed from.
+	// diygoapi.AnyOrigin allows any origin. An App can allow its own
+	// origins in place of these, found by the CORSServicer.
+	AllowedOrigins []string
+
+	// AllowedMethods are the methods allowed in preflight responses
+	AllowedMethods []string
+
+	// AllowedHeaders are the request headers allowed in preflight
+	// responses, e.g. X-APP-ID
+	AllowedHeaders []string
+
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// corsHandler wraps the router to answer CORS preflight requests, which
+// are not routed as no route is registered for OPTIONS and must be
+// answered before authentication as browsers send them without
+// credentials. The Access-Control-Allow-Origin header is set for other
+// requests from an allowed origin, which appCORSHandler may change once
+// the App is known.
+func (s *Server) corsHandler(h http.Handler) http.Handler {
+	if s.CORS == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		origin, err := diygoapi.ParseOrigin(origin)
+		if err != nil {
+			// not an origin any App could allow
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			s.handlePreflight(w, r, origin)
+			return
+		}
+
+		if diygoapi.OriginAllowed(s.CORS.AllowedOrigins, origin) {
+			setCORSOriginHeaders(w.Header(), origin)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// handlePreflight answers a preflight request with 204 No Content. The
+// Access-Control-Allow-* headers are only sent if the origin is allowed
+// by the configuration or any App, and the method by the
+// configuration, otherwise the browser does not make the request.
+func (s *Server) handlePreflight(w http.ResponseWriter, r *http.Request, origin string) {
+	h := w.Header()
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+
+	allowed := diygoapi.OriginAllowed(s.CORS.AllowedOrigins, origin)
+	if !allowed && s.CORSServicer != nil {
+		var err error
+		allowed, err = s.CORSServicer.AnyAppAllowsOrigin(r.Context(), origin)
+		if err != nil {
+			s.Logger.Error().Err(err).Str("origin", origin).Msg("app allowed origins not found, preflight refused")
+		}
+	}
+
+	method := r.Header.Get("Access-Control-Request-Method")
+	if !allowed || !containsFold(s.CORS.AllowedMethods, method) {
+		s.Logger.Debug().Str("origin", origin).Str("method", method).Msg("CORS preflight refused")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h.Set("Access-Control-Allow-Origin", origin)
+	h.Set("Access-Control-Allow-Methods", strings.Join(s.CORS.AllowedMethods, ", "))
+	h.Set("Access-Control-Allow-Headers", strings.Join(s.CORS.AllowedHeaders, ", "))
+	if s.CORS.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(int(s.CORS.MaxAge.Seconds())))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// appCORSHandler middleware applies the allowed origins of the App in
+// the request context, if it has its own, in place of the configured
+// ones
+func (s *Server) appCORSHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if s.CORS == nil || s.CORSServicer == nil || origin == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		origin, err := diygoapi.ParseOrigin(origin)
+		if err != nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		a, err := diygoapi.AppFromRequest(r)
+		if err != nil {
+			// no App, the configured origins apply
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		origins, err := s.CORSServicer.FindAppAllowedOrigins(r.Context(), a)
+		if err != nil {
+			hlog.FromRequest(r).Error().Err(err).Msg("app allowed origins not found, configured origins apply")
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if origins != nil {
+			if diygoapi.OriginAllowed(origins, origin) {
+				setCORSOriginHeaders(w.Header(), origin)
+			} else {
+				w.Header().Del("Access-Control-Allow-Origin")
+				w.Header().Del("Access-Control-Expose-Headers")
+			}
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// setCORSOriginHeaders allows the browser to read the response from
+// origin. The origin is echoed rather than "*", so responses are the
+// same whether any or specific origins are allowed.
+func setCORSOriginHeaders(h http.Header, origin string) {
+	h.Set("Access-Control-Allow-Origin", origin)
+	h.Set("Access-Control-Expose-Headers", corsExposedHeaders)
+}
+
+func containsFold(values []string, v string) bool {
+	for _, s := range values {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
+)
+
+type mockCORSService struct {
+	origins []string
+}
+
+func (s mockCORSService) AnyAppAllowsOrigin(ctx context.Context, origin string) (bool, error) {
+	return diygoapi.OriginAllowed(s.origins, origin), nil
+}
+
+func (s mockCORSService) FindAppAllowedOrigins(ctx context.Context, a *diygoapi.App) ([]string, error) {
+	return s.origins, nil
+}
+
+func TestServer_corsHandler(t *testing.T) {
+	c := qt.New(t)
+
+	s := New(http.NewServeMux(), nopDriver{}, zerolog.Nop())
+	s.CORS = &CORS{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Authorization", diygoapi.AppIDHeaderKey},
+		MaxAge:         10 * time.Minute,
+	}
+	s.CORSServicer = mockCORSService{origins: []string{"https://partner.example.com"}}
+	h := s.corsHandler(s.mux)
+
+	preflight := func(origin, method string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodOptions, "/api/v1/movies", nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", method)
+		r.Header.Set("Access-Control-Request-Headers", "authorization,x-app-id")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	c.Run("preflight", func(c *qt.C) {
+		// answered before authentication, which would otherwise fail
+		// as no AuthenticationServicer is set
+		w := preflight("https://app.example.com", http.MethodPost)
+		c.Assert(w.Code, qt.Equals, http.StatusNoContent)
+		c.Assert(w.Header().Get("Access-Control-Allow-Origin"), qt.Equals, "https://app.example.com")
+		c.Assert(w.Header().Get("Access-Control-Allow-Methods"), qt.Equals, "GET, POST")
+		c.Assert(w.Header().Get("Access-Control-Allow-Headers"), qt.Equals, "Authorization, X-APP-ID")
+		c.Assert(w.Header().Get("Access-Control-Max-Age"), qt.Equals, "600")
+		c.Assert(w.Header().Values("Vary"), qt.Contains, "Origin")
+	})
+
+	c.Run("preflight from an app's origin", func(c *qt.C) {
+		w := preflight("https://partner.example.com", http.MethodGet)
+		c.Assert(w.Code, qt.Equals, http.StatusNoContent)
+		c.Assert(w.Header().Get("Access-Control-Allow-Origin"), qt.Equals, "https://partner.example.com")
+	})
+
+	c.Run("preflight refused", func(c *qt.C) {
+		for _, tt := range []struct{ origin, method string }{
+			{"https://evil.example.com", http.MethodGet},
+			{"https://app.example.com", http.MethodDelete},
+		} {
+			w := preflight(tt.origin, tt.method)
+			c.Assert(w.Code, qt.Equals, http.StatusNoContent)
+			c.Assert(w.Header().Get("Access-Control-Allow-Origin"), qt.Equals, "")
+			c.Assert(w.Header().Get("Access-Control-Allow-Methods"), qt.Equals, "")
+		}
+	})
+
+	c.Run("request", func(c *qt.C) {
+		r := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		r.Header.Set("Origin", "https://app.example.com")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		c.Assert(w.Code, qt.Equals, http.StatusOK)
+		c.Assert(w.Header().Get("Access-Control-Allow-Origin"), qt.Equals, "https://app.example.com")
+		c.Assert(w.Header().Get("Access-Control-Expose-Headers"), qt.Contains, "Request-Id")
+
+		// no CORS headers for other origins, or requests without one
+		r.Header.Set("Origin", "https://evil.example.com")
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		c.Assert(w.Header().Get("Access-Control-Allow-Origin"), qt.Equals, "")
+
+		r.Header.Del("Origin")
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		c.Assert(w.Header().Get("Vary"), qt.Equals, "")
+	})
+
+	c.Run("app origins", func(c *qt.C) {
+		app := &diygoapi.App{ExternalID: []byte("app"), Org: &diygoapi.Org{}}
+		appHandler := s.corsHandler(s.appCORSHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "ok")
+		})))
+		serve := func(origin string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodGet, "/test", nil)
+			r = r.WithContext(diygoapi.NewContextWithApp(r.Context(), app))
+			r.Header.Set("Origin", origin)
+			w := httptest.NewRecorder()
+			appHandler.ServeHTTP(w, r)
+			return w
+		}
+
+		// the app's origins are allowed in place of the configured ones
+		w := serve("https://partner.example.com")
+		c.Assert(w.Header().Get("Access-Control-Allow-Origin"), qt.Equals, "https://partner.example.com")
+		w = serve("https://app.example.com")
+		c.Assert(w.Header().Get("Access-Control-Allow-Origin"), qt.Equals, "")
+
+		// an app without origins of its own uses the configured ones
+		s.CORSServicer = mockCORSService{}
+		w = serve("https://app.example.com")
+		c.Assert(w.Header().Get("Access-Control-Allow-Origin"), qt.Equals, "https://app.example.com")
+	})
+}
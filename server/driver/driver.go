@@ -12,7 +12,9 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// @gilcrest - removed TLSServer interface
+// @gilcrest - TLSServer takes a tls.Config rather than certificate and
+// key files, so the certificate can be reloaded and client certificates
+// verified
 
 // Package driver defines an interface for custom HTTP listeners.
 // Application code should use package server.
@@ -20,6 +22,7 @@ package driver
 
 import (
 	"context"
+	"crypto/tls"
 	"net/http"
 )
 
@@ -42,3 +45,12 @@ type Server interface {
 	// underlying Listener(s).
 	Shutdown(ctx context.Context) error
 }
+
+// TLSServer is an optional interface for Server that enables TLS.
+type TLSServer interface {
+	Server
+	// ListenAndServeTLS is the same as ListenAndServe, but it serves
+	// HTTPS on incoming connections using cfg, which has the
+	// certificate to serve.
+	ListenAndServeTLS(addr string, cfg *tls.Config, h http.Handler) error
+}
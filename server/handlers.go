@@ -1,9 +1,19 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/hlog"
 
 	"github.com/gilcrest/diygoapi"
@@ -23,20 +33,1047 @@ func (s *Server) handleMovieCreate(w http.ResponseWriter, r *http.Request) {
 	// Declare request body (rb) as an instance of service.MovieRequest
 	rb := new(diygoapi.CreateMovieRequest)
 
-	// Decode JSON HTTP request body into a Decoder type
-	// and unmarshal that into the CreateMovieRequest struct (rb)
-	err = json.NewDecoder(r.Body).Decode(&rb)
+	// Decode the JSON request body into rb
+	err = s.decodeJSON(w, r, rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var response *diygoapi.MovieResponse
+	response, err = s.MovieServicer.Create(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleMovieUpdate handles PUT requests for the /movies/{id} endpoint
+// and updates the given movie
+func (s *Server) handleMovieUpdate(w http.ResponseWriter, r *http.Request) {
+
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// return the extlID from the Path
+	extlID := r.PathValue("extlID")
+
+	// Declare request body (rb) as an instance of service.MovieRequest
+	rb := new(diygoapi.UpdateMovieRequest)
+
+	// Decode the JSON request body into rb
+	err = s.decodeJSON(w, r, rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// External ID is from path variable, need to set separate
+	// from decoding response body
+	rb.ExternalID = extlID
+
+	var response *diygoapi.MovieResponse
+	response, err = s.MovieServicer.Update(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleMovieDelete handles DELETE requests for the /movies/{id} endpoint
+// and updates the given movie
+func (s *Server) handleMovieDelete(w http.ResponseWriter, r *http.Request) {
+
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// return the extlID from the Path
+	extlID := r.PathValue("extlID")
+
+	var response diygoapi.DeleteResponse
+	response, err = s.MovieServicer.Delete(r.Context(), extlID, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleMovieRestore handles POST requests for the /movies/{id}/restore endpoint
+// and restores a deleted movie
+func (s *Server) handleMovieRestore(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// return the extlID from the Path
+	extlID := r.PathValue("extlID")
+
+	var response *diygoapi.MovieResponse
+	response, err = s.MovieServicer.Restore(r.Context(), extlID, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleMovieImport handles POST requests for the /movies:import endpoint
+// and creates movies in bulk from a CSV or NDJSON request body
+func (s *Server) handleMovieImport(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var format diygoapi.ImportFormat
+	format, err = importFormat(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var mode diygoapi.ImportMode
+	mode, err = diygoapi.ParseImportMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
 	defer r.Body.Close()
-	// Call decoderErr to determine if body is nil, json is malformed
-	// or any other error
-	err = decoderErr(err)
+	rb := &diygoapi.ImportMoviesRequest{
+		Format: format,
+		Mode:   mode,
+		Data:   r.Body,
+	}
+
+	var response *diygoapi.ImportMoviesResponse
+	response, err = s.MovieServicer.Import(r.Context(), rb, adt)
+	if err != nil {
+		if response == nil {
+			errs.HTTPErrorResponse(w, logger, err)
+			return
+		}
+		// a rejected import is still sent with the outcome of every
+		// row, so the caller can see which rows to fix
+		importRejectedResponse(w, logger, err)
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// importRejectedResponse logs the error which rejected an import and
+// writes a 400 Bad Request status. The caller writes the response
+// body with the result of each row.
+func importRejectedResponse(w http.ResponseWriter, lgr zerolog.Logger, err error) {
+	var e *errs.Error
+	if !errors.As(err, &e) {
+		e = &errs.Error{Kind: errs.Validation}
+	}
+	if kr, ok := w.(errs.ErrorKindRecorder); ok {
+		kr.RecordErrorKind(e.Kind)
+	}
+
+	lgr.Error().Err(err).
+		Int("http_statuscode", http.StatusBadRequest).
+		Str("Kind", e.Kind.String()).
+		Str("Code", string(e.Code)).
+		Msg("import rejected")
+
+	w.Header().Set(contentTypeHeaderKey, "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+}
+
+// importFormat determines the bulk import format from the
+// request Content-Type header
+func importFormat(r *http.Request) (diygoapi.ImportFormat, error) {
+	contentType := r.Header.Get(contentTypeHeaderKey)
+	if contentType == "" {
+		return "", errs.E(errs.InvalidRequest, "Missing "+contentTypeHeaderKey+" header")
+	}
+
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", errs.E(errs.InvalidRequest, "Malformed "+contentTypeHeaderKey+" header")
+	}
+
+	switch mt {
+	case "text/csv":
+		return diygoapi.CSVImportFormat, nil
+	case "application/x-ndjson", "application/ndjson":
+		return diygoapi.NDJSONImportFormat, nil
+	}
+
+	return "", errs.E(errs.UnsupportedMediaType, contentTypeHeaderKey+" header must be text/csv or application/x-ndjson")
+}
+
+// handleMovieExport handles GET requests for the /movies:export endpoint
+// and streams all movies as CSV or NDJSON
+func (s *Server) handleMovieExport(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	s.handleExport(w, r, func(ctx context.Context, f diygoapi.ExportFormat, w io.Writer) error {
+		return s.MovieServicer.Export(ctx, f, w, adt)
+	})
+}
+
+// handleMovieSearch handles GET requests for the /movies/search endpoint
+// and finds movies matching the q query parameter
+func (s *Server) handleMovieSearch(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	rb := &diygoapi.SearchMoviesRequest{Query: r.URL.Query().Get("q")}
+
+	if l := r.URL.Query().Get("limit"); l != "" {
+		rb.Limit, err = strconv.Atoi(l)
+		if err != nil {
+			errs.HTTPErrorResponse(w, logger, errs.E(errs.Validation, errs.Parameter("limit"), "limit must be a whole number"))
+			return
+		}
+	}
+
+	var response []*diygoapi.MovieSearchResponse
+	response, err = s.MovieServicer.Search(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleFindMoviesByCredit handles GET requests for the /movies/credits
+// endpoint and finds the movies the person given by the name query
+// parameter is credited on, optionally limited to a role
+func (s *Server) handleFindMoviesByCredit(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	rb := &diygoapi.FindMoviesByCreditRequest{
+		Name: r.URL.Query().Get("name"),
+		Role: r.URL.Query().Get("role"),
+	}
+
+	var response []*diygoapi.MovieResponse
+	response, err = s.MovieServicer.FindMoviesByCredit(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleFindMoviesByGenre handles GET requests for the
+// /genres/{genre}/movies endpoint and finds the movies in a genre
+func (s *Server) handleFindMoviesByGenre(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var response []*diygoapi.MovieResponse
+	response, err = s.MovieServicer.FindMoviesByGenre(r.Context(), r.PathValue("genre"), adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleFindAllGenres handles GET requests for the /genres endpoint
+// and lists the genre taxonomy
+func (s *Server) handleFindAllGenres(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	response, err := s.MovieServicer.FindAllGenres(r.Context())
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleFindAllRatings handles GET requests for the /ratings endpoint
+// and lists the ratings of each rating system
+func (s *Server) handleFindAllRatings(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	response, err := s.MovieServicer.FindAllRatings(r.Context())
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleFindMovieByID handles GET requests for the /movies/{id} endpoint
+// and finds a movie by its ID
+func (s *Server) handleFindMovieByID(w http.ResponseWriter, r *http.Request) {
+
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// return the extlID from the Path
+	extlID := r.PathValue("extlID")
+
+	var response *diygoapi.MovieResponse
+	response, err = s.MovieServicer.FindMovieByExternalID(r.Context(), extlID, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleFindAllMovies handles GET requests for the /movies endpoint and finds
+// all movies
+func (s *Server) handleFindAllMovies(w http.ResponseWriter, r *http.Request) {
+
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var response []*diygoapi.MovieResponse
+	response, err = s.MovieServicer.FindAllMovies(r.Context(), adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// posterMultipartOverhead is the room allowed in an upload request body
+// for the multipart framing around the poster image
+const posterMultipartOverhead = 64 << 10
+
+// handleMoviePosterUpload handles PUT requests for the
+// /movies/{extlID}/poster endpoint. The body is multipart/form-data
+// with the image in a part named poster.
+func (s *Server) handleMoviePosterUpload(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, diygoapi.MaxPosterSize+posterMultipartOverhead)
+	defer r.Body.Close()
+
+	var part *multipart.Part
+	part, err = posterPart(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+	defer part.Close()
+
+	// the content type of the part is checked against the image
+	// itself by the service, parameters are not significant
+	contentType, _, _ := mime.ParseMediaType(part.Header.Get(contentTypeHeaderKey))
+
+	rb := &diygoapi.UploadPosterRequest{
+		MovieExternalID: r.PathValue("extlID"),
+		ContentType:     contentType,
+		Data:            part,
+	}
+
+	var response *diygoapi.PosterResponse
+	response, err = s.PosterServicer.Upload(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// posterPart returns the part of a multipart upload request named poster
+func posterPart(r *http.Request) (*multipart.Part, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		if errors.Is(err, http.ErrNotMultipart) {
+			return nil, errs.E(errs.UnsupportedMediaType, contentTypeHeaderKey+" header must be multipart/form-data")
+		}
+		return nil, errs.E(errs.InvalidRequest, err)
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errs.E(errs.Validation, errs.Parameter("poster"), errs.MissingField("poster"))
+			}
+			return nil, errs.E(errs.InvalidRequest, err)
+		}
+		if part.FormName() == "poster" {
+			return part, nil
+		}
+	}
+}
+
+// handleMoviePosterFind handles GET requests for the
+// /movies/{extlID}/poster endpoint and serves the poster image. When
+// the v query parameter is the Version of the current image, as it is
+// in the poster_url of a movie, the response can be cached indefinitely.
+func (s *Server) handleMoviePosterFind(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var (
+		p  *diygoapi.Poster
+		rc io.ReadCloser
+	)
+	p, rc, err = s.PosterServicer.Find(r.Context(), r.PathValue("extlID"), adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+	defer rc.Close()
+
+	// posters are only served to authenticated users, so shared
+	// caches must not keep them
+	cacheControl := "private, no-cache"
+	if r.URL.Query().Get("v") == p.Version() {
+		cacheControl = "private, max-age=31536000, immutable"
+	}
+
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("ETag", p.ETag())
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Last-Modified", p.UpdateTime.UTC().Format(http.TimeFormat))
+
+	if etagMatch(r.Header.Get("If-None-Match"), p.ETag()) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set(contentTypeHeaderKey, p.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(p.Size, 10))
+
+	_, err = io.Copy(w, rc)
+	if err != nil {
+		// the status has already been sent, so the error is only logged
+		logger.Error().Err(err).Msg("serving poster failed after response started")
+	}
+}
+
+// etagMatch reports whether an If-None-Match header matches the etag
+func etagMatch(ifNoneMatch, etag string) bool {
+	for _, t := range strings.Split(ifNoneMatch, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == "*" || t == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// handleReviewCreate handles POST requests for the /movies/{extlID}/reviews
+// endpoint and reviews the movie as the authenticated user
+func (s *Server) handleReviewCreate(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Declare request body (rb) as an instance of diygoapi.CreateReviewRequest
+	rb := new(diygoapi.CreateReviewRequest)
+
+	// Decode the JSON request body into rb
+	err = s.decodeJSON(w, r, rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// the movie External ID is from the path
+	rb.MovieExternalID = r.PathValue("extlID")
+
+	var response *diygoapi.ReviewResponse
+	response, err = s.ReviewServicer.Create(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleReviewUpdate handles PUT requests for the
+// /movies/{extlID}/reviews/{reviewExtlID} endpoint and edits the
+// review. Only the author of a review can edit it.
+func (s *Server) handleReviewUpdate(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Declare request body (rb) as an instance of diygoapi.UpdateReviewRequest
+	rb := new(diygoapi.UpdateReviewRequest)
+
+	// Decode the JSON request body into rb
+	err = s.decodeJSON(w, r, rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// External IDs are from path variables, need to set separate
+	// from decoding response body
+	rb.MovieExternalID = r.PathValue("extlID")
+	rb.ExternalID = r.PathValue("reviewExtlID")
+
+	var response *diygoapi.ReviewResponse
+	response, err = s.ReviewServicer.Update(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleReviewFindByMovie handles GET requests for the
+// /movies/{extlID}/reviews endpoint and lists the reviews of the movie
+func (s *Server) handleReviewFindByMovie(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var response []*diygoapi.ReviewResponse
+	response, err = s.ReviewServicer.FindByMovie(r.Context(), r.PathValue("extlID"), adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleWatchlistCreate handles POST requests for the
+// /users/me/watchlists endpoint and creates a watchlist owned by the
+// authenticated user
+func (s *Server) handleWatchlistCreate(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Declare request body (rb) as an instance of diygoapi.CreateWatchlistRequest
+	rb := new(diygoapi.CreateWatchlistRequest)
+
+	// Decode the JSON request body into rb
+	err = s.decodeJSON(w, r, rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var response *diygoapi.WatchlistResponse
+	response, err = s.WatchlistServicer.Create(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleWatchlistFindMine handles GET requests for the
+// /users/me/watchlists endpoint and lists the watchlists of the
+// authenticated user
+func (s *Server) handleWatchlistFindMine(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var response []*diygoapi.WatchlistResponse
+	response, err = s.WatchlistServicer.FindMine(r.Context(), adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleWatchlistUpdate handles PUT requests for the
+// /users/me/watchlists/{extlID} endpoint and renames or (un)shares
+// the watchlist
+func (s *Server) handleWatchlistUpdate(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Declare request body (rb) as an instance of diygoapi.UpdateWatchlistRequest
+	rb := new(diygoapi.UpdateWatchlistRequest)
+
+	// Decode the JSON request body into rb
+	err = s.decodeJSON(w, r, rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// External ID is from path variable, need to set separate
+	// from decoding response body
+	rb.ExternalID = r.PathValue("extlID")
+
+	var response *diygoapi.WatchlistResponse
+	response, err = s.WatchlistServicer.Update(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleWatchlistDelete handles DELETE requests for the
+// /users/me/watchlists/{extlID} endpoint and deletes the watchlist
+func (s *Server) handleWatchlistDelete(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var response diygoapi.DeleteResponse
+	response, err = s.WatchlistServicer.Delete(r.Context(), r.PathValue("extlID"), adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleWatchlistAddMovie handles POST requests for the
+// /users/me/watchlists/{extlID}/movies endpoint and adds a movie to
+// the end of the watchlist
+func (s *Server) handleWatchlistAddMovie(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Declare request body (rb) as an instance of diygoapi.AddWatchlistMovieRequest
+	rb := new(diygoapi.AddWatchlistMovieRequest)
+
+	// Decode the JSON request body into rb
+	err = s.decodeJSON(w, r, rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// the watchlist External ID is from the path
+	rb.ExternalID = r.PathValue("extlID")
+
+	var response *diygoapi.WatchlistResponse
+	response, err = s.WatchlistServicer.AddMovie(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleWatchlistReorder handles PUT requests for the
+// /users/me/watchlists/{extlID}/movies endpoint and puts the movies
+// of the watchlist in the order given
+func (s *Server) handleWatchlistReorder(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Declare request body (rb) as an instance of diygoapi.ReorderWatchlistRequest
+	rb := new(diygoapi.ReorderWatchlistRequest)
+
+	// Decode the JSON request body into rb
+	err = s.decodeJSON(w, r, rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// the watchlist External ID is from the path
+	rb.ExternalID = r.PathValue("extlID")
+
+	var response *diygoapi.WatchlistResponse
+	response, err = s.WatchlistServicer.Reorder(r.Context(), rb, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleWatchlistRemoveMovie handles DELETE requests for the
+// /users/me/watchlists/{extlID}/movies/{movieExtlID} endpoint and
+// removes the movie from the watchlist
+func (s *Server) handleWatchlistRemoveMovie(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var response *diygoapi.WatchlistResponse
+	response, err = s.WatchlistServicer.RemoveMovie(r.Context(), r.PathValue("extlID"), r.PathValue("movieExtlID"), adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleWatchlistFindByExtlID handles GET requests for the
+// /watchlists/{extlID} endpoint and returns a watchlist of the
+// authenticated user or one shared within their org
+func (s *Server) handleWatchlistFindByExtlID(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	var response *diygoapi.WatchlistResponse
+	response, err = s.WatchlistServicer.FindByExternalID(r.Context(), r.PathValue("extlID"), adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleJobFindAll handles GET requests for the /jobs endpoint and
+// lists the most recently created jobs, optionally only those in the
+// state given by the state query parameter
+func (s *Server) handleJobFindAll(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	var (
+		rb  diygoapi.FindJobsRequest
+		err error
+	)
+
+	if st := r.URL.Query().Get("state"); st != "" {
+		rb.State, err = diygoapi.ParseJobState(st)
+		if err != nil {
+			errs.HTTPErrorResponse(w, logger, err)
+			return
+		}
+	}
+
+	if l := r.URL.Query().Get("limit"); l != "" {
+		rb.Limit, err = strconv.Atoi(l)
+		if err != nil {
+			errs.HTTPErrorResponse(w, logger, errs.E(errs.Validation, errs.Parameter("limit"), "limit must be a whole number"))
+			return
+		}
+	}
+
+	var response []*diygoapi.JobResponse
+	response, err = s.JobServicer.FindJobs(r.Context(), &rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleJobFindByExtlID handles GET requests for the /jobs/{extlID}
+// endpoint and returns the job
+func (s *Server) handleJobFindByExtlID(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	response, err := s.JobServicer.FindJobByExternalID(r.Context(), r.PathValue("extlID"))
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleJobRetry handles POST requests for the /jobs/{extlID}/retry
+// endpoint and queues a dead job to run again
+func (s *Server) handleJobRetry(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	response, err := s.JobServicer.Retry(r.Context(), r.PathValue("extlID"))
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleWebhookCreate handles POST requests for the /webhooks endpoint
+// and registers a webhook for the App making the request. The secret
+// used to sign deliveries is only returned in this response.
+func (s *Server) handleWebhookCreate(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Declare request body (rb) as an instance of diygoapi.CreateWebhookRequest
+	rb := new(diygoapi.CreateWebhookRequest)
+
+	// Decode the JSON request body into rb
+	err = s.decodeJSON(w, r, rb)
 	if err != nil {
 		errs.HTTPErrorResponse(w, logger, err)
 		return
 	}
 
-	var response *diygoapi.MovieResponse
-	response, err = s.MovieServicer.Create(r.Context(), rb, adt)
+	var response *diygoapi.WebhookResponse
+	response, err = s.WebhookServicer.Create(r.Context(), rb, adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, logger, err)
 		return
@@ -50,10 +1087,9 @@ func (s *Server) handleMovieCreate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleMovieUpdate handles PUT requests for the /movies/{id} endpoint
-// and updates the given movie
-func (s *Server) handleMovieUpdate(w http.ResponseWriter, r *http.Request) {
-
+// handleWebhookFindAll handles GET requests for the /webhooks endpoint
+// and lists the webhooks of the App making the request
+func (s *Server) handleWebhookFindAll(w http.ResponseWriter, r *http.Request) {
 	logger := *hlog.FromRequest(r)
 
 	adt, err := diygoapi.AuditFromRequest(r)
@@ -62,30 +1098,8 @@ func (s *Server) handleMovieUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// return the extlID from the Path
-	extlID := r.PathValue("extlID")
-
-	// Declare request body (rb) as an instance of service.MovieRequest
-	rb := new(diygoapi.UpdateMovieRequest)
-
-	// Decode JSON HTTP request body into a Decoder type
-	// and unmarshal that into requestData
-	err = json.NewDecoder(r.Body).Decode(&rb)
-	defer r.Body.Close()
-	// Call DecoderErr to determine if body is nil, json is malformed
-	// or any other error
-	err = decoderErr(err)
-	if err != nil {
-		errs.HTTPErrorResponse(w, logger, err)
-		return
-	}
-
-	// External ID is from path variable, need to set separate
-	// from decoding response body
-	rb.ExternalID = extlID
-
-	var response *diygoapi.MovieResponse
-	response, err = s.MovieServicer.Update(r.Context(), rb, adt)
+	var response []*diygoapi.WebhookResponse
+	response, err = s.WebhookServicer.FindAll(r.Context(), adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, logger, err)
 		return
@@ -99,16 +1113,19 @@ func (s *Server) handleMovieUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleMovieDelete handles DELETE requests for the /movies/{id} endpoint
-// and updates the given movie
-func (s *Server) handleMovieDelete(w http.ResponseWriter, r *http.Request) {
-
+// handleWebhookDelete handles DELETE requests for the
+// /webhooks/{extlID} endpoint and deletes the webhook
+func (s *Server) handleWebhookDelete(w http.ResponseWriter, r *http.Request) {
 	logger := *hlog.FromRequest(r)
 
-	// return the extlID from the Path
-	extlID := r.PathValue("extlID")
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
 
-	response, err := s.MovieServicer.Delete(r.Context(), extlID)
+	var response diygoapi.DeleteResponse
+	response, err = s.WebhookServicer.Delete(r.Context(), r.PathValue("extlID"), adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, logger, err)
 		return
@@ -122,16 +1139,20 @@ func (s *Server) handleMovieDelete(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleFindMovieByID handles GET requests for the /movies/{id} endpoint
-// and finds a movie by its ID
-func (s *Server) handleFindMovieByID(w http.ResponseWriter, r *http.Request) {
-
+// handleWebhookDeliveries handles GET requests for the
+// /webhooks/{extlID}/deliveries endpoint and lists the most recent
+// deliveries to the webhook with the history of their attempts
+func (s *Server) handleWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
 	logger := *hlog.FromRequest(r)
 
-	// return the extlID from the Path
-	extlID := r.PathValue("extlID")
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
 
-	response, err := s.MovieServicer.FindMovieByExternalID(r.Context(), extlID)
+	var response []*diygoapi.WebhookDeliveryResponse
+	response, err = s.WebhookServicer.FindDeliveries(r.Context(), r.PathValue("extlID"), adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, logger, err)
 		return
@@ -145,24 +1166,93 @@ func (s *Server) handleFindMovieByID(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleFindAllMovies handles GET requests for the /movies endpoint and finds
-// all movies
-func (s *Server) handleFindAllMovies(w http.ResponseWriter, r *http.Request) {
-
+// handleEventStream handles GET requests for the /events endpoint and
+// streams the domain events of the org as Server-Sent Events. A client
+// resumes after the last event it received by sending its id in the
+// Last-Event-ID header, as EventSource does when reconnecting.
+func (s *Server) handleEventStream(w http.ResponseWriter, r *http.Request) {
 	logger := *hlog.FromRequest(r)
 
-	response, err := s.MovieServicer.FindAllMovies(r.Context())
+	adt, err := diygoapi.AuditFromRequest(r)
 	if err != nil {
 		errs.HTTPErrorResponse(w, logger, err)
 		return
 	}
 
-	// Encode response struct to JSON for the response body
-	err = json.NewEncoder(w).Encode(response)
+	// streams are ended when the server is shut down, as it would
+	// otherwise wait on them
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	if s.streams != nil {
+		stop := context.AfterFunc(s.streams, cancel)
+		defer stop()
+	}
+
+	rb := &diygoapi.StreamEventsRequest{LastEventID: r.Header.Get("Last-Event-ID")}
+
+	sse := &sseWriter{w: w, rc: http.NewResponseController(w)}
+	err = s.EventServicer.Stream(ctx, rb, adt, sse)
 	if err != nil {
-		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		// once the stream has started, the status has been sent and the
+		// error can only be logged
+		if !sse.started {
+			errs.HTTPErrorResponse(w, logger, err)
+			return
+		}
+		logger.Info().Err(err).Msg("event stream ended")
+	}
+}
+
+// sseWriter writes events in the Server-Sent Events format. The
+// response headers are written along with the first event or
+// heartbeat.
+type sseWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	started bool
+}
+
+// Send writes an event with its Sequence as the id
+func (sse *sseWriter) Send(e *diygoapi.EventResponse) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	sse.start()
+
+	_, err = fmt.Fprintf(sse.w, "id: %d\nevent: %s\ndata: %s\n\n", e.Sequence, e.Type, data)
+	if err != nil {
+		return err
+	}
+	return sse.rc.Flush()
+}
+
+// Heartbeat writes a comment, which clients ignore
+func (sse *sseWriter) Heartbeat() error {
+	sse.start()
+
+	_, err := io.WriteString(sse.w, ": heartbeat\n\n")
+	if err != nil {
+		return err
+	}
+	return sse.rc.Flush()
+}
+
+func (sse *sseWriter) start() {
+	if sse.started {
 		return
 	}
+	sse.started = true
+
+	// the server timeouts are meant for requests, not a stream left
+	// open for as long as the client wants it
+	_ = sse.rc.SetReadDeadline(time.Time{})
+	_ = sse.rc.SetWriteDeadline(time.Time{})
+
+	sse.w.Header().Set(contentTypeHeaderKey, "text/event-stream")
+	sse.w.Header().Set("Cache-Control", "no-cache")
+	sse.w.Header().Set("X-Accel-Buffering", "no")
+	sse.w.WriteHeader(http.StatusOK)
 }
 
 // handleOrgCreate is a HandlerFunc used to create an Org
@@ -181,11 +1271,8 @@ func (s *Server) handleOrgCreate(w http.ResponseWriter, r *http.Request) {
 	// Decode JSON HTTP request body into a Decoder type
 	// and unmarshal that into the MovieRequest struct in the
 	// AddMovieHandler
-	err = json.NewDecoder(r.Body).Decode(&rb)
-	defer r.Body.Close()
-	// Call decoderErr to determine if body is nil, json is malformed
-	// or any other error
-	err = decoderErr(err)
+	// Decode the JSON request body into rb
+	err = s.decodeJSON(w, r, rb)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -222,11 +1309,8 @@ func (s *Server) handleOrgUpdate(w http.ResponseWriter, r *http.Request) {
 	// Decode JSON HTTP request body into a Decoder type
 	// and unmarshal that into the MovieRequest struct in the
 	// AddMovieHandler
-	err = json.NewDecoder(r.Body).Decode(&rb)
-	defer r.Body.Close()
-	// Call decoderErr to determine if body is nil, json is malformed
-	// or any other error
-	err = decoderErr(err)
+	// Decode the JSON request body into rb
+	err = s.decodeJSON(w, r, rb)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -254,10 +1338,45 @@ func (s *Server) handleOrgUpdate(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleOrgDelete(w http.ResponseWriter, r *http.Request) {
 	lgr := *hlog.FromRequest(r)
 
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// return the extlID from the Path
+	extlID := r.PathValue("extlID")
+
+	var response diygoapi.DeleteResponse
+	response, err = s.OrgServicer.Delete(r.Context(), extlID, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleOrgRestore is a HandlerFunc used to restore a deleted Org
+func (s *Server) handleOrgRestore(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
 	// return the extlID from the Path
 	extlID := r.PathValue("extlID")
 
-	response, err := s.OrgServicer.Delete(r.Context(), extlID)
+	var response *diygoapi.OrgResponse
+	response, err = s.OrgServicer.Restore(r.Context(), extlID, adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -326,11 +1445,8 @@ func (s *Server) handleAppCreate(w http.ResponseWriter, r *http.Request) {
 	// Decode JSON HTTP request body into a Decoder type
 	// and unmarshal that into the MovieRequest struct in the
 	// AddMovieHandler
-	err = json.NewDecoder(r.Body).Decode(&rb)
-	defer r.Body.Close()
-	// Call decoderErr to determine if body is nil, json is malformed
-	// or any other error
-	err = decoderErr(err)
+	// Decode the JSON request body into rb
+	err = s.decodeJSON(w, r, rb)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -351,6 +1467,62 @@ func (s *Server) handleAppCreate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleAppDelete is a HandlerFunc used to delete an App
+func (s *Server) handleAppDelete(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// return the extlID from the Path
+	extlID := r.PathValue("extlID")
+
+	var response diygoapi.DeleteResponse
+	response, err = s.AppServicer.Delete(r.Context(), extlID, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleAppRestore is a HandlerFunc used to restore a deleted App
+func (s *Server) handleAppRestore(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// return the extlID from the Path
+	extlID := r.PathValue("extlID")
+
+	var response *diygoapi.AppResponse
+	response, err = s.AppServicer.Restore(r.Context(), extlID, adt)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
 // handleNewUser is a HandlerFunc used to register a User
 func (s *Server) handleNewUser(w http.ResponseWriter, r *http.Request) {
 	lgr := *hlog.FromRequest(r)
@@ -398,20 +1570,22 @@ func (s *Server) handleLoggerUpdate(w http.ResponseWriter, r *http.Request) {
 	// Declare rb as an instance of service.LoggerRequest
 	rb := new(diygoapi.LoggerRequest)
 
-	// Decode JSON HTTP request body into a json.Decoder type
-	// and unmarshal that into rb
-	err := json.NewDecoder(r.Body).Decode(&rb)
-	defer r.Body.Close()
-	// Call DecoderErr to determine if body is nil, json is malformed
-	// or any other error
-	err = decoderErr(err)
+	// Decode the JSON request body into rb
+	err := s.decodeJSON(w, r, rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
+	var adt diygoapi.Audit
+	adt, err = diygoapi.AuditFromRequest(r)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
 	}
 
 	var response *diygoapi.LoggerResponse
-	response, err = s.LoggerService.Update(rb)
+	response, err = s.LoggerService.Update(r.Context(), rb, adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -425,6 +1599,82 @@ func (s *Server) handleLoggerUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleSecurityEventFindAll handles GET requests for the
+// /security-events endpoint and lists security events, newest first,
+// filtered by the query parameters given
+func (s *Server) handleSecurityEventFindAll(w http.ResponseWriter, r *http.Request) {
+	logger := *hlog.FromRequest(r)
+
+	var (
+		rb  diygoapi.FindSecurityEventsRequest
+		err error
+	)
+
+	q := r.URL.Query()
+	if a := q.Get("action"); a != "" {
+		rb.Action, err = diygoapi.ParseSecurityAction(a)
+		if err != nil {
+			errs.HTTPErrorResponse(w, logger, err)
+			return
+		}
+	}
+	if o := q.Get("outcome"); o != "" {
+		rb.Outcome, err = diygoapi.ParseSecurityOutcome(o)
+		if err != nil {
+			errs.HTTPErrorResponse(w, logger, err)
+			return
+		}
+	}
+	rb.ActorExtlID = q.Get("actor")
+	rb.AppExtlID = q.Get("app")
+	rb.OrgExtlID = q.Get("org")
+
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"since", &rb.Since}, {"until", &rb.Until}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		*p.t, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			errs.HTTPErrorResponse(w, logger, errs.E(errs.Validation, errs.Parameter(p.name), p.name+" must be an RFC 3339 date-time"))
+			return
+		}
+	}
+
+	if b := q.Get("before"); b != "" {
+		rb.Before, err = strconv.ParseInt(b, 10, 64)
+		if err != nil || rb.Before < 1 {
+			errs.HTTPErrorResponse(w, logger, errs.E(errs.Validation, errs.Parameter("before"), "before must be the sequence of a security event"))
+			return
+		}
+	}
+
+	if l := q.Get("limit"); l != "" {
+		rb.Limit, err = strconv.Atoi(l)
+		if err != nil {
+			errs.HTTPErrorResponse(w, logger, errs.E(errs.Validation, errs.Parameter("limit"), "limit must be a whole number"))
+			return
+		}
+	}
+
+	var response []*diygoapi.SecurityEventResponse
+	response, err = s.SecurityEventServicer.FindSecurityEvents(r.Context(), &rb)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	// Encode response struct to JSON for the response body
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, errs.E(errs.Internal, err))
+		return
+	}
+}
+
 // Ping handles GET requests for the /ping endpoint
 func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
 	// pull logger from request context
@@ -443,6 +1693,53 @@ func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleLiveness handles GET requests for the /healthz endpoint. The
+// server is live as long as it can respond; dependencies are not
+// checked, so an orchestrator does not restart the server when, for
+// instance, the database is down.
+func (s *Server) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	response := diygoapi.HealthResponse{Status: healthOK}
+
+	// Encode response struct to JSON for the response body
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
+// handleReadiness handles GET requests for the /readyz endpoint. The
+// server is ready when every HealthChecker passes, and is not ready
+// once it has begun shutting down.
+func (s *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	lgr := *hlog.FromRequest(r)
+
+	response := diygoapi.HealthResponse{Status: healthOK}
+	if s.draining.Load() {
+		response.Status = healthShuttingDown
+	} else {
+		response.Checks = s.checkHealth(r.Context(), lgr)
+		for _, result := range response.Checks {
+			if result != healthOK {
+				response.Status = healthUnavailable
+			}
+		}
+	}
+
+	if response.Status != healthOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	// Encode response struct to JSON for the response body
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+		return
+	}
+}
+
 // handleGenesis handles POST requests for the /genesis endpoint
 func (s *Server) handleGenesis(w http.ResponseWriter, r *http.Request) {
 	lgr := *hlog.FromRequest(r)
@@ -450,13 +1747,8 @@ func (s *Server) handleGenesis(w http.ResponseWriter, r *http.Request) {
 	// Declare rb as an instance of service.LoggerRequest
 	rb := new(diygoapi.GenesisRequest)
 
-	// Decode JSON HTTP request body into a json.Decoder type
-	// and unmarshal that into rb
-	err := json.NewDecoder(r.Body).Decode(&rb)
-	defer r.Body.Close()
-	// Call DecoderErr to determine if body is nil, json is malformed
-	// or any other error
-	err = decoderErr(err)
+	// Decode the JSON request body into rb
+	err := s.decodeJSON(w, r, rb)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -516,13 +1808,8 @@ func (s *Server) handlePermissionCreate(w http.ResponseWriter, r *http.Request)
 	// Declare rb as an instance of service.PermissionRequest
 	rb := new(diygoapi.CreatePermissionRequest)
 
-	// Decode JSON HTTP request body into a json.Decoder type
-	// and unmarshal that into rb
-	err = json.NewDecoder(r.Body).Decode(&rb)
-	defer r.Body.Close()
-	// Call DecoderErr to determine if body is nil, json is malformed
-	// or any other error
-	err = decoderErr(err)
+	// Decode the JSON request body into rb
+	err = s.decodeJSON(w, r, rb)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -565,10 +1852,17 @@ func (s *Server) handlePermissionFindAll(w http.ResponseWriter, r *http.Request)
 func (s *Server) handlePermissionDelete(w http.ResponseWriter, r *http.Request) {
 	lgr := *hlog.FromRequest(r)
 
+	adt, err := diygoapi.AuditFromRequest(r)
+	if err != nil {
+		errs.HTTPErrorResponse(w, lgr, err)
+		return
+	}
+
 	// return the extlID from the Path
 	extlID := r.PathValue("extlID")
 
-	response, err := s.PermissionServicer.Delete(r.Context(), extlID)
+	var response diygoapi.DeleteResponse
+	response, err = s.PermissionServicer.Delete(r.Context(), extlID, adt)
 	if err != nil {
 		errs.HTTPErrorResponse(w, lgr, err)
 		return
@@ -581,3 +1875,90 @@ func (s *Server) handlePermissionDelete(w http.ResponseWriter, r *http.Request)
 		return
 	}
 }
+
+// handleChangeHistoryFindByExtlID returns a HandlerFunc which finds the
+// change history for an entity of the given type using the extlID path value
+func (s *Server) handleChangeHistoryFindByExtlID(et diygoapi.EntityType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lgr := *hlog.FromRequest(r)
+
+		adt, err := diygoapi.AuditFromRequest(r)
+		if err != nil {
+			errs.HTTPErrorResponse(w, lgr, err)
+			return
+		}
+
+		// return the extlID from the Path
+		extlID := r.PathValue("extlID")
+
+		var response []*diygoapi.ChangeHistoryResponse
+		response, err = s.ChangeHistoryServicer.FindByExternalID(r.Context(), et, extlID, adt)
+		if err != nil {
+			errs.HTTPErrorResponse(w, lgr, err)
+			return
+		}
+
+		// Encode response struct to JSON for the response body
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			errs.HTTPErrorResponse(w, lgr, errs.E(errs.Internal, err))
+			return
+		}
+	}
+}
+
+// handleOrgExport handles GET requests for the /orgs:export endpoint
+// and streams all orgs as CSV or NDJSON
+func (s *Server) handleOrgExport(w http.ResponseWriter, r *http.Request) {
+	s.handleExport(w, r, s.OrgServicer.Export)
+}
+
+// exportWriteTimeout is how far the write deadline is pushed out
+// before each write of a streaming export
+const exportWriteTimeout = 30 * time.Second
+
+// handleExport streams an export in the format given by the format
+// query parameter. The server WriteTimeout would otherwise end a long
+// export, so the write deadline is extended as the export progresses.
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request, export func(context.Context, diygoapi.ExportFormat, io.Writer) error) {
+	logger := *hlog.FromRequest(r)
+
+	f, err := diygoapi.ParseExportFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+
+	w.Header().Set(contentTypeHeaderKey, f.ContentType())
+
+	dw := &deadlineExtendingWriter{w: w, rc: http.NewResponseController(w)}
+	err = export(r.Context(), f, dw)
+	if err != nil {
+		// once the body has started the status can no longer
+		// be changed, so the error is only logged
+		if dw.written {
+			logger.Error().Err(err).Msg("export failed after response started")
+			return
+		}
+		errs.HTTPErrorResponse(w, logger, err)
+		return
+	}
+}
+
+// deadlineExtendingWriter extends the response write deadline ahead of
+// every write, so the deadline applies to each write rather than to the
+// response as a whole
+type deadlineExtendingWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	written bool
+}
+
+func (dw *deadlineExtendingWriter) Write(p []byte) (int, error) {
+	err := dw.rc.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return 0, err
+	}
+	dw.written = true
+	return dw.w.Write(p)
+}
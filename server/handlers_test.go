@@ -1,5 +1,21 @@
 package server
 
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+)
+
 // TODO - these tests all need to be refactored after sqlc changes
 
 //// MockTransactor is a mock which satisfies the moviestore.Transactor
@@ -1091,3 +1107,114 @@ package server
 //func (m mockPinger) PingDB(ctx context.Context) error {
 //	return nil
 //}
+
+func TestSSEWriter(t *testing.T) {
+	c := qt.New(t)
+
+	w := httptest.NewRecorder()
+	sse := &sseWriter{w: w, rc: http.NewResponseController(w)}
+
+	c.Assert(sse.Heartbeat(), qt.IsNil)
+	c.Assert(sse.Send(&diygoapi.EventResponse{
+		Sequence:      42,
+		ExternalID:    "tJOIjQnABnLZkwJm",
+		Type:          diygoapi.MovieCreatedEvent,
+		SubjectExtlID: "gL6SGafJhvxyTBSU",
+		CreateTime:    "2024-01-02T03:04:05Z",
+		Data:          json.RawMessage(`{"title":"Repo Man"}`),
+	}), qt.IsNil)
+
+	c.Assert(w.Code, qt.Equals, http.StatusOK)
+	c.Assert(w.Header().Get("Content-Type"), qt.Equals, "text/event-stream")
+	c.Assert(w.Flushed, qt.IsTrue)
+	c.Assert(w.Body.String(), qt.Equals, ": heartbeat\n\n"+
+		"id: 42\nevent: movie.created\n"+
+		`data: {"id":"tJOIjQnABnLZkwJm","type":"movie.created","subject_extl_id":"gL6SGafJhvxyTBSU","create_date_time":"2024-01-02T03:04:05Z","data":{"title":"Repo Man"}}`+
+		"\n\n")
+}
+
+// mockMovieImportService is a MovieServicer which rejects every import
+type mockMovieImportService struct {
+	diygoapi.MovieServicer
+}
+
+func (m mockMovieImportService) Import(ctx context.Context, r *diygoapi.ImportMoviesRequest, adt diygoapi.Audit) (*diygoapi.ImportMoviesResponse, error) {
+	response := &diygoapi.ImportMoviesResponse{
+		Mode:    r.Mode,
+		Rows:    1,
+		Failed:  1,
+		Results: []diygoapi.ImportMovieResult{{Line: 2, Error: "director is required"}},
+	}
+	return response, errs.E(errs.Validation, errs.Code("import_rejected"), "1 of 1 rows are invalid, no movies were imported")
+}
+
+func TestServer_handleMovieImport_rejected(t *testing.T) {
+	c := qt.New(t)
+
+	s := New(http.NewServeMux(), nopDriver{}, zerolog.Nop())
+	s.MovieServicer = mockMovieImportService{}
+
+	ctx := diygoapi.NewContextWithApp(context.Background(), &diygoapi.App{ExternalID: []byte("app"), Org: &diygoapi.Org{}})
+	ctx = diygoapi.NewContextWithUser(ctx, &diygoapi.User{ExternalID: []byte("user")})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/movies:import",
+		strings.NewReader("title,writer\nSid and Nancy,Alex Cox\n")).WithContext(ctx)
+	req.Header.Set(contentTypeHeaderKey, "text/csv")
+	w := httptest.NewRecorder()
+	s.handleMovieImport(w, req)
+
+	c.Assert(w.Code, qt.Equals, http.StatusBadRequest)
+	c.Assert(w.Header().Get(contentTypeHeaderKey), qt.Equals, "application/json")
+
+	var got diygoapi.ImportMoviesResponse
+	c.Assert(json.NewDecoder(w.Body).Decode(&got), qt.IsNil)
+	c.Assert(got.Mode, qt.Equals, diygoapi.AllOrNothingImportMode)
+	c.Assert(got.Failed, qt.Equals, 1)
+	c.Assert(got.Results, qt.DeepEquals, []diygoapi.ImportMovieResult{{Line: 2, Error: "director is required"}})
+}
+
+// mockPosterService is a PosterServicer which finds the same poster
+// for every movie
+type mockPosterService struct {
+	diygoapi.PosterServicer
+}
+
+func (m mockPosterService) Find(ctx context.Context, movieExtlID string, adt diygoapi.Audit) (*diygoapi.Poster, io.ReadCloser, error) {
+	p := &diygoapi.Poster{
+		ContentType: "image/png",
+		SHA256:      "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+	}
+	return p, io.NopCloser(strings.NewReader("png")), nil
+}
+
+func TestServer_handleMoviePosterFind_cacheControl(t *testing.T) {
+	s := New(http.NewServeMux(), nopDriver{}, zerolog.Nop())
+	s.PosterServicer = mockPosterService{}
+
+	cacheControl := func(c *qt.C, target string) string {
+		c.Helper()
+		ctx := diygoapi.NewContextWithApp(context.Background(), &diygoapi.App{ExternalID: []byte("app"), Org: &diygoapi.Org{}})
+		ctx = diygoapi.NewContextWithUser(ctx, &diygoapi.User{ExternalID: []byte("user")})
+		w := httptest.NewRecorder()
+		s.handleMoviePosterFind(w, httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx))
+		c.Assert(w.Code, qt.Equals, http.StatusOK)
+		return w.Header().Get("Cache-Control")
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{"poster_url version", "/api/v1/movies/abc/poster?v=9f86d081884c", "private, max-age=31536000, immutable"},
+		{"no version", "/api/v1/movies/abc/poster", "private, no-cache"},
+		{"shorter prefix", "/api/v1/movies/abc/poster?v=9f", "private, no-cache"},
+		{"full hash", "/api/v1/movies/abc/poster?v=9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", "private, no-cache"},
+		{"other version", "/api/v1/movies/abc/poster?v=000000000000", "private, no-cache"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(cacheControl(c, tt.target), qt.Equals, tt.want)
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+const metricsNamespace = "diygoapi"
+
+// Metrics are the Prometheus metrics recorded by the Server. HTTP
+// metrics are labeled by the pattern of the handler the request was
+// routed to, e.g. "GET /api/v1/movies/{extlID}", so the number of
+// series is bounded by the number of routes.
+type Metrics struct {
+	requests        *prometheus.CounterVec
+	duration        *prometheus.HistogramVec
+	inFlight        *prometheus.GaugeVec
+	authentications *prometheus.CounterVec
+	authorizations  *prometheus.CounterVec
+	errors          *prometheus.CounterVec
+
+	handler http.Handler
+}
+
+// NewMetrics initializes Metrics, registering them with reg. The
+// /metrics endpoint serves everything registered with reg.
+func NewMetrics(reg *prometheus.Registry) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests completed, by handler pattern and status code.",
+		}, []string{"pattern", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to respond to HTTP requests, by handler pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"pattern"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests being served, by handler pattern.",
+		}, []string{"pattern"}),
+		authentications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "authentications_total",
+			Help:      "Number of user authentications, by provider and outcome (success or failure).",
+		}, []string{"provider", "outcome"}),
+		authorizations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "authorizations_total",
+			Help:      "Number of user authorization decisions, by decision (allow, deny or error).",
+		}, []string{"decision"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "errors_total",
+			Help:      "Number of error responses sent, by error kind.",
+		}, []string{"kind"}),
+		handler: promhttp.HandlerFor(reg, promhttp.HandlerOpts{}),
+	}
+
+	reg.MustRegister(m.requests, m.duration, m.inFlight, m.authentications, m.authorizations, m.errors)
+
+	return m
+}
+
+// recordAuthentication counts the outcome of authenticating a user
+// with the provider
+func (m *Metrics) recordAuthentication(provider diygoapi.Provider, err error) {
+	if m == nil {
+		return
+	}
+
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	m.authentications.WithLabelValues(provider.String(), outcome).Inc()
+}
+
+// recordAuthorization counts the decision made authorizing a user
+func (m *Metrics) recordAuthorization(err error) {
+	if m == nil {
+		return
+	}
+
+	decision := "allow"
+	switch {
+	case errs.KindIs(errs.Unauthorized, err):
+		decision = "deny"
+	case err != nil:
+		decision = "error"
+	}
+	m.authorizations.WithLabelValues(decision).Inc()
+}
+
+// metricsHandler middleware records the count, duration and in-flight
+// requests of the handler pattern added to the request context by
+// addRequestHandlerPatternContextHandler, and the kind of any error
+// response.
+func (s *Server) metricsHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m := s.Metrics
+		if m == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		pattern, err := diygoapi.RequestHandlerPatternFromContext(r.Context())
+		if err != nil {
+			pattern = "unknown"
+		}
+
+		inFlight := m.inFlight.WithLabelValues(pattern)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		mw := &metricsResponseWriter{ResponseWriter: w, metrics: m}
+		start := time.Now()
+
+		h.ServeHTTP(mw, r)
+
+		m.duration.WithLabelValues(pattern).Observe(time.Since(start).Seconds())
+		m.requests.WithLabelValues(pattern, strconv.Itoa(mw.statusCode())).Inc()
+	})
+}
+
+// metricsResponseWriter records the status code of the response and
+// counts error responses by kind. Unwrap allows an
+// http.ResponseController to reach the underlying writer, e.g. to
+// flush event streams.
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	metrics *Metrics
+	status  int
+}
+
+func (w *metricsResponseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *metricsResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// RecordErrorKind implements errs.ErrorKindRecorder
+func (w *metricsResponseWriter) RecordErrorKind(k errs.Kind) {
+	w.metrics.errors.WithLabelValues(k.String()).Inc()
+}
+
+func (w *metricsResponseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// handleMetrics handles GET requests for the /metrics endpoint when
+// metrics are served with the API rather than on their own listener
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if s.Metrics == nil || s.MetricsAddr != "" {
+		http.NotFound(w, r)
+		return
+	}
+	s.Metrics.handler.ServeHTTP(w, r)
+}
+
+// listenAndServeMetrics serves /metrics on its own listener at
+// MetricsAddr until the Server is shut down
+func (s *Server) listenAndServeMetrics() {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", s.Metrics.handler)
+	srv := &http.Server{
+		Addr:              s.MetricsAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	s.metricsServer.Store(srv)
+
+	s.Logger.Info().Msgf("metrics listening on %s", s.MetricsAddr)
+	go func() {
+		err := srv.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.Logger.Error().Err(err).Msg("metrics server failed")
+		}
+	}()
+}
+
+// shutdownMetrics stops the metrics listener, if any
+func (s *Server) shutdownMetrics(ctx context.Context) {
+	srv := s.metricsServer.Load()
+	if srv == nil {
+		return
+	}
+	err := srv.Shutdown(ctx)
+	if err != nil {
+		s.Logger.Error().Err(err).Msg("metrics server did not shut down cleanly")
+	}
+}
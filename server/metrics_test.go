@@ -0,0 +1,89 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/justinas/alice"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+func TestServer_metricsHandler(t *testing.T) {
+	c := qt.New(t)
+
+	s := New(http.NewServeMux(), nopDriver{}, zerolog.Nop())
+	s.Metrics = NewMetrics(prometheus.NewRegistry())
+
+	const pattern = "GET /test/{extlID}"
+	s.mux.Handle(pattern,
+		alice.New(hlog.NewHandler(s.Logger)).
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.metricsHandler).
+			ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.PathValue("extlID") == "bad" {
+					errs.HTTPErrorResponse(w, *hlog.FromRequest(r), errs.E(errs.Validation, "bad extlID"))
+					return
+				}
+				_, _ = io.WriteString(w, "ok")
+			}))
+
+	for _, path := range []string{"/test/a", "/test/b", "/test/bad"} {
+		s.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	m := s.Metrics
+	c.Assert(testutil.ToFloat64(m.requests.WithLabelValues(pattern, "200")), qt.Equals, 2.0)
+	c.Assert(testutil.ToFloat64(m.requests.WithLabelValues(pattern, "400")), qt.Equals, 1.0)
+	c.Assert(testutil.ToFloat64(m.inFlight.WithLabelValues(pattern)), qt.Equals, 0.0)
+	c.Assert(testutil.ToFloat64(m.errors.WithLabelValues(errs.Validation.String())), qt.Equals, 1.0)
+	c.Assert(testutil.CollectAndCount(m.duration), qt.Equals, 1)
+
+	m.recordAuthentication(diygoapi.Google, nil)
+	m.recordAuthentication(diygoapi.UnknownProvider, errs.E(errs.Unauthenticated, "no provider"))
+	c.Assert(testutil.ToFloat64(m.authentications.WithLabelValues("google", "success")), qt.Equals, 1.0)
+	c.Assert(testutil.ToFloat64(m.authentications.WithLabelValues("unknown_provider", "failure")), qt.Equals, 1.0)
+
+	m.recordAuthorization(nil)
+	m.recordAuthorization(errs.E(errs.Unauthorized, "not allowed"))
+	c.Assert(testutil.ToFloat64(m.authorizations.WithLabelValues("allow")), qt.Equals, 1.0)
+	c.Assert(testutil.ToFloat64(m.authorizations.WithLabelValues("deny")), qt.Equals, 1.0)
+
+	// metrics are served with the API as there is no MetricsAddr
+	w := httptest.NewRecorder()
+	s.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	c.Assert(w.Code, qt.Equals, http.StatusOK)
+	c.Assert(strings.Contains(w.Body.String(), `diygoapi_http_requests_total{code="400",pattern="GET /test/{extlID}"} 1`), qt.IsTrue)
+
+	// and not once they have their own listener
+	s.MetricsAddr = ":9090"
+	w = httptest.NewRecorder()
+	s.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	c.Assert(w.Code, qt.Equals, http.StatusNotFound)
+}
+
+func TestServer_metricsHandlerNil(t *testing.T) {
+	c := qt.New(t)
+
+	// a Server without Metrics serves requests as normal
+	s := New(http.NewServeMux(), nopDriver{}, zerolog.Nop())
+	s.mux.Handle("GET /test", alice.New(s.metricsHandler).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	w := httptest.NewRecorder()
+	s.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+	c.Assert(w.Body.String(), qt.Equals, "ok")
+
+	w = httptest.NewRecorder()
+	s.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	c.Assert(w.Code, qt.Equals, http.StatusNotFound)
+}
@@ -7,10 +7,14 @@ import (
 	"time"
 
 	"github.com/justinas/alice"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/hlog"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/gilcrest/diygoapi"
 	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/logger"
+	"github.com/gilcrest/diygoapi/tracing"
 )
 
 const (
@@ -21,10 +25,17 @@ const (
 	contentTypeHeaderKey string = "Content-Type"
 	// application/json header value for Content-Type header key
 	appJSONContentTypeHeaderVal string = "application/json"
+	// Debug log level header key, a privileged user can send it to
+	// have their request logged at the given level
+	debugLogLevelHeaderKey string = "X-Debug-Log-Level"
+	// debugLogLevelPattern is the handler pattern a user must be
+	// authorized for to have the debug log level header honored
+	debugLogLevelPattern string = "PUT /api/v1/logger"
 )
 
 // addRequestHandlerPatternContextHandler middleware adds the registered pattern that
-// matches the request to the request context.
+// matches the request to the request context. The pattern is also the
+// component of the request logger, which writes at the level set for it.
 func (s *Server) addRequestHandlerPatternContextHandler(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -33,6 +44,10 @@ func (s *Server) addRequestHandlerPatternContextHandler(h http.Handler) http.Han
 		// get a new context with app added
 		ctx := diygoapi.NewContextWithRequestHandlerPattern(r.Context(), pattern)
 
+		ctx = logger.NewContextWithComponent(ctx, pattern)
+		lgr := hlog.FromRequest(r).With().Ctx(ctx).Logger()
+		ctx = lgr.WithContext(ctx)
+
 		h.ServeHTTP(w, r.WithContext(ctx)) // call original
 	})
 }
@@ -94,7 +109,14 @@ func (s *Server) appHandler(h http.Handler) http.Handler {
 		// retrieve the context from the http.Request
 		ctx := r.Context()
 
-		a, err := s.AuthenticationServicer.FindAppByAPIKey(r, defaultRealm)
+		tr, span := startMiddlewareSpan(r, "server/appHandler")
+		a, err := s.AuthenticationServicer.FindAppByAPIKey(tr, defaultRealm)
+		if errs.KindIs(errs.NotExist, err) {
+			// app authentication is optional, so not an error of the span
+			span.End()
+		} else {
+			tracing.EndSpan(span, &err)
+		}
 		if err != nil {
 			var e *errs.Error
 			if errors.As(err, &e) {
@@ -149,19 +171,27 @@ func (s *Server) authHandler(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		lgr := *hlog.FromRequest(r)
 
-		auth, err := s.AuthenticationServicer.FindExistingAuth(r, defaultRealm)
+		tr, span := startMiddlewareSpan(r, "server/authHandler")
+		auth, err := s.AuthenticationServicer.FindExistingAuth(tr, defaultRealm)
 		if err != nil {
+			tracing.EndSpan(span, &err)
+			s.Metrics.recordAuthentication(diygoapi.ParseProvider(r.Header.Get(diygoapi.AuthProviderHeaderKey)), err)
 			errs.HTTPErrorResponse(w, lgr, err)
 			return
 		}
+		s.Metrics.recordAuthentication(auth.Provider, nil)
 
-		ctx := diygoapi.NewContextWithUser(r.Context(), auth.User)
+		ctx := diygoapi.NewContextWithUser(tr.Context(), auth.User)
 
 		ctx, err = s.AuthenticationServicer.DetermineAppContext(ctx, auth, defaultRealm)
+		tracing.EndSpan(span, &err)
 		if err != nil {
 			errs.HTTPErrorResponse(w, lgr, err)
 			return
 		}
+		// downstream handlers are children of the request span, not
+		// the authHandler span
+		ctx = trace.ContextWithSpan(ctx, trace.SpanFromContext(r.Context()))
 
 		// call original, with new context
 		h.ServeHTTP(w, r.WithContext(ctx))
@@ -182,16 +212,61 @@ func (s *Server) authorizeUserHandler(h http.Handler) http.Handler {
 		}
 
 		// authorize user can access the path/method
-		err = s.AuthorizationServicer.Authorize(r, lgr, adt)
+		tr, span := startMiddlewareSpan(r, "server/authorizeUserHandler")
+		err = s.AuthorizationServicer.Authorize(tr, lgr, adt)
+		tracing.EndSpan(span, &err)
+		s.Metrics.recordAuthorization(err)
 		if err != nil {
 			errs.HTTPErrorResponse(w, lgr, err)
 			return
 		}
 
+		if lvl := r.Header.Get(debugLogLevelHeaderKey); lvl != "" {
+			r, err = s.debugLogLevel(r, lgr, adt, lvl)
+			if err != nil {
+				errs.HTTPErrorResponse(w, lgr, err)
+				return
+			}
+		}
+
 		h.ServeHTTP(w, r) // call original
 	})
 }
 
+// debugLogLevel returns the request with its logger writing at lvl,
+// sent in the debug log level header, if the user is authorized to
+// update the logger. Otherwise, the header is ignored and the request
+// returned as is.
+func (s *Server) debugLogLevel(r *http.Request, lgr zerolog.Logger, adt diygoapi.Audit, lvl string) (*http.Request, error) {
+	const op errs.Op = "server/debugLogLevel"
+
+	// authorize the user for the logger update route, without
+	// changing the request passed on. The check is a probe, so a user
+	// without access is not recorded as denied.
+	pctx := diygoapi.NewContextWithAuthorizationProbe(r.Context())
+	pr := r.WithContext(diygoapi.NewContextWithRequestHandlerPattern(pctx, debugLogLevelPattern))
+	pr.Method = http.MethodPut
+	err := s.AuthorizationServicer.Authorize(pr, lgr, adt)
+	if errs.KindIs(errs.Unauthorized, err) {
+		lgr.Info().Str("user_extl_id", adt.User.ExternalID.String()).Msg(debugLogLevelHeaderKey + " header ignored, user is not authorized for it")
+		return r, nil
+	}
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	level, err := zerolog.ParseLevel(lvl)
+	if err != nil || level == zerolog.NoLevel {
+		return nil, errs.E(op, errs.InvalidRequest, errs.Parameter(debugLogLevelHeaderKey), "invalid log level: "+lvl)
+	}
+
+	ctx := logger.NewContextWithRequestLevel(r.Context(), level)
+	rlgr := lgr.With().Ctx(ctx).Logger()
+	rlgr.Info().Str("log_level", level.String()).Msg("request logged at debug log level")
+
+	return r.WithContext(rlgr.WithContext(ctx)), nil
+}
+
 // genesisAuthHandler middleware is used to parse the request authentication
 // provider and authorization Bearer token HTTP headers (X-AUTH-PROVIDER +
 // Authorization respectively) and determine authentication. Authentication
@@ -242,3 +317,10 @@ func (s *Server) loggerChain() alice.Chain {
 
 	return ac
 }
+
+// probeChain returns a middleware chain for the health endpoints.
+// Orchestrators probe them every few seconds, so requests are not
+// access logged, though the logger is still added to the context.
+func (s *Server) probeChain() alice.Chain {
+	return alice.New(hlog.NewHandler(s.Logger))
+}
@@ -1,16 +1,20 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
 	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
 
 	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
 	"github.com/gilcrest/diygoapi/logger"
 	"github.com/gilcrest/diygoapi/uuid"
 )
@@ -127,3 +131,93 @@ func TestServer_appHandler(t *testing.T) {
 		c.Assert(rr.Code, qt.Equals, http.StatusOK)
 	})
 }
+
+// mockAuthorizationService authorizes every user for the request
+// pattern, except for the logger update route which only admin is
+// authorized for
+type mockAuthorizationService struct{}
+
+func (s mockAuthorizationService) Authorize(r *http.Request, lgr zerolog.Logger, adt diygoapi.Audit) error {
+	pattern, err := diygoapi.HandlerPatternFromRequest(r)
+	if err != nil {
+		return err
+	}
+	if pattern == debugLogLevelPattern {
+		// the debug log level check must not be recorded as a denial
+		if !diygoapi.AuthorizationProbeFromContext(r.Context()) {
+			return errs.E(errs.Internal, "debug log level authorization is not a probe")
+		}
+		if string(adt.User.ExternalID) != "admin" {
+			return errs.E(errs.Unauthorized, "not authorized")
+		}
+		return nil
+	}
+	if diygoapi.AuthorizationProbeFromContext(r.Context()) {
+		return errs.E(errs.Internal, "request authorization is a probe")
+	}
+	return nil
+}
+
+func TestServer_authorizeUserHandler_debugLogLevel(t *testing.T) {
+	var b bytes.Buffer
+	lvls := logger.NewLevels(zerolog.InfoLevel)
+	lgr := zerolog.New(&b).Level(zerolog.TraceLevel).Hook(lvls)
+
+	s := New(http.NewServeMux(), nopDriver{}, lgr)
+	s.AuthorizationServicer = mockAuthorizationService{}
+	s.mux.Handle("GET /test", hlog.NewHandler(s.Logger)(
+		s.addRequestHandlerPatternContextHandler(
+			s.authorizeUserHandler(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					hlog.FromRequest(r).Debug().Msg("handled")
+				})))))
+
+	app := &diygoapi.App{ExternalID: []byte("app"), Org: &diygoapi.Org{}}
+	// serve returns the response status and if the handler debug log
+	// was written
+	serve := func(c *qt.C, user, lvl string) (int, bool) {
+		c.Helper()
+		b.Reset()
+		ctx := diygoapi.NewContextWithApp(context.Background(), app)
+		ctx = diygoapi.NewContextWithUser(ctx, &diygoapi.User{ExternalID: []byte(user)})
+		req := httptest.NewRequest(http.MethodGet, "/test", nil).WithContext(ctx)
+		if lvl != "" {
+			req.Header.Set(debugLogLevelHeaderKey, lvl)
+		}
+		w := httptest.NewRecorder()
+		s.mux.ServeHTTP(w, req)
+		return w.Code, strings.Contains(b.String(), `"message":"handled"`)
+	}
+
+	t.Run("privileged user", func(t *testing.T) {
+		c := qt.New(t)
+
+		code, logged := serve(c, "admin", "debug")
+		c.Assert(code, qt.Equals, http.StatusOK)
+		c.Assert(logged, qt.IsTrue)
+
+		code, logged = serve(c, "admin", "")
+		c.Assert(code, qt.Equals, http.StatusOK)
+		c.Assert(logged, qt.IsFalse)
+
+		code, _ = serve(c, "admin", "loud")
+		c.Assert(code, qt.Equals, http.StatusBadRequest)
+	})
+	t.Run("unprivileged user", func(t *testing.T) {
+		c := qt.New(t)
+
+		code, logged := serve(c, "alice", "debug")
+		c.Assert(code, qt.Equals, http.StatusOK)
+		c.Assert(logged, qt.IsFalse)
+	})
+	t.Run("handler pattern level", func(t *testing.T) {
+		c := qt.New(t)
+
+		lvls.SetComponent("GET /test", zerolog.DebugLevel, 0)
+		defer lvls.RemoveComponent("GET /test")
+
+		code, logged := serve(c, "alice", "")
+		c.Assert(code, qt.Equals, http.StatusOK)
+		c.Assert(logged, qt.IsTrue)
+	})
+}
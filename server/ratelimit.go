@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+// rateLimitKey is the request context key for the rateLimitState of
+// a request
+type rateLimitKey struct{}
+
+// rateLimitState is what appRateLimitHandler leaves in the request
+// context for userRateLimitHandler: the limits of the App and the
+// result of counting the App's limit, if it was counted
+type rateLimitState struct {
+	limits  diygoapi.AppRateLimits
+	result  diygoapi.RateLimitResult
+	counted bool
+}
+
+// appRateLimitHandler middleware counts the request against the rate
+// limit of the App in the request context. It runs before the user is
+// authenticated, so a caller over the App's limit is refused before
+// any work is done to authenticate them. The RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers describe the App's
+// limit. A request over the limit is refused with 429 Too Many
+// Requests and a Retry-After header.
+//
+// Requests are let through if the limits cannot be found or counted,
+// as a failing rate limiter should not take the API down with it.
+func (s *Server) appRateLimitHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.RateLimiter == nil || s.RateLimitServicer == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		lgr := *hlog.FromRequest(r)
+		ctx := r.Context()
+
+		a, err := diygoapi.AppFromRequest(r)
+		if err != nil {
+			errs.HTTPErrorResponse(w, lgr, err)
+			return
+		}
+
+		limits, err := s.RateLimitServicer.FindAppRateLimits(ctx, a)
+		if err != nil {
+			lgr.Error().Err(err).Msg("rate limits not found, request not limited")
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		state := &rateLimitState{limits: limits}
+		if !limits.App.Unlimited() {
+			key := "app:" + a.ExternalID.String()
+			res, aerr := s.RateLimiter.Allow(ctx, key, limits.App)
+			if aerr != nil {
+				lgr.Error().Err(aerr).Str("key", key).Msg("rate limit not counted")
+			} else {
+				state.result, state.counted = res, true
+				if !allowRateLimited(w, lgr, res) {
+					return
+				}
+			}
+		}
+
+		ctx = context.WithValue(ctx, rateLimitKey{}, state)
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// userRateLimitHandler middleware counts the request against the rate
+// limit each User of the App is given, using the limits found by
+// appRateLimitHandler. It runs once the User is authenticated. The
+// RateLimit-* headers are replaced when the User's limit is closer to
+// being reached than the App's. A request over the limit is refused
+// the same as one over the App's limit.
+func (s *Server) userRateLimitHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state, ok := r.Context().Value(rateLimitKey{}).(*rateLimitState)
+		if !ok || s.RateLimiter == nil || state.limits.User.Unlimited() {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		lgr := *hlog.FromRequest(r)
+
+		a, err := diygoapi.AppFromRequest(r)
+		if err != nil {
+			errs.HTTPErrorResponse(w, lgr, err)
+			return
+		}
+		u, err := diygoapi.UserFromRequest(r)
+		if err != nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		key := "app:" + a.ExternalID.String() + ":user:" + u.ExternalID.String()
+		res, err := s.RateLimiter.Allow(r.Context(), key, state.limits.User)
+		if err != nil {
+			lgr.Error().Err(err).Str("key", key).Msg("rate limit not counted")
+			h.ServeHTTP(w, r)
+			return
+		}
+		if state.counted && res.Allowed && res.Remaining >= state.result.Remaining {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if !allowRateLimited(w, lgr, res) {
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// allowRateLimited sets the RateLimit-* headers from res and reports
+// if the request is allowed. A request which is not allowed is sent
+// 429 Too Many Requests with a Retry-After header.
+func allowRateLimited(w http.ResponseWriter, lgr zerolog.Logger, res diygoapi.RateLimitResult) bool {
+	setRateLimitHeaders(w.Header(), res)
+	if res.Allowed {
+		return true
+	}
+
+	retryAfter := ceilSeconds(res.RetryAfter)
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	errs.HTTPErrorResponse(w, lgr, errs.E(errs.RateLimited, fmt.Sprintf("rate limit exceeded, retry in %d seconds", retryAfter)))
+	return false
+}
+
+// setRateLimitHeaders sets the RateLimit-Limit, RateLimit-Remaining
+// and RateLimit-Reset (in seconds) headers from the IETF draft
+//
+//	https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers/
+func setRateLimitHeaders(h http.Header, res diygoapi.RateLimitResult) {
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+}
+
+// ceilSeconds returns d in whole seconds, rounded up so a caller
+// waiting that long is not refused again
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
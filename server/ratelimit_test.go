@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/ratelimit"
+)
+
+type mockRateLimitService struct {
+	limits diygoapi.AppRateLimits
+	err    error
+}
+
+func (s mockRateLimitService) FindAppRateLimits(ctx context.Context, a *diygoapi.App) (diygoapi.AppRateLimits, error) {
+	return s.limits, s.err
+}
+
+func TestServer_appRateLimitHandler_userRateLimitHandler(t *testing.T) {
+	c := qt.New(t)
+
+	app := &diygoapi.App{ExternalID: []byte("app"), Org: &diygoapi.Org{}}
+	alice := &diygoapi.User{ExternalID: []byte("alice")}
+	bob := &diygoapi.User{ExternalID: []byte("bob")}
+
+	newServer := func(svc mockRateLimitService) http.Handler {
+		s := New(http.NewServeMux(), nopDriver{}, zerolog.Nop())
+		s.RateLimiter = ratelimit.NewMemoryLimiter()
+		s.RateLimitServicer = svc
+		return s.appRateLimitHandler(s.userRateLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "ok")
+		})))
+	}
+	serve := func(h http.Handler, u *diygoapi.User) *httptest.ResponseRecorder {
+		ctx := diygoapi.NewContextWithApp(context.Background(), app)
+		if u != nil {
+			ctx = diygoapi.NewContextWithUser(ctx, u)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil).WithContext(ctx))
+		return w
+	}
+
+	c.Run("app limit", func(c *qt.C) {
+		h := newServer(mockRateLimitService{limits: diygoapi.AppRateLimits{
+			App: diygoapi.RateLimit{RequestsPerMinute: 60, Burst: 2},
+		}})
+
+		w := serve(h, alice)
+		c.Assert(w.Code, qt.Equals, http.StatusOK)
+		c.Assert(w.Header().Get("RateLimit-Limit"), qt.Equals, "2")
+		c.Assert(w.Header().Get("RateLimit-Remaining"), qt.Equals, "1")
+		c.Assert(w.Header().Get("RateLimit-Reset"), qt.Equals, "1")
+
+		c.Assert(serve(h, bob).Code, qt.Equals, http.StatusOK)
+
+		// the limit is shared by all the app's users
+		w = serve(h, alice)
+		c.Assert(w.Code, qt.Equals, http.StatusTooManyRequests)
+		c.Assert(w.Header().Get("Retry-After"), qt.Equals, "1")
+		c.Assert(w.Header().Get("RateLimit-Remaining"), qt.Equals, "0")
+		c.Assert(w.Body.String(), qt.Contains, "rate limit exceeded")
+	})
+
+	c.Run("user limit", func(c *qt.C) {
+		h := newServer(mockRateLimitService{limits: diygoapi.AppRateLimits{
+			App:  diygoapi.RateLimit{RequestsPerMinute: 60, Burst: 10},
+			User: diygoapi.RateLimit{RequestsPerMinute: 6, Burst: 1},
+		}})
+
+		// the headers describe the user's limit, which is closer to
+		// being reached
+		w := serve(h, alice)
+		c.Assert(w.Code, qt.Equals, http.StatusOK)
+		c.Assert(w.Header().Get("RateLimit-Limit"), qt.Equals, "1")
+		c.Assert(w.Header().Get("RateLimit-Remaining"), qt.Equals, "0")
+
+		w = serve(h, alice)
+		c.Assert(w.Code, qt.Equals, http.StatusTooManyRequests)
+		c.Assert(w.Header().Get("Retry-After"), qt.Equals, "10")
+
+		// other users have their own limit
+		c.Assert(serve(h, bob).Code, qt.Equals, http.StatusOK)
+	})
+
+	c.Run("app limit counted before authentication", func(c *qt.C) {
+		s := New(http.NewServeMux(), nopDriver{}, zerolog.Nop())
+		s.RateLimiter = ratelimit.NewMemoryLimiter()
+		s.RateLimitServicer = mockRateLimitService{limits: diygoapi.AppRateLimits{
+			App: diygoapi.RateLimit{RequestsPerMinute: 60, Burst: 1},
+		}}
+		var authenticated int
+		h := s.appRateLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authenticated++
+		}))
+
+		c.Assert(serve(h, nil).Code, qt.Equals, http.StatusOK)
+		c.Assert(serve(h, nil).Code, qt.Equals, http.StatusTooManyRequests)
+		c.Assert(authenticated, qt.Equals, 1)
+	})
+
+	c.Run("unlimited", func(c *qt.C) {
+		h := newServer(mockRateLimitService{})
+		for range 3 {
+			w := serve(h, alice)
+			c.Assert(w.Code, qt.Equals, http.StatusOK)
+			c.Assert(w.Header().Get("RateLimit-Limit"), qt.Equals, "")
+		}
+	})
+
+	c.Run("limits not found", func(c *qt.C) {
+		// requests are let through
+		h := newServer(mockRateLimitService{err: errors.New("database down")})
+		c.Assert(serve(h, alice).Code, qt.Equals, http.StatusOK)
+	})
+}
@@ -1,5 +1,7 @@
 package server
 
+import "github.com/gilcrest/diygoapi"
+
 // register routes/middleware/handlers to the Server ServeMux
 func (s *Server) registerRoutes() {
 
@@ -8,9 +10,14 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("POST /api/v1/movies",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.enforceJSONContentTypeHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleMovieCreate))
@@ -20,9 +27,14 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("PUT /api/v1/movies/{extlID}",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.enforceJSONContentTypeHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleMovieUpdate))
@@ -31,18 +43,149 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("DELETE /api/v1/movies/{extlID}",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleMovieDelete))
 
+	// Match only POST requests at /api/v1/movies/{extlID}/restore
+	s.mux.Handle("POST /api/v1/movies/{extlID}/restore",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleMovieRestore))
+
+	// Match only POST requests at /api/v1/movies:import
+	// with Content-Type header = text/csv or application/x-ndjson
+	s.mux.Handle("POST /api/v1/movies:import",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.maxBodyHandler(diygoapi.MaxImportBytes)).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleMovieImport))
+
+	// Match only GET requests at /api/v1/movies:export
+	s.mux.Handle("GET /api/v1/movies:export",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			ThenFunc(s.handleMovieExport))
+
+	// Match only GET requests at /api/v1/movies/search
+	s.mux.Handle("GET /api/v1/movies/search",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleMovieSearch))
+
+	// Match only GET requests at /api/v1/movies/credits
+	s.mux.Handle("GET /api/v1/movies/credits",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleFindMoviesByCredit))
+
+	// Match only GET requests at /api/v1/genres
+	s.mux.Handle("GET /api/v1/genres",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleFindAllGenres))
+
+	// Match only GET requests at /api/v1/ratings
+	s.mux.Handle("GET /api/v1/ratings",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleFindAllRatings))
+
+	// Match only GET requests at /api/v1/genres/{genre}/movies
+	s.mux.Handle("GET /api/v1/genres/{genre}/movies",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleFindMoviesByGenre))
+
 	// Match only GET requests having an ID at /api/v1/movies/{extlID}
 	s.mux.Handle("GET /api/v1/movies/{extlID}",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleFindMovieByID))
@@ -51,20 +194,377 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("GET /api/v1/movies",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleFindAllMovies))
 
+	// Match only GET requests at /api/v1/movies/{extlID}/history
+	s.mux.Handle("GET /api/v1/movies/{extlID}/history",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleChangeHistoryFindByExtlID(diygoapi.MovieEntityType)))
+
+	// Match only PUT requests at /api/v1/movies/{extlID}/poster
+	// with a multipart/form-data body
+	s.mux.Handle("PUT /api/v1/movies/{extlID}/poster",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleMoviePosterUpload))
+
+	// Match only GET requests at /api/v1/movies/{extlID}/poster
+	// The response is the image itself, not JSON
+	s.mux.Handle("GET /api/v1/movies/{extlID}/poster",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			ThenFunc(s.handleMoviePosterFind))
+
+	// Match only POST requests at /api/v1/movies/{extlID}/reviews
+	s.mux.Handle("POST /api/v1/movies/{extlID}/reviews",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleReviewCreate))
+
+	// Match only GET requests at /api/v1/movies/{extlID}/reviews
+	s.mux.Handle("GET /api/v1/movies/{extlID}/reviews",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleReviewFindByMovie))
+
+	// Match only PUT requests at /api/v1/movies/{extlID}/reviews/{reviewExtlID}
+	s.mux.Handle("PUT /api/v1/movies/{extlID}/reviews/{reviewExtlID}",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleReviewUpdate))
+
+	// Match only POST requests at /api/v1/users/me/watchlists
+	s.mux.Handle("POST /api/v1/users/me/watchlists",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleWatchlistCreate))
+
+	// Match only GET requests at /api/v1/users/me/watchlists
+	s.mux.Handle("GET /api/v1/users/me/watchlists",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleWatchlistFindMine))
+
+	// Match only PUT requests at /api/v1/users/me/watchlists/{extlID}
+	s.mux.Handle("PUT /api/v1/users/me/watchlists/{extlID}",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleWatchlistUpdate))
+
+	// Match only DELETE requests at /api/v1/users/me/watchlists/{extlID}
+	s.mux.Handle("DELETE /api/v1/users/me/watchlists/{extlID}",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleWatchlistDelete))
+
+	// Match only POST requests at /api/v1/users/me/watchlists/{extlID}/movies
+	s.mux.Handle("POST /api/v1/users/me/watchlists/{extlID}/movies",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleWatchlistAddMovie))
+
+	// Match only PUT requests at /api/v1/users/me/watchlists/{extlID}/movies
+	s.mux.Handle("PUT /api/v1/users/me/watchlists/{extlID}/movies",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleWatchlistReorder))
+
+	// Match only DELETE requests at /api/v1/users/me/watchlists/{extlID}/movies/{movieExtlID}
+	s.mux.Handle("DELETE /api/v1/users/me/watchlists/{extlID}/movies/{movieExtlID}",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleWatchlistRemoveMovie))
+
+	// Match only GET requests at /api/v1/watchlists/{extlID}
+	s.mux.Handle("GET /api/v1/watchlists/{extlID}",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleWatchlistFindByExtlID))
+
+	// Match only GET requests at /api/v1/jobs
+	s.mux.Handle("GET /api/v1/jobs",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleJobFindAll))
+
+	// Match only GET requests at /api/v1/security-events
+	s.mux.Handle("GET /api/v1/security-events",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleSecurityEventFindAll))
+
+	// Match only GET requests at /api/v1/jobs/{extlID}
+	s.mux.Handle("GET /api/v1/jobs/{extlID}",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleJobFindByExtlID))
+
+	// Match only POST requests at /api/v1/jobs/{extlID}/retry
+	s.mux.Handle("POST /api/v1/jobs/{extlID}/retry",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleJobRetry))
+
+	// Match only POST requests at /api/v1/webhooks
+	// with Content-Type header = application/json
+	s.mux.Handle("POST /api/v1/webhooks",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.enforceJSONContentTypeHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleWebhookCreate))
+
+	// Match only GET requests at /api/v1/webhooks
+	s.mux.Handle("GET /api/v1/webhooks",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleWebhookFindAll))
+
+	// Match only DELETE requests at /api/v1/webhooks/{extlID}
+	s.mux.Handle("DELETE /api/v1/webhooks/{extlID}",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleWebhookDelete))
+
+	// Match only GET requests at /api/v1/webhooks/{extlID}/deliveries
+	s.mux.Handle("GET /api/v1/webhooks/{extlID}/deliveries",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleWebhookDeliveries))
+
+	// Match only GET requests at /api/v1/events
+	s.mux.Handle("GET /api/v1/events",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			ThenFunc(s.handleEventStream))
+
 	// Match only POST requests at /api/v1/orgs
 	// with Content-Type header = application/json
 	s.mux.Handle("POST /api/v1/orgs",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.enforceJSONContentTypeHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleOrgCreate))
@@ -74,9 +574,14 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("PUT /api/v1/orgs/{extlID}",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.enforceJSONContentTypeHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleOrgUpdate))
@@ -85,50 +590,163 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("DELETE /api/v1/orgs/{extlID}",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleOrgDelete))
 
+	// Match only POST requests at /api/v1/orgs/{extlID}/restore
+	s.mux.Handle("POST /api/v1/orgs/{extlID}/restore",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleOrgRestore))
+
 	// Match only GET requests at /api/v1/orgs
 	s.mux.Handle("GET /api/v1/orgs",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleOrgFindAll))
 
+	// Match only GET requests at /api/v1/orgs:export
+	s.mux.Handle("GET /api/v1/orgs:export",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			ThenFunc(s.handleOrgExport))
+
 	// Match only GET requests at /api/v1/orgs/{extlID}
 	s.mux.Handle("GET /api/v1/orgs/{extlID}",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleOrgFindByExtlID))
 
+	// Match only GET requests at /api/v1/orgs/{extlID}/history
+	s.mux.Handle("GET /api/v1/orgs/{extlID}/history",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleChangeHistoryFindByExtlID(diygoapi.OrgEntityType)))
+
 	// Match only POST requests at /api/v1/apps
 	// with Content-Type header = application/json
 	s.mux.Handle("POST /api/v1/apps",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.enforceJSONContentTypeHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleAppCreate))
 
+	// Match only DELETE requests at /api/v1/apps/{extlID}
+	s.mux.Handle("DELETE /api/v1/apps/{extlID}",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleAppDelete))
+
+	// Match only POST requests at /api/v1/apps/{extlID}/restore
+	s.mux.Handle("POST /api/v1/apps/{extlID}/restore",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleAppRestore))
+
+	// Match only GET requests at /api/v1/apps/{extlID}/history
+	s.mux.Handle("GET /api/v1/apps/{extlID}/history",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleChangeHistoryFindByExtlID(diygoapi.AppEntityType)))
+
 	// Match only POST requests at /api/v1/users
 	s.mux.Handle("POST /api/v1/users",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.enforceJSONContentTypeHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.appCORSHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleNewUser))
 
@@ -136,8 +754,13 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("GET /api/v1/logger",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleLoggerRead))
@@ -146,9 +769,14 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("PUT /api/v1/logger",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.enforceJSONContentTypeHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleLoggerUpdate))
@@ -157,19 +785,48 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("GET /api/v1/ping",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.authorizeUserHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handlePing))
 
+	// Match only GET requests at /healthz. The health endpoints are
+	// for orchestrators and load balancers, so do not require auth.
+	s.mux.Handle("GET /healthz",
+		s.probeChain().
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleLiveness))
+
+	// Match only GET requests at /readyz
+	s.mux.Handle("GET /readyz",
+		s.probeChain().
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleReadiness))
+
+	// Match only GET requests at /metrics, served here unless the
+	// Server has a separate MetricsAddr
+	s.mux.Handle("GET /metrics",
+		s.probeChain().
+			ThenFunc(s.handleMetrics))
+
 	// Match only POST requests at /api/v1/permissions
 	s.mux.Handle("POST /api/v1/permissions",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.enforceJSONContentTypeHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handlePermissionCreate))
 
@@ -177,8 +834,13 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("GET /api/v1/permissions",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handlePermissionFindAll))
 
@@ -186,15 +848,52 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("DELETE /api/v1/permissions/{extlID}",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
 			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handlePermissionDelete))
 
+	// Match only GET requests at /api/v1/permissions/{extlID}/history
+	s.mux.Handle("GET /api/v1/permissions/{extlID}/history",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleChangeHistoryFindByExtlID(diygoapi.PermissionEntityType)))
+
+	// Match only GET requests at /api/v1/roles/{extlID}/history
+	s.mux.Handle("GET /api/v1/roles/{extlID}/history",
+		s.loggerChain().
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
+			Append(s.appHandler).
+			Append(s.appRateLimitHandler).
+			Append(s.authHandler).
+			Append(s.appCORSHandler).
+			Append(s.userRateLimitHandler).
+			Append(s.authorizeUserHandler).
+			Append(s.jsonContentTypeResponseHandler).
+			ThenFunc(s.handleChangeHistoryFindByExtlID(diygoapi.RoleEntityType)))
+
 	// Match only POST requests at /api/v1/genesis
 	s.mux.Handle("POST /api/v1/genesis",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.genesisAuthHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleGenesis))
@@ -203,6 +902,8 @@ func (s *Server) registerRoutes() {
 	s.mux.Handle("GET /api/v1/genesis",
 		s.loggerChain().
 			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			Append(s.metricsHandler).
 			Append(s.jsonContentTypeResponseHandler).
 			ThenFunc(s.handleGenesisRead))
 }
@@ -22,19 +22,27 @@
 //      opencensus as I have not worked with it and think it has moved to
 //      opentelemetry anyway
 // - removed health checkers
-//      I will likely add these back, but removing to simplify for now
+//      added back as diygoapi.HealthChecker, run by the /readyz endpoint
 // - removed TLS
-//      I am using Google Cloud Run which handles TLS for me. To keep this as
-//      simple as possible, I am removing TLS for now
+//      I am using Google Cloud Run which handles TLS for me. Added back as
+//      Server.TLS for deployments which terminate TLS in-process, with the
+//      certificate reloaded when it changes
 
 // Package server provides a preconfigured HTTP server.
 package server
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -46,6 +54,21 @@ import (
 
 const pathPrefix string = "/api"
 
+// health endpoint statuses
+const (
+	healthOK           = "ok"
+	healthUnavailable  = "unavailable"
+	healthShuttingDown = "shutting down"
+)
+
+// DefaultMaxRequestBodyBytes is the size JSON request bodies are limited
+// to, unless the Server or route sets another
+const DefaultMaxRequestBodyBytes int64 = 1 << 20
+
+// healthCheckTimeout bounds how long the readiness endpoint waits on
+// each HealthChecker
+const healthCheckTimeout = 5 * time.Second
+
 // Services are used by the application service handlers
 type Services struct {
 	OrgServicer            diygoapi.OrgServicer
@@ -59,6 +82,16 @@ type Services struct {
 	PermissionServicer     diygoapi.PermissionServicer
 	RoleServicer           diygoapi.RoleServicer
 	MovieServicer          diygoapi.MovieServicer
+	ChangeHistoryServicer  diygoapi.ChangeHistoryServicer
+	ReviewServicer         diygoapi.ReviewServicer
+	WatchlistServicer      diygoapi.WatchlistServicer
+	PosterServicer         diygoapi.PosterServicer
+	JobServicer            diygoapi.JobServicer
+	WebhookServicer        diygoapi.WebhookServicer
+	EventServicer          diygoapi.EventServicer
+	RateLimitServicer      diygoapi.RateLimitServicer
+	CORSServicer           diygoapi.CORSServicer
+	SecurityEventServicer  diygoapi.SecurityEventServicer
 }
 
 // Server represents an HTTP server.
@@ -78,6 +111,57 @@ type Server struct {
 
 	// Services used by the various HTTP routes and middleware.
 	Services
+
+	// JobWorkers optionally run background jobs. If set, they are
+	// drained when the Server is shut down.
+	JobWorkers diygoapi.JobWorkers
+
+	// RateLimiter optionally limits the rate of requests of each App,
+	// and each User of an App, to the limits found by the
+	// RateLimitServicer
+	RateLimiter diygoapi.RateLimiter
+
+	// MaxRequestBodyBytes is the size JSON request bodies are limited
+	// to on routes without a limit of their own. If zero,
+	// DefaultMaxRequestBodyBytes is used.
+	MaxRequestBodyBytes int64
+
+	// TLS optionally serves HTTPS in place of plain HTTP. The Driver
+	// must implement driver.TLSServer.
+	TLS *TLS
+
+	// CORS optionally allows browser clients on other origins to call
+	// the API. If nil, no CORS headers are sent.
+	CORS *CORS
+
+	// ShutdownDrainDelay is how long Shutdown fails readiness before it
+	// stops accepting connections, so load balancers have time to stop
+	// sending requests to the Server. If zero, there is no delay.
+	ShutdownDrainDelay time.Duration
+
+	// HealthCheckers are the dependencies checked by the readiness
+	// endpoint, by name. They must be set before ListenAndServe.
+	HealthCheckers map[string]diygoapi.HealthChecker
+
+	// Metrics optionally records Prometheus metrics, served at /metrics
+	Metrics *Metrics
+
+	// MetricsAddr optionally specifies a separate TCP address, e.g. an
+	// admin port not exposed publicly, to serve /metrics on. If empty,
+	// /metrics is served with the API.
+	MetricsAddr string
+
+	// metricsServer serves /metrics when MetricsAddr is set. It is
+	// started by ListenAndServe and stopped by Shutdown, which are
+	// called from different goroutines.
+	metricsServer atomic.Pointer[http.Server]
+
+	// draining is set on Shutdown to fail readiness checks
+	draining atomic.Bool
+
+	// streams is canceled on Shutdown to end open event streams
+	streams     context.Context
+	stopStreams context.CancelFunc
 }
 
 // New initializes a new Server and registers
@@ -88,6 +172,7 @@ func New(sm *http.ServeMux, ds driver.Server, lgr zerolog.Logger) *Server {
 		Driver: ds,
 		Logger: lgr,
 	}
+	s.streams, s.stopStreams = context.WithCancel(context.Background())
 
 	// register routes to the router
 	s.registerRoutes()
@@ -107,13 +192,95 @@ func (s *Server) ListenAndServe() error {
 	if s.Driver == nil {
 		return errs.E(op, errs.Internal, "Server driver is nil")
 	}
+	if s.Metrics != nil && s.MetricsAddr != "" {
+		s.listenAndServeMetrics()
+	}
+	if s.TLS != nil {
+		ts, ok := s.Driver.(driver.TLSServer)
+		if !ok {
+			return errs.E(op, errs.Internal, "Server driver does not support TLS")
+		}
+		cfg, err := s.TLS.config(s.Logger)
+		if err != nil {
+			return errs.E(op, err)
+		}
+		s.Logger.Info().Msgf("server listening on %s with TLS", s.Addr)
+		return ts.ListenAndServeTLS(s.Addr, cfg, s.corsHandler(s.mux))
+	}
 	s.Logger.Info().Msgf("server listening on %s", s.Addr)
-	return s.Driver.ListenAndServe(s.Addr, s.mux)
+	return s.Driver.ListenAndServe(s.Addr, s.corsHandler(s.mux))
 }
 
-// Shutdown gracefully shuts down the server without interrupting any active connections.
+// Shutdown gracefully shuts down the server without interrupting any
+// active connections, then drains the JobWorkers, if any. Jobs left
+// queued stay in the job table for the next start. Readiness fails from
+// the start, and requests are still served for the ShutdownDrainDelay
+// after. Event streams are then ended, as they would otherwise never
+// finish.
 func (s *Server) Shutdown(ctx context.Context) error {
-	return s.Driver.Shutdown(ctx)
+	s.draining.Store(true)
+	s.Logger.Info().Msg("readiness set to failing")
+
+	if s.ShutdownDrainDelay > 0 {
+		s.Logger.Info().Msgf("serving requests for %s while load balancers stop sending them", s.ShutdownDrainDelay)
+		t := time.NewTimer(s.ShutdownDrainDelay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+		}
+	}
+
+	if s.stopStreams != nil {
+		s.stopStreams()
+	}
+
+	err := s.Driver.Shutdown(ctx)
+	if err == nil {
+		s.Logger.Info().Msg("http server stopped, in-flight requests drained")
+	}
+
+	if s.JobWorkers != nil {
+		err = errors.Join(err, s.JobWorkers.Shutdown(ctx))
+	}
+
+	// metrics are served until last, so the drain can be observed
+	s.shutdownMetrics(ctx)
+
+	return err
+}
+
+// checkHealth runs the HealthCheckers concurrently and returns the
+// outcome of each by name. The endpoint is unauthenticated, so errors
+// are logged rather than returned.
+func (s *Server) checkHealth(ctx context.Context, lgr zerolog.Logger) map[string]string {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]string, len(s.HealthCheckers))
+	)
+	for name, hc := range s.HealthCheckers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := healthOK
+			if err := hc.CheckHealth(ctx); err != nil {
+				lgr.Error().Err(err).Str("check", name).Msg("health check failed")
+				result = healthUnavailable
+			}
+
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return results
 }
 
 // Driver implements the driver.Server interface. The zero value is a valid http.Server.
@@ -140,19 +307,115 @@ func (d *Driver) ListenAndServe(addr string, h http.Handler) error {
 	return d.Server.ListenAndServe()
 }
 
+// ListenAndServeTLS sets the address, handler and TLS configuration on
+// Driver's http.Server, then calls ListenAndServeTLS on it. HTTP/2 is
+// negotiated with clients which support it, HTTP/1.1 is served to
+// others.
+func (d *Driver) ListenAndServeTLS(addr string, cfg *tls.Config, h http.Handler) error {
+	d.Server.Addr = addr
+	d.Server.Handler = h
+	d.Server.TLSConfig = cfg
+	if d.Server.Protocols == nil {
+		d.Server.Protocols = new(http.Protocols)
+		d.Server.Protocols.SetHTTP1(true)
+	}
+	d.Server.Protocols.SetHTTP2(true)
+	// the certificate is in cfg
+	return d.Server.ListenAndServeTLS("", "")
+}
+
+// EnableH2C has the Driver serve unencrypted HTTP/2 (h2c) to clients
+// with prior knowledge, as well as HTTP/1.1, when not serving TLS. It
+// is meant for a proxy which terminates TLS and speaks HTTP/2 to the
+// server, e.g. Cloud Run with end-to-end HTTP/2.
+func (d *Driver) EnableH2C() {
+	if d.Server.Protocols == nil {
+		d.Server.Protocols = new(http.Protocols)
+		d.Server.Protocols.SetHTTP1(true)
+	}
+	d.Server.Protocols.SetUnencryptedHTTP2(true)
+}
+
 // Shutdown gracefully shuts down the server without interrupting any active connections,
 // by calling Shutdown on Driver's http.Server
 func (d *Driver) Shutdown(ctx context.Context) error {
 	return d.Server.Shutdown(ctx)
 }
 
+// decodeJSON decodes the JSON request body into dst. The body is
+// limited to the size set for the route by maxBodyHandler, or the
+// Server's MaxRequestBodyBytes, fields not in dst are rejected, as is
+// anything after the JSON value, so client mistakes are not silently
+// ignored. Errors are returned by decoderErr.
+func (s *Server) decodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	const op errs.Op = "server/Server.decodeJSON"
+
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxBodyBytes(r))
+	defer r.Body.Close()
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	err := decoderErr(dec.Decode(dst))
+	if err != nil {
+		return err
+	}
+
+	err = dec.Decode(&struct{}{})
+	if err != io.EOF {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return decoderErr(err)
+		}
+		return errs.E(op, errs.InvalidRequest, "request body must contain a single JSON value")
+	}
+
+	return nil
+}
+
+// maxBodyKey is the request context key for the request body size
+// limit of a route
+type maxBodyKey struct{}
+
+// maxBodyHandler returns middleware which limits the request body of a
+// route to n bytes, in place of the Server's MaxRequestBodyBytes
+func (s *Server) maxBodyHandler(n int64) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			ctx := context.WithValue(r.Context(), maxBodyKey{}, n)
+			h.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// maxBodyBytes returns the request body size limit for the request
+func (s *Server) maxBodyBytes(r *http.Request) int64 {
+	if n, ok := r.Context().Value(maxBodyKey{}).(int64); ok {
+		return n
+	}
+	if s.MaxRequestBodyBytes > 0 {
+		return s.MaxRequestBodyBytes
+	}
+	return DefaultMaxRequestBodyBytes
+}
+
 // decoderErr is a convenience function to handle errors returned by
 // json.NewDecoder(r.Body).Decode(&data) and return the appropriate
-// error response
+// error response. Errors caused by a field of the request body have
+// the field as the errs.Parameter.
 func decoderErr(err error) error {
 	const op errs.Op = "server/decoderErr"
 
+	var (
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+		maxBytesErr *http.MaxBytesError
+	)
+
 	switch {
+	case err == nil:
+		return nil
 	// If the request body is empty (io.EOF)
 	// return an error
 	case err == io.EOF:
@@ -161,9 +424,36 @@ func decoderErr(err error) error {
 	// return an error
 	case errors.Is(err, io.ErrUnexpectedEOF):
 		return errs.E(op, errs.InvalidRequest, "malformed JSON")
+	case errors.As(err, &syntaxErr):
+		return errs.E(op, errs.InvalidRequest, fmt.Sprintf("malformed JSON at position %d", syntaxErr.Offset))
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return errs.E(op, errs.InvalidRequest, fmt.Sprintf("request body cannot be a JSON %s", typeErr.Value))
+		}
+		return errs.E(op, errs.InvalidRequest, errs.Parameter(typeErr.Field), fmt.Sprintf("%s cannot be a JSON %s", typeErr.Field, typeErr.Value))
+	case errors.As(err, &maxBytesErr):
+		return errs.E(op, errs.InvalidRequest, fmt.Sprintf("request body cannot be larger than %d bytes", maxBytesErr.Limit))
+	}
+
+	// DisallowUnknownFields errors have no type of their own
+	if field, ok := unknownField(err); ok {
+		return errs.E(op, errs.InvalidRequest, errs.Parameter(field), fmt.Sprintf("unknown field %s", field))
+	}
+
 	// return other errors
-	case err != nil:
-		return errs.E(op, err)
+	return errs.E(op, err)
+}
+
+// unknownField returns the field named in an unknown field error
+// returned by a json.Decoder with DisallowUnknownFields
+func unknownField(err error) (string, bool) {
+	quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return "", false
 	}
-	return nil
+	field, uerr := strconv.Unquote(quoted)
+	if uerr != nil {
+		return quoted, true
+	}
+	return field, true
 }
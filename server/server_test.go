@@ -2,13 +2,19 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
+	"github.com/rs/zerolog"
 
+	"github.com/gilcrest/diygoapi"
 	"github.com/gilcrest/diygoapi/errs"
 )
 
@@ -130,3 +136,197 @@ func TestDecoderErr(t *testing.T) {
 		c.Assert(err != nil, qt.Equals, true)
 	})
 }
+
+func TestServer_decodeJSON(t *testing.T) {
+	type testBody struct {
+		Director string `json:"director"`
+		Credits  struct {
+			RunTime int `json:"run_time"`
+		} `json:"credits"`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{"typical", `{"director": "Alex Cox", "credits": {"run_time": 97}}`, nil},
+		{"trailing whitespace", "{\"director\": \"Alex Cox\"}\n", nil},
+		{"empty request body", ``, errs.E(errs.InvalidRequest, errors.New("request body cannot be empty"))},
+		{"malformed JSON", `{"director": "Alex Cox"`, errs.E(errs.InvalidRequest, errors.New("malformed JSON"))},
+		{"syntax error", `{"director": Alex Cox}`, errs.E(errs.InvalidRequest, errors.New("malformed JSON at position 14"))},
+		{"unknown field", `{"director": "Alex Cox", "writr": "Alex Cox"}`, errs.E(errs.InvalidRequest, errs.Parameter("writr"), errors.New("unknown field writr"))},
+		{"wrong type", `{"credits": {"run_time": "97 minutes"}}`, errs.E(errs.InvalidRequest, errs.Parameter("credits.run_time"), errors.New("credits.run_time cannot be a JSON string"))},
+		{"not an object", `["Alex Cox"]`, errs.E(errs.InvalidRequest, errors.New("request body cannot be a JSON array"))},
+		{"trailing data", `{"director": "Alex Cox"} {"director": "Alex Cox"}`, errs.E(errs.InvalidRequest, errors.New("request body must contain a single JSON value"))},
+		{"too large", `{"director": "` + strings.Repeat("x", 64) + `"}`, errs.E(errs.InvalidRequest, errors.New("request body cannot be larger than 64 bytes"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+
+			s := &Server{MaxRequestBodyBytes: 64}
+
+			r := httptest.NewRequest(http.MethodPost, "/fake", strings.NewReader(tt.body))
+			err := s.decodeJSON(httptest.NewRecorder(), r, new(testBody))
+			if tt.wantErr == nil {
+				c.Assert(err, qt.IsNil)
+				return
+			}
+			c.Assert(errs.Match(tt.wantErr, err), qt.IsTrue, qt.Commentf("got %v", err))
+		})
+	}
+
+	t.Run("route limit", func(t *testing.T) {
+		c := qt.New(t)
+
+		s := &Server{MaxRequestBodyBytes: 16}
+		body := `{"director": "` + strings.Repeat("x", 64) + `"}`
+
+		decode := func(limit int64) error {
+			var err error
+			h := s.maxBodyHandler(limit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				err = s.decodeJSON(w, r, new(testBody))
+			}))
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/fake", strings.NewReader(body)))
+			return err
+		}
+
+		// the route limit applies in place of the Server's
+		c.Assert(decode(1024), qt.IsNil)
+		err := decode(8)
+		c.Assert(errs.Match(errs.E(errs.InvalidRequest, errors.New("request body cannot be larger than 8 bytes")), err), qt.IsTrue, qt.Commentf("got %v", err))
+	})
+}
+
+// nopDriver is a driver.Server which does nothing
+type nopDriver struct{}
+
+func (nopDriver) ListenAndServe(string, http.Handler) error { return nil }
+
+func (nopDriver) Shutdown(context.Context) error { return nil }
+
+func TestServer_health(t *testing.T) {
+	get := func(c *qt.C, s *Server, path string) (int, diygoapi.HealthResponse) {
+		c.Helper()
+
+		w := httptest.NewRecorder()
+		s.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		var hr diygoapi.HealthResponse
+		c.Assert(json.NewDecoder(w.Body).Decode(&hr), qt.IsNil)
+		return w.Code, hr
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		c := qt.New(t)
+
+		s := New(http.NewServeMux(), nopDriver{}, zerolog.Nop())
+		s.HealthCheckers = map[string]diygoapi.HealthChecker{
+			"database": diygoapi.HealthCheckerFunc(func(context.Context) error { return nil }),
+		}
+
+		code, hr := get(c, s, "/readyz")
+		c.Assert(code, qt.Equals, http.StatusOK)
+		c.Assert(hr, qt.DeepEquals, diygoapi.HealthResponse{Status: "ok", Checks: map[string]string{"database": "ok"}})
+	})
+	t.Run("failing check", func(t *testing.T) {
+		c := qt.New(t)
+
+		s := New(http.NewServeMux(), nopDriver{}, zerolog.Nop())
+		s.HealthCheckers = map[string]diygoapi.HealthChecker{
+			"database": diygoapi.HealthCheckerFunc(func(context.Context) error { return nil }),
+			"schema_version": diygoapi.HealthCheckerFunc(func(context.Context) error {
+				return errs.E(errs.Database, "schema version is 27, want 28")
+			}),
+		}
+
+		code, hr := get(c, s, "/readyz")
+		c.Assert(code, qt.Equals, http.StatusServiceUnavailable)
+		c.Assert(hr, qt.DeepEquals, diygoapi.HealthResponse{
+			Status: "unavailable",
+			Checks: map[string]string{"database": "ok", "schema_version": "unavailable"},
+		})
+
+		// liveness does not depend on the checks
+		code, hr = get(c, s, "/healthz")
+		c.Assert(code, qt.Equals, http.StatusOK)
+		c.Assert(hr.Status, qt.Equals, "ok")
+	})
+	t.Run("shutting down", func(t *testing.T) {
+		c := qt.New(t)
+
+		s := New(http.NewServeMux(), nopDriver{}, zerolog.Nop())
+		c.Assert(s.Shutdown(context.Background()), qt.IsNil)
+
+		code, hr := get(c, s, "/readyz")
+		c.Assert(code, qt.Equals, http.StatusServiceUnavailable)
+		c.Assert(hr.Status, qt.Equals, "shutting down")
+
+		code, _ = get(c, s, "/healthz")
+		c.Assert(code, qt.Equals, http.StatusOK)
+	})
+	t.Run("drain delay", func(t *testing.T) {
+		c := qt.New(t)
+
+		d := &shutdownDriver{stopped: make(chan struct{})}
+		s := New(http.NewServeMux(), d, zerolog.Nop())
+		s.ShutdownDrainDelay = 200 * time.Millisecond
+
+		start := time.Now()
+		done := make(chan error)
+		go func() { done <- s.Shutdown(context.Background()) }()
+
+		// readiness fails straight away, while requests are still served
+		c.Assert(waitForReadyz(c, s, http.StatusServiceUnavailable), qt.IsTrue)
+		select {
+		case <-d.stopped:
+			c.Fatal("driver shut down before the drain delay")
+		default:
+		}
+
+		c.Assert(<-done, qt.IsNil)
+		c.Assert(time.Since(start) >= s.ShutdownDrainDelay, qt.IsTrue)
+	})
+	t.Run("drain delay cut short", func(t *testing.T) {
+		c := qt.New(t)
+
+		d := &shutdownDriver{stopped: make(chan struct{})}
+		s := New(http.NewServeMux(), d, zerolog.Nop())
+		s.ShutdownDrainDelay = time.Hour
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		c.Assert(s.Shutdown(ctx), qt.IsNil)
+		<-d.stopped
+	})
+}
+
+// shutdownDriver is a driver.Server which closes stopped when it is
+// shut down
+type shutdownDriver struct {
+	stopped chan struct{}
+}
+
+func (d *shutdownDriver) ListenAndServe(string, http.Handler) error { return nil }
+
+func (d *shutdownDriver) Shutdown(context.Context) error {
+	close(d.stopped)
+	return nil
+}
+
+// waitForReadyz reports if the readiness endpoint responds with code
+// within a second
+func waitForReadyz(c *qt.C, s *Server, code int) bool {
+	c.Helper()
+
+	for range 100 {
+		w := httptest.NewRecorder()
+		s.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if w.Code == code {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+// certReloadInterval is how often the certificate and key files are
+// checked for changes
+const certReloadInterval = 10 * time.Second
+
+// TLS configures the Server to serve HTTPS
+type TLS struct {
+	// CertFile and KeyFile are the PEM encoded certificate, followed
+	// by any intermediates, and its private key. They are reloaded
+	// when changed, so a renewed certificate is served without a
+	// restart.
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile optionally has the PEM encoded certificates of the
+	// CAs client certificates are verified against. If set, clients
+	// must present a certificate issued by one of them (mutual TLS).
+	ClientCAFile string
+}
+
+// config returns the tls.Config to serve with, having loaded the
+// certificate, and the client CAs if any
+func (t *TLS) config(lgr zerolog.Logger) (*tls.Config, error) {
+	const op errs.Op = "server/TLS.config"
+
+	cr, err := newCertReloader(t.CertFile, t.KeyFile, lgr)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: cr.GetCertificate,
+	}
+
+	if t.ClientCAFile != "" {
+		var pem []byte
+		pem, err = os.ReadFile(t.ClientCAFile)
+		if err != nil {
+			return nil, errs.E(op, errs.Internal, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errs.E(op, errs.Internal, "no certificates found in client CA file "+t.ClientCAFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg, nil
+}
+
+// certReloader serves a certificate loaded from files, loading it
+// again once the files change. The files are checked at most every
+// certReloadInterval, during a handshake.
+type certReloader struct {
+	certFile, keyFile string
+	logger            zerolog.Logger
+	now               func() time.Time
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+	checked time.Time
+}
+
+// newCertReloader returns a certReloader having loaded the certificate
+func newCertReloader(certFile, keyFile string, lgr zerolog.Logger) (*certReloader, error) {
+	const op errs.Op = "server/newCertReloader"
+
+	cr := &certReloader{certFile: certFile, keyFile: keyFile, logger: lgr, now: time.Now}
+	err := cr.reload()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	cr.checked = cr.now()
+
+	return cr, nil
+}
+
+// GetCertificate returns the certificate to serve, for
+// tls.Config.GetCertificate. If the changed files cannot be loaded,
+// e.g. as the key has not been written yet, the certificate already
+// loaded is served and loading is tried again at the next check.
+func (cr *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	now := cr.now()
+	if now.Sub(cr.checked) >= certReloadInterval {
+		cr.checked = now
+		err := cr.reload()
+		if err != nil {
+			cr.logger.Error().Err(err).Msg("TLS certificate not reloaded, serving the one loaded before")
+		}
+	}
+
+	return cr.cert, nil
+}
+
+// reload loads the certificate and key, unless neither file has
+// changed since they were last loaded
+func (cr *certReloader) reload() error {
+	const op errs.Op = "server/certReloader.reload"
+
+	certInfo, err := os.Stat(cr.certFile)
+	if err != nil {
+		return errs.E(op, errs.Internal, err)
+	}
+	keyInfo, err := os.Stat(cr.keyFile)
+	if err != nil {
+		return errs.E(op, errs.Internal, err)
+	}
+	if cr.cert != nil && certInfo.ModTime().Equal(cr.certMod) && keyInfo.ModTime().Equal(cr.keyMod) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return errs.E(op, errs.Internal, err)
+	}
+
+	loaded := cr.cert != nil
+	cr.cert, cr.certMod, cr.keyMod = &cert, certInfo.ModTime(), keyInfo.ModTime()
+	if loaded {
+		cr.logger.Info().Str("cert_file", cr.certFile).Msg("TLS certificate reloaded")
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/rs/zerolog"
+)
+
+// testCert is a certificate and key for tests, which can issue other
+// certificates
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert returns a certificate for 127.0.0.1, usable by servers
+// and clients, issued by issuer or, if nil, self-signed
+func newTestCert(c *qt.C, cn string, issuer *testCert) *testCert {
+	c.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, qt.IsNil)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  issuer == nil,
+	}
+	parent, parentKey := tmpl, key
+	if issuer != nil {
+		parent, parentKey = issuer.cert, issuer.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	c.Assert(err, qt.IsNil)
+	cert, err := x509.ParseCertificate(der)
+	c.Assert(err, qt.IsNil)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, qt.IsNil)
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// writeTestCert writes the certificate and key to cert.pem and key.pem
+// in dir, with the given modification time
+func writeTestCert(c *qt.C, dir string, tc *testCert, mod time.Time) (certFile, keyFile string) {
+	c.Helper()
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	c.Assert(os.WriteFile(certFile, tc.certPEM, 0o600), qt.IsNil)
+	c.Assert(os.WriteFile(keyFile, tc.keyPEM, 0o600), qt.IsNil)
+	c.Assert(os.Chtimes(certFile, mod, mod), qt.IsNil)
+	c.Assert(os.Chtimes(keyFile, mod, mod), qt.IsNil)
+
+	return certFile, keyFile
+}
+
+func TestCertReloader(t *testing.T) {
+	c := qt.New(t)
+
+	dir := c.TempDir()
+	mod := time.Now().Add(-time.Hour)
+	certFile, keyFile := writeTestCert(c, dir, newTestCert(c, "first", nil), mod)
+
+	cr, err := newCertReloader(certFile, keyFile, zerolog.Nop())
+	c.Assert(err, qt.IsNil)
+	now := cr.checked
+	cr.now = func() time.Time { return now }
+
+	commonName := func() string {
+		c.Helper()
+		cert, err := cr.GetCertificate(nil)
+		c.Assert(err, qt.IsNil)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		c.Assert(err, qt.IsNil)
+		return leaf.Subject.CommonName
+	}
+	c.Assert(commonName(), qt.Equals, "first")
+
+	// the change is not seen until the files are checked again
+	writeTestCert(c, dir, newTestCert(c, "second", nil), mod.Add(time.Minute))
+	c.Assert(commonName(), qt.Equals, "first")
+	now = now.Add(certReloadInterval)
+	c.Assert(commonName(), qt.Equals, "second")
+
+	// a key not matching the certificate is not loaded, the
+	// certificate loaded before is served
+	third := newTestCert(c, "third", nil)
+	c.Assert(os.WriteFile(certFile, third.certPEM, 0o600), qt.IsNil)
+	now = now.Add(certReloadInterval)
+	c.Assert(commonName(), qt.Equals, "second")
+
+	// until the key is written too
+	writeTestCert(c, dir, third, mod.Add(2*time.Minute))
+	now = now.Add(certReloadInterval)
+	c.Assert(commonName(), qt.Equals, "third")
+}
+
+func TestTLS_config(t *testing.T) {
+	t.Run("missing certificate", func(t *testing.T) {
+		c := qt.New(t)
+
+		dir := c.TempDir()
+		tl := &TLS{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")}
+		_, err := tl.config(zerolog.Nop())
+		c.Assert(err, qt.IsNotNil)
+	})
+	t.Run("no client CA certificates", func(t *testing.T) {
+		c := qt.New(t)
+
+		dir := c.TempDir()
+		certFile, keyFile := writeTestCert(c, dir, newTestCert(c, "server", nil), time.Now())
+		caFile := filepath.Join(dir, "ca.pem")
+		c.Assert(os.WriteFile(caFile, []byte("not a certificate"), 0o600), qt.IsNil)
+
+		tl := &TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile}
+		_, err := tl.config(zerolog.Nop())
+		c.Assert(err, qt.ErrorMatches, ".*no certificates found in client CA file.*")
+	})
+}
+
+func TestServer_ListenAndServeTLS(t *testing.T) {
+	// listen starts a Server with TLS on a free port and returns its
+	// address, it is shut down when the test ends
+	listen := func(c *qt.C, tl *TLS) string {
+		c.Helper()
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		c.Assert(err, qt.IsNil)
+		addr := l.Addr().String()
+		c.Assert(l.Close(), qt.IsNil)
+
+		s := New(http.NewServeMux(), NewDriver(), zerolog.Nop())
+		s.Addr = addr
+		s.TLS = tl
+
+		served := make(chan error, 1)
+		go func() { served <- s.ListenAndServe() }()
+		c.Cleanup(func() {
+			c.Check(s.Shutdown(context.Background()), qt.IsNil)
+			c.Check(<-served, qt.ErrorIs, http.ErrServerClosed)
+		})
+
+		// wait for the server to listen
+		for range 50 {
+			conn, derr := net.Dial("tcp", addr)
+			if derr == nil {
+				conn.Close()
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		return addr
+	}
+
+	client := func(ca *testCert, clientCert *testCert) *http.Client {
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+		cfg := &tls.Config{RootCAs: roots}
+		if clientCert != nil {
+			cfg.Certificates = []tls.Certificate{{Certificate: [][]byte{clientCert.cert.Raw}, PrivateKey: clientCert.key}}
+		}
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: cfg, ForceAttemptHTTP2: true}}
+	}
+
+	t.Run("http2", func(t *testing.T) {
+		c := qt.New(t)
+
+		ca := newTestCert(c, "server", nil)
+		certFile, keyFile := writeTestCert(c, c.TempDir(), ca, time.Now())
+		addr := listen(c, &TLS{CertFile: certFile, KeyFile: keyFile})
+
+		resp, err := client(ca, nil).Get("https://" + addr + "/healthz")
+		c.Assert(err, qt.IsNil)
+		defer resp.Body.Close()
+		c.Assert(resp.StatusCode, qt.Equals, http.StatusOK)
+		c.Assert(resp.ProtoMajor, qt.Equals, 2)
+	})
+	t.Run("client certificates", func(t *testing.T) {
+		c := qt.New(t)
+
+		ca := newTestCert(c, "ca", nil)
+		dir := c.TempDir()
+		certFile, keyFile := writeTestCert(c, dir, newTestCert(c, "server", ca), time.Now())
+		caFile := filepath.Join(dir, "ca.pem")
+		c.Assert(os.WriteFile(caFile, ca.certPEM, 0o600), qt.IsNil)
+		addr := listen(c, &TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+
+		resp, err := client(ca, newTestCert(c, "client", ca)).Get("https://" + addr + "/healthz")
+		c.Assert(err, qt.IsNil)
+		resp.Body.Close()
+		c.Assert(resp.StatusCode, qt.Equals, http.StatusOK)
+
+		// without a certificate the handshake fails
+		_, err = client(ca, nil).Get("https://" + addr + "/healthz")
+		c.Assert(err, qt.IsNotNil)
+
+		// as it does with one from another CA
+		_, err = client(ca, newTestCert(c, "client", newTestCert(c, "other", nil))).Get("https://" + addr + "/healthz")
+		c.Assert(err, qt.IsNotNil)
+	})
+	t.Run("driver without TLS", func(t *testing.T) {
+		c := qt.New(t)
+
+		s := New(http.NewServeMux(), nopDriver{}, zerolog.Nop())
+		s.Addr = ":0"
+		s.TLS = &TLS{}
+		err := s.ListenAndServe()
+		c.Assert(err, qt.ErrorMatches, ".*does not support TLS.*")
+	})
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gilcrest/diygoapi"
+)
+
+var tracer = otel.Tracer("github.com/gilcrest/diygoapi/server")
+
+// tracingHandler middleware starts a span for the request, named by the
+// handler pattern added to the request context by
+// addRequestHandlerPatternContextHandler. W3C trace context sent by the
+// caller is continued. The request_id is added to the span and the
+// trace_id and span_id are added to the request logger, so logs and
+// traces of a request can be found from one another.
+func (s *Server) tracingHandler(h http.Handler) http.Handler {
+	correlate := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+		if id, ok := hlog.IDFromRequest(r); ok {
+			span.SetAttributes(attribute.String("request_id", id.String()))
+		}
+		if sc := span.SpanContext(); sc.IsValid() {
+			hlog.FromRequest(r).UpdateContext(func(c zerolog.Context) zerolog.Context {
+				return c.Str("trace_id", sc.TraceID().String()).Str("span_id", sc.SpanID().String())
+			})
+		}
+		h.ServeHTTP(w, r)
+	})
+
+	return otelhttp.NewHandler(correlate, "",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			pattern, err := diygoapi.RequestHandlerPatternFromContext(r.Context())
+			if err != nil {
+				return r.Method
+			}
+			return pattern
+		}))
+}
+
+// startMiddlewareSpan starts a child span of the request span for an
+// authentication or authorization middleware. Only calls made by the
+// middleware itself should use the returned context, so the spans of
+// downstream handlers are not nested under it.
+func startMiddlewareSpan(r *http.Request, name string) (*http.Request, trace.Span) {
+	ctx, span := tracer.Start(r.Context(), name)
+	return r.WithContext(ctx), span
+}
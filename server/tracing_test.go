@@ -0,0 +1,76 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/justinas/alice"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestServer_tracingHandler(t *testing.T) {
+	c := qt.New(t)
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	prevTP, prevProp, prevTracer := otel.GetTracerProvider(), otel.GetTextMapPropagator(), tracer
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	// the package tracer only follows the first global provider set,
+	// so it is replaced for tests run more than once
+	tracer = tp.Tracer("github.com/gilcrest/diygoapi/server")
+	c.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+		tracer = prevTracer
+	})
+
+	var buf bytes.Buffer
+	s := New(http.NewServeMux(), nopDriver{}, zerolog.New(&buf))
+
+	const pattern = "GET /test/{extlID}"
+	s.mux.Handle(pattern,
+		alice.New(hlog.NewHandler(s.Logger), hlog.RequestIDHandler("request_id", "Request-Id")).
+			Append(s.addRequestHandlerPatternContextHandler).
+			Append(s.tracingHandler).
+			ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+				// a middleware span is a child of the request span
+				_, span := startMiddlewareSpan(r, "server/appHandler")
+				span.End()
+				hlog.FromRequest(r).Info().Msg("handled")
+				_, _ = io.WriteString(w, "ok")
+			}))
+
+	// the caller's trace is continued
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/test/a", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	s.mux.ServeHTTP(w, req)
+	c.Assert(w.Code, qt.Equals, http.StatusOK)
+
+	spans := sr.Ended()
+	c.Assert(spans, qt.HasLen, 2)
+	child, root := spans[0], spans[1]
+	c.Assert(root.Name(), qt.Equals, pattern)
+	c.Assert(root.SpanContext().TraceID().String(), qt.Equals, traceID)
+	c.Assert(root.Parent().SpanID().String(), qt.Equals, "00f067aa0ba902b7")
+	c.Assert(child.Name(), qt.Equals, "server/appHandler")
+	c.Assert(child.Parent().SpanID(), qt.Equals, root.SpanContext().SpanID())
+
+	// the request id is on the span and the trace id in the logs
+	requestID := w.Header().Get("Request-Id")
+	c.Assert(root.Attributes(), qt.Contains, attribute.String("request_id", requestID))
+	c.Assert(buf.String(), qt.Contains, `"trace_id":"`+traceID+`"`)
+	c.Assert(buf.String(), qt.Contains, `"request_id":"`+requestID+`"`)
+}
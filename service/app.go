@@ -12,6 +12,7 @@ import (
 	"github.com/gilcrest/diygoapi/errs"
 	"github.com/gilcrest/diygoapi/secure"
 	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
 	"github.com/gilcrest/diygoapi/uuid"
 )
 
@@ -55,12 +56,18 @@ type AppService struct {
 	Datastorer      diygoapi.Datastorer
 	APIKeyGenerator diygoapi.APIKeyGenerator
 	EncryptionKey   *[32]byte
+	// DeleteRetention is how long a deleted app can be restored.
+	// diygoapi.DefaultDeleteRetention is used if not set.
+	DeleteRetention time.Duration
 }
 
 // Create is used to create an App
 func (s *AppService) Create(ctx context.Context, r *diygoapi.CreateAppRequest, adt diygoapi.Audit) (ar *diygoapi.AppResponse, err error) {
 	const op errs.Op = "service/AppService.Create"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	var (
 		a  *diygoapi.App
 		aa appAudit
@@ -105,6 +112,17 @@ func (s *AppService) Create(ctx context.Context, r *diygoapi.CreateAppRequest, a
 		return nil, errs.E(op, err)
 	}
 
+	err = emitEventTx(ctx, tx, eventParams{
+		Type:          diygoapi.AppCreatedEvent,
+		OrgID:         a.Org.ID,
+		SubjectExtlID: a.ExternalID.String(),
+		Data:          newAppImage(*a),
+		Moment:        adt.Moment,
+	})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
@@ -219,6 +237,28 @@ func createAppTx(ctx context.Context, tx pgx.Tx, aa appAudit) (err error) {
 		if apiKeyRowsAffected != 1 {
 			return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", apiKeyRowsAffected))
 		}
+
+		// the key itself is never written, only when it deactivates
+		e := diygoapi.NewSecurityEvent(diygoapi.CreateAPIKeySecurityAction, diygoapi.SuccessSecurityOutcome, aa.SimpleAudit.Create)
+		e.Org = aa.App.Org
+		e.Target = aa.App.ExternalID.String()
+		e.Detail = "deactivation date: " + key.DeactivationDate().Format(time.DateOnly)
+		err = writeSecurityEventTx(ctx, tx, e)
+		if err != nil {
+			return errs.E(op, err)
+		}
+	}
+
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.AppEntityType,
+		EntityID:     aa.App.ID,
+		EntityExtlID: aa.App.ExternalID.String(),
+		Action:       diygoapi.CreateChangeAction,
+		After:        newAppImage(*aa.App),
+		Audit:        aa.SimpleAudit.Create,
+	})
+	if err != nil {
+		return errs.E(op, err)
 	}
 
 	return nil
@@ -228,6 +268,9 @@ func createAppTx(ctx context.Context, tx pgx.Tx, aa appAudit) (err error) {
 func (s *AppService) Update(ctx context.Context, r *diygoapi.UpdateAppRequest, adt diygoapi.Audit) (ar *diygoapi.AppResponse, err error) {
 	const op errs.Op = "service/AppService.Update"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -251,6 +294,9 @@ func (s *AppService) Update(ctx context.Context, r *diygoapi.UpdateAppRequest, a
 	// overwrite Update audit with the current audit
 	aa.SimpleAudit.Update = adt
 
+	// capture the app as it exists prior to the update for change history
+	before := newAppImage(*aa.App)
+
 	// override fields with data from request
 	aa.App.Name = r.Name
 	aa.App.Description = r.Description
@@ -274,6 +320,30 @@ func (s *AppService) Update(ctx context.Context, r *diygoapi.UpdateAppRequest, a
 		return nil, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
 	}
 
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.AppEntityType,
+		EntityID:     aa.App.ID,
+		EntityExtlID: aa.App.ExternalID.String(),
+		Action:       diygoapi.UpdateChangeAction,
+		Before:       before,
+		After:        newAppImage(*aa.App),
+		Audit:        adt,
+	})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = emitEventTx(ctx, tx, eventParams{
+		Type:          diygoapi.AppUpdatedEvent,
+		OrgID:         aa.App.Org.ID,
+		SubjectExtlID: aa.App.ExternalID.String(),
+		Data:          newAppImage(*aa.App),
+		Moment:        adt.Moment,
+	})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
@@ -283,10 +353,14 @@ func (s *AppService) Update(ctx context.Context, r *diygoapi.UpdateAppRequest, a
 	return newAppResponse(aa), nil
 }
 
-// Delete is used to delete an App
-func (s *AppService) Delete(ctx context.Context, extlID string) (dr diygoapi.DeleteResponse, err error) {
+// Delete is used to delete an App. The App is marked as deleted
+// and can be restored until the retention period has passed.
+func (s *AppService) Delete(ctx context.Context, extlID string, adt diygoapi.Audit) (dr diygoapi.DeleteResponse, err error) {
 	const op errs.Op = "service/AppService.Delete"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -308,7 +382,7 @@ func (s *AppService) Delete(ctx context.Context, extlID string) (dr diygoapi.Del
 		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
 	}
 
-	err = deleteAppTx(ctx, tx, a)
+	err = deleteAppTx(ctx, tx, a, adt)
 	if err != nil {
 		return diygoapi.DeleteResponse{}, errs.E(op, err)
 	}
@@ -327,23 +401,136 @@ func (s *AppService) Delete(ctx context.Context, extlID string) (dr diygoapi.Del
 	return response, nil
 }
 
-func deleteAppTx(ctx context.Context, tx pgx.Tx, a diygoapi.App) (err error) {
+// deleteAppTx marks an App as deleted and records the deletion in
+// the change history. The App's API keys are left in place so they
+// work again if the App is restored, but a deleted App can not be
+// found and so can not authenticate.
+func deleteAppTx(ctx context.Context, tx pgx.Tx, a diygoapi.App, adt diygoapi.Audit) (err error) {
 	const op errs.Op = "service/deleteAppTx"
 
-	// one-to-many API keys can be associated with an App. This will
-	// delete them all.
-	var apiKeysRowsAffected int64
-	apiKeysRowsAffected, err = datastore.New(tx).DeleteAppAPIKeys(ctx, a.ID.PgxUUID())
+	softDeleteAppParams := datastore.SoftDeleteAppParams{
+		DeleteAppID:     adt.App.ID.PgxUUID(),
+		DeleteUserID:    adt.User.ID.PgxUUID(),
+		DeleteTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+		AppID:           a.ID.PgxUUID(),
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).SoftDeleteApp(ctx, softDeleteAppParams)
 	if err != nil {
 		return errs.E(op, errs.Database, err)
 	}
 
-	if apiKeysRowsAffected < 1 {
-		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be at least 1, actual: %d", apiKeysRowsAffected))
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.AppEntityType,
+		EntityID:     a.ID,
+		EntityExtlID: a.ExternalID.String(),
+		Action:       diygoapi.DeleteChangeAction,
+		Before:       newAppImage(a),
+		Audit:        adt,
+	})
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	err = emitEventTx(ctx, tx, eventParams{
+		Type:          diygoapi.AppDeletedEvent,
+		OrgID:         a.Org.ID,
+		SubjectExtlID: a.ExternalID.String(),
+		Data:          newAppImage(a),
+		Moment:        adt.Moment,
+	})
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	return nil
+}
+
+// Restore is used to restore a deleted App, provided it was
+// deleted within the retention period. An App which was deleted
+// along with its Org is restored by restoring the Org.
+func (s *AppService) Restore(ctx context.Context, extlID string, adt diygoapi.Audit) (ar *diygoapi.AppResponse, err error) {
+	const op errs.Op = "service/AppService.Restore"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	// retrieve deleted App
+	var row datastore.FindDeletedAppByExternalIDRow
+	row, err = datastore.New(tx).FindDeletedAppByExternalID(ctx, extlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.Validation, "No deleted app exists for the given external ID")
+		}
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if row.OrgDeleteTimestamp.Valid {
+		return nil, errs.E(op, errs.Validation, "the org for this app is deleted, restore the org instead")
+	}
+
+	if !restorable(row.DeleteTimestamp.Time, s.DeleteRetention, adt.Moment) {
+		return nil, errs.E(op, errs.Validation, "app was deleted before the retention period and can no longer be restored")
+	}
+
+	a := diygoapi.App{
+		ID:          row.AppID.Bytes,
+		ExternalID:  secure.MustParseIdentifier(row.AppExtlID),
+		Org:         &diygoapi.Org{ID: row.OrgID.Bytes, ExternalID: secure.MustParseIdentifier(row.OrgExtlID)},
+		Name:        row.AppName,
+		Description: row.AppDescription,
+	}
+
+	err = restoreAppTx(ctx, tx, a, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var aa appAudit
+	aa, err = findAppByExternalIDWithAudit(ctx, tx, extlID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newAppResponse(aa), nil
+}
+
+// restoreAppTx clears the deleted mark of an App and records the
+// restore in the change history.
+func restoreAppTx(ctx context.Context, tx pgx.Tx, a diygoapi.App, adt diygoapi.Audit) (err error) {
+	const op errs.Op = "service/restoreAppTx"
+
+	restoreAppParams := datastore.RestoreAppParams{
+		UpdateAppID:     adt.App.ID.PgxUUID(),
+		UpdateUserID:    adt.User.ID.PgxUUID(),
+		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+		AppID:           a.ID.PgxUUID(),
 	}
 
 	var rowsAffected int64
-	rowsAffected, err = datastore.New(tx).DeleteApp(ctx, a.ID.PgxUUID())
+	rowsAffected, err = datastore.New(tx).RestoreApp(ctx, restoreAppParams)
 	if err != nil {
 		return errs.E(op, errs.Database, err)
 	}
@@ -352,6 +539,29 @@ func deleteAppTx(ctx context.Context, tx pgx.Tx, a diygoapi.App) (err error) {
 		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
 	}
 
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.AppEntityType,
+		EntityID:     a.ID,
+		EntityExtlID: a.ExternalID.String(),
+		Action:       diygoapi.RestoreChangeAction,
+		After:        newAppImage(a),
+		Audit:        adt,
+	})
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	err = emitEventTx(ctx, tx, eventParams{
+		Type:          diygoapi.AppRestoredEvent,
+		OrgID:         a.Org.ID,
+		SubjectExtlID: a.ExternalID.String(),
+		Data:          newAppImage(a),
+		Moment:        adt.Moment,
+	})
+	if err != nil {
+		return errs.E(op, err)
+	}
+
 	return nil
 }
 
@@ -359,6 +569,9 @@ func deleteAppTx(ctx context.Context, tx pgx.Tx, a diygoapi.App) (err error) {
 func (s *AppService) FindByExternalID(ctx context.Context, extlID string) (ar *diygoapi.AppResponse, err error) {
 	const op errs.Op = "service/AppService.FindByExternalID"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -383,6 +596,9 @@ func (s *AppService) FindByExternalID(ctx context.Context, extlID string) (ar *d
 func (s *AppService) FindAll(ctx context.Context) (sar []*diygoapi.AppResponse, err error) {
 	const op errs.Op = "service/AppService.FindAll"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
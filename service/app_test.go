@@ -245,7 +245,7 @@ func TestAppService(t *testing.T) {
 		}
 
 		var got diygoapi.DeleteResponse
-		got, err = s.Delete(context.Background(), testAppRow.AppExtlID)
+		got, err = s.Delete(context.Background(), testAppRow.AppExtlID, adt)
 		want := diygoapi.DeleteResponse{
 			ExternalID: testAppRow.AppExtlID,
 			Deleted:    true,
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -17,8 +18,10 @@ import (
 
 	"github.com/gilcrest/diygoapi"
 	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/logger"
 	"github.com/gilcrest/diygoapi/secure"
 	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
 	"github.com/gilcrest/diygoapi/uuid"
 )
 
@@ -29,18 +32,56 @@ type DBAuthenticationService struct {
 	TokenExchanger  diygoapi.TokenExchanger
 	EncryptionKey   *[32]byte
 	LanguageMatcher language.Matcher
+	// FailureEventLimiter, if set, throttles the security events
+	// recorded for failed authentications from the same remote address,
+	// so a flood of bad credentials cannot flood the security_event
+	// table as well. Failures over the limit are only logged.
+	FailureEventLimiter diygoapi.RateLimiter
+}
+
+// failureEventLimit is the rate at which failed authentications of one
+// remote address are recorded as security events
+var failureEventLimit = diygoapi.RateLimit{RequestsPerMinute: 10, Burst: 20}
+
+// recordFailure records the security event of a failed authentication,
+// unless the remote address of the request has failed more often than
+// failureEventLimit allows.
+func (s DBAuthenticationService) recordFailure(ctx context.Context, r *http.Request, e diygoapi.SecurityEvent) {
+	if s.FailureEventLimiter != nil {
+		source := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			source = host
+		}
+		res, err := s.FailureEventLimiter.Allow(ctx, "security_event:"+string(e.Action)+":"+source, failureEventLimit)
+		if err == nil && !res.Allowed {
+			zerolog.Ctx(ctx).Warn().Str("action", string(e.Action)).Str("source", source).Msg("security event not recorded, too many failed authentications")
+			return
+		}
+	}
+	recordSecurityEvent(ctx, s.Datastorer, e)
 }
 
 // FindAppByAPIKey finds an app given its External ID and determines
 // if the given API key is a valid key for it. It is used as part of
 // app authentication
-func (s DBAuthenticationService) FindAppByAPIKey(r *http.Request, realm string) (*diygoapi.App, error) {
+func (s DBAuthenticationService) FindAppByAPIKey(r *http.Request, realm string) (a *diygoapi.App, err error) {
 	const op errs.Op = "service/DBAuthenticationService.FindAppByAPIKey"
 
-	var (
-		appExtlID string
-		err       error
-	)
+	ctx, span := tracer.Start(r.Context(), string(op))
+	defer span.End()
+
+	// app authentication is optional, so only a failed attempt, not
+	// missing headers, is a security event
+	defer func() {
+		if errs.KindIs(errs.Unauthenticated, err) {
+			e := diygoapi.NewSecurityEvent(diygoapi.AuthenticateAppSecurityAction, diygoapi.FailureSecurityOutcome, diygoapi.Audit{})
+			e.Target = r.Header.Get(diygoapi.AppIDHeaderKey)
+			e.Detail = err.Error()
+			s.recordFailure(ctx, r, e)
+		}
+	}()
+
+	var appExtlID string
 	appExtlID, err = parseAppHeader(realm, r.Header, diygoapi.AppIDHeaderKey)
 	if err != nil {
 		return nil, errs.E(op, err)
@@ -52,8 +93,7 @@ func (s DBAuthenticationService) FindAppByAPIKey(r *http.Request, realm string)
 		return nil, errs.E(op, err)
 	}
 
-	var a *diygoapi.App
-	a, err = s.findAppByAPIKeyDB(r.Context(), realm, appExtlID, apiKey)
+	a, err = s.findAppByAPIKeyDB(ctx, realm, appExtlID, apiKey)
 	if err != nil {
 		return nil, errs.E(op, err)
 	}
@@ -76,13 +116,24 @@ func (s DBAuthenticationService) FindAppByAPIKey(r *http.Request, realm string)
 // exception is if an app is already set to the request context from upstream
 // authentication, in which case, the upstream app overrides the app derived
 // from the Oauth2 provider.
-func (s DBAuthenticationService) FindExistingAuth(r *http.Request, realm string) (diygoapi.Auth, error) {
+func (s DBAuthenticationService) FindExistingAuth(r *http.Request, realm string) (_ diygoapi.Auth, err error) {
 	const op errs.Op = "service/DBAuthenticationService.FindExistingAuth"
 
-	var (
-		provider diygoapi.Provider
-		err      error
-	)
+	ctx, span := tracer.Start(r.Context(), string(op))
+	defer span.End()
+
+	defer func() {
+		if errs.KindIs(errs.Unauthenticated, err) {
+			// the app is known if authenticated by API key upstream
+			a, _ := diygoapi.AppFromContext(ctx)
+			e := diygoapi.NewSecurityEvent(diygoapi.AuthenticateUserSecurityAction, diygoapi.FailureSecurityOutcome, diygoapi.Audit{App: a})
+			e.Target = r.Header.Get(diygoapi.AuthProviderHeaderKey)
+			e.Detail = err.Error()
+			s.recordFailure(ctx, r, e)
+		}
+	}()
+
+	var provider diygoapi.Provider
 
 	// get the X-AUTH-PROVIDER header value (e.g. Google, Github, etc.)
 	provider, err = parseProviderHeader(realm, r.Header)
@@ -103,7 +154,7 @@ func (s DBAuthenticationService) FindExistingAuth(r *http.Request, realm string)
 	}
 
 	var auth diygoapi.Auth
-	auth, err = s.findAuthDB(r.Context(), params)
+	auth, err = s.findAuthDB(ctx, params)
 	if err != nil {
 		return diygoapi.Auth{}, errs.E(op, err)
 	}
@@ -171,6 +222,10 @@ func (s DBAuthenticationService) findAuthDB(ctx context.Context, params *diygoap
 func (s DBAuthenticationService) DetermineAppContext(ctx context.Context, auth diygoapi.Auth, realm string) (context.Context, error) {
 	const op errs.Op = "server/Server.determineAppContext"
 
+	// the span is kept out of the returned context, as it ends here
+	spanCtx, span := tracer.Start(ctx, "service/DBAuthenticationService.DetermineAppContext")
+	defer span.End()
+
 	var (
 		a   *diygoapi.App
 		err error
@@ -178,8 +233,12 @@ func (s DBAuthenticationService) DetermineAppContext(ctx context.Context, auth d
 	_, err = diygoapi.AppFromContext(ctx)
 	if err != nil {
 		// no app found in request, lookup app from Auth
-		a, err = s.FindAppByProviderClientID(ctx, realm, auth)
+		a, err = s.FindAppByProviderClientID(spanCtx, realm, auth)
 		if err != nil {
+			e := diygoapi.NewSecurityEvent(diygoapi.AuthenticateAppSecurityAction, diygoapi.FailureSecurityOutcome, diygoapi.Audit{User: auth.User})
+			e.Target = auth.ProviderClientID
+			e.Detail = err.Error()
+			recordSecurityEvent(spanCtx, s.Datastorer, e)
 			return nil, errs.E(op, err)
 		}
 
@@ -323,6 +382,9 @@ func findAuthByProviderExternalID(ctx context.Context, tx pgx.Tx, params findAut
 func (s DBAuthenticationService) FindAppByProviderClientID(ctx context.Context, realm string, auth diygoapi.Auth) (a *diygoapi.App, err error) {
 	const op errs.Op = "service/DBAuthenticationService.FindAppByProviderClientID"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -417,6 +479,9 @@ func (s DBAuthenticationService) findAppByAPIKeyDB(ctx context.Context, realm, a
 func (s DBAuthenticationService) AuthenticationParamExchange(ctx context.Context, params *diygoapi.AuthenticationParams) (*diygoapi.ProviderInfo, error) {
 	const op errs.Op = "service/DBAuthenticationService.TokenExchange"
 
+	ctx, span := tracer.Start(ctx, "service/DBAuthenticationService.AuthenticationParamExchange")
+	defer span.End()
+
 	providerInfo, err := s.TokenExchanger.Exchange(ctx, params.Realm, params.Provider, params.Token)
 	if err != nil {
 		return nil, errs.E(op, err)
@@ -436,6 +501,9 @@ func (s DBAuthenticationService) AuthenticationParamExchange(ctx context.Context
 func (s DBAuthenticationService) SelfRegister(ctx context.Context, params *diygoapi.AuthenticationParams) (ur *diygoapi.UserResponse, err error) {
 	const op errs.Op = "service/DBAuthenticationService.SelfRegister"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -777,7 +845,10 @@ type DBAuthorizationService struct {
 func (s *DBAuthorizationService) Authorize(r *http.Request, lgr zerolog.Logger, adt diygoapi.Audit) (err error) {
 	const op errs.Op = "service/DBAuthorizationService.Authorize"
 
-	ctx := r.Context()
+	lgr = logger.ForComponent(r.Context(), lgr, string(op))
+
+	ctx, span := tracer.Start(r.Context(), string(op))
+	defer tracing.EndSpan(span, &err)
 
 	// Retrieve the handler pattern added to the request context as part of
 	// the server middleware chain when routing requests.
@@ -817,10 +888,11 @@ func (s *DBAuthorizationService) Authorize(r *http.Request, lgr zerolog.Logger,
 
 	// call IsAuthorized method to validate user has access to the resource and operation
 	var authorizedID pgtype.UUID
-	authorizedID, err = datastore.New(tx).IsAuthorized(r.Context(), arg)
+	authorizedID, err = datastore.New(tx).IsAuthorized(ctx, arg)
 	if err != nil {
 		lgr.Info().Str("user_extl_id", adt.User.ExternalID.String()).Str("resource", resource).Str("operation", r.Method).
 			Msgf("Unauthorized (user_extl_id: %s, resource: %s, operation: %s)", adt.User.ExternalID.String(), resource, r.Method)
+		s.recordDenial(ctx, adt, handlerPattern)
 
 		// "In summary, a 401 Unauthorized response should be used for missing or
 		// bad authentication, and a 403 Forbidden response should be used afterward,
@@ -834,6 +906,7 @@ func (s *DBAuthorizationService) Authorize(r *http.Request, lgr zerolog.Logger,
 	if authorizedID.Bytes == uuid.Nil {
 		lgr.Info().Str("user_extl_id", adt.User.ExternalID.String()).Str("resource", resource).Str("operation", r.Method).
 			Msgf("Unauthorized (user_extl_id: %s, resource: %s, operation: %s)", adt.User.ExternalID.String(), resource, r.Method)
+		s.recordDenial(ctx, adt, handlerPattern)
 
 		// "In summary, a 401 Unauthorized response should be used for missing or
 		// bad authentication, and a 403 Forbidden response should be used afterward,
@@ -850,6 +923,19 @@ func (s *DBAuthorizationService) Authorize(r *http.Request, lgr zerolog.Logger,
 	return nil
 }
 
+// recordDenial records the user of the audit being denied access to
+// the handler pattern as a security event, unless the authorization
+// was only a probe
+func (s *DBAuthorizationService) recordDenial(ctx context.Context, adt diygoapi.Audit, handlerPattern string) {
+	if diygoapi.AuthorizationProbeFromContext(ctx) {
+		return
+	}
+
+	e := diygoapi.NewSecurityEvent(diygoapi.AuthorizeSecurityAction, diygoapi.DeniedSecurityOutcome, adt)
+	e.Target = handlerPattern
+	recordSecurityEvent(ctx, s.Datastorer, e)
+}
+
 // PermissionService is a service for creating, reading, updating and deleting a Permission
 type PermissionService struct {
 	Datastorer diygoapi.Datastorer
@@ -859,6 +945,9 @@ type PermissionService struct {
 func (s *PermissionService) Create(ctx context.Context, r *diygoapi.CreatePermissionRequest, adt diygoapi.Audit) (response *diygoapi.PermissionResponse, err error) {
 	const op errs.Op = "service/PermissionService.Create"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -944,6 +1033,18 @@ func createPermissionTx(ctx context.Context, tx pgx.Tx, r *diygoapi.CreatePermis
 		return diygoapi.Permission{}, errs.E(op, errs.Database, fmt.Sprintf("Create() should insert 1 row, actual: %d", rowsAffected))
 	}
 
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.PermissionEntityType,
+		EntityID:     p.ID,
+		EntityExtlID: p.ExternalID.String(),
+		Action:       diygoapi.CreateChangeAction,
+		After:        newPermissionImage(p),
+		Audit:        adt,
+	})
+	if err != nil {
+		return diygoapi.Permission{}, errs.E(op, err)
+	}
+
 	return p, nil
 }
 
@@ -951,6 +1052,9 @@ func createPermissionTx(ctx context.Context, tx pgx.Tx, r *diygoapi.CreatePermis
 func (s *PermissionService) FindAll(ctx context.Context) (permissions []*diygoapi.PermissionResponse, err error) {
 	const op errs.Op = "service/PermissionService.FindAll"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -984,9 +1088,12 @@ func (s *PermissionService) FindAll(ctx context.Context) (permissions []*diygoap
 }
 
 // Delete is used to delete a Permission
-func (s *PermissionService) Delete(ctx context.Context, extlID string) (dr diygoapi.DeleteResponse, err error) {
+func (s *PermissionService) Delete(ctx context.Context, extlID string, adt diygoapi.Audit) (dr diygoapi.DeleteResponse, err error) {
 	const op errs.Op = "service/PermissionService.Delete"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -998,6 +1105,16 @@ func (s *PermissionService) Delete(ctx context.Context, extlID string) (dr diygo
 		err = s.Datastorer.RollbackTx(ctx, tx, err)
 	}()
 
+	// retrieve existing Permission
+	var ap datastore.Permission
+	ap, err = datastore.New(tx).FindPermissionByExternalID(ctx, extlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return diygoapi.DeleteResponse{}, errs.E(op, errs.Validation, "No permission exists for the given external ID")
+		}
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
 	var rowsAffected int64
 	rowsAffected, err = datastore.New(tx).DeletePermissionByExternalID(ctx, extlID)
 	if err != nil {
@@ -1008,6 +1125,19 @@ func (s *PermissionService) Delete(ctx context.Context, extlID string) (dr diygo
 		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
 	}
 
+	p := newPermission(ap)
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.PermissionEntityType,
+		EntityID:     p.ID,
+		EntityExtlID: p.ExternalID.String(),
+		Action:       diygoapi.DeleteChangeAction,
+		Before:       newPermissionImage(*p),
+		Audit:        adt,
+	})
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
@@ -1043,6 +1173,9 @@ type RoleService struct {
 func (s *RoleService) Create(ctx context.Context, r *diygoapi.CreateRoleRequest, adt diygoapi.Audit) (response *diygoapi.RoleResponse, err error) {
 	const op errs.Op = "service/RoleService.Create"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -1129,6 +1262,30 @@ func createRoleTx(ctx context.Context, tx pgx.Tx, role diygoapi.Role, adt diygoa
 		return errs.E(op, err)
 	}
 
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.RoleEntityType,
+		EntityID:     role.ID,
+		EntityExtlID: role.ExternalID.String(),
+		Action:       diygoapi.CreateChangeAction,
+		After:        newRoleImage(role),
+		Audit:        adt,
+	})
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		permissions = append(permissions, p.Operation+" "+p.Resource)
+	}
+	e := diygoapi.NewSecurityEvent(diygoapi.CreateRoleSecurityAction, diygoapi.SuccessSecurityOutcome, adt)
+	e.Target = role.Code
+	e.Detail = "permissions: " + strings.Join(permissions, ", ")
+	err = writeSecurityEventTx(ctx, tx, e)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
 	return nil
 }
 
@@ -1251,6 +1408,15 @@ func grantOrgRole(ctx context.Context, tx pgx.Tx, p grantOrgRoleParams) (err err
 		return errs.E(op, errs.Database, fmt.Sprintf("CreateUsersRole() should insert 1 row, actual: %d", rowsAffected))
 	}
 
+	e := diygoapi.NewSecurityEvent(diygoapi.GrantRoleSecurityAction, diygoapi.SuccessSecurityOutcome, p.Audit)
+	e.Org = p.Org
+	e.Target = p.User.ExternalID.String()
+	e.Detail = "role: " + p.Role.Code
+	err = writeSecurityEventTx(ctx, tx, e)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
 	return nil
 }
 
@@ -1,14 +1,19 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	qt "github.com/frankban/quicktest"
 	"github.com/gilcrest/diygoapi"
 	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/ratelimit"
 	"github.com/google/go-cmp/cmp"
+	"github.com/jackc/pgx/v5"
 	"golang.org/x/oauth2"
 	"net/http"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func Test_parseAppHeader(t *testing.T) {
@@ -109,3 +114,90 @@ func Test_parseAuthorizationHeader(t *testing.T) {
 		})
 	}
 }
+
+// countingDatastorer is a diygoapi.Datastorer which counts the
+// transactions begun with it and fails to begin any
+type countingDatastorer struct {
+	diygoapi.Datastorer
+	begun int
+}
+
+func (d *countingDatastorer) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	d.begun++
+	return nil, errs.E(errs.Database, "no database")
+}
+
+func TestDBAuthorizationService_recordDenial(t *testing.T) {
+	adt := diygoapi.Audit{
+		App:  &diygoapi.App{Org: &diygoapi.Org{}},
+		User: &diygoapi.User{},
+	}
+
+	t.Run("denial", func(t *testing.T) {
+		c := qt.New(t)
+
+		ds := &countingDatastorer{}
+		s := DBAuthorizationService{Datastorer: ds}
+		s.recordDenial(context.Background(), adt, "PUT /api/v1/logger")
+		c.Assert(ds.begun, qt.Equals, 1)
+	})
+	t.Run("probe", func(t *testing.T) {
+		c := qt.New(t)
+
+		ds := &countingDatastorer{}
+		s := DBAuthorizationService{Datastorer: ds}
+		s.recordDenial(diygoapi.NewContextWithAuthorizationProbe(context.Background()), adt, "PUT /api/v1/logger")
+		c.Assert(ds.begun, qt.Equals, 0)
+	})
+}
+
+func TestDBAuthenticationService_recordFailure(t *testing.T) {
+	e := diygoapi.NewSecurityEvent(diygoapi.AuthenticateAppSecurityAction, diygoapi.FailureSecurityOutcome, diygoapi.Audit{})
+
+	t.Run("throttled by remote address", func(t *testing.T) {
+		c := qt.New(t)
+
+		ds := &countingDatastorer{}
+		s := DBAuthenticationService{Datastorer: ds, FailureEventLimiter: ratelimit.NewMemoryLimiter()}
+		r, err := http.NewRequest(http.MethodGet, "/api/v1/movies", nil)
+		c.Assert(err, qt.IsNil)
+		r.RemoteAddr = "192.0.2.1:1234"
+
+		for i := 0; i < failureEventLimit.Burst+5; i++ {
+			s.recordFailure(context.Background(), r, e)
+		}
+		c.Assert(ds.begun, qt.Equals, failureEventLimit.Burst)
+
+		// another port of the same host is the same source
+		r.RemoteAddr = "192.0.2.1:5678"
+		s.recordFailure(context.Background(), r, e)
+		c.Assert(ds.begun, qt.Equals, failureEventLimit.Burst)
+
+		r.RemoteAddr = "192.0.2.2:1234"
+		s.recordFailure(context.Background(), r, e)
+		c.Assert(ds.begun, qt.Equals, failureEventLimit.Burst+1)
+	})
+	t.Run("no limiter", func(t *testing.T) {
+		c := qt.New(t)
+
+		ds := &countingDatastorer{}
+		s := DBAuthenticationService{Datastorer: ds}
+		r, err := http.NewRequest(http.MethodGet, "/api/v1/movies", nil)
+		c.Assert(err, qt.IsNil)
+
+		for i := 0; i < failureEventLimit.Burst+5; i++ {
+			s.recordFailure(context.Background(), r, e)
+		}
+		c.Assert(ds.begun, qt.Equals, failureEventLimit.Burst+5)
+	})
+}
+
+func Test_truncateSecurityEventText(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(truncateSecurityEventText("google"), qt.Equals, "google")
+	long := strings.Repeat("é", maxSecurityEventTextLength+10)
+	got := truncateSecurityEventText(long)
+	c.Assert(utf8.RuneCountInString(got), qt.Equals, maxSecurityEventTextLength)
+	c.Assert(utf8.ValidString(got), qt.IsTrue)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+// allowedOriginsCacheTTL is how long the allowed origins of all Apps
+// are kept before they are read from the datastore again
+const allowedOriginsCacheTTL = time.Minute
+
+// CORSService finds the origins Apps allow browser requests from, set
+// in the app_allowed_origin table. All Apps' origins are read together
+// and cached, as preflight requests, which do not identify the App,
+// must be checked against every App's origins. A change to them
+// applies within allowedOriginsCacheTTL.
+type CORSService struct {
+	Datastorer diygoapi.Datastorer
+	Logger     zerolog.Logger
+
+	mu      sync.Mutex
+	origins map[uuid.UUID][]string
+	any     map[string]bool
+	expires time.Time
+}
+
+// AnyAppAllowsOrigin reports whether any App allows requests from
+// origin
+func (s *CORSService) AnyAppAllowsOrigin(ctx context.Context, origin string) (bool, error) {
+	const op errs.Op = "service/CORSService.AnyAppAllowsOrigin"
+
+	err := s.load(ctx)
+	if err != nil {
+		return false, errs.E(op, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.any[diygoapi.AnyOrigin] || s.any[origin], nil
+}
+
+// FindAppAllowedOrigins returns the origins the App allows, or nil if
+// it has none of its own
+func (s *CORSService) FindAppAllowedOrigins(ctx context.Context, a *diygoapi.App) ([]string, error) {
+	const op errs.Op = "service/CORSService.FindAppAllowedOrigins"
+
+	if a == nil {
+		return nil, errs.E(op, errs.Internal, "app is required")
+	}
+
+	err := s.load(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.origins[a.ID], nil
+}
+
+// load reads the allowed origins of all Apps from the datastore, unless
+// those cached have not expired
+func (s *CORSService) load(ctx context.Context) (err error) {
+	const op errs.Op = "service/CORSService.load"
+
+	now := time.Now()
+	s.mu.Lock()
+	fresh := now.Before(s.expires)
+	s.mu.Unlock()
+	if fresh {
+		return nil
+	}
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rows []datastore.AppAllowedOrigin
+	rows, err = datastore.New(tx).FindAppAllowedOrigins(ctx)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	origins := make(map[uuid.UUID][]string)
+	anyApp := make(map[string]bool)
+	for _, row := range rows {
+		origin, perr := diygoapi.ParseOrigin(row.Origin)
+		if perr != nil {
+			// one bad row should not stop every App's browser requests
+			s.Logger.Warn().Err(perr).Msg("invalid app_allowed_origin ignored")
+			continue
+		}
+		appID := uuid.UUID(row.AppID.Bytes)
+		origins[appID] = append(origins[appID], origin)
+		anyApp[origin] = true
+	}
+
+	s.mu.Lock()
+	s.origins, s.any, s.expires = origins, anyApp, now.Add(allowedOriginsCacheTTL)
+	s.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+// newCredits initializes Credits from CreditRequests. Each credit is
+// billed in the order it was given among the requests with the same role.
+func newCredits(reqs []diygoapi.CreditRequest) ([]diygoapi.Credit, error) {
+	const op errs.Op = "service/newCredits"
+
+	credits := make([]diygoapi.Credit, 0, len(reqs))
+	orders := make(map[diygoapi.CreditRole]int)
+	for _, r := range reqs {
+		role, err := diygoapi.ParseCreditRole(r.Role)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+
+		c := diygoapi.Credit{
+			Role:       role,
+			PersonName: strings.TrimSpace(r.Name),
+		}
+		if role == diygoapi.ActorCreditRole {
+			c.Character = strings.TrimSpace(r.Character)
+		}
+
+		orders[role]++
+		c.Order = orders[role]
+
+		credits = append(credits, c)
+	}
+
+	return credits, nil
+}
+
+// reconcileCredits makes the Director and Writer of a Movie agree with
+// its director and writer credits. Where there are credits for the
+// role, the summary is rebuilt from them. Where there are none, the
+// summary, if any, becomes the only credit for the role.
+func reconcileCredits(m *diygoapi.Movie) {
+	m.Director = reconcileCreditRole(m, diygoapi.DirectorCreditRole, m.Director)
+	m.Writer = reconcileCreditRole(m, diygoapi.WriterCreditRole, m.Writer)
+}
+
+func reconcileCreditRole(m *diygoapi.Movie, role diygoapi.CreditRole, summary string) string {
+	var names []string
+	for _, c := range m.Credits {
+		if c.Role == role {
+			names = append(names, c.PersonName)
+		}
+	}
+
+	if len(names) > 0 {
+		return strings.Join(names, ", ")
+	}
+
+	if summary != "" {
+		m.Credits = append(m.Credits, diygoapi.Credit{
+			Role:       role,
+			PersonName: summary,
+			Order:      1,
+		})
+	}
+
+	return summary
+}
+
+// withoutCreditRole returns the credits which are not for the given role
+func withoutCreditRole(credits []diygoapi.Credit, role diygoapi.CreditRole) []diygoapi.Credit {
+	var kept []diygoapi.Credit
+	for _, c := range credits {
+		if c.Role != role {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// newCreditResponses initializes CreditResponses. An empty slice is
+// returned rather than nil so a movie without credits is rendered as
+// an empty JSON array.
+func newCreditResponses(credits []diygoapi.Credit) []diygoapi.CreditResponse {
+	responses := make([]diygoapi.CreditResponse, 0, len(credits))
+	for _, c := range credits {
+		responses = append(responses, diygoapi.CreditResponse{
+			Role:      string(c.Role),
+			Name:      c.PersonName,
+			Character: c.Character,
+			Order:     c.Order,
+		})
+	}
+	return responses
+}
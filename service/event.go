@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+// eventParams is the parameters for emitting a domain event. OrgID is
+// the org the event belongs to, whose webhooks are sent it. Data is
+// the entity the event is about, marshaled to JSON.
+type eventParams struct {
+	Type          diygoapi.EventType
+	OrgID         uuid.UUID
+	SubjectExtlID string
+	Data          any
+	Moment        time.Time
+}
+
+// webhookDeliveryJob is the payload of a webhook delivery job
+type webhookDeliveryJob struct {
+	DeliveryExtlID string `json:"delivery_extl_id"`
+}
+
+// emitEventTx emits a single domain event, see emitEventsTx
+func emitEventTx(ctx context.Context, tx pgx.Tx, p eventParams) error {
+	return emitEventsTx(ctx, tx, []eventParams{p})
+}
+
+// emitEventsTx writes domain events to the outbox and queues a
+// delivery to each webhook subscribed to them, all using the same
+// transaction as the change the events describe. Nothing is published
+// unless the change is committed, and nothing committed goes
+// unpublished.
+func emitEventsTx(ctx context.Context, tx pgx.Tx, events []eventParams) (err error) {
+	const op errs.Op = "service/emitEventsTx"
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	args := make([]datastore.CreateEventsParams, 0, len(events))
+	for _, e := range events {
+		var data []byte
+		data, err = json.Marshal(e.Data)
+		if err != nil {
+			return errs.E(op, errs.Internal, err)
+		}
+
+		args = append(args, datastore.CreateEventsParams{
+			EventID:         uuid.New().PgxUUID(),
+			ExtlID:          secure.NewID().String(),
+			EventType:       string(e.Type),
+			OrgID:           e.OrgID.PgxUUID(),
+			SubjectExtlID:   e.SubjectExtlID,
+			Payload:         data,
+			CreateTimestamp: diygoapi.NewPgxTimestampTZ(e.Moment),
+		})
+	}
+
+	q := datastore.New(tx)
+
+	q.CreateEvents(ctx, args).Exec(firstBatchError(&err))
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	// subscribed webhooks are found once for each org and event type,
+	// as an import emits many events of the same type
+	type subscription struct {
+		orgID     uuid.UUID
+		eventType diygoapi.EventType
+	}
+	subscribers := make(map[subscription][]datastore.Webhook)
+
+	for i, e := range events {
+		key := subscription{orgID: e.OrgID, eventType: e.Type}
+
+		webhooks, ok := subscribers[key]
+		if !ok {
+			webhooks, err = q.FindWebhooksForEvent(ctx, datastore.FindWebhooksForEventParams{
+				OrgID:     e.OrgID.PgxUUID(),
+				EventType: string(e.Type),
+			})
+			if err != nil {
+				return errs.E(op, errs.Database, err)
+			}
+			subscribers[key] = webhooks
+		}
+
+		for _, wh := range webhooks {
+			err = createWebhookDeliveryTx(ctx, tx, wh.WebhookID, args[i].EventID, e.Moment)
+			if err != nil {
+				return errs.E(op, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// createWebhookDeliveryTx records the delivery of an event to a
+// webhook and queues the job which sends it
+func createWebhookDeliveryTx(ctx context.Context, tx pgx.Tx, webhookID, eventID pgtype.UUID, moment time.Time) error {
+	const op errs.Op = "service/createWebhookDeliveryTx"
+
+	extlID := secure.NewID().String()
+
+	rowsAffected, err := datastore.New(tx).CreateWebhookDelivery(ctx, datastore.CreateWebhookDeliveryParams{
+		DeliveryID:      uuid.New().PgxUUID(),
+		ExtlID:          extlID,
+		WebhookID:       webhookID,
+		EventID:         eventID,
+		CreateTimestamp: diygoapi.NewPgxTimestampTZ(moment),
+		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(moment),
+	})
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	j, err := diygoapi.NewJob(diygoapi.WebhookDeliveryJobKind, webhookDeliveryJob{DeliveryExtlID: extlID})
+	if err != nil {
+		return errs.E(op, err)
+	}
+	j.MaxAttempts = diygoapi.WebhookDeliveryMaxAttempts
+
+	err = EnqueueJobTx(ctx, tx, j)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	return nil
+}
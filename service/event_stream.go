@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+)
+
+// eventOutboxChannel is the channel notified by the event_outbox
+// table trigger when events are written
+const eventOutboxChannel = "event_outbox"
+
+// DefaultEventHeartbeatInterval is how long an event stream is left
+// idle before a heartbeat is sent. The outbox is read again at the same
+// time, in case a notification was missed.
+const DefaultEventHeartbeatInterval = 15 * time.Second
+
+// eventStreamBatchSize is the number of events read from the outbox
+// at a time
+const eventStreamBatchSize = 100
+
+// eventBrokerRetryInterval is how long the EventBroker waits before
+// listening again after its connection fails
+const eventBrokerRetryInterval = 5 * time.Second
+
+// eventReadPermissions are the permissions which allow a user to be
+// streamed the events of an entity, by entity type. They are the
+// permissions to read the entity itself.
+var eventReadPermissions = map[string]struct{ resource, operation string }{
+	"movie": {resource: "/api/v1/movies/{extlID}", operation: "GET"},
+	"org":   {resource: "/api/v1/orgs/{extlID}", operation: "GET"},
+	"app":   {resource: "/api/v1/apps/{extlID}/history", operation: "GET"},
+}
+
+// EventBroker tells event streams when events are written. It holds a
+// single connection listening for the notification sent on commit of
+// events to the outbox, however many streams are open.
+type EventBroker struct {
+	Listener diygoapi.Listener
+	Logger   zerolog.Logger
+
+	mu          sync.Mutex
+	subscribers map[chan struct{}]struct{}
+}
+
+// Start listens for notifications until the context is done. If the
+// connection fails it is opened again, and subscribers are told in
+// case events were written in the meantime.
+func (b *EventBroker) Start(ctx context.Context) {
+	go func() {
+		for {
+			err := b.Listener.Listen(ctx, eventOutboxChannel, func(string) { b.broadcast() })
+			if ctx.Err() != nil {
+				return
+			}
+			b.Logger.Error().Err(err).Msg("event outbox listener failed")
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(eventBrokerRetryInterval):
+			}
+			b.broadcast()
+		}
+	}()
+}
+
+// subscribe returns a channel which receives a value when events are
+// written, and a function to stop receiving them. Notifications are
+// coalesced, a subscriber which has not caught up misses none.
+func (b *EventBroker) subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	if b.subscribers == nil {
+		b.subscribers = make(map[chan struct{}]struct{})
+	}
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		b.mu.Unlock()
+	}
+}
+
+func (b *EventBroker) broadcast() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// EventService streams the domain events written to the outbox
+type EventService struct {
+	Datastorer diygoapi.Datastorer
+
+	// Broker wakes streams as events are written. If nil, streams
+	// only read the outbox every HeartbeatInterval.
+	Broker *EventBroker
+
+	// HeartbeatInterval defaults to DefaultEventHeartbeatInterval
+	HeartbeatInterval time.Duration
+}
+
+// Stream sends the events of the org of the App making the request to
+// sender, in order, until the context is done or a send fails. Only
+// events about entities the user has permission to read are sent.
+func (s *EventService) Stream(ctx context.Context, r *diygoapi.StreamEventsRequest, adt diygoapi.Audit, sender diygoapi.EventSender) error {
+	const op errs.Op = "service/EventService.Stream"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer span.End()
+
+	eventTypes, err := s.permittedEventTypes(ctx, adt)
+	if err != nil {
+		return errs.E(op, err)
+	}
+	if len(eventTypes) == 0 {
+		return errs.E(op, errs.Unauthorized, "user does not have permission to read any events")
+	}
+
+	var after int64
+	if r.LastEventID != "" {
+		after, err = strconv.ParseInt(r.LastEventID, 10, 64)
+		if err != nil || after < 0 {
+			return errs.E(op, errs.Validation, errs.Parameter("Last-Event-ID"), "Last-Event-ID must be the id of an event")
+		}
+	} else {
+		after, err = s.latestSeq(ctx, adt)
+		if err != nil {
+			return errs.E(op, err)
+		}
+	}
+
+	var notified <-chan struct{}
+	if s.Broker != nil {
+		var unsubscribe func()
+		notified, unsubscribe = s.Broker.subscribe()
+		defer unsubscribe()
+	}
+
+	interval := s.HeartbeatInterval
+	if interval <= 0 {
+		interval = DefaultEventHeartbeatInterval
+	}
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+
+	err = sender.Heartbeat()
+	if err != nil {
+		return errs.E(op, errs.IO, err)
+	}
+
+	for {
+		var events []*diygoapi.EventResponse
+		events, err = s.findEventsAfter(ctx, adt, after, eventTypes)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errs.E(op, err)
+		}
+
+		for _, e := range events {
+			err = sender.Send(e)
+			if err != nil {
+				return errs.E(op, errs.IO, err)
+			}
+			after = e.Sequence
+		}
+		if len(events) > 0 {
+			heartbeat.Reset(interval)
+		}
+
+		// a full batch likely means there are more to catch up on
+		if len(events) == eventStreamBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-notified:
+		case <-heartbeat.C:
+			err = sender.Heartbeat()
+			if err != nil {
+				return errs.E(op, errs.IO, err)
+			}
+		}
+	}
+}
+
+// permittedEventTypes returns the event types about entities the user
+// has permission to read
+func (s *EventService) permittedEventTypes(ctx context.Context, adt diygoapi.Audit) (eventTypes []string, err error) {
+	const op errs.Op = "service/EventService.permittedEventTypes"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	q := datastore.New(tx)
+
+	permitted := make(map[string]bool, len(eventReadPermissions))
+	for entity, p := range eventReadPermissions {
+		_, err = q.IsAuthorized(ctx, datastore.IsAuthorizedParams{
+			Resource:  p.resource,
+			Operation: p.operation,
+			UserID:    adt.User.ID.PgxUUID(),
+			OrgID:     adt.App.Org.ID.PgxUUID(),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				err = nil
+				continue
+			}
+			return nil, errs.E(op, errs.Database, err)
+		}
+		permitted[entity] = true
+	}
+
+	for _, et := range diygoapi.EventTypes {
+		entity, _, _ := strings.Cut(string(et), ".")
+		if permitted[entity] {
+			eventTypes = append(eventTypes, string(et))
+		}
+	}
+
+	return eventTypes, nil
+}
+
+// latestSeq returns the sequence of the latest event of the org, for a
+// stream which is not resuming
+func (s *EventService) latestSeq(ctx context.Context, adt diygoapi.Audit) (seq int64, err error) {
+	const op errs.Op = "service/EventService.latestSeq"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return 0, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	seq, err = datastore.New(tx).FindLatestEventSeq(ctx, adt.App.Org.ID.PgxUUID())
+	if err != nil {
+		return 0, errs.E(op, errs.Database, err)
+	}
+
+	return seq, nil
+}
+
+// findEventsAfter reads the next batch of events of the org from the outbox
+func (s *EventService) findEventsAfter(ctx context.Context, adt diygoapi.Audit, after int64, eventTypes []string) (events []*diygoapi.EventResponse, err error) {
+	const op errs.Op = "service/EventService.findEventsAfter"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any. The stream
+	// ends by its context being canceled, which must not keep the
+	// transaction from being rolled back.
+	defer func() {
+		err = s.Datastorer.RollbackTx(context.WithoutCancel(ctx), tx, err)
+	}()
+
+	var rows []datastore.FindEventsAfterRow
+	rows, err = datastore.New(tx).FindEventsAfter(ctx, datastore.FindEventsAfterParams{
+		OrgID:      adt.App.Org.ID.PgxUUID(),
+		AfterSeq:   after,
+		EventTypes: eventTypes,
+		RowLimit:   eventStreamBatchSize,
+	})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	for _, row := range rows {
+		events = append(events, &diygoapi.EventResponse{
+			Sequence:      row.EventSeq,
+			ExternalID:    row.ExtlID,
+			Type:          diygoapi.EventType(row.EventType),
+			SubjectExtlID: row.SubjectExtlID,
+			CreateTime:    row.CreateTimestamp.Time.Format(time.RFC3339),
+			Data:          row.Payload,
+		})
+	}
+
+	return events, nil
+}
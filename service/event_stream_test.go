@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb/sqldbtest"
+)
+
+// chanEventSender is an EventSender which passes events to a channel
+type chanEventSender struct {
+	events     chan *diygoapi.EventResponse
+	heartbeats chan struct{}
+}
+
+func newChanEventSender() *chanEventSender {
+	return &chanEventSender{
+		events:     make(chan *diygoapi.EventResponse, 10),
+		heartbeats: make(chan struct{}, 10),
+	}
+}
+
+func (s *chanEventSender) Send(e *diygoapi.EventResponse) error {
+	s.events <- e
+	return nil
+}
+
+func (s *chanEventSender) Heartbeat() error {
+	select {
+	case s.heartbeats <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (s *chanEventSender) next(c *qt.C) *diygoapi.EventResponse {
+	c.Helper()
+
+	select {
+	case e := <-s.events:
+		return e
+	case <-time.After(10 * time.Second):
+		c.Fatal("no event received")
+		return nil
+	}
+}
+
+func TestEventService(t *testing.T) {
+	t.Run("stream and resume", func(t *testing.T) {
+		c := qt.New(t)
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+
+		tx, err := db.BeginTx(ctx)
+		c.Assert(err, qt.IsNil)
+		adt := findPrincipalTestAudit(ctx, c, tx)
+		c.Assert(db.RollbackTx(ctx, tx, nil), qt.IsNil)
+
+		brokerCtx, stopBroker := context.WithCancel(ctx)
+		defer stopBroker()
+		broker := &service.EventBroker{Listener: db, Logger: zerolog.Nop()}
+		broker.Start(brokerCtx)
+
+		es := service.EventService{Datastorer: db, Broker: broker, HeartbeatInterval: time.Second}
+
+		streamCtx, stopStream := context.WithCancel(ctx)
+		sender := newChanEventSender()
+		done := make(chan error, 1)
+		go func() { done <- es.Stream(streamCtx, &diygoapi.StreamEventsRequest{}, adt, sender) }()
+
+		// the stream has found where to start from once it first
+		// sends a heartbeat
+		select {
+		case <-sender.heartbeats:
+		case <-time.After(10 * time.Second):
+			c.Fatal("stream not started")
+		}
+
+		ms := service.MovieService{Datastorer: db}
+		m, err := ms.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "Sid and Nancy",
+			Rated:    "R",
+			Released: "1986-10-17T00:00:00Z",
+			RunTime:  112,
+			Director: "Alex Cox",
+			Writer:   "Alex Cox",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		_, err = ms.Delete(ctx, m.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+
+		created := sender.next(c)
+		c.Assert(created.Type, qt.Equals, diygoapi.MovieCreatedEvent)
+		c.Assert(created.SubjectExtlID, qt.Equals, m.ExternalID)
+
+		deleted := sender.next(c)
+		c.Assert(deleted.Type, qt.Equals, diygoapi.MovieDeletedEvent)
+		c.Assert(deleted.SubjectExtlID, qt.Equals, m.ExternalID)
+		c.Assert(deleted.Sequence > created.Sequence, qt.IsTrue)
+
+		stopStream()
+		c.Assert(<-done, qt.IsNil)
+
+		// resuming after the created event starts with the deleted one
+		resumeCtx, stopResume := context.WithCancel(ctx)
+		resumed := newChanEventSender()
+		go func() {
+			done <- es.Stream(resumeCtx, &diygoapi.StreamEventsRequest{LastEventID: strconv.FormatInt(created.Sequence, 10)}, adt, resumed)
+		}()
+
+		e := resumed.next(c)
+		c.Assert(e.ExternalID, qt.Equals, deleted.ExternalID)
+
+		stopResume()
+		c.Assert(<-done, qt.IsNil)
+
+		err = es.Stream(ctx, &diygoapi.StreamEventsRequest{LastEventID: "latest"}, adt, newChanEventSender())
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+	})
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+// exportBatchSize is the number of rows fetched from an export
+// cursor at a time, which bounds the memory used by an export
+const exportBatchSize = 500
+
+// exportWriter writes the records of a streaming export in the
+// requested format
+type exportWriter struct {
+	csv *csv.Writer
+	enc *json.Encoder
+}
+
+// newExportWriter initializes an exportWriter. For CSV, the header
+// row is written immediately so an empty export still has columns.
+func newExportWriter(f diygoapi.ExportFormat, w io.Writer, header []string) (*exportWriter, error) {
+	const op errs.Op = "service/newExportWriter"
+
+	switch f {
+	case diygoapi.CSVExportFormat:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return nil, errs.E(op, errs.IO, err)
+		}
+		return &exportWriter{csv: cw}, nil
+	case diygoapi.NDJSONExportFormat:
+		return &exportWriter{enc: json.NewEncoder(w)}, nil
+	}
+
+	return nil, errs.E(op, errs.Validation, errs.Parameter("format"), "format must be one of csv or ndjson")
+}
+
+// Write writes a single record, using record for CSV and v for NDJSON
+func (ew *exportWriter) Write(record []string, v any) error {
+	const op errs.Op = "service/exportWriter.Write"
+
+	var err error
+	if ew.csv != nil {
+		err = ew.csv.Write(record)
+	} else {
+		err = ew.enc.Encode(v)
+	}
+	if err != nil {
+		return errs.E(op, errs.IO, err)
+	}
+
+	return nil
+}
+
+// Flush writes any buffered CSV records to the underlying writer.
+// NDJSON records are written as they are encoded.
+func (ew *exportWriter) Flush() error {
+	const op errs.Op = "service/exportWriter.Flush"
+
+	if ew.csv == nil {
+		return nil
+	}
+
+	ew.csv.Flush()
+	if err := ew.csv.Error(); err != nil {
+		return errs.E(op, errs.IO, err)
+	}
+
+	return nil
+}
@@ -15,15 +15,16 @@ import (
 	"github.com/gilcrest/diygoapi/errs"
 	"github.com/gilcrest/diygoapi/secure"
 	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
 	"github.com/gilcrest/diygoapi/uuid"
 )
 
 const (
 	// PrincipalOrgName is the first organization created as part of
 	// the Genesis event and is the central administration org.
-	PrincipalOrgName               = "Principal"
-	principalOrgDescription        = "The Principal org represents the first organization created in the database and exists for the administrative purpose of creating other organizations, apps and users."
-	principalOrgKind        string = "principal"
+	PrincipalOrgName        = "Principal"
+	principalOrgDescription = "The Principal org represents the first organization created in the database and exists for the administrative purpose of creating other organizations, apps and users."
+	principalOrgKind        = diygoapi.PrincipalOrgKind
 	// PrincipalAppName is the first app created as part of the
 	// Genesis event and is the central administration app.
 	PrincipalAppName        = "Developer Dashboard"
@@ -82,6 +83,9 @@ type GenesisService struct {
 func (s *GenesisService) Arche(ctx context.Context, r *diygoapi.GenesisRequest) (gr diygoapi.GenesisResponse, err error) {
 	const op errs.Op = "service/GenesisService.Arche"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -150,6 +154,12 @@ func (s *GenesisService) Arche(ctx context.Context, r *diygoapi.GenesisRequest)
 		return gr, errs.E(op, err)
 	}
 
+	// record Genesis in the security event log
+	err = writeSecurityEventTx(ctx, tx, diygoapi.NewSecurityEvent(diygoapi.GenesisSecurityAction, diygoapi.SuccessSecurityOutcome, ps.Audit))
+	if err != nil {
+		return gr, errs.E(op, err)
+	}
+
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
+)
+
+// genreTaxonomy is every Genre, keyed by code
+type genreTaxonomy map[string]diygoapi.Genre
+
+// findGenreTaxonomy retrieves the genre taxonomy from the datastore
+func findGenreTaxonomy(ctx context.Context, dbtx datastore.DBTX) (genreTaxonomy, error) {
+	const op errs.Op = "service/findGenreTaxonomy"
+
+	rows, err := datastore.New(dbtx).FindGenres(ctx)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	gt := make(genreTaxonomy, len(rows))
+	for _, row := range rows {
+		gt[row.GenreCd] = diygoapi.Genre{
+			ID:   row.GenreID.Bytes,
+			Code: row.GenreCd,
+			Name: row.GenreName,
+		}
+	}
+
+	return gt, nil
+}
+
+// resolve returns the Genres for the given case-insensitive codes,
+// ignoring duplicates. A code which is not in the taxonomy is a
+// validation error.
+func (gt genreTaxonomy) resolve(codes []string) ([]diygoapi.Genre, error) {
+	const op errs.Op = "service/genreTaxonomy.resolve"
+
+	genres := make([]diygoapi.Genre, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		code = strings.ToLower(strings.TrimSpace(code))
+		g, ok := gt[code]
+		if !ok {
+			return nil, errs.E(op, errs.Validation, errs.Parameter("genres"), fmt.Sprintf("unknown genre: %q", code))
+		}
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		genres = append(genres, g)
+	}
+
+	return genres, nil
+}
+
+// newGenreResponse initializes GenreResponse
+func newGenreResponse(g diygoapi.Genre) *diygoapi.GenreResponse {
+	return &diygoapi.GenreResponse{
+		Code: g.Code,
+		Name: g.Name,
+	}
+}
+
+// newGenreResponses initializes GenreResponses for a movie. An empty
+// slice is returned rather than nil so a movie without genres is
+// rendered as an empty JSON array.
+func newGenreResponses(genres []diygoapi.Genre) []diygoapi.GenreResponse {
+	responses := make([]diygoapi.GenreResponse, 0, len(genres))
+	for _, g := range genres {
+		responses = append(responses, *newGenreResponse(g))
+	}
+	return responses
+}
+
+// FindAllGenres lists the genre taxonomy, ordered by name
+func (s *MovieService) FindAllGenres(ctx context.Context) (responses []*diygoapi.GenreResponse, err error) {
+	const op errs.Op = "service/MovieService.FindAllGenres"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rows []datastore.Genre
+	rows, err = datastore.New(tx).FindGenres(ctx)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	for _, row := range rows {
+		responses = append(responses, newGenreResponse(diygoapi.Genre{
+			ID:   row.GenreID.Bytes,
+			Code: row.GenreCd,
+			Name: row.GenreName,
+		}))
+	}
+
+	return responses, nil
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+// movieImage is the JSON representation of a Movie written to change history
+type movieImage struct {
+	ExternalID    string                    `json:"external_id"`
+	Title         string                    `json:"title"`
+	Rated         string                    `json:"rated"`
+	RatingCountry string                    `json:"rating_country"`
+	Released      string                    `json:"release_date"`
+	RunTime       int64                     `json:"run_time"`
+	Director      string                    `json:"director"`
+	Writer        string                    `json:"writer"`
+	Credits       []diygoapi.CreditResponse `json:"credits,omitempty"`
+	Genres        []string                  `json:"genres,omitempty"`
+}
+
+func newMovieImage(m diygoapi.Movie) movieImage {
+	mi := movieImage{
+		ExternalID:    m.ExternalID.String(),
+		Title:         m.Title,
+		Rated:         m.Rated,
+		RatingCountry: m.RatingCountry,
+		Released:      m.Released.Format(time.RFC3339),
+		RunTime:       m.RunTime,
+		Director:      m.Director,
+		Writer:        m.Writer,
+	}
+	if len(m.Credits) > 0 {
+		mi.Credits = newCreditResponses(m.Credits)
+	}
+	for _, g := range m.Genres {
+		mi.Genres = append(mi.Genres, g.Code)
+	}
+	return mi
+}
+
+// orgImage is the JSON representation of an Org written to change history
+type orgImage struct {
+	ExternalID  string `json:"external_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Kind        string `json:"kind"`
+}
+
+func newOrgImage(o diygoapi.Org) orgImage {
+	oi := orgImage{
+		ExternalID:  o.ExternalID.String(),
+		Name:        o.Name,
+		Description: o.Description,
+	}
+	if o.Kind != nil {
+		oi.Kind = o.Kind.ExternalID
+	}
+	return oi
+}
+
+// appImage is the JSON representation of an App written to change
+// history. API keys are deliberately never written to history.
+type appImage struct {
+	ExternalID    string `json:"external_id"`
+	OrgExternalID string `json:"org_external_id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+}
+
+func newAppImage(a diygoapi.App) appImage {
+	ai := appImage{
+		ExternalID:  a.ExternalID.String(),
+		Name:        a.Name,
+		Description: a.Description,
+	}
+	if a.Org != nil {
+		ai.OrgExternalID = a.Org.ExternalID.String()
+	}
+	return ai
+}
+
+// permissionImage is the JSON representation of a Permission written to change history
+type permissionImage struct {
+	ExternalID  string `json:"external_id"`
+	Resource    string `json:"resource"`
+	Operation   string `json:"operation"`
+	Description string `json:"description"`
+	Active      bool   `json:"active"`
+}
+
+func newPermissionImage(p diygoapi.Permission) permissionImage {
+	return permissionImage{
+		ExternalID:  p.ExternalID.String(),
+		Resource:    p.Resource,
+		Operation:   p.Operation,
+		Description: p.Description,
+		Active:      p.Active,
+	}
+}
+
+// roleImage is the JSON representation of a Role written to change history
+type roleImage struct {
+	ExternalID  string            `json:"external_id"`
+	Code        string            `json:"role_cd"`
+	Description string            `json:"role_description"`
+	Active      bool              `json:"active"`
+	Permissions []permissionImage `json:"permissions"`
+}
+
+func newRoleImage(r diygoapi.Role) roleImage {
+	ri := roleImage{
+		ExternalID:  r.ExternalID.String(),
+		Code:        r.Code,
+		Description: r.Description,
+		Active:      r.Active,
+	}
+	for _, p := range r.Permissions {
+		ri.Permissions = append(ri.Permissions, newPermissionImage(*p))
+	}
+	return ri
+}
+
+// changeHistoryParams is the parameters for writing a change
+// history entry. Before should be nil for a create or restore and
+// After should be nil for a delete.
+type changeHistoryParams struct {
+	EntityType   diygoapi.EntityType
+	EntityID     uuid.UUID
+	EntityExtlID string
+	Action       diygoapi.ChangeAction
+	Before       any
+	After        any
+	Audit        diygoapi.Audit
+}
+
+// newCreateChangeHistoryParams marshals the before and after images
+// and initializes the params needed to write a change history entry.
+func newCreateChangeHistoryParams(p changeHistoryParams) (datastore.CreateChangeHistoryParams, error) {
+	const op errs.Op = "service/newCreateChangeHistoryParams"
+
+	var (
+		before, after []byte
+		err           error
+	)
+	if p.Before != nil {
+		before, err = json.Marshal(p.Before)
+		if err != nil {
+			return datastore.CreateChangeHistoryParams{}, errs.E(op, errs.Internal, err)
+		}
+	}
+	if p.After != nil {
+		after, err = json.Marshal(p.After)
+		if err != nil {
+			return datastore.CreateChangeHistoryParams{}, errs.E(op, errs.Internal, err)
+		}
+	}
+
+	return datastore.CreateChangeHistoryParams{
+		ChangeHistoryID: uuid.New().PgxUUID(),
+		EntityType:      string(p.EntityType),
+		EntityID:        p.EntityID.PgxUUID(),
+		EntityExtlID:    p.EntityExtlID,
+		ChangeAction:    string(p.Action),
+		BeforeImage:     before,
+		AfterImage:      after,
+		ChangeAppID:     p.Audit.App.ID.PgxUUID(),
+		ChangeUserID:    p.Audit.User.ID.PgxUUID(),
+		ChangeTimestamp: diygoapi.NewPgxTimestampTZ(p.Audit.Moment),
+	}, nil
+}
+
+// writeChangeHistoryTx appends a change history entry using the
+// same transaction as the change itself, so history is only
+// written if the change is committed.
+func writeChangeHistoryTx(ctx context.Context, tx pgx.Tx, p changeHistoryParams) (err error) {
+	const op errs.Op = "service/writeChangeHistoryTx"
+
+	var arg datastore.CreateChangeHistoryParams
+	arg, err = newCreateChangeHistoryParams(p)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).CreateChangeHistory(ctx, arg)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	return nil
+}
+
+// ChangeHistoryService reads the change history of domain entities
+type ChangeHistoryService struct {
+	Datastorer diygoapi.Datastorer
+}
+
+// FindByExternalID returns all changes made to the entity of the given
+// type and External ID, oldest first. The history of a movie is only
+// found for the org which owns the movie.
+func (s *ChangeHistoryService) FindByExternalID(ctx context.Context, et diygoapi.EntityType, extlID string, adt diygoapi.Audit) (responses []*diygoapi.ChangeHistoryResponse, err error) {
+	const op errs.Op = "service/ChangeHistoryService.FindByExternalID"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	notFound := errs.E(op, errs.NotExist, fmt.Sprintf("no change history found for %s with external ID: %s", et, extlID))
+
+	// change history is not scoped to an org, so movie history is only
+	// read once the movie is known to belong to the acting org
+	if et == diygoapi.MovieEntityType {
+		var owned bool
+		owned, err = isOrgMovieTx(ctx, tx, extlID, adt)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+		if !owned {
+			return nil, notFound
+		}
+	}
+
+	arg := datastore.FindChangeHistoryByEntityExtlIDParams{
+		EntityType:   string(et),
+		EntityExtlID: extlID,
+	}
+
+	var rows []datastore.FindChangeHistoryByEntityExtlIDRow
+	rows, err = datastore.New(tx).FindChangeHistoryByEntityExtlID(ctx, arg)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if len(rows) == 0 {
+		return nil, notFound
+	}
+
+	for _, row := range rows {
+		responses = append(responses, &diygoapi.ChangeHistoryResponse{
+			EntityType:          row.EntityType,
+			ExternalID:          row.EntityExtlID,
+			Action:              row.ChangeAction,
+			Before:              row.BeforeImage,
+			After:               row.AfterImage,
+			ChangeAppExtlID:     row.ChangeAppExtlID.String,
+			ChangeUserFirstName: row.ChangeUserFirstName.String,
+			ChangeUserLastName:  row.ChangeUserLastName.String,
+			ChangeDateTime:      row.ChangeTimestamp.Time.Format(time.RFC3339),
+		})
+	}
+
+	return responses, nil
+}
+
+// isOrgMovieTx reports whether the movie with the given external ID,
+// deleted or not, belongs to the acting org of the audit
+func isOrgMovieTx(ctx context.Context, tx pgx.Tx, extlID string, adt diygoapi.Audit) (bool, error) {
+	const op errs.Op = "service/isOrgMovieTx"
+
+	orgID, err := actingOrgID(adt)
+	if err != nil {
+		return false, errs.E(op, err)
+	}
+
+	q := datastore.New(tx)
+	_, err = q.FindMovieByExternalID(ctx, datastore.FindMovieByExternalIDParams{ExtlID: extlID, OrgID: orgID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		_, err = q.FindDeletedMovieByExternalID(ctx, datastore.FindDeletedMovieByExternalIDParams{ExtlID: extlID, OrgID: orgID})
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, errs.E(op, errs.Database, err)
+	}
+
+	return true, nil
+}
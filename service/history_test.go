@@ -0,0 +1,165 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb/sqldbtest"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+func TestChangeHistoryService(t *testing.T) {
+	t.Run("movie create, update and delete", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		ms := service.MovieService{Datastorer: db}
+
+		rd, _ := time.Parse(time.RFC3339, "1984-03-02T00:00:00Z")
+		var mr *diygoapi.MovieResponse
+		mr, err = ms.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "Repo Man",
+			Rated:    "R",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  92,
+			Director: "Alex Cox",
+			Writer:   "Alex Cox",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		_, err = ms.Update(ctx, &diygoapi.UpdateMovieRequest{
+			ExternalID: mr.ExternalID,
+			Title:      "Repo Man (Director's Cut)",
+			Rated:      "R",
+			Released:   rd.Format(time.RFC3339),
+			RunTime:    92,
+			Director:   "Alex Cox",
+			Writer:     "Alex Cox",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		_, err = ms.Delete(ctx, mr.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+
+		s := service.ChangeHistoryService{Datastorer: db}
+
+		var got []*diygoapi.ChangeHistoryResponse
+		got, err = s.FindByExternalID(ctx, diygoapi.MovieEntityType, mr.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.HasLen, 3)
+
+		c.Assert(got[0].Action, qt.Equals, string(diygoapi.CreateChangeAction))
+		c.Assert(got[0].Before, qt.IsNil)
+		c.Assert(got[1].Action, qt.Equals, string(diygoapi.UpdateChangeAction))
+		c.Assert(got[2].Action, qt.Equals, string(diygoapi.DeleteChangeAction))
+		c.Assert(got[2].After, qt.IsNil)
+
+		var before, after struct {
+			Title string `json:"title"`
+		}
+		c.Assert(json.Unmarshal(got[1].Before, &before), qt.IsNil)
+		c.Assert(json.Unmarshal(got[1].After, &after), qt.IsNil)
+		c.Assert(before.Title, qt.Equals, "Repo Man")
+		c.Assert(after.Title, qt.Equals, "Repo Man (Director's Cut)")
+		c.Assert(got[1].ChangeAppExtlID, qt.Equals, adt.App.ExternalID.String())
+	})
+	t.Run("movie history is not visible to other orgs", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		ms := service.MovieService{Datastorer: db}
+
+		rd, _ := time.Parse(time.RFC3339, "1988-11-04T00:00:00Z")
+		var mr *diygoapi.MovieResponse
+		mr, err = ms.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "They Live",
+			Rated:    "R",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  93,
+			Director: "John Carpenter",
+			Writer:   "John Carpenter",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		// act on behalf of an app belonging to a different org
+		otherApp := *adt.App
+		otherApp.Org = &diygoapi.Org{ID: uuid.New()}
+		otherAdt := adt
+		otherAdt.App = &otherApp
+		otherCtx := diygoapi.NewContextWithApp(context.Background(), &otherApp)
+
+		s := service.ChangeHistoryService{Datastorer: db}
+
+		_, err = s.FindByExternalID(otherCtx, diygoapi.MovieEntityType, mr.ExternalID, otherAdt)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+
+		// still visible once deleted, to the owning org only
+		_, err = ms.Delete(ctx, mr.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+
+		var got []*diygoapi.ChangeHistoryResponse
+		got, err = s.FindByExternalID(ctx, diygoapi.MovieEntityType, mr.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.HasLen, 2)
+
+		_, err = s.FindByExternalID(otherCtx, diygoapi.MovieEntityType, mr.ExternalID, otherAdt)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+	})
+	t.Run("no history", func(t *testing.T) {
+		c := qt.New(t)
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		s := service.ChangeHistoryService{Datastorer: db}
+
+		adt := diygoapi.Audit{App: &diygoapi.App{Org: &diygoapi.Org{ID: uuid.New()}}}
+		_, err := s.FindByExternalID(context.Background(), diygoapi.MovieEntityType, secure.NewID().String(), adt)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+	})
+}
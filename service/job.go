@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
+)
+
+// JobService is a service for inspecting and retrying background jobs
+type JobService struct {
+	Datastorer diygoapi.Datastorer
+}
+
+// newJobFromRow initializes a Job given a datastore.Job
+func newJobFromRow(row datastore.Job) diygoapi.Job {
+	return diygoapi.Job{
+		ID:          row.JobID.Bytes,
+		ExternalID:  secure.MustParseIdentifier(row.ExtlID),
+		Kind:        row.JobKind,
+		Payload:     row.Payload,
+		State:       diygoapi.JobState(row.JobState),
+		Attempts:    int(row.Attempts),
+		MaxAttempts: int(row.MaxAttempts),
+		RunAt:       row.RunAt.Time,
+		LastError:   row.LastError.String,
+	}
+}
+
+// newJobResponse initializes JobResponse given a datastore.Job
+func newJobResponse(row datastore.Job) *diygoapi.JobResponse {
+	j := newJobFromRow(row)
+
+	return &diygoapi.JobResponse{
+		ExternalID:     j.ExternalID.String(),
+		Kind:           j.Kind,
+		Payload:        j.Payload,
+		State:          j.State,
+		Attempts:       j.Attempts,
+		MaxAttempts:    j.MaxAttempts,
+		RunAt:          j.RunAt.Format(time.RFC3339),
+		LastError:      j.LastError,
+		CreateDateTime: row.CreateTimestamp.Time.Format(time.RFC3339),
+		UpdateDateTime: row.UpdateTimestamp.Time.Format(time.RFC3339),
+	}
+}
+
+// EnqueueJobTx queues a job using the given transaction, so the job is
+// only run if the transaction commits. A job with a zero RunAt is run
+// as soon as a worker is free.
+func EnqueueJobTx(ctx context.Context, tx pgx.Tx, j diygoapi.Job) error {
+	const op errs.Op = "service/EnqueueJobTx"
+
+	err := j.IsValid()
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	now := time.Now()
+	if j.RunAt.IsZero() {
+		j.RunAt = now
+	}
+
+	rowsAffected, err := datastore.New(tx).CreateJob(ctx, datastore.CreateJobParams{
+		JobID:           j.ID.PgxUUID(),
+		ExtlID:          j.ExternalID.String(),
+		JobKind:         j.Kind,
+		Payload:         j.Payload,
+		MaxAttempts:     int32(j.MaxAttempts),
+		RunAt:           diygoapi.NewPgxTimestampTZ(j.RunAt),
+		CreateTimestamp: diygoapi.NewPgxTimestampTZ(now),
+		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(now),
+	})
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	return nil
+}
+
+// findJobTx finds a job given its external ID
+func findJobTx(ctx context.Context, tx pgx.Tx, extlID string) (datastore.Job, error) {
+	const op errs.Op = "service/findJobTx"
+
+	row, err := datastore.New(tx).FindJobByExternalID(ctx, extlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return datastore.Job{}, errs.E(op, errs.NotExist, "no job exists for the given external ID")
+		}
+		return datastore.Job{}, errs.E(op, errs.Database, err)
+	}
+
+	return row, nil
+}
+
+// FindJobs lists the most recently created jobs, optionally only
+// those in the given state
+func (s *JobService) FindJobs(ctx context.Context, r *diygoapi.FindJobsRequest) (responses []*diygoapi.JobResponse, err error) {
+	const op errs.Op = "service/JobService.FindJobs"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	limit := r.Limit
+	switch {
+	case limit == 0:
+		limit = diygoapi.DefaultJobListLimit
+	case limit < 0 || limit > diygoapi.MaxJobListLimit:
+		return nil, errs.E(op, errs.Validation, errs.Parameter("limit"),
+			fmt.Sprintf("limit must be between 1 and %d", diygoapi.MaxJobListLimit))
+	}
+
+	var state pgtype.Text
+	if r.State != "" {
+		state = diygoapi.NewPgxText(string(r.State))
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rows []datastore.Job
+	rows, err = datastore.New(tx).FindJobs(ctx, datastore.FindJobsParams{
+		JobState: state,
+		RowLimit: int32(limit),
+	})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	responses = make([]*diygoapi.JobResponse, 0, len(rows))
+	for _, row := range rows {
+		responses = append(responses, newJobResponse(row))
+	}
+
+	return responses, nil
+}
+
+// FindJobByExternalID finds a job given its external ID
+func (s *JobService) FindJobByExternalID(ctx context.Context, extlID string) (response *diygoapi.JobResponse, err error) {
+	const op errs.Op = "service/JobService.FindJobByExternalID"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var row datastore.Job
+	row, err = findJobTx(ctx, tx, extlID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newJobResponse(row), nil
+}
+
+// Retry queues a dead job to run again with a fresh set of attempts.
+// Only dead jobs can be retried.
+func (s *JobService) Retry(ctx context.Context, extlID string) (response *diygoapi.JobResponse, err error) {
+	const op errs.Op = "service/JobService.Retry"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var row datastore.Job
+	row, err = findJobTx(ctx, tx, extlID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if diygoapi.JobState(row.JobState) != diygoapi.JobDead {
+		return nil, errs.E(op, errs.Invalid, fmt.Sprintf("only dead jobs can be retried, job is %s", row.JobState))
+	}
+
+	q := datastore.New(tx)
+
+	var rowsAffected int64
+	rowsAffected, err = q.RetryJob(ctx, datastore.RetryJobParams{
+		Now:   diygoapi.NewPgxTimestampTZ(time.Now()),
+		JobID: row.JobID,
+	})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	// a worker cannot claim a dead job, so it is still dead unless
+	// retried concurrently
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Invalid, "the job was retried by another request")
+	}
+
+	row, err = findJobTx(ctx, tx, extlID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newJobResponse(row), nil
+}
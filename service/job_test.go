@@ -0,0 +1,171 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb/sqldbtest"
+)
+
+func TestJobService(t *testing.T) {
+	t.Run("run, dead-letter and retry jobs", func(t *testing.T) {
+		c := qt.New(t)
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+
+		// a kind unique to this run, so jobs left behind by an earlier
+		// run are not counted
+		kind := "test." + secure.NewID().String()
+
+		ok, err := diygoapi.NewJob(kind, map[string]bool{"fail": false})
+		c.Assert(err, qt.IsNil)
+		bad, err := diygoapi.NewJob(kind, map[string]bool{"fail": true})
+		c.Assert(err, qt.IsNil)
+		bad.MaxAttempts = 1
+
+		// jobs are only visible to the workers once committed
+		tx, err := db.BeginTx(ctx)
+		c.Assert(err, qt.IsNil)
+		c.Assert(service.EnqueueJobTx(ctx, tx, ok), qt.IsNil)
+		c.Assert(service.EnqueueJobTx(ctx, tx, bad), qt.IsNil)
+		c.Assert(db.CommitTx(ctx, tx), qt.IsNil)
+
+		w := &service.JobWorkers{
+			Datastorer: db,
+			Logger:     zerolog.Nop(),
+			Handlers: map[string]diygoapi.JobHandler{
+				kind: diygoapi.JobHandlerFunc(func(ctx context.Context, j diygoapi.Job) error {
+					if string(j.Payload) == `{"fail":true}` {
+						return errors.New("boom")
+					}
+					return nil
+				}),
+			},
+			Concurrency:  2,
+			PollInterval: 50 * time.Millisecond,
+		}
+		w.Start(ctx)
+
+		s := service.JobService{Datastorer: db}
+
+		waitForJobState(c, s, ok.ExternalID.String(), diygoapi.JobSucceeded)
+		dead := waitForJobState(c, s, bad.ExternalID.String(), diygoapi.JobDead)
+		c.Assert(dead.Attempts, qt.Equals, 1)
+		c.Assert(dead.LastError, qt.Equals, "boom")
+
+		_, err = s.Retry(ctx, ok.ExternalID.String())
+		c.Assert(errs.KindIs(errs.Invalid, err), qt.IsTrue)
+
+		var retried *diygoapi.JobResponse
+		retried, err = s.Retry(ctx, bad.ExternalID.String())
+		c.Assert(err, qt.IsNil)
+		c.Assert(retried.Attempts, qt.Equals, 0)
+
+		// the retried job fails again and is dead-lettered once more
+		waitForJobState(c, s, bad.ExternalID.String(), diygoapi.JobDead)
+
+		shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		c.Assert(w.Shutdown(shutdownCtx), qt.IsNil)
+
+		var jobs []*diygoapi.JobResponse
+		jobs, err = s.FindJobs(ctx, &diygoapi.FindJobsRequest{State: diygoapi.JobDead, Limit: diygoapi.MaxJobListLimit})
+		c.Assert(err, qt.IsNil)
+		var found bool
+		for _, j := range jobs {
+			if j.ExternalID == bad.ExternalID.String() {
+				found = true
+			}
+		}
+		c.Assert(found, qt.IsTrue)
+
+		_, err = s.FindJobs(ctx, &diygoapi.FindJobsRequest{Limit: diygoapi.MaxJobListLimit + 1})
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+
+		_, err = s.FindJobByExternalID(ctx, secure.NewID().String())
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+	})
+	t.Run("dead-letter a job whose lease expired on its last attempt", func(t *testing.T) {
+		c := qt.New(t)
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+
+		kind := "test." + secure.NewID().String()
+
+		stuck, err := diygoapi.NewJob(kind, map[string]bool{})
+		c.Assert(err, qt.IsNil)
+		stuck.MaxAttempts = 1
+
+		tx, err := db.BeginTx(ctx)
+		c.Assert(err, qt.IsNil)
+		c.Assert(service.EnqueueJobTx(ctx, tx, stuck), qt.IsNil)
+		c.Assert(db.CommitTx(ctx, tx), qt.IsNil)
+
+		// the handler outlives its lease, as if its worker had died
+		release := make(chan struct{})
+		w := &service.JobWorkers{
+			Datastorer: db,
+			Logger:     zerolog.Nop(),
+			Handlers: map[string]diygoapi.JobHandler{
+				kind: diygoapi.JobHandlerFunc(func(ctx context.Context, j diygoapi.Job) error {
+					<-release
+					return nil
+				}),
+			},
+			Concurrency:  2,
+			PollInterval: 50 * time.Millisecond,
+			Lease:        200 * time.Millisecond,
+		}
+		w.Start(ctx)
+
+		s := service.JobService{Datastorer: db}
+
+		// the idle worker dead-letters the job rather than claiming it
+		// for an attempt it does not have
+		dead := waitForJobState(c, s, stuck.ExternalID.String(), diygoapi.JobDead)
+		c.Assert(dead.Attempts, qt.Equals, 1)
+		close(release)
+
+		shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		c.Assert(w.Shutdown(shutdownCtx), qt.IsNil)
+
+		// the late success of the stuck worker does not revive the job
+		dead = waitForJobState(c, s, stuck.ExternalID.String(), diygoapi.JobDead)
+		c.Assert(dead.Attempts, qt.Equals, 1)
+	})
+}
+
+// waitForJobState polls until the job is in the given state
+func waitForJobState(c *qt.C, s service.JobService, extlID string, state diygoapi.JobState) *diygoapi.JobResponse {
+	c.Helper()
+
+	ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		j, err := s.FindJobByExternalID(ctx, extlID)
+		c.Assert(err, qt.IsNil)
+		if j.State == state {
+			return j
+		}
+		if time.Now().After(deadline) {
+			c.Fatalf("job %s is %s, want %s", extlID, j.State, state)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
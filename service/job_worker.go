@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/logger"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
+)
+
+// Job worker defaults
+const (
+	DefaultJobConcurrency  = 4
+	DefaultJobPollInterval = 5 * time.Second
+	DefaultJobLease        = 5 * time.Minute
+)
+
+// jobBookkeepingTimeout bounds the update recording the outcome of a
+// job, which is made even after the worker has been told to stop
+const jobBookkeepingTimeout = 10 * time.Second
+
+// JobWorkers claims queued jobs from the job table and runs each with
+// the JobHandler registered for its kind. Jobs are claimed with
+// select ... for update skip locked, so any number of workers, in any
+// number of processes, can share the table.
+//
+// Handlers are run with a context which bypasses row level security,
+// as jobs are not run on behalf of a request. A handler must scope
+// its queries itself, e.g. by an org ID in the job payload.
+type JobWorkers struct {
+	Datastorer diygoapi.Datastorer
+	Logger     zerolog.Logger
+
+	// Handlers maps a job kind to the handler which runs it. A job of
+	// a kind without a handler is dead-lettered.
+	Handlers map[string]diygoapi.JobHandler
+
+	// Concurrency is the number of jobs run at once. If zero,
+	// DefaultJobConcurrency is used.
+	Concurrency int
+
+	// PollInterval is how long an idle worker waits before looking
+	// for a job again. If zero, DefaultJobPollInterval is used.
+	PollInterval time.Duration
+
+	// Lease is how long a job may run. A job still running after its
+	// lease is assumed to have lost its worker and is claimed again,
+	// or dead-lettered if it was on its last attempt. If zero,
+	// DefaultJobLease is used.
+	Lease time.Duration
+
+	stopClaiming context.CancelFunc
+	stopRunning  context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+// Start starts the workers. They run until Shutdown is called or ctx
+// is done.
+func (w *JobWorkers) Start(ctx context.Context) {
+	if w.Concurrency == 0 {
+		w.Concurrency = DefaultJobConcurrency
+	}
+	if w.PollInterval == 0 {
+		w.PollInterval = DefaultJobPollInterval
+	}
+	if w.Lease == 0 {
+		w.Lease = DefaultJobLease
+	}
+
+	ctx = diygoapi.NewContextWithRowSecurityBypass(ctx)
+
+	// claiming stops as soon as Shutdown is called, while jobs
+	// already running are given until the Shutdown context is done
+	var claimCtx, runCtx context.Context
+	claimCtx, w.stopClaiming = context.WithCancel(ctx)
+	runCtx, w.stopRunning = context.WithCancel(context.WithoutCancel(ctx))
+
+	for i := 0; i < w.Concurrency; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.work(claimCtx, runCtx)
+		}()
+	}
+
+	w.Logger.Info().Msgf("started %d job workers", w.Concurrency)
+}
+
+// Shutdown stops the workers claiming jobs and waits for the jobs
+// they are running to finish. If ctx is done first, the running jobs
+// are cancelled and will be retried.
+func (w *JobWorkers) Shutdown(ctx context.Context) error {
+	const op errs.Op = "service/JobWorkers.Shutdown"
+
+	if w.stopClaiming == nil {
+		return nil
+	}
+	w.stopClaiming()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.stopRunning()
+		w.Logger.Info().Msg("job workers drained")
+		return nil
+	case <-ctx.Done():
+		w.stopRunning()
+		<-done
+		return errs.E(op, errs.Internal, fmt.Sprintf("job workers did not drain in time, running jobs were cancelled: %v", ctx.Err()))
+	}
+}
+
+// work claims and runs jobs until claimCtx is done
+func (w *JobWorkers) work(claimCtx, runCtx context.Context) {
+	for {
+		j, ok, err := w.claim(claimCtx)
+		if err != nil && claimCtx.Err() == nil {
+			w.Logger.Error().Err(err).Msg("claiming job failed")
+		}
+
+		if !ok {
+			// an idle worker also dead-letters jobs which lost their
+			// worker on the last attempt, as they are never claimed
+			if err == nil {
+				err = w.deadLetterExpired(claimCtx)
+				if err != nil && claimCtx.Err() == nil {
+					w.Logger.Error().Err(err).Msg("dead-lettering expired jobs failed")
+				}
+			}
+
+			select {
+			case <-claimCtx.Done():
+				return
+			case <-time.After(w.PollInterval):
+			}
+			continue
+		}
+
+		w.run(runCtx, j)
+	}
+}
+
+// claim claims the next job due to run, if any
+func (w *JobWorkers) claim(ctx context.Context) (j diygoapi.Job, ok bool, err error) {
+	const op errs.Op = "service/JobWorkers.claim"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = w.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return diygoapi.Job{}, false, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = w.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	now := time.Now()
+
+	var row datastore.Job
+	row, err = datastore.New(tx).ClaimJob(ctx, datastore.ClaimJobParams{
+		Now:                diygoapi.NewPgxTimestampTZ(now),
+		LeaseExpiredBefore: diygoapi.NewPgxTimestampTZ(now.Add(-w.Lease)),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return diygoapi.Job{}, false, nil
+		}
+		return diygoapi.Job{}, false, errs.E(op, errs.Database, err)
+	}
+
+	err = w.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return diygoapi.Job{}, false, errs.E(op, err)
+	}
+
+	return newJobFromRow(row), true, nil
+}
+
+// deadLetterExpired dead-letters running jobs whose lease expired on
+// their last attempt
+func (w *JobWorkers) deadLetterExpired(ctx context.Context) (err error) {
+	const op errs.Op = "service/JobWorkers.deadLetterExpired"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = w.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = w.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	now := time.Now()
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).DeadLetterExpiredJobs(ctx, datastore.DeadLetterExpiredJobsParams{
+		LastError:          diygoapi.NewPgxText("the lease expired on the last attempt, the job's worker is assumed to have died"),
+		Now:                diygoapi.NewPgxTimestampTZ(now),
+		LeaseExpiredBefore: diygoapi.NewPgxTimestampTZ(now.Add(-w.Lease)),
+	})
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	err = w.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	if rowsAffected > 0 {
+		w.Logger.Error().Int64("jobs", rowsAffected).Msg("jobs whose lease expired on their last attempt dead-lettered")
+	}
+
+	return nil
+}
+
+// run runs a claimed job with its handler and records the outcome
+func (w *JobWorkers) run(ctx context.Context, j diygoapi.Job) {
+	const op errs.Op = "service/JobWorkers.run"
+
+	lgr := w.Logger.With().
+		Ctx(logger.NewContextWithComponent(ctx, string(op))).
+		Str("job_extl_id", j.ExternalID.String()).
+		Str("job_kind", j.Kind).
+		Int("attempt", j.Attempts).
+		Logger()
+
+	h, ok := w.Handlers[j.Kind]
+	if !ok {
+		// retrying cannot help until a handler is registered, at
+		// which point the job can be retried from the admin API
+		j.Attempts = j.MaxAttempts
+		w.fail(j, errs.E(errs.Internal, "no handler is registered for job kind "+j.Kind), lgr)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.Lease)
+	defer cancel()
+
+	err := handleJob(ctx, h, j)
+	if err != nil {
+		w.fail(j, err, lgr)
+		return
+	}
+
+	w.complete(j, lgr)
+}
+
+// handleJob calls the handler, turning a panic into an error so one
+// bad job cannot take down the worker. Each job is traced as its own
+// root span.
+func handleJob(ctx context.Context, h diygoapi.JobHandler, j diygoapi.Job) (err error) {
+	ctx, span := tracer.Start(ctx, "job "+j.Kind, trace.WithAttributes(
+		attribute.String("job.extl_id", j.ExternalID.String()),
+		attribute.Int("job.attempt", j.Attempts)))
+	defer tracing.EndSpan(span, &err)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errs.E(errs.Internal, fmt.Sprintf("job handler panicked: %v", r))
+		}
+	}()
+
+	return h.HandleJob(ctx, j)
+}
+
+// complete marks the job as succeeded
+func (w *JobWorkers) complete(j diygoapi.Job, lgr zerolog.Logger) {
+	ctx, cancel := context.WithTimeout(diygoapi.NewContextWithRowSecurityBypass(context.Background()), jobBookkeepingTimeout)
+	defer cancel()
+
+	err := w.recordOutcome(ctx, func(q *datastore.Queries) (int64, error) {
+		return q.CompleteJob(ctx, datastore.CompleteJobParams{
+			UpdateTimestamp: diygoapi.NewPgxTimestampTZ(time.Now()),
+			JobID:           j.ID.PgxUUID(),
+			Attempts:        int32(j.Attempts),
+		})
+	})
+	if err != nil {
+		lgr.Error().Err(err).Msg("recording job success failed")
+		return
+	}
+
+	lgr.Debug().Msg("job succeeded")
+}
+
+// fail records a failed attempt. The job is queued again after a
+// backoff, or dead-lettered if it has no attempts left.
+func (w *JobWorkers) fail(j diygoapi.Job, jobErr error, lgr zerolog.Logger) {
+	ctx, cancel := context.WithTimeout(diygoapi.NewContextWithRowSecurityBypass(context.Background()), jobBookkeepingTimeout)
+	defer cancel()
+
+	now := time.Now()
+	state := diygoapi.JobQueued
+	runAt := now.Add(diygoapi.JobBackoff(j.Attempts))
+	if j.Attempts >= j.MaxAttempts {
+		state = diygoapi.JobDead
+		runAt = now
+	}
+
+	err := w.recordOutcome(ctx, func(q *datastore.Queries) (int64, error) {
+		return q.FailJob(ctx, datastore.FailJobParams{
+			JobState:        string(state),
+			RunAt:           diygoapi.NewPgxTimestampTZ(runAt),
+			LastError:       diygoapi.NewPgxText(jobErr.Error()),
+			UpdateTimestamp: diygoapi.NewPgxTimestampTZ(now),
+			JobID:           j.ID.PgxUUID(),
+			Attempts:        int32(j.Attempts),
+		})
+	})
+	if err != nil {
+		lgr.Error().Err(err).Msg("recording job failure failed")
+		return
+	}
+
+	if state == diygoapi.JobDead {
+		lgr.Error().Err(jobErr).Msg("job dead-lettered")
+		return
+	}
+	lgr.Warn().Err(jobErr).Time("retry_at", runAt).Msg("job failed, will retry")
+}
+
+// recordOutcome runs the update recording the outcome of a job in its
+// own transaction. No rows are updated if the lease expired and
+// another worker claimed the job in the meantime, in which case the
+// outcome of that worker stands.
+func (w *JobWorkers) recordOutcome(ctx context.Context, update func(q *datastore.Queries) (int64, error)) (err error) {
+	const op errs.Op = "service/JobWorkers.recordOutcome"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = w.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = w.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rowsAffected int64
+	rowsAffected, err = update(datastore.New(tx))
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, "the job is no longer held by this worker, its lease expired")
+	}
+
+	return w.Datastorer.CommitTx(ctx, tx)
+}
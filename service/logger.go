@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/rs/zerolog"
 
@@ -13,67 +17,160 @@ import (
 // LoggerService reads and updates the logger state
 type LoggerService struct {
 	Logger zerolog.Logger
+	// Levels is the hook of Logger setting the level it writes at,
+	// globally and by component
+	Levels *logger.Levels
+	// SecurityEventServicer records updates to the logger
+	SecurityEventServicer diygoapi.SecurityEventServicer
 }
 
 // ReadLogger handles GET requests for the /logger endpoint
 func (ls *LoggerService) Read() *diygoapi.LoggerResponse {
-
-	var logErrorStack bool
-	if zerolog.ErrorStackMarshaler != nil {
-		logErrorStack = true
-	}
-
-	response := &diygoapi.LoggerResponse{
-		LoggerMinimumLevel: ls.Logger.GetLevel().String(),
-		GlobalLogLevel:     zerolog.GlobalLevel().String(),
-		LogErrorStack:      logErrorStack,
-	}
-
-	return response
+	return ls.newLoggerResponse()
 }
 
 // Update handles PUT requests for the /logger endpoint
-// and updates the logger globals
-func (ls *LoggerService) Update(r *diygoapi.LoggerRequest) (*diygoapi.LoggerResponse, error) {
+// and updates the logger globals. The request is validated in full
+// and recorded as a security event before any of it is applied.
+func (ls *LoggerService) Update(ctx context.Context, r *diygoapi.LoggerRequest, adt diygoapi.Audit) (*diygoapi.LoggerResponse, error) {
 	const op errs.Op = "service/LoggerService.Update"
 
+	var (
+		lvl zerolog.Level
+		ttl time.Duration
+		les bool
+		err error
+	)
 	if r.GlobalLogLevel != "" {
 		// parse input level from request (if present) and set to that
-		lvl, err := zerolog.ParseLevel(r.GlobalLogLevel)
+		lvl, err = zerolog.ParseLevel(r.GlobalLogLevel)
 		if err != nil {
-			return nil, errs.E(op, errs.Validation, err)
+			return nil, errs.E(op, errs.Validation, errs.Parameter("global_log_level"), err)
 		}
-
-		clvl := zerolog.GlobalLevel()
-
-		if lvl != clvl {
-			// set the global logging level
-			zerolog.SetGlobalLevel(lvl)
+	}
+	if r.GlobalLogLevelTTL != "" {
+		if r.GlobalLogLevel == "" {
+			return nil, errs.E(op, errs.Validation, errs.Parameter("global_log_level_ttl"), "global_log_level_ttl requires global_log_level")
+		}
+		ttl, err = parseLogLevelTTL("global_log_level_ttl", r.GlobalLogLevelTTL)
+		if err != nil {
+			return nil, errs.E(op, err)
 		}
 	}
 
 	if r.LogErrorStack != "" {
-		var (
-			les bool
-			err error
-		)
 		if les, err = strconv.ParseBool(r.LogErrorStack); err != nil {
 			return nil, errs.E(op, errs.Validation, "Invalid value sent for log_error_stack")
 		}
+	}
+
+	type componentLevel struct {
+		lvl    zerolog.Level
+		ttl    time.Duration
+		remove bool
+	}
+	components := make(map[string]componentLevel, len(r.ComponentLogLevels))
+	for i, clr := range r.ComponentLogLevels {
+		if clr.Component == "" {
+			return nil, errs.E(op, errs.Validation, errs.Parameter("component"), fmt.Sprintf("component_log_levels[%d] has no component", i))
+		}
+		if clr.LogLevel == "" {
+			if clr.TTL != "" {
+				return nil, errs.E(op, errs.Validation, errs.Parameter("ttl"), fmt.Sprintf("ttl for component %s requires log_level", clr.Component))
+			}
+			components[clr.Component] = componentLevel{remove: true}
+			continue
+		}
+		var cl componentLevel
+		cl.lvl, err = zerolog.ParseLevel(clr.LogLevel)
+		if err != nil {
+			return nil, errs.E(op, errs.Validation, errs.Parameter("log_level"), fmt.Sprintf("component %s: %s", clr.Component, err))
+		}
+		if clr.TTL != "" {
+			cl.ttl, err = parseLogLevelTTL("ttl", clr.TTL)
+			if err != nil {
+				return nil, errs.E(op, err)
+			}
+		}
+		components[clr.Component] = cl
+	}
+
+	// the request is the detail of the event, it holds no secrets
+	detail, err := json.Marshal(r)
+	if err != nil {
+		return nil, errs.E(op, errs.Internal, err)
+	}
+	e := diygoapi.NewSecurityEvent(diygoapi.UpdateLoggerSecurityAction, diygoapi.SuccessSecurityOutcome, adt)
+	e.Detail = string(detail)
+	err = ls.SecurityEventServicer.Record(ctx, e)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if r.GlobalLogLevel != "" {
+		ls.Levels.SetDefault(lvl, ttl)
+		ls.Logger.Info().Str("global_log_level", lvl.String()).Dur("ttl", ttl).Msg("global log level updated")
+	}
+	if r.LogErrorStack != "" {
 		// use input LogErrorStack boolean to determine whether to write error stack
 		logger.LogErrorStackViaPkgErrors(les)
 	}
+	for c, cl := range components {
+		if cl.remove {
+			ls.Levels.RemoveComponent(c)
+			ls.Logger.Info().Str("component", c).Msg("component log level removed")
+			continue
+		}
+		ls.Levels.SetComponent(c, cl.lvl, cl.ttl)
+		ls.Logger.Info().Str("component", c).Str("log_level", cl.lvl.String()).Dur("ttl", cl.ttl).Msg("component log level updated")
+	}
+
+	return ls.newLoggerResponse(), nil
+}
 
+// newLoggerResponse returns the current state of the logger
+func (ls *LoggerService) newLoggerResponse() *diygoapi.LoggerResponse {
 	var logErrorStack bool
 	if zerolog.ErrorStackMarshaler != nil {
 		logErrorStack = true
 	}
 
+	lvl, reverts := ls.Levels.Default()
 	response := &diygoapi.LoggerResponse{
 		LoggerMinimumLevel: ls.Logger.GetLevel().String(),
-		GlobalLogLevel:     zerolog.GlobalLevel().String(),
+		GlobalLogLevel:     lvl.String(),
 		LogErrorStack:      logErrorStack,
+		ComponentLogLevels: []diygoapi.ComponentLogLevelResponse{},
+	}
+	if !reverts.IsZero() {
+		response.GlobalLogLevelRevertsAt = reverts.Format(time.RFC3339)
+	}
+	for _, cl := range ls.Levels.Components() {
+		clr := diygoapi.ComponentLogLevelResponse{
+			Component: cl.Component,
+			LogLevel:  cl.Level.String(),
+		}
+		if !cl.Expires.IsZero() {
+			clr.ExpiresAt = cl.Expires.Format(time.RFC3339)
+		}
+		response.ComponentLogLevels = append(response.ComponentLogLevels, clr)
+	}
+
+	return response
+}
+
+// parseLogLevelTTL parses the TTL of a log level, which must be a
+// positive duration
+func parseLogLevelTTL(param, s string) (time.Duration, error) {
+	const op errs.Op = "service/parseLogLevelTTL"
+
+	ttl, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errs.E(op, errs.Validation, errs.Parameter(param), err)
+	}
+	if ttl <= 0 {
+		return 0, errs.E(op, errs.Validation, errs.Parameter(param), param+" must be a positive duration")
 	}
 
-	return response, nil
+	return ttl, nil
 }
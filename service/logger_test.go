@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/logger"
+	"github.com/gilcrest/diygoapi/service"
+)
+
+// securityEventRecorder is a diygoapi.SecurityEventServicer which
+// keeps the events it records, or fails with err
+type securityEventRecorder struct {
+	events []diygoapi.SecurityEvent
+	err    error
+}
+
+func (r *securityEventRecorder) Record(_ context.Context, e diygoapi.SecurityEvent) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.events = append(r.events, e)
+	return nil
+}
+
+func (r *securityEventRecorder) FindSecurityEvents(context.Context, *diygoapi.FindSecurityEventsRequest) ([]*diygoapi.SecurityEventResponse, error) {
+	return nil, nil
+}
+
+func TestLoggerService(t *testing.T) {
+	newLoggerService := func() *service.LoggerService {
+		return &service.LoggerService{
+			Logger:                zerolog.Nop().Level(zerolog.DebugLevel),
+			Levels:                logger.NewLevels(zerolog.InfoLevel),
+			SecurityEventServicer: &securityEventRecorder{},
+		}
+	}
+	adt := diygoapi.Audit{App: &diygoapi.App{Name: "app"}, User: &diygoapi.User{FirstName: "Otto"}}
+
+	t.Run("update", func(t *testing.T) {
+		c := qt.New(t)
+
+		ls := newLoggerService()
+		got, err := ls.Update(context.Background(), &diygoapi.LoggerRequest{
+			GlobalLogLevel:    "debug",
+			GlobalLogLevelTTL: "30m",
+			ComponentLogLevels: []diygoapi.ComponentLogLevelRequest{
+				{Component: "service/MovieService", LogLevel: "trace", TTL: "1h"},
+				{Component: "GET /api/v1/movies", LogLevel: "warn"},
+			},
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got.LoggerMinimumLevel, qt.Equals, "debug")
+		c.Assert(got.GlobalLogLevel, qt.Equals, "debug")
+		c.Assert(got.GlobalLogLevelRevertsAt, qt.Not(qt.Equals), "")
+		c.Assert(got.ComponentLogLevels, qt.HasLen, 2)
+		c.Assert(got.ComponentLogLevels[0].Component, qt.Equals, "GET /api/v1/movies")
+		c.Assert(got.ComponentLogLevels[0].LogLevel, qt.Equals, "warn")
+		c.Assert(got.ComponentLogLevels[0].ExpiresAt, qt.Equals, "")
+		c.Assert(got.ComponentLogLevels[1].Component, qt.Equals, "service/MovieService")
+		c.Assert(got.ComponentLogLevels[1].ExpiresAt, qt.Not(qt.Equals), "")
+
+		// a level without a TTL is kept, an empty level removes the
+		// one set for a component
+		got, err = ls.Update(context.Background(), &diygoapi.LoggerRequest{
+			GlobalLogLevel: "warn",
+			ComponentLogLevels: []diygoapi.ComponentLogLevelRequest{
+				{Component: "GET /api/v1/movies"},
+			},
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got.GlobalLogLevel, qt.Equals, "warn")
+		c.Assert(got.GlobalLogLevelRevertsAt, qt.Equals, "")
+		c.Assert(got.ComponentLogLevels, qt.HasLen, 1)
+		c.Assert(ls.Read(), qt.DeepEquals, got)
+
+		// each update is recorded as a security event of the user
+		events := ls.SecurityEventServicer.(*securityEventRecorder).events
+		c.Assert(events, qt.HasLen, 2)
+		c.Assert(events[1].Action, qt.Equals, diygoapi.UpdateLoggerSecurityAction)
+		c.Assert(events[1].Outcome, qt.Equals, diygoapi.SuccessSecurityOutcome)
+		c.Assert(events[1].Actor, qt.Equals, adt.User)
+		c.Assert(events[1].Detail, qt.Contains, `"global_log_level":"warn"`)
+	})
+
+	t.Run("not recorded", func(t *testing.T) {
+		c := qt.New(t)
+
+		// an update which cannot be recorded is not applied
+		ls := newLoggerService()
+		ls.SecurityEventServicer = &securityEventRecorder{err: errs.E(errs.Database, "no database")}
+		_, err := ls.Update(context.Background(), &diygoapi.LoggerRequest{GlobalLogLevel: "trace"}, adt)
+		c.Assert(errs.Match(errs.E(errs.Database), err), qt.IsTrue, qt.Commentf("%v", err))
+		c.Assert(ls.Read().GlobalLogLevel, qt.Equals, "info")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			r     *diygoapi.LoggerRequest
+			param errs.Parameter
+		}{
+			{"global level", &diygoapi.LoggerRequest{GlobalLogLevel: "loud"}, "global_log_level"},
+			{"global ttl without level", &diygoapi.LoggerRequest{GlobalLogLevelTTL: "1m"}, "global_log_level_ttl"},
+			{"global ttl", &diygoapi.LoggerRequest{GlobalLogLevel: "debug", GlobalLogLevelTTL: "-1m"}, "global_log_level_ttl"},
+			{"no component", &diygoapi.LoggerRequest{ComponentLogLevels: []diygoapi.ComponentLogLevelRequest{{LogLevel: "debug"}}}, "component"},
+			{"component level", &diygoapi.LoggerRequest{ComponentLogLevels: []diygoapi.ComponentLogLevelRequest{{Component: "service", LogLevel: "loud"}}}, "log_level"},
+			{"component ttl", &diygoapi.LoggerRequest{ComponentLogLevels: []diygoapi.ComponentLogLevelRequest{{Component: "service", LogLevel: "debug", TTL: "soon"}}}, "ttl"},
+			{"component ttl without level", &diygoapi.LoggerRequest{ComponentLogLevels: []diygoapi.ComponentLogLevelRequest{{Component: "service", TTL: "1m"}}}, "ttl"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				c := qt.New(t)
+
+				ls := newLoggerService()
+				// a valid global level is not applied along with an
+				// invalid component level
+				if tt.r.GlobalLogLevel == "" && tt.r.GlobalLogLevelTTL == "" {
+					tt.r.GlobalLogLevel = "trace"
+				}
+				_, err := ls.Update(context.Background(), tt.r, adt)
+				c.Assert(errs.Match(errs.E(errs.Validation, tt.param), err), qt.IsTrue, qt.Commentf("%v", err))
+				c.Assert(ls.Read().GlobalLogLevel, qt.Equals, "info")
+				c.Assert(ls.SecurityEventServicer.(*securityEventRecorder).events, qt.HasLen, 0)
+			})
+		}
+	})
+}
@@ -1,17 +1,27 @@
 package service
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/gilcrest/diygoapi"
 	"github.com/gilcrest/diygoapi/errs"
 	"github.com/gilcrest/diygoapi/secure"
 	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
 	"github.com/gilcrest/diygoapi/uuid"
 )
 
@@ -23,14 +33,25 @@ type movieAudit struct {
 
 // newMovieResponse initializes MovieResponse
 func newMovieResponse(ma movieAudit) *diygoapi.MovieResponse {
+	var posterURL string
+	if ma.Movie.Poster != nil {
+		posterURL = ma.Movie.Poster.URL(ma.Movie.ExternalID.String())
+	}
+
 	return &diygoapi.MovieResponse{
 		ExternalID:          ma.Movie.ExternalID.String(),
 		Title:               ma.Movie.Title,
 		Rated:               ma.Movie.Rated,
+		RatingCountry:       ma.Movie.RatingCountry,
 		Released:            ma.Movie.Released.Format(time.RFC3339),
 		RunTime:             ma.Movie.RunTime,
 		Director:            ma.Movie.Director,
 		Writer:              ma.Movie.Writer,
+		Credits:             newCreditResponses(ma.Movie.Credits),
+		Genres:              newGenreResponses(ma.Movie.Genres),
+		AverageScore:        diygoapi.AverageScore(ma.Movie.ReviewCount, ma.Movie.ReviewScoreTotal),
+		ReviewCount:         ma.Movie.ReviewCount,
+		PosterURL:           posterURL,
 		CreateAppExtlID:     ma.SimpleAudit.Create.App.ExternalID.String(),
 		CreateUserFirstName: ma.SimpleAudit.Create.User.FirstName,
 		CreateUserLastName:  ma.SimpleAudit.Create.User.LastName,
@@ -42,59 +63,229 @@ func newMovieResponse(ma movieAudit) *diygoapi.MovieResponse {
 	}
 }
 
-// MovieService is a service for creating a Movie
-type MovieService struct {
-	Datastorer diygoapi.Datastorer
-}
-
-// Create is used to create a Movie
-func (s *MovieService) Create(ctx context.Context, r *diygoapi.CreateMovieRequest, adt diygoapi.Audit) (mr *diygoapi.MovieResponse, err error) {
-	const op errs.Op = "service/MovieService.Create"
-
-	if r == nil {
-		return nil, errs.E(op, errs.Validation, "CreateMovieRequest must have a value when creating a Movie")
-	}
+// newMovie initializes and validates a Movie from a CreateMovieRequest,
+// generating its ID and External ID. Genre codes are resolved using
+// the given genre taxonomy and the rating is normalized to and
+// validated against the given ratings.
+func newMovie(r *diygoapi.CreateMovieRequest, gt genreTaxonomy, ratings diygoapi.Ratings) (diygoapi.Movie, error) {
+	const op errs.Op = "service/newMovie"
 
-	var released time.Time
-	released, err = time.Parse(time.RFC3339, r.Released)
+	released, err := time.Parse(time.RFC3339, r.Released)
 	if err != nil {
-		return nil, errs.E(op, errs.Validation,
+		return diygoapi.Movie{}, errs.E(op, errs.Validation,
 			errs.Code("invalid_date_format"),
 			errs.Parameter("release_date"),
 			err)
 	}
 
-	// initialize Movie and inject dependent fields
+	ratingCountry := diygoapi.DefaultRatingCountry
+	if r.RatingCountry != "" {
+		ratingCountry = strings.ToUpper(r.RatingCountry)
+	}
+
 	m := diygoapi.Movie{
-		ID:         uuid.New(),
-		ExternalID: secure.NewID(),
-		Title:      r.Title,
-		Rated:      r.Rated,
-		Released:   released,
-		RunTime:    r.RunTime,
-		Director:   r.Director,
-		Writer:     r.Writer,
+		ID:            uuid.New(),
+		ExternalID:    secure.NewID(),
+		Title:         r.Title,
+		Rated:         ratings.Normalize(ratingCountry, r.Rated),
+		RatingCountry: ratingCountry,
+		Released:      released,
+		RunTime:       r.RunTime,
+		Director:      r.Director,
+		Writer:        r.Writer,
 	}
 
-	sa := diygoapi.SimpleAudit{
-		Create: adt,
-		Update: adt,
+	m.Credits, err = newCredits(r.Credits)
+	if err != nil {
+		return diygoapi.Movie{}, errs.E(op, err)
 	}
+	reconcileCredits(&m)
 
-	err = m.IsValid()
+	m.Genres, err = gt.resolve(r.Genres)
 	if err != nil {
-		return nil, errs.E(op, err)
+		return diygoapi.Movie{}, errs.E(op, err)
+	}
+
+	err = m.IsValid(ratings)
+	if err != nil {
+		return diygoapi.Movie{}, errs.E(op, err)
+	}
+
+	return m, nil
+}
+
+// firstBatchError returns a callback for a batch Exec which sets err
+// to the first error returned by the batch
+func firstBatchError(err *error) func(int, error) {
+	return func(_ int, batchErr error) {
+		if batchErr != nil && *err == nil {
+			*err = batchErr
+		}
+	}
+}
+
+// createMovieRelationsTx writes the credits and genres of the given
+// movies. The movies must already be written.
+func createMovieRelationsTx(ctx context.Context, tx pgx.Tx, movies []diygoapi.Movie, sa diygoapi.SimpleAudit) (err error) {
+	const op errs.Op = "service/createMovieRelationsTx"
+
+	var (
+		creditParams []datastore.CreateMovieCreditsParams
+		genreParams  []datastore.CreateMovieGenresParams
+	)
+	for _, m := range movies {
+		for _, c := range m.Credits {
+			creditParams = append(creditParams, datastore.CreateMovieCreditsParams{
+				MovieCreditID:   uuid.New().PgxUUID(),
+				MovieID:         m.ID.PgxUUID(),
+				CreditRole:      string(c.Role),
+				PersonName:      c.PersonName,
+				CharacterName:   diygoapi.NewPgxText(c.Character),
+				BillingOrder:    int32(c.Order),
+				CreateAppID:     sa.Create.App.ID.PgxUUID(),
+				CreateUserID:    sa.Create.User.ID.PgxUUID(),
+				CreateTimestamp: diygoapi.NewPgxTimestampTZ(sa.Create.Moment),
+				UpdateAppID:     sa.Update.App.ID.PgxUUID(),
+				UpdateUserID:    sa.Update.User.ID.PgxUUID(),
+				UpdateTimestamp: diygoapi.NewPgxTimestampTZ(sa.Update.Moment),
+			})
+		}
+		for _, g := range m.Genres {
+			genreParams = append(genreParams, datastore.CreateMovieGenresParams{
+				MovieID:         m.ID.PgxUUID(),
+				GenreID:         g.ID.PgxUUID(),
+				CreateAppID:     sa.Create.App.ID.PgxUUID(),
+				CreateUserID:    sa.Create.User.ID.PgxUUID(),
+				CreateTimestamp: diygoapi.NewPgxTimestampTZ(sa.Create.Moment),
+				UpdateAppID:     sa.Update.App.ID.PgxUUID(),
+				UpdateUserID:    sa.Update.User.ID.PgxUUID(),
+				UpdateTimestamp: diygoapi.NewPgxTimestampTZ(sa.Update.Moment),
+			})
+		}
+	}
+
+	q := datastore.New(tx)
+
+	if len(creditParams) > 0 {
+		q.CreateMovieCredits(ctx, creditParams).Exec(firstBatchError(&err))
+		if err != nil {
+			return errs.E(op, errs.Database, err)
+		}
+	}
+
+	if len(genreParams) > 0 {
+		q.CreateMovieGenres(ctx, genreParams).Exec(firstBatchError(&err))
+		if err != nil {
+			return errs.E(op, errs.Database, err)
+		}
+	}
+
+	return nil
+}
+
+// findMovieRelations retrieves the credits, genres and poster of the
+// given movies from the datastore and sets them to each Movie
+func findMovieRelations(ctx context.Context, dbtx datastore.DBTX, movies ...*diygoapi.Movie) error {
+	const op errs.Op = "service/findMovieRelations"
+
+	if len(movies) == 0 {
+		return nil
+	}
+
+	ids := make([]pgtype.UUID, 0, len(movies))
+	byID := make(map[uuid.UUID]*diygoapi.Movie, len(movies))
+	for _, m := range movies {
+		ids = append(ids, m.ID.PgxUUID())
+		byID[m.ID] = m
+	}
+
+	q := datastore.New(dbtx)
+
+	creditRows, err := q.FindMovieCreditsByMovieIDs(ctx, ids)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+	for _, row := range creditRows {
+		m := byID[row.MovieID.Bytes]
+		m.Credits = append(m.Credits, diygoapi.Credit{
+			Role:       diygoapi.CreditRole(row.CreditRole),
+			PersonName: row.PersonName,
+			Character:  row.CharacterName.String,
+			Order:      int(row.BillingOrder),
+		})
+	}
+
+	genreRows, err := q.FindMovieGenresByMovieIDs(ctx, ids)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+	for _, row := range genreRows {
+		m := byID[row.MovieID.Bytes]
+		m.Genres = append(m.Genres, diygoapi.Genre{
+			ID:   row.GenreID.Bytes,
+			Code: row.GenreCd,
+			Name: row.GenreName,
+		})
+	}
+
+	posterRows, err := q.FindMoviePostersByMovieIDs(ctx, ids)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
 	}
+	for _, row := range posterRows {
+		p := newPosterFromRow(row)
+		byID[row.MovieID.Bytes].Poster = &p
+	}
+
+	return nil
+}
+
+// actingOrgID returns the ID of the org of the app in the audit.
+// Movies are owned by an org and every movie query is scoped to the
+// acting org, so a movie owned by another org is treated as not existing.
+func actingOrgID(adt diygoapi.Audit) (pgtype.UUID, error) {
+	const op errs.Op = "service/actingOrgID"
+
+	if adt.App == nil || adt.App.Org == nil || adt.App.Org.ID == uuid.Nil {
+		return pgtype.UUID{}, errs.E(op, errs.Internal, "audit app org must be set to act on movies")
+	}
+
+	return adt.App.Org.ID.PgxUUID(), nil
+}
+
+// findOrgMovie finds a movie given its external ID, scoped to the
+// acting org
+func findOrgMovie(ctx context.Context, tx pgx.Tx, extlID string, orgID pgtype.UUID) (datastore.Movie, error) {
+	const op errs.Op = "service/findOrgMovie"
+
+	dbm, err := datastore.New(tx).FindMovieByExternalID(ctx, datastore.FindMovieByExternalIDParams{
+		ExtlID: extlID,
+		OrgID:  orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return datastore.Movie{}, errs.E(op, errs.NotExist, "no movie exists for the given external ID")
+		}
+		return datastore.Movie{}, errs.E(op, errs.Database, err)
+	}
+
+	return dbm, nil
+}
 
-	createMovieParams := datastore.CreateMovieParams{
+// newCreateMovieParams maps a Movie, its owning org and its audit to
+// the params needed to insert it
+func newCreateMovieParams(m diygoapi.Movie, orgID pgtype.UUID, sa diygoapi.SimpleAudit) datastore.CreateMovieParams {
+	return datastore.CreateMovieParams{
 		MovieID:         m.ID.PgxUUID(),
 		ExtlID:          m.ExternalID.String(),
+		OrgID:           orgID,
 		Title:           m.Title,
 		Rated:           diygoapi.NewPgxText(m.Rated),
-		Released:        diygoapi.NewPgxDate(released),
+		Released:        diygoapi.NewPgxDate(m.Released),
 		RunTime:         diygoapi.NewPgxInt8(m.RunTime),
 		Director:        diygoapi.NewPgxText(m.Director),
 		Writer:          diygoapi.NewPgxText(m.Writer),
+		RatingCountry:   m.RatingCountry,
 		CreateAppID:     sa.Create.App.ID.PgxUUID(),
 		CreateUserID:    sa.Create.User.ID.PgxUUID(),
 		CreateTimestamp: diygoapi.NewPgxTimestampTZ(sa.Create.Moment),
@@ -102,6 +293,32 @@ func (s *MovieService) Create(ctx context.Context, r *diygoapi.CreateMovieReques
 		UpdateUserID:    sa.Update.User.ID.PgxUUID(),
 		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(sa.Update.Moment),
 	}
+}
+
+// MovieService is a service for creating a Movie
+type MovieService struct {
+	Datastorer diygoapi.Datastorer
+	// DeleteRetention is how long a deleted movie can be restored.
+	// diygoapi.DefaultDeleteRetention is used if not set.
+	DeleteRetention time.Duration
+}
+
+// Create is used to create a Movie
+func (s *MovieService) Create(ctx context.Context, r *diygoapi.CreateMovieRequest, adt diygoapi.Audit) (mr *diygoapi.MovieResponse, err error) {
+	const op errs.Op = "service/MovieService.Create"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	if r == nil {
+		return nil, errs.E(op, errs.Validation, "CreateMovieRequest must have a value when creating a Movie")
+	}
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
 
 	// start db txn using pgxpool
 	var tx pgx.Tx
@@ -114,11 +331,64 @@ func (s *MovieService) Create(ctx context.Context, r *diygoapi.CreateMovieReques
 		err = s.Datastorer.RollbackTx(ctx, tx, err)
 	}()
 
+	var gt genreTaxonomy
+	gt, err = findGenreTaxonomy(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var ratings diygoapi.Ratings
+	ratings, err = findRatings(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var m diygoapi.Movie
+	m, err = newMovie(r, gt, ratings)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	sa := diygoapi.SimpleAudit{
+		Create: adt,
+		Update: adt,
+	}
+
+	createMovieParams := newCreateMovieParams(m, orgID, sa)
+
 	_, err = datastore.New(tx).CreateMovie(ctx, createMovieParams)
 	if err != nil {
 		return nil, errs.E(op, errs.Database, err)
 	}
 
+	err = createMovieRelationsTx(ctx, tx, []diygoapi.Movie{m}, sa)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.MovieEntityType,
+		EntityID:     m.ID,
+		EntityExtlID: m.ExternalID.String(),
+		Action:       diygoapi.CreateChangeAction,
+		After:        newMovieImage(m),
+		Audit:        adt,
+	})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = emitEventTx(ctx, tx, eventParams{
+		Type:          diygoapi.MovieCreatedEvent,
+		OrgID:         orgID.Bytes,
+		SubjectExtlID: m.ExternalID.String(),
+		Data:          newMovieImage(m),
+		Moment:        adt.Moment,
+	})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
@@ -134,6 +404,15 @@ func (s *MovieService) Create(ctx context.Context, r *diygoapi.CreateMovieReques
 func (s *MovieService) Update(ctx context.Context, r *diygoapi.UpdateMovieRequest, adt diygoapi.Audit) (mr *diygoapi.MovieResponse, err error) {
 	const op errs.Op = "service/MovieService.Update"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
 	var released time.Time
 	released, err = time.Parse(time.RFC3339, r.Released)
 	if err != nil {
@@ -156,34 +435,87 @@ func (s *MovieService) Update(ctx context.Context, r *diygoapi.UpdateMovieReques
 
 	// retrieve existing Movie
 	var row datastore.FindMovieByExternalIDWithAuditRow
-	row, err = datastore.New(tx).FindMovieByExternalIDWithAudit(ctx, r.ExternalID)
+	row, err = datastore.New(tx).FindMovieByExternalIDWithAudit(ctx, datastore.FindMovieByExternalIDWithAuditParams{
+		ExtlID: r.ExternalID,
+		OrgID:  orgID,
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errs.E(op, errs.Validation, "No movie exists for the given external ID")
+			return nil, errs.E(op, errs.NotExist, "No movie exists for the given external ID")
 		}
 		return nil, errs.E(op, errs.Database, err)
 	}
 
 	m := diygoapi.Movie{
-		ID:         row.MovieID.Bytes,
-		ExternalID: secure.MustParseIdentifier(row.ExtlID),
-		Title:      row.Title,
-		Rated:      row.Rated.String,
-		Released:   row.Released.Time,
-		RunTime:    row.RunTime.Int64,
-		Director:   row.Director.String,
-		Writer:     row.Writer.String,
+		ID:               row.MovieID.Bytes,
+		ExternalID:       secure.MustParseIdentifier(row.ExtlID),
+		Title:            row.Title,
+		Rated:            row.Rated.String,
+		RatingCountry:    row.RatingCountry,
+		ReviewCount:      int64(row.ReviewCount),
+		ReviewScoreTotal: row.ReviewScoreTotal,
+		Released:         row.Released.Time,
+		RunTime:          row.RunTime.Int64,
+		Director:         row.Director.String,
+		Writer:           row.Writer.String,
+	}
+
+	err = findMovieRelations(ctx, tx, &m)
+	if err != nil {
+		return nil, errs.E(op, err)
 	}
 
+	// capture the movie as it exists prior to the update for change history
+	before := newMovieImage(m)
+
 	// update fields from request
 	m.Title = r.Title
+	if r.RatingCountry != "" {
+		m.RatingCountry = strings.ToUpper(r.RatingCountry)
+	}
 	m.Rated = r.Rated
 	m.Released = released
 	m.RunTime = r.RunTime
+
+	if r.Credits != nil {
+		m.Credits, err = newCredits(r.Credits)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+	} else {
+		// keep the existing credits, but a changed director or writer
+		// replaces the credits for that role
+		if r.Director != "" && r.Director != m.Director {
+			m.Credits = withoutCreditRole(m.Credits, diygoapi.DirectorCreditRole)
+		}
+		if r.Writer != "" && r.Writer != m.Writer {
+			m.Credits = withoutCreditRole(m.Credits, diygoapi.WriterCreditRole)
+		}
+	}
 	m.Director = r.Director
 	m.Writer = r.Writer
+	reconcileCredits(&m)
+
+	if r.Genres != nil {
+		var gt genreTaxonomy
+		gt, err = findGenreTaxonomy(ctx, tx)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+		m.Genres, err = gt.resolve(r.Genres)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+	}
+
+	var ratings diygoapi.Ratings
+	ratings, err = findRatings(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	m.Rated = ratings.Normalize(m.RatingCountry, m.Rated)
 
-	err = m.IsValid()
+	err = m.IsValid(ratings)
 	if err != nil {
 		return nil, errs.E(op, err)
 	}
@@ -216,10 +548,12 @@ func (s *MovieService) Update(ctx context.Context, r *diygoapi.UpdateMovieReques
 		RunTime:         diygoapi.NewPgxInt8(m.RunTime),
 		Director:        diygoapi.NewPgxText(m.Director),
 		Writer:          diygoapi.NewPgxText(m.Writer),
+		RatingCountry:   m.RatingCountry,
 		UpdateAppID:     adt.App.ID.PgxUUID(),
 		UpdateUserID:    adt.User.ID.PgxUUID(),
 		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
 		MovieID:         m.ID.PgxUUID(),
+		OrgID:           orgID,
 	}
 
 	err = datastore.New(tx).UpdateMovie(ctx, updateMovieParams)
@@ -227,6 +561,44 @@ func (s *MovieService) Update(ctx context.Context, r *diygoapi.UpdateMovieReques
 		return nil, errs.E(op, errs.Database, err)
 	}
 
+	// credits and genres are replaced rather than updated in place
+	_, err = datastore.New(tx).DeleteMovieCredits(ctx, m.ID.PgxUUID())
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+	_, err = datastore.New(tx).DeleteMovieGenres(ctx, m.ID.PgxUUID())
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+	err = createMovieRelationsTx(ctx, tx, []diygoapi.Movie{m}, diygoapi.SimpleAudit{Create: adt, Update: adt})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.MovieEntityType,
+		EntityID:     m.ID,
+		EntityExtlID: m.ExternalID.String(),
+		Action:       diygoapi.UpdateChangeAction,
+		Before:       before,
+		After:        newMovieImage(m),
+		Audit:        adt,
+	})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = emitEventTx(ctx, tx, eventParams{
+		Type:          diygoapi.MovieUpdatedEvent,
+		OrgID:         orgID.Bytes,
+		SubjectExtlID: m.ExternalID.String(),
+		Data:          newMovieImage(m),
+		Moment:        adt.Moment,
+	})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
@@ -238,10 +610,20 @@ func (s *MovieService) Update(ctx context.Context, r *diygoapi.UpdateMovieReques
 	return mr, nil
 }
 
-// Delete is used to delete a movie
-func (s *MovieService) Delete(ctx context.Context, extlID string) (dr diygoapi.DeleteResponse, err error) {
+// Delete is used to delete a movie. The movie is marked as deleted
+// and can be restored until the retention period has passed.
+func (s *MovieService) Delete(ctx context.Context, extlID string, adt diygoapi.Audit) (dr diygoapi.DeleteResponse, err error) {
 	const op errs.Op = "service/MovieService.Delete"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -255,16 +637,27 @@ func (s *MovieService) Delete(ctx context.Context, extlID string) (dr diygoapi.D
 
 	// retrieve existing Movie
 	var dbm datastore.Movie
-	dbm, err = datastore.New(tx).FindMovieByExternalID(ctx, extlID)
+	dbm, err = datastore.New(tx).FindMovieByExternalID(ctx, datastore.FindMovieByExternalIDParams{
+		ExtlID: extlID,
+		OrgID:  orgID,
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return diygoapi.DeleteResponse{}, errs.E(op, errs.Validation, "No movie exists for the given external ID")
+			return diygoapi.DeleteResponse{}, errs.E(op, errs.NotExist, "No movie exists for the given external ID")
 		}
 		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
 	}
 
+	softDeleteMovieParams := datastore.SoftDeleteMovieParams{
+		DeleteAppID:     adt.App.ID.PgxUUID(),
+		DeleteUserID:    adt.User.ID.PgxUUID(),
+		DeleteTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+		MovieID:         dbm.MovieID,
+		OrgID:           orgID,
+	}
+
 	var rowsAffected int64
-	rowsAffected, err = datastore.New(tx).DeleteMovie(ctx, dbm.MovieID)
+	rowsAffected, err = datastore.New(tx).SoftDeleteMovie(ctx, softDeleteMovieParams)
 	if err != nil {
 		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
 	}
@@ -273,6 +666,48 @@ func (s *MovieService) Delete(ctx context.Context, extlID string) (dr diygoapi.D
 		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
 	}
 
+	m := diygoapi.Movie{
+		ID:               dbm.MovieID.Bytes,
+		ExternalID:       secure.MustParseIdentifier(dbm.ExtlID),
+		Title:            dbm.Title,
+		Rated:            dbm.Rated.String,
+		RatingCountry:    dbm.RatingCountry,
+		ReviewCount:      int64(dbm.ReviewCount),
+		ReviewScoreTotal: dbm.ReviewScoreTotal,
+		Released:         dbm.Released.Time,
+		RunTime:          dbm.RunTime.Int64,
+		Director:         dbm.Director.String,
+		Writer:           dbm.Writer.String,
+	}
+
+	err = findMovieRelations(ctx, tx, &m)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.MovieEntityType,
+		EntityID:     m.ID,
+		EntityExtlID: m.ExternalID.String(),
+		Action:       diygoapi.DeleteChangeAction,
+		Before:       newMovieImage(m),
+		Audit:        adt,
+	})
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	err = emitEventTx(ctx, tx, eventParams{
+		Type:          diygoapi.MovieDeletedEvent,
+		OrgID:         orgID.Bytes,
+		SubjectExtlID: m.ExternalID.String(),
+		Data:          newMovieImage(m),
+		Moment:        adt.Moment,
+	})
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
@@ -287,9 +722,19 @@ func (s *MovieService) Delete(ctx context.Context, extlID string) (dr diygoapi.D
 	return response, nil
 }
 
-// FindMovieByExternalID is used to find an individual movie
-func (s *MovieService) FindMovieByExternalID(ctx context.Context, extlID string) (mr *diygoapi.MovieResponse, err error) {
-	const op errs.Op = "service/MovieService.FindMovieByExternalID"
+// Restore is used to restore a deleted movie, provided it was
+// deleted within the retention period
+func (s *MovieService) Restore(ctx context.Context, extlID string, adt diygoapi.Audit) (mr *diygoapi.MovieResponse, err error) {
+	const op errs.Op = "service/MovieService.Restore"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
 
 	// start db txn using pgxpool
 	var tx pgx.Tx
@@ -302,75 +747,211 @@ func (s *MovieService) FindMovieByExternalID(ctx context.Context, extlID string)
 		err = s.Datastorer.RollbackTx(ctx, tx, err)
 	}()
 
-	var row datastore.FindMovieByExternalIDWithAuditRow
-	row, err = datastore.New(tx).FindMovieByExternalIDWithAudit(ctx, extlID)
+	// retrieve deleted Movie
+	var dbm datastore.Movie
+	dbm, err = datastore.New(tx).FindDeletedMovieByExternalID(ctx, datastore.FindDeletedMovieByExternalIDParams{
+		ExtlID: extlID,
+		OrgID:  orgID,
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errs.E(op, errs.Validation, "no movie exists for the given external ID")
+			return nil, errs.E(op, errs.NotExist, "No deleted movie exists for the given external ID")
 		}
 		return nil, errs.E(op, errs.Database, err)
 	}
 
-	m := diygoapi.Movie{
-		ID:         row.MovieID.Bytes,
-		ExternalID: secure.MustParseIdentifier(row.ExtlID),
-		Title:      row.Title,
-		Rated:      row.Rated.String,
-		Released:   row.Released.Time,
-		RunTime:    row.RunTime.Int64,
-		Director:   row.Director.String,
-		Writer:     row.Writer.String,
+	if !restorable(dbm.DeleteTimestamp.Time, s.DeleteRetention, adt.Moment) {
+		return nil, errs.E(op, errs.Validation, "movie was deleted before the retention period and can no longer be restored")
 	}
 
-	sa := diygoapi.SimpleAudit{
-		Create: diygoapi.Audit{
-			App: &diygoapi.App{
-				ID:          row.CreateAppID.Bytes,
-				ExternalID:  secure.MustParseIdentifier(row.CreateAppExtlID),
-				Org:         &diygoapi.Org{ID: row.CreateAppOrgID.Bytes},
-				Name:        row.CreateAppName,
-				Description: row.CreateAppDescription,
-				APIKeys:     nil,
-			},
-			User: &diygoapi.User{
-				ID:        row.CreateUserID.Bytes,
-				FirstName: row.CreateUserFirstName.String,
-				LastName:  row.CreateUserLastName.String,
-			},
-			Moment: row.CreateTimestamp.Time,
-		},
-		Update: diygoapi.Audit{
-			App: &diygoapi.App{
-				ID:          row.UpdateAppID.Bytes,
-				ExternalID:  secure.MustParseIdentifier(row.UpdateAppExtlID),
-				Org:         &diygoapi.Org{ID: row.UpdateAppOrgID.Bytes},
-				Name:        row.UpdateAppName,
-				Description: row.UpdateAppDescription,
-				APIKeys:     nil,
-			},
-			User: &diygoapi.User{
-				ID:        row.UpdateUserID.Bytes,
-				FirstName: row.UpdateUserFirstName.String,
-				LastName:  row.UpdateUserLastName.String,
-			},
-			Moment: row.UpdateTimestamp.Time,
-		},
+	restoreMovieParams := datastore.RestoreMovieParams{
+		UpdateAppID:     adt.App.ID.PgxUUID(),
+		UpdateUserID:    adt.User.ID.PgxUUID(),
+		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+		MovieID:         dbm.MovieID,
+		OrgID:           orgID,
 	}
 
-	mr = newMovieResponse(movieAudit{m, sa})
-
-	return mr, nil
-}
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).RestoreMovie(ctx, restoreMovieParams)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
 
-// FindAllMovies is used to list all movies in the db
-func (s *MovieService) FindAllMovies(ctx context.Context) (smr []*diygoapi.MovieResponse, err error) {
-	const op errs.Op = "service/MovieService.FindAllMovies"
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
 
-	// start db txn using pgxpool
-	var tx pgx.Tx
-	tx, err = s.Datastorer.BeginTx(ctx)
+	var row datastore.FindMovieByExternalIDWithAuditRow
+	row, err = datastore.New(tx).FindMovieByExternalIDWithAudit(ctx, datastore.FindMovieByExternalIDWithAuditParams{
+		ExtlID: extlID,
+		OrgID:  orgID,
+	})
 	if err != nil {
-		return nil, errs.E(op, err)
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	ma := newMovieAuditFromRow(row)
+
+	err = findMovieRelations(ctx, tx, &ma.Movie)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.MovieEntityType,
+		EntityID:     ma.Movie.ID,
+		EntityExtlID: ma.Movie.ExternalID.String(),
+		Action:       diygoapi.RestoreChangeAction,
+		After:        newMovieImage(ma.Movie),
+		Audit:        adt,
+	})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = emitEventTx(ctx, tx, eventParams{
+		Type:          diygoapi.MovieRestoredEvent,
+		OrgID:         orgID.Bytes,
+		SubjectExtlID: ma.Movie.ExternalID.String(),
+		Data:          newMovieImage(ma.Movie),
+		Moment:        adt.Moment,
+	})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newMovieResponse(ma), nil
+}
+
+// FindMovieByExternalID is used to find an individual movie owned by
+// the acting org
+func (s *MovieService) FindMovieByExternalID(ctx context.Context, extlID string, adt diygoapi.Audit) (mr *diygoapi.MovieResponse, err error) {
+	const op errs.Op = "service/MovieService.FindMovieByExternalID"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var row datastore.FindMovieByExternalIDWithAuditRow
+	row, err = datastore.New(tx).FindMovieByExternalIDWithAudit(ctx, datastore.FindMovieByExternalIDWithAuditParams{
+		ExtlID: extlID,
+		OrgID:  orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.NotExist, "no movie exists for the given external ID")
+		}
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	ma := newMovieAuditFromRow(row)
+
+	err = findMovieRelations(ctx, tx, &ma.Movie)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	mr = newMovieResponse(ma)
+
+	return mr, nil
+}
+
+// newMovieAuditFromRow initializes a movieAudit given a
+// FindMovieByExternalIDWithAuditRow
+func newMovieAuditFromRow(row datastore.FindMovieByExternalIDWithAuditRow) movieAudit {
+	m := diygoapi.Movie{
+		ID:               row.MovieID.Bytes,
+		ExternalID:       secure.MustParseIdentifier(row.ExtlID),
+		Title:            row.Title,
+		Rated:            row.Rated.String,
+		RatingCountry:    row.RatingCountry,
+		ReviewCount:      int64(row.ReviewCount),
+		ReviewScoreTotal: row.ReviewScoreTotal,
+		Released:         row.Released.Time,
+		RunTime:          row.RunTime.Int64,
+		Director:         row.Director.String,
+		Writer:           row.Writer.String,
+	}
+
+	sa := diygoapi.SimpleAudit{
+		Create: diygoapi.Audit{
+			App: &diygoapi.App{
+				ID:          row.CreateAppID.Bytes,
+				ExternalID:  secure.MustParseIdentifier(row.CreateAppExtlID),
+				Org:         &diygoapi.Org{ID: row.CreateAppOrgID.Bytes},
+				Name:        row.CreateAppName,
+				Description: row.CreateAppDescription,
+				APIKeys:     nil,
+			},
+			User: &diygoapi.User{
+				ID:        row.CreateUserID.Bytes,
+				FirstName: row.CreateUserFirstName.String,
+				LastName:  row.CreateUserLastName.String,
+			},
+			Moment: row.CreateTimestamp.Time,
+		},
+		Update: diygoapi.Audit{
+			App: &diygoapi.App{
+				ID:          row.UpdateAppID.Bytes,
+				ExternalID:  secure.MustParseIdentifier(row.UpdateAppExtlID),
+				Org:         &diygoapi.Org{ID: row.UpdateAppOrgID.Bytes},
+				Name:        row.UpdateAppName,
+				Description: row.UpdateAppDescription,
+				APIKeys:     nil,
+			},
+			User: &diygoapi.User{
+				ID:        row.UpdateUserID.Bytes,
+				FirstName: row.UpdateUserFirstName.String,
+				LastName:  row.UpdateUserLastName.String,
+			},
+			Moment: row.UpdateTimestamp.Time,
+		},
+	}
+
+	return movieAudit{m, sa}
+}
+
+// FindAllMovies is used to list all movies owned by the acting org
+func (s *MovieService) FindAllMovies(ctx context.Context, adt diygoapi.Audit) (smr []*diygoapi.MovieResponse, err error) {
+	const op errs.Op = "service/MovieService.FindAllMovies"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
 	}
 	// defer transaction rollback and handle error, if any
 	defer func() {
@@ -378,7 +959,7 @@ func (s *MovieService) FindAllMovies(ctx context.Context) (smr []*diygoapi.Movie
 	}()
 
 	var rows []datastore.FindMoviesRow
-	rows, err = datastore.New(tx).FindMovies(ctx)
+	rows, err = datastore.New(tx).FindMovies(ctx, orgID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errs.E(op, errs.Validation, "no movies exists")
@@ -386,54 +967,686 @@ func (s *MovieService) FindAllMovies(ctx context.Context) (smr []*diygoapi.Movie
 		return nil, errs.E(op, errs.Database, err)
 	}
 
+	smr, err = newMovieResponses(ctx, tx, rows)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return smr, nil
+}
+
+// newMovieAuditFromFindMoviesRow initializes a movieAudit given a
+// FindMoviesRow
+func newMovieAuditFromFindMoviesRow(row datastore.FindMoviesRow) movieAudit {
+	m := diygoapi.Movie{
+		ID:               row.MovieID.Bytes,
+		ExternalID:       secure.MustParseIdentifier(row.ExtlID),
+		Title:            row.Title,
+		Rated:            row.Rated.String,
+		RatingCountry:    row.RatingCountry,
+		ReviewCount:      int64(row.ReviewCount),
+		ReviewScoreTotal: row.ReviewScoreTotal,
+		Released:         row.Released.Time,
+		RunTime:          row.RunTime.Int64,
+		Director:         row.Director.String,
+		Writer:           row.Writer.String,
+	}
+	sa := diygoapi.SimpleAudit{
+		Create: diygoapi.Audit{
+			App: &diygoapi.App{
+				ID:          row.CreateAppID.Bytes,
+				ExternalID:  secure.MustParseIdentifier(row.CreateAppExtlID),
+				Org:         &diygoapi.Org{ID: row.CreateAppOrgID.Bytes},
+				Name:        row.CreateAppName,
+				Description: row.CreateAppDescription,
+				APIKeys:     nil,
+			},
+			User: &diygoapi.User{
+				ID:        row.CreateUserID.Bytes,
+				FirstName: row.CreateUserFirstName.String,
+				LastName:  row.CreateUserLastName.String,
+			},
+			Moment: row.CreateTimestamp.Time,
+		},
+		Update: diygoapi.Audit{
+			App: &diygoapi.App{
+				ID:          row.UpdateAppID.Bytes,
+				ExternalID:  secure.MustParseIdentifier(row.UpdateAppExtlID),
+				Org:         &diygoapi.Org{ID: row.UpdateAppOrgID.Bytes},
+				Name:        row.UpdateAppName,
+				Description: row.UpdateAppDescription,
+				APIKeys:     nil,
+			},
+			User: &diygoapi.User{
+				ID:        row.UpdateUserID.Bytes,
+				FirstName: row.UpdateUserFirstName.String,
+				LastName:  row.UpdateUserLastName.String,
+			},
+			Moment: row.UpdateTimestamp.Time,
+		},
+	}
+
+	return movieAudit{m, sa}
+}
+
+// newMovieResponses initializes MovieResponses given FindMoviesRows,
+// retrieving the credits and genres of every movie
+func newMovieResponses(ctx context.Context, dbtx datastore.DBTX, rows []datastore.FindMoviesRow) ([]*diygoapi.MovieResponse, error) {
+	const op errs.Op = "service/newMovieResponses"
+
+	mas := make([]movieAudit, 0, len(rows))
+	for _, row := range rows {
+		mas = append(mas, newMovieAuditFromFindMoviesRow(row))
+	}
+
+	movies := make([]*diygoapi.Movie, 0, len(mas))
+	for i := range mas {
+		movies = append(movies, &mas[i].Movie)
+	}
+
+	err := findMovieRelations(ctx, dbtx, movies...)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	responses := make([]*diygoapi.MovieResponse, 0, len(mas))
+	for _, ma := range mas {
+		responses = append(responses, newMovieResponse(ma))
+	}
+
+	return responses, nil
+}
+
+// FindMoviesByCredit is used to list the movies owned by the acting org
+// which a person is credited on, optionally in a given role
+func (s *MovieService) FindMoviesByCredit(ctx context.Context, r *diygoapi.FindMoviesByCreditRequest, adt diygoapi.Audit) (smr []*diygoapi.MovieResponse, err error) {
+	const op errs.Op = "service/MovieService.FindMoviesByCredit"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	if r == nil || strings.TrimSpace(r.Name) == "" {
+		return nil, errs.E(op, errs.Validation, errs.Parameter("name"), errs.MissingField("name"))
+	}
+
+	var role pgtype.Text
+	if r.Role != "" {
+		var cr diygoapi.CreditRole
+		cr, err = diygoapi.ParseCreditRole(r.Role)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+		role = diygoapi.NewPgxText(string(cr))
+	}
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rows []datastore.FindMoviesByCreditRow
+	rows, err = datastore.New(tx).FindMoviesByCredit(ctx, datastore.FindMoviesByCreditParams{
+		OrgID:      orgID,
+		PersonName: strings.TrimSpace(r.Name),
+		CreditRole: role,
+	})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	fmr := make([]datastore.FindMoviesRow, 0, len(rows))
 	for _, row := range rows {
-		m := diygoapi.Movie{
-			ID:         row.MovieID.Bytes,
-			ExternalID: secure.MustParseIdentifier(row.ExtlID),
+		fmr = append(fmr, datastore.FindMoviesRow(row))
+	}
+
+	smr, err = newMovieResponses(ctx, tx, fmr)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return smr, nil
+}
+
+// FindMoviesByGenre is used to list the movies owned by the acting org
+// which are categorized in the genre with the given code
+func (s *MovieService) FindMoviesByGenre(ctx context.Context, genre string, adt diygoapi.Audit) (smr []*diygoapi.MovieResponse, err error) {
+	const op errs.Op = "service/MovieService.FindMoviesByGenre"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var gt genreTaxonomy
+	gt, err = findGenreTaxonomy(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	g, ok := gt[strings.ToLower(genre)]
+	if !ok {
+		return nil, errs.E(op, errs.NotExist, fmt.Sprintf("no genre exists for the given code: %q", genre))
+	}
+
+	var rows []datastore.FindMoviesByGenreRow
+	rows, err = datastore.New(tx).FindMoviesByGenre(ctx, datastore.FindMoviesByGenreParams{
+		OrgID:   orgID,
+		GenreCd: g.Code,
+	})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	fmr := make([]datastore.FindMoviesRow, 0, len(rows))
+	for _, row := range rows {
+		fmr = append(fmr, datastore.FindMoviesRow(row))
+	}
+
+	smr, err = newMovieResponses(ctx, tx, fmr)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return smr, nil
+}
+
+// Search finds movies owned by the acting org which match a search
+// query, most relevant first
+func (s *MovieService) Search(ctx context.Context, r *diygoapi.SearchMoviesRequest, adt diygoapi.Audit) (responses []*diygoapi.MovieSearchResponse, err error) {
+	const op errs.Op = "service/MovieService.Search"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	if r == nil {
+		return nil, errs.E(op, errs.Validation, "SearchMoviesRequest must have a value when searching movies")
+	}
+
+	query := strings.TrimSpace(r.Query)
+	if query == "" {
+		return nil, errs.E(op, errs.Validation, errs.Parameter("q"), errs.MissingField("q"))
+	}
+
+	limit := r.Limit
+	switch {
+	case limit == 0:
+		limit = diygoapi.DefaultMovieSearchLimit
+	case limit < 0 || limit > diygoapi.MaxMovieSearchLimit:
+		return nil, errs.E(op, errs.Validation, errs.Parameter("limit"),
+			fmt.Sprintf("limit must be between 1 and %d", diygoapi.MaxMovieSearchLimit))
+	}
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rows []datastore.SearchMoviesRow
+	rows, err = datastore.New(tx).SearchMovies(ctx, datastore.SearchMoviesParams{
+		Search:   query,
+		OrgID:    orgID,
+		RowLimit: int32(limit),
+	})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	responses = make([]*diygoapi.MovieSearchResponse, 0, len(rows))
+	for _, row := range rows {
+		responses = append(responses, &diygoapi.MovieSearchResponse{
+			ExternalID: row.ExtlID,
 			Title:      row.Title,
 			Rated:      row.Rated.String,
-			Released:   row.Released.Time,
+			Released:   row.Released.Time.Format(time.RFC3339),
 			RunTime:    row.RunTime.Int64,
 			Director:   row.Director.String,
 			Writer:     row.Writer.String,
+			Rank:       row.Rank,
+			Snippet:    row.Snippet,
+		})
+	}
+
+	return responses, nil
+}
+
+// movieExportColumns is the CSV header row of a movie export
+var movieExportColumns = []string{"external_id", "title", "rated", "release_date", "run_time", "director", "writer", "create_date_time", "update_date_time"}
+
+// Export streams all movies owned by the acting org to w in the given
+// format. Movies are read from a server-side cursor in batches, so memory
+// use does not grow with the size of the catalog.
+func (s *MovieService) Export(ctx context.Context, f diygoapi.ExportFormat, w io.Writer, adt diygoapi.Audit) (err error) {
+	const op errs.Op = "service/MovieService.Export"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	var ew *exportWriter
+	ew, err = newExportWriter(f, w, movieExportColumns)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	q := datastore.New(tx)
+
+	err = q.DeclareMovieExportCursor(ctx, orgID)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	for {
+		var rows []datastore.FetchMovieExportCursorRow
+		rows, err = q.FetchMovieExportCursor(ctx, exportBatchSize)
+		if err != nil {
+			return errs.E(op, errs.Database, err)
 		}
-		sa := diygoapi.SimpleAudit{
-			Create: diygoapi.Audit{
-				App: &diygoapi.App{
-					ID:          row.CreateAppID.Bytes,
-					ExternalID:  secure.MustParseIdentifier(row.CreateAppExtlID),
-					Org:         &diygoapi.Org{ID: row.CreateAppOrgID.Bytes},
-					Name:        row.CreateAppName,
-					Description: row.CreateAppDescription,
-					APIKeys:     nil,
-				},
-				User: &diygoapi.User{
-					ID:        row.CreateUserID.Bytes,
-					FirstName: row.CreateUserFirstName.String,
-					LastName:  row.CreateUserLastName.String,
-				},
-				Moment: row.CreateTimestamp.Time,
-			},
-			Update: diygoapi.Audit{
-				App: &diygoapi.App{
-					ID:          row.UpdateAppID.Bytes,
-					ExternalID:  secure.MustParseIdentifier(row.UpdateAppExtlID),
-					Org:         &diygoapi.Org{ID: row.UpdateAppOrgID.Bytes},
-					Name:        row.UpdateAppName,
-					Description: row.UpdateAppDescription,
-					APIKeys:     nil,
-				},
-				User: &diygoapi.User{
-					ID:        row.UpdateUserID.Bytes,
-					FirstName: row.UpdateUserLastName.String,
-					LastName:  row.UpdateUserLastName.String,
-				},
-				Moment: row.UpdateTimestamp.Time,
+		if len(rows) == 0 {
+			break
+		}
+
+		for _, row := range rows {
+			me := diygoapi.MovieExport{
+				ExternalID:     row.ExtlID,
+				Title:          row.Title,
+				Rated:          row.Rated.String,
+				Released:       row.Released.Time.Format(time.RFC3339),
+				RunTime:        row.RunTime.Int64,
+				Director:       row.Director.String,
+				Writer:         row.Writer.String,
+				CreateDateTime: row.CreateTimestamp.Time.Format(time.RFC3339),
+				UpdateDateTime: row.UpdateTimestamp.Time.Format(time.RFC3339),
+			}
+			err = ew.Write([]string{me.ExternalID, me.Title, me.Rated, me.Released, strconv.FormatInt(me.RunTime, 10),
+				me.Director, me.Writer, me.CreateDateTime, me.UpdateDateTime}, me)
+			if err != nil {
+				return errs.E(op, err)
+			}
+		}
+
+		err = ew.Flush()
+		if err != nil {
+			return errs.E(op, err)
+		}
+	}
+
+	// flush once more, as an empty CSV export still has its header
+	err = ew.Flush()
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	return nil
+}
+
+// importBatchSize is the number of movies written to the database at a
+// time during a bulk import
+const importBatchSize = 500
+
+// importRow is a single row read from a bulk movie import stream.
+// err is set if the row could not be read.
+type importRow struct {
+	line int
+	req  diygoapi.CreateMovieRequest
+	err  error
+}
+
+// Import creates movies in bulk from a CSV or NDJSON stream. Each row
+// is validated the same as Create and the valid rows are inserted in
+// batches within a single transaction. In AllOrNothingImportMode no
+// movies are imported if any row is invalid, while BestEffortImportMode
+// imports the valid rows and skips the rest. Either way, the response
+// reports the outcome of every row. An AllOrNothingImportMode import
+// which is rejected returns the response along with a Validation error.
+func (s *MovieService) Import(ctx context.Context, r *diygoapi.ImportMoviesRequest, adt diygoapi.Audit) (response *diygoapi.ImportMoviesResponse, err error) {
+	const op errs.Op = "service/MovieService.Import"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	if r == nil || r.Data == nil {
+		return nil, errs.E(op, errs.Validation, "ImportMoviesRequest must have a value when importing movies")
+	}
+
+	var rows []importRow
+	switch r.Format {
+	case diygoapi.CSVImportFormat:
+		rows, err = readCSVImportRows(r.Data)
+	case diygoapi.NDJSONImportFormat:
+		rows, err = readNDJSONImportRows(r.Data)
+	default:
+		return nil, errs.E(op, errs.Validation, fmt.Sprintf("unsupported import format: %q", r.Format))
+	}
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	if len(rows) == 0 {
+		return nil, errs.E(op, errs.Validation, "no movies were found to import")
+	}
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	mode := r.Mode
+	if mode == "" {
+		mode = diygoapi.AllOrNothingImportMode
+	}
+
+	response = &diygoapi.ImportMoviesResponse{
+		Mode:    mode,
+		Rows:    len(rows),
+		Results: make([]diygoapi.ImportMovieResult, len(rows)),
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var gt genreTaxonomy
+	gt, err = findGenreTaxonomy(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var ratings diygoapi.Ratings
+	ratings, err = findRatings(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// validate every row before anything is written, keeping track of
+	// which result each valid movie belongs to
+	var (
+		movies     []diygoapi.Movie
+		resultIdxs []int
+	)
+	for i, row := range rows {
+		response.Results[i].Line = row.line
+
+		var m diygoapi.Movie
+		if row.err == nil {
+			m, row.err = newMovie(&row.req, gt, ratings)
+		}
+		if row.err != nil {
+			response.Results[i].Error = row.err.Error()
+			response.Failed++
+			continue
+		}
+
+		movies = append(movies, m)
+		resultIdxs = append(resultIdxs, i)
+	}
+
+	if response.Failed > 0 && mode == diygoapi.AllOrNothingImportMode {
+		return response, errs.E(op, errs.Validation, errs.Code("import_rejected"),
+			fmt.Sprintf("%d of %d rows are invalid, no movies were imported", response.Failed, response.Rows))
+	}
+	if len(movies) == 0 {
+		return response, nil
+	}
+
+	sa := diygoapi.SimpleAudit{
+		Create: adt,
+		Update: adt,
+	}
+
+	q := datastore.New(tx)
+
+	for start := 0; start < len(movies); start += importBatchSize {
+		batch := movies[start:min(start+importBatchSize, len(movies))]
+
+		movieParams := make([]datastore.CreateMoviesParams, 0, len(batch))
+		historyParams := make([]datastore.CreateChangeHistoriesParams, 0, len(batch))
+		events := make([]eventParams, 0, len(batch))
+		for _, m := range batch {
+			movieParams = append(movieParams, datastore.CreateMoviesParams(newCreateMovieParams(m, orgID, sa)))
+
+			var chp datastore.CreateChangeHistoryParams
+			chp, err = newCreateChangeHistoryParams(changeHistoryParams{
+				EntityType:   diygoapi.MovieEntityType,
+				EntityID:     m.ID,
+				EntityExtlID: m.ExternalID.String(),
+				Action:       diygoapi.CreateChangeAction,
+				After:        newMovieImage(m),
+				Audit:        adt,
+			})
+			if err != nil {
+				return nil, errs.E(op, err)
+			}
+			historyParams = append(historyParams, datastore.CreateChangeHistoriesParams(chp))
+
+			events = append(events, eventParams{
+				Type:          diygoapi.MovieCreatedEvent,
+				OrgID:         orgID.Bytes,
+				SubjectExtlID: m.ExternalID.String(),
+				Data:          newMovieImage(m),
+				Moment:        adt.Moment,
+			})
+		}
+
+		// movies are inserted with a batch of statements rather than
+		// COPY, as COPY FROM is not supported on tables with row level
+		// security
+		q.CreateMovies(ctx, movieParams).Exec(firstBatchError(&err))
+		if err != nil {
+			return nil, errs.E(op, errs.Database, err)
+		}
+
+		err = createMovieRelationsTx(ctx, tx, batch, sa)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+
+		var rowsAffected int64
+		rowsAffected, err = q.CreateChangeHistories(ctx, historyParams)
+		if err != nil {
+			return nil, errs.E(op, errs.Database, err)
+		}
+		if rowsAffected != int64(len(batch)) {
+			return nil, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be %d, actual: %d", len(batch), rowsAffected))
+		}
+
+		err = emitEventsTx(ctx, tx, events)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	for i, m := range movies {
+		response.Results[resultIdxs[i]].ExternalID = m.ExternalID.String()
+	}
+	response.Imported = len(movies)
+
+	return response, nil
+}
+
+// importCSVColumns are the columns allowed in the header row of a CSV
+// import. They match the CreateMovieRequest JSON field names.
+var importCSVColumns = []string{"title", "rated", "rating_country", "release_date", "run_time", "director", "writer"}
+
+// readCSVImportRows reads a CSV import stream. The first record must be
+// a header row naming the columns, which can be in any order. Rows which
+// cannot be parsed are returned with an error rather than stopping the read.
+func readCSVImportRows(r io.Reader) ([]importRow, error) {
+	const op errs.Op = "service/readCSVImportRows"
+
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, errs.E(op, errs.Validation, err)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if !slices.Contains(importCSVColumns, h) {
+			return nil, errs.E(op, errs.Validation, fmt.Sprintf("unknown column in CSV header: %q", h))
+		}
+		if _, ok := cols[h]; ok {
+			return nil, errs.E(op, errs.Validation, fmt.Sprintf("duplicate column in CSV header: %q", h))
+		}
+		cols[h] = i
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	var rows []importRow
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			rows = append(rows, importRow{line: pe.StartLine, err: errs.E(op, errs.Validation, pe.Err)})
+			continue
+		}
+		if err != nil {
+			return nil, errs.E(op, errs.Validation, err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		row := importRow{
+			line: line,
+			req: diygoapi.CreateMovieRequest{
+				Title:         field(record, "title"),
+				Rated:         field(record, "rated"),
+				RatingCountry: field(record, "rating_country"),
+				Released:      field(record, "release_date"),
+				Director:      field(record, "director"),
+				Writer:        field(record, "writer"),
 			},
 		}
-		mr := newMovieResponse(movieAudit{m, sa})
-		smr = append(smr, mr)
+
+		if rt := field(record, "run_time"); rt != "" {
+			row.req.RunTime, err = strconv.ParseInt(rt, 10, 64)
+			if err != nil {
+				row.err = errs.E(op, errs.Validation, errs.Parameter("run_time"), "run_time must be a whole number")
+			}
+		}
+
+		rows = append(rows, row)
 	}
 
-	return smr, nil
+	return rows, nil
+}
+
+// readNDJSONImportRows reads an NDJSON import stream, one
+// CreateMovieRequest per line. Blank lines are skipped.
+func readNDJSONImportRows(r io.Reader) ([]importRow, error) {
+	const op errs.Op = "service/readNDJSONImportRows"
+
+	var rows []importRow
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), int(diygoapi.MaxImportBytes))
+	for line := 1; sc.Scan(); line++ {
+		b := bytes.TrimSpace(sc.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+
+		row := importRow{line: line}
+		err := json.Unmarshal(b, &row.req)
+		if err != nil {
+			row.err = errs.E(op, errs.Validation, err)
+		}
+
+		rows = append(rows, row)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errs.E(op, errs.Validation, err)
+	}
+
+	return rows, nil
 }
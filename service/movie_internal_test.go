@@ -0,0 +1,77 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+func Test_readCSVImportRows(t *testing.T) {
+	t.Run("all columns", func(t *testing.T) {
+		c := qt.New(t)
+
+		const data = `Title, Rated, Rating_Country, Release_Date, Run_Time, Director, Writer
+Withnail and I,15,GB,1987-06-19T00:00:00Z,107,Bruce Robinson,Bruce Robinson
+`
+		rows, err := readCSVImportRows(strings.NewReader(data))
+		c.Assert(err, qt.IsNil)
+		c.Assert(rows, qt.HasLen, 1)
+		c.Assert(rows[0].err, qt.IsNil)
+		c.Assert(rows[0].line, qt.Equals, 2)
+		c.Assert(rows[0].req.Title, qt.Equals, "Withnail and I")
+		c.Assert(rows[0].req.Rated, qt.Equals, "15")
+		c.Assert(rows[0].req.RatingCountry, qt.Equals, "GB")
+		c.Assert(rows[0].req.Released, qt.Equals, "1987-06-19T00:00:00Z")
+		c.Assert(rows[0].req.RunTime, qt.Equals, int64(107))
+		c.Assert(rows[0].req.Director, qt.Equals, "Bruce Robinson")
+		c.Assert(rows[0].req.Writer, qt.Equals, "Bruce Robinson")
+	})
+	t.Run("unknown column", func(t *testing.T) {
+		c := qt.New(t)
+
+		_, err := readCSVImportRows(strings.NewReader("title,country\n"))
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+	})
+	t.Run("bad run time", func(t *testing.T) {
+		c := qt.New(t)
+
+		rows, err := readCSVImportRows(strings.NewReader("title,run_time\nWithnail and I,long\n"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(rows, qt.HasLen, 1)
+		c.Assert(errs.KindIs(errs.Validation, rows[0].err), qt.IsTrue)
+	})
+}
+
+func Test_readNDJSONImportRows(t *testing.T) {
+	t.Run("line longer than the default scanner limit", func(t *testing.T) {
+		c := qt.New(t)
+
+		writer := strings.Repeat("Bruce Robinson ", bufio.MaxScanTokenSize/10)
+		data := fmt.Sprintf(`{"title": "Withnail and I", "rating_country": "GB", "writer": %q}
+
+{"title": "How to Get Ahead in Advertising"}
+`, writer)
+
+		rows, err := readNDJSONImportRows(strings.NewReader(data))
+		c.Assert(err, qt.IsNil)
+		c.Assert(rows, qt.HasLen, 2)
+		c.Assert(rows[0].err, qt.IsNil)
+		c.Assert(rows[0].req.Writer, qt.Equals, writer)
+		c.Assert(rows[0].req.RatingCountry, qt.Equals, "GB")
+		c.Assert(rows[1].line, qt.Equals, 3)
+		c.Assert(rows[1].req.Title, qt.Equals, "How to Get Ahead in Advertising")
+	})
+	t.Run("invalid JSON", func(t *testing.T) {
+		c := qt.New(t)
+
+		rows, err := readNDJSONImportRows(strings.NewReader("{\"title\": \n"))
+		c.Assert(err, qt.IsNil)
+		c.Assert(rows, qt.HasLen, 1)
+		c.Assert(errs.KindIs(errs.Validation, rows[0].err), qt.IsTrue)
+	})
+}
@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/gilcrest/diygoapi/service"
 	"github.com/gilcrest/diygoapi/sqldb/datastore"
 	"github.com/gilcrest/diygoapi/sqldb/sqldbtest"
+	"github.com/gilcrest/diygoapi/uuid"
 )
 
 func TestMovieService(t *testing.T) {
@@ -53,8 +55,11 @@ func TestMovieService(t *testing.T) {
 		db, cleanup := sqldbtest.NewDB(t)
 		c.Cleanup(cleanup)
 
+		// movies are subject to row level security. The test transaction
+		// looks up movies directly, so it is begun exempt from it.
+		//
 		// start db txn using pgxpool
-		ctx := context.Background()
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
 		var tx pgx.Tx
 		tx, err = db.BeginTx(ctx)
 		if err != nil {
@@ -80,17 +85,23 @@ func TestMovieService(t *testing.T) {
 		adt := findPrincipalTestAudit(ctx, c, tx)
 
 		var got *diygoapi.MovieResponse
-		got, err = s.Create(context.Background(), &r, adt)
+		got, err = s.Create(ctx, &r, adt)
 		c.Assert(err, qt.IsNil)
 
 		want := &diygoapi.MovieResponse{
-			ExternalID:          got.ExternalID,
-			Title:               "The Return of the Living Dead",
-			Rated:               "R",
-			Released:            rd.Format(time.RFC3339),
-			RunTime:             91,
-			Director:            "Dan O'Bannon",
-			Writer:              "Russell Streiner",
+			ExternalID:    got.ExternalID,
+			Title:         "The Return of the Living Dead",
+			Rated:         "R",
+			RatingCountry: "US",
+			Released:      rd.Format(time.RFC3339),
+			RunTime:       91,
+			Director:      "Dan O'Bannon",
+			Writer:        "Russell Streiner",
+			Credits: []diygoapi.CreditResponse{
+				{Role: "director", Name: "Dan O'Bannon", Order: 1},
+				{Role: "writer", Name: "Russell Streiner", Order: 1},
+			},
+			Genres:              []diygoapi.GenreResponse{},
 			CreateAppExtlID:     adt.App.ExternalID.String(),
 			CreateUserFirstName: adt.User.FirstName,
 			CreateUserLastName:  adt.User.LastName,
@@ -110,7 +121,7 @@ func TestMovieService(t *testing.T) {
 		c.Cleanup(cleanup)
 
 		// start db txn using pgxpool
-		ctx := context.Background()
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
 		var tx pgx.Tx
 		tx, err = db.BeginTx(ctx)
 		if err != nil {
@@ -121,8 +132,13 @@ func TestMovieService(t *testing.T) {
 			err = db.RollbackTx(ctx, tx, err)
 		}()
 
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
 		var movies []datastore.Movie
-		movies, err = datastore.New(tx).FindMoviesByTitle(ctx, "The Return of the Living Dead")
+		movies, err = datastore.New(tx).FindMoviesByTitle(ctx, datastore.FindMoviesByTitleParams{
+			Title: "The Return of the Living Dead",
+			OrgID: adt.App.Org.ID.PgxUUID(),
+		})
 		if err != nil {
 			t.Fatalf("FindMoviesByTitle() error = %v", err)
 		}
@@ -133,28 +149,105 @@ func TestMovieService(t *testing.T) {
 		s := service.MovieService{Datastorer: db}
 
 		var got *diygoapi.MovieResponse
-		got, err = s.FindMovieByExternalID(context.Background(), dbm.ExtlID)
+		got, err = s.FindMovieByExternalID(ctx, dbm.ExtlID, adt)
 		want := "The Return of the Living Dead"
 		c.Assert(err, qt.IsNil)
 		c.Assert(got.Title, qt.Equals, want)
 	})
+	t.Run("movies are not visible to other orgs", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		s := service.MovieService{Datastorer: db}
+
+		rd, _ := time.Parse(time.RFC3339, "1982-06-25T00:00:00Z")
+		var mr *diygoapi.MovieResponse
+		mr, err = s.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "The Thing",
+			Rated:    "R",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  109,
+			Director: "John Carpenter",
+			Writer:   "Bill Lancaster",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		// act on behalf of an app belonging to a different org
+		otherApp := *adt.App
+		otherApp.Org = &diygoapi.Org{ID: uuid.New()}
+		otherAdt := adt
+		otherAdt.App = &otherApp
+		// as for a request, the app is set to the context, so the
+		// service transactions are also subject to row level security
+		otherCtx := diygoapi.NewContextWithApp(context.Background(), &otherApp)
+
+		_, err = s.FindMovieByExternalID(otherCtx, mr.ExternalID, otherAdt)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+
+		_, err = s.Update(otherCtx, &diygoapi.UpdateMovieRequest{
+			ExternalID: mr.ExternalID,
+			Title:      "The Thing",
+			Rated:      "R",
+			Released:   rd.Format(time.RFC3339),
+			RunTime:    109,
+			Director:   "John Carpenter",
+			Writer:     "Bill Lancaster",
+		}, otherAdt)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+
+		var all []*diygoapi.MovieResponse
+		all, err = s.FindAllMovies(otherCtx, otherAdt)
+		c.Assert(err, qt.IsNil)
+		for _, m := range all {
+			c.Assert(m.ExternalID, qt.Not(qt.Equals), mr.ExternalID)
+		}
+	})
 	t.Run("Find All Movies", func(t *testing.T) {
 		c := qt.New(t)
 
+		var err error
+
 		db, cleanup := sqldbtest.NewDB(t)
 		c.Cleanup(cleanup)
 
-		ctx := context.Background()
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
 
 		s := service.MovieService{
 			Datastorer: db,
 		}
 
-		var (
-			got []*diygoapi.MovieResponse
-			err error
-		)
-		got, err = s.FindAllMovies(ctx)
+		var got []*diygoapi.MovieResponse
+		got, err = s.FindAllMovies(ctx, adt)
 		c.Assert(err, qt.IsNil)
 		c.Assert(len(got) >= 1, qt.IsTrue, qt.Commentf("movies found = %d", len(got)))
 		c.Logf("movies found = %d", len(got))
@@ -168,7 +261,7 @@ func TestMovieService(t *testing.T) {
 		c.Cleanup(cleanup)
 
 		// start db txn using pgxpool
-		ctx := context.Background()
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
 		var tx pgx.Tx
 		tx, err = db.BeginTx(ctx)
 		if err != nil {
@@ -179,8 +272,13 @@ func TestMovieService(t *testing.T) {
 			err = db.RollbackTx(ctx, tx, err)
 		}()
 
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
 		var movies []datastore.Movie
-		movies, err = datastore.New(tx).FindMoviesByTitle(ctx, "The Return of the Living Dead")
+		movies, err = datastore.New(tx).FindMoviesByTitle(ctx, datastore.FindMoviesByTitleParams{
+			Title: "The Return of the Living Dead",
+			OrgID: adt.App.Org.ID.PgxUUID(),
+		})
 		if err != nil {
 			t.Fatalf("FindMoviesByTitle() error = %v", err)
 		}
@@ -201,20 +299,24 @@ func TestMovieService(t *testing.T) {
 			Writer:     "Russell Streiner",
 		}
 
-		adt := findPrincipalTestAudit(ctx, c, tx)
-
 		var got *diygoapi.MovieResponse
-		got, err = s.Update(context.Background(), &r, adt)
+		got, err = s.Update(ctx, &r, adt)
 		c.Assert(err, qt.IsNil)
 
 		want := &diygoapi.MovieResponse{
-			ExternalID:          got.ExternalID,
-			Title:               "The Return of the Living Thread",
-			Rated:               "R",
-			Released:            rd.Format(time.RFC3339),
-			RunTime:             91,
-			Director:            "Dan O'Bannon",
-			Writer:              "Russell Streiner",
+			ExternalID:    got.ExternalID,
+			Title:         "The Return of the Living Thread",
+			Rated:         "R",
+			RatingCountry: "US",
+			Released:      rd.Format(time.RFC3339),
+			RunTime:       91,
+			Director:      "Dan O'Bannon",
+			Writer:        "Russell Streiner",
+			Credits: []diygoapi.CreditResponse{
+				{Role: "director", Name: "Dan O'Bannon", Order: 1},
+				{Role: "writer", Name: "Russell Streiner", Order: 1},
+			},
+			Genres:              []diygoapi.GenreResponse{},
 			CreateAppExtlID:     adt.App.ExternalID.String(),
 			CreateUserFirstName: adt.User.FirstName,
 			CreateUserLastName:  adt.User.LastName,
@@ -234,7 +336,7 @@ func TestMovieService(t *testing.T) {
 		c.Cleanup(cleanup)
 
 		// start db txn using pgxpool
-		ctx := context.Background()
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
 		var tx pgx.Tx
 		tx, err = db.BeginTx(ctx)
 		if err != nil {
@@ -245,8 +347,13 @@ func TestMovieService(t *testing.T) {
 			err = db.RollbackTx(ctx, tx, err)
 		}()
 
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
 		var movies []datastore.Movie
-		movies, err = datastore.New(tx).FindMoviesByTitle(ctx, "The Return of the Living Thread")
+		movies, err = datastore.New(tx).FindMoviesByTitle(ctx, datastore.FindMoviesByTitleParams{
+			Title: "The Return of the Living Thread",
+			OrgID: adt.App.Org.ID.PgxUUID(),
+		})
 		if err != nil {
 			t.Fatalf("FindMoviesByTitle() error = %v", err)
 		}
@@ -259,7 +366,7 @@ func TestMovieService(t *testing.T) {
 		}
 
 		var got diygoapi.DeleteResponse
-		got, err = s.Delete(context.Background(), dbm.ExtlID)
+		got, err = s.Delete(ctx, dbm.ExtlID, adt)
 		want := diygoapi.DeleteResponse{
 			ExternalID: dbm.ExtlID,
 			Deleted:    true,
@@ -267,4 +374,461 @@ func TestMovieService(t *testing.T) {
 		c.Assert(err, qt.IsNil)
 		c.Assert(got, qt.CmpEquals(), want)
 	})
+	t.Run("restore movie", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		s := service.MovieService{
+			Datastorer:      db,
+			DeleteRetention: time.Hour,
+		}
+
+		rd, _ := time.Parse(time.RFC3339, "1986-08-15T00:00:00Z")
+		var mr *diygoapi.MovieResponse
+		mr, err = s.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "The Return of the Living Dead Part Restored",
+			Rated:    "R",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  91,
+			Director: "Dan O'Bannon",
+			Writer:   "Dan O'Bannon",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		_, err = s.Delete(ctx, mr.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+
+		// a deleted movie is hidden from finders
+		_, err = s.FindMovieByExternalID(ctx, mr.ExternalID, adt)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+
+		// a movie deleted before the retention period can not be restored
+		late := adt
+		late.Moment = adt.Moment.Add(2 * time.Hour)
+		_, err = s.Restore(ctx, mr.ExternalID, late)
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+
+		var got *diygoapi.MovieResponse
+		got, err = s.Restore(ctx, mr.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got.ExternalID, qt.Equals, mr.ExternalID)
+
+		_, err = s.FindMovieByExternalID(ctx, mr.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+
+		// a movie which is not deleted can not be restored
+		_, err = s.Restore(ctx, mr.ExternalID, adt)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+	})
+	t.Run("import movies", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		s := service.MovieService{Datastorer: db}
+
+		// the second data row is missing a director
+		const csvData = `title,rated,release_date,run_time,director,writer
+Day of the Dead,R,1985-07-19T00:00:00Z,100,George A. Romero,George A. Romero
+Re-Animator,R,1985-10-18T00:00:00Z,86,,Dennis Paoli
+`
+
+		var ir *diygoapi.ImportMoviesResponse
+		ir, err = s.Import(ctx, &diygoapi.ImportMoviesRequest{
+			Format: diygoapi.CSVImportFormat,
+			Mode:   diygoapi.AllOrNothingImportMode,
+			Data:   strings.NewReader(csvData),
+		}, adt)
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+		c.Assert(ir.Rows, qt.Equals, 2)
+		c.Assert(ir.Imported, qt.Equals, 0)
+		c.Assert(ir.Failed, qt.Equals, 1)
+		c.Assert(ir.Results[0].Line, qt.Equals, 2)
+		c.Assert(ir.Results[0].ExternalID, qt.Equals, "")
+		c.Assert(ir.Results[1].Line, qt.Equals, 3)
+		c.Assert(ir.Results[1].Error, qt.Not(qt.Equals), "")
+
+		ir, err = s.Import(ctx, &diygoapi.ImportMoviesRequest{
+			Format: diygoapi.CSVImportFormat,
+			Mode:   diygoapi.BestEffortImportMode,
+			Data:   strings.NewReader(csvData),
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(ir.Imported, qt.Equals, 1)
+		c.Assert(ir.Failed, qt.Equals, 1)
+
+		var mr *diygoapi.MovieResponse
+		mr, err = s.FindMovieByExternalID(ctx, ir.Results[0].ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(mr.Title, qt.Equals, "Day of the Dead")
+
+		const ndjsonData = `{"title": "Night of the Creeps", "rated": "R", "release_date": "1986-08-22T00:00:00Z", "run_time": 88, "director": "Fred Dekker", "writer": "Fred Dekker"}
+
+{"title": "The Stuff", "rated": "R", "release_date": "1985-06-14T00:00:00Z", "run_time": 87, "director": "Larry Cohen", "writer": "Larry Cohen"}
+`
+		ir, err = s.Import(ctx, &diygoapi.ImportMoviesRequest{
+			Format: diygoapi.NDJSONImportFormat,
+			Data:   strings.NewReader(ndjsonData),
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(ir.Mode, qt.Equals, diygoapi.AllOrNothingImportMode)
+		c.Assert(ir.Imported, qt.Equals, 2)
+		c.Assert(ir.Results[1].Line, qt.Equals, 3)
+	})
+	t.Run("export movies", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		s := service.MovieService{Datastorer: db}
+
+		rd, _ := time.Parse(time.RFC3339, "1985-08-16T00:00:00Z")
+		var mr *diygoapi.MovieResponse
+		mr, err = s.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "The Return of the Living Dead Exported",
+			Rated:    "R",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  91,
+			Director: "Dan O'Bannon",
+			Writer:   "Russell Streiner",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		var sb strings.Builder
+		err = s.Export(ctx, diygoapi.CSVExportFormat, &sb, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(strings.HasPrefix(sb.String(), "external_id,title,rated,release_date,run_time,director,writer,"), qt.IsTrue)
+		c.Assert(strings.Contains(sb.String(), mr.ExternalID+",The Return of the Living Dead Exported,R,"), qt.IsTrue)
+
+		sb.Reset()
+		err = s.Export(ctx, diygoapi.NDJSONExportFormat, &sb, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(strings.Contains(sb.String(), `"external_id":"`+mr.ExternalID+`"`), qt.IsTrue)
+
+		// an org with no movies gets just the header
+		otherApp := *adt.App
+		otherApp.Org = &diygoapi.Org{ID: uuid.New()}
+		otherAdt := adt
+		otherAdt.App = &otherApp
+		otherCtx := diygoapi.NewContextWithApp(context.Background(), &otherApp)
+
+		sb.Reset()
+		err = s.Export(otherCtx, diygoapi.CSVExportFormat, &sb, otherAdt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(sb.String(), qt.Equals, "external_id,title,rated,release_date,run_time,director,writer,create_date_time,update_date_time\n")
+	})
+	t.Run("credits and genres", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		s := service.MovieService{Datastorer: db}
+
+		rd, _ := time.Parse(time.RFC3339, "1996-03-08T00:00:00Z")
+		var mr *diygoapi.MovieResponse
+		mr, err = s.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "Fargo",
+			Rated:    "R",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  98,
+			Credits: []diygoapi.CreditRequest{
+				{Role: "director", Name: "Joel Coen"},
+				{Role: "director", Name: "Ethan Coen"},
+				{Role: "writer", Name: "Ethan Coen"},
+				{Role: "writer", Name: "Joel Coen"},
+				{Role: "actor", Name: "Frances McDormand", Character: "Marge Gunderson"},
+			},
+			Genres: []string{"crime", "Thriller"},
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		// the director and writer are summarized from the credits
+		c.Assert(mr.Director, qt.Equals, "Joel Coen, Ethan Coen")
+		c.Assert(mr.Writer, qt.Equals, "Ethan Coen, Joel Coen")
+		c.Assert(mr.Credits, qt.HasLen, 5)
+		c.Assert(mr.Credits[1], qt.Equals, diygoapi.CreditResponse{Role: "director", Name: "Ethan Coen", Order: 2})
+		c.Assert(mr.Genres, qt.DeepEquals, []diygoapi.GenreResponse{{Code: "crime", Name: "Crime"}, {Code: "thriller", Name: "Thriller"}})
+
+		var got []*diygoapi.MovieResponse
+		got, err = s.FindMoviesByCredit(ctx, &diygoapi.FindMoviesByCreditRequest{Name: "ethan coen", Role: "director"}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(containsMovie(got, mr.ExternalID), qt.IsTrue)
+
+		got, err = s.FindMoviesByCredit(ctx, &diygoapi.FindMoviesByCreditRequest{Name: "Frances McDormand", Role: "director"}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(containsMovie(got, mr.ExternalID), qt.IsFalse)
+
+		got, err = s.FindMoviesByGenre(ctx, "crime", adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(containsMovie(got, mr.ExternalID), qt.IsTrue)
+
+		_, err = s.FindMoviesByGenre(ctx, "not_a_genre", adt)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+
+		// an update without credits or genres keeps them
+		var ur *diygoapi.MovieResponse
+		ur, err = s.Update(ctx, &diygoapi.UpdateMovieRequest{
+			ExternalID: mr.ExternalID,
+			Title:      "Fargo",
+			Rated:      "R",
+			Released:   rd.Format(time.RFC3339),
+			RunTime:    98,
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(ur.Credits, qt.DeepEquals, mr.Credits)
+		c.Assert(ur.Genres, qt.DeepEquals, mr.Genres)
+
+		_, err = s.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "Fargo",
+			Rated:    "R",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  98,
+			Director: "Joel Coen",
+			Writer:   "Ethan Coen",
+			Genres:   []string{"not_a_genre"},
+		}, adt)
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+	})
+	t.Run("ratings", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		s := service.MovieService{Datastorer: db}
+
+		var ratings []*diygoapi.RatingResponse
+		ratings, err = s.FindAllRatings(ctx)
+		c.Assert(err, qt.IsNil)
+		c.Assert(ratings, qt.Contains, &diygoapi.RatingResponse{System: "MPAA", Country: "US", Code: "PG-13", Description: "Parents Strongly Cautioned"})
+
+		rd, _ := time.Parse(time.RFC3339, "1984-06-08T00:00:00Z")
+		r := diygoapi.CreateMovieRequest{
+			Title:    "Gremlins",
+			Rated:    "pg13",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  106,
+			Director: "Joe Dante",
+			Writer:   "Chris Columbus",
+		}
+
+		// the rating is normalized
+		var mr *diygoapi.MovieResponse
+		mr, err = s.Create(ctx, &r, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(mr.Rated, qt.Equals, "PG-13")
+		c.Assert(mr.RatingCountry, qt.Equals, diygoapi.DefaultRatingCountry)
+
+		// the rating must belong to the rating system of the rating country
+		var ur *diygoapi.MovieResponse
+		ur, err = s.Update(ctx, &diygoapi.UpdateMovieRequest{
+			ExternalID:    mr.ExternalID,
+			Title:         r.Title,
+			Rated:         "12a",
+			RatingCountry: "gb",
+			Released:      r.Released,
+			RunTime:       r.RunTime,
+			Director:      r.Director,
+			Writer:        r.Writer,
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(ur.Rated, qt.Equals, "12A")
+		c.Assert(ur.RatingCountry, qt.Equals, "GB")
+
+		r.Rated = "12A"
+		_, err = s.Create(ctx, &r, adt)
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+
+		// the rating country can be given as a CSV import column
+		const csvData = `title,rated,rating_country,release_date,run_time,director,writer
+Gremlins,12a,gb,1984-06-08T00:00:00Z,106,Joe Dante,Chris Columbus
+`
+		var ir *diygoapi.ImportMoviesResponse
+		ir, err = s.Import(ctx, &diygoapi.ImportMoviesRequest{
+			Format: diygoapi.CSVImportFormat,
+			Data:   strings.NewReader(csvData),
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(ir.Imported, qt.Equals, 1)
+
+		var imr *diygoapi.MovieResponse
+		imr, err = s.FindMovieByExternalID(ctx, ir.Results[0].ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(imr.Rated, qt.Equals, "12A")
+		c.Assert(imr.RatingCountry, qt.Equals, "GB")
+	})
+	t.Run("search movies", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		s := service.MovieService{Datastorer: db}
+
+		rd, _ := time.Parse(time.RFC3339, "1988-02-05T00:00:00Z")
+		var mr *diygoapi.MovieResponse
+		mr, err = s.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "The Serpent and the Rainbow",
+			Rated:    "R",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  98,
+			Director: "Wes Craven",
+			Writer:   "Richard Maxwell",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		// a full-text match is highlighted in the snippet
+		var got []*diygoapi.MovieSearchResponse
+		got, err = s.Search(ctx, &diygoapi.SearchMoviesRequest{Query: "serpent rainbow"}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(len(got) > 0, qt.IsTrue)
+		c.Assert(got[0].ExternalID, qt.Equals, mr.ExternalID)
+		c.Assert(strings.Contains(got[0].Snippet, "<mark>Serpent</mark>"), qt.IsTrue)
+
+		// the snippet is HTML, so the text around the marks is escaped
+		var esc *diygoapi.MovieResponse
+		esc, err = s.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "Snakes & Ladders <Uncut>",
+			Rated:    "R",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  98,
+			Director: "Wes Craven",
+			Writer:   `Richard "Dick" Maxwell`,
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		got, err = s.Search(ctx, &diygoapi.SearchMoviesRequest{Query: "ladders craven"}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(len(got) > 0, qt.IsTrue)
+		c.Assert(got[0].ExternalID, qt.Equals, esc.ExternalID)
+		c.Assert(got[0].Snippet, qt.Equals, `Snakes &amp; <mark>Ladders</mark> &lt;Uncut&gt; / Wes <mark>Craven</mark> / Richard &quot;Dick&quot; Maxwell`)
+
+		// a misspelled, partial director name still matches
+		got, err = s.Search(ctx, &diygoapi.SearchMoviesRequest{Query: "Cravn"}, adt)
+		c.Assert(err, qt.IsNil)
+		var found bool
+		for _, r := range got {
+			if r.ExternalID == mr.ExternalID {
+				found = true
+			}
+		}
+		c.Assert(found, qt.IsTrue)
+
+		_, err = s.Search(ctx, &diygoapi.SearchMoviesRequest{Query: "  "}, adt)
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+
+		_, err = s.Search(ctx, &diygoapi.SearchMoviesRequest{Query: "craven", Limit: diygoapi.MaxMovieSearchLimit + 1}, adt)
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+	})
+}
+
+// containsMovie reports whether a movie with the given external ID is
+// in the list of movies
+func containsMovie(movies []*diygoapi.MovieResponse, extlID string) bool {
+	for _, m := range movies {
+		if m.ExternalID == extlID {
+			return true
+		}
+	}
+	return false
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -12,6 +13,7 @@ import (
 	"github.com/gilcrest/diygoapi/errs"
 	"github.com/gilcrest/diygoapi/secure"
 	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
 	"github.com/gilcrest/diygoapi/uuid"
 )
 
@@ -50,12 +52,18 @@ type OrgService struct {
 	Datastorer      diygoapi.Datastorer
 	APIKeyGenerator diygoapi.APIKeyGenerator
 	EncryptionKey   *[32]byte
+	// DeleteRetention is how long a deleted org can be restored.
+	// diygoapi.DefaultDeleteRetention is used if not set.
+	DeleteRetention time.Duration
 }
 
 // Create is used to create an Org
 func (s *OrgService) Create(ctx context.Context, r *diygoapi.CreateOrgRequest, adt diygoapi.Audit) (or *diygoapi.OrgResponse, err error) {
 	const op errs.Op = "service/OrgService.Create"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	if r == nil || *r.CreateAppRequest == (diygoapi.CreateAppRequest{}) {
 		return nil, errs.E(op, errs.Validation, "CreateOrgRequest must have a value when creating an Org")
 	}
@@ -148,6 +156,28 @@ func (s *OrgService) Create(ctx context.Context, r *diygoapi.CreateOrgRequest, a
 		}
 	}
 
+	events := []eventParams{{
+		Type:          diygoapi.OrgCreatedEvent,
+		OrgID:         o.ID,
+		SubjectExtlID: o.ExternalID.String(),
+		Data:          newOrgImage(*o),
+		Moment:        adt.Moment,
+	}}
+	if aa.App != nil {
+		events = append(events, eventParams{
+			Type:          diygoapi.AppCreatedEvent,
+			OrgID:         o.ID,
+			SubjectExtlID: aa.App.ExternalID.String(),
+			Data:          newAppImage(*aa.App),
+			Moment:        adt.Moment,
+		})
+	}
+
+	err = emitEventsTx(ctx, tx, events)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
@@ -179,6 +209,18 @@ func createOrgTx(ctx context.Context, tx pgx.Tx, oa *orgAudit) error {
 		return errs.E(op, errs.Database, fmt.Sprintf("CreateOrg() should insert 1 row, actual: %d", rowsAffected))
 	}
 
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.OrgEntityType,
+		EntityID:     oa.Org.ID,
+		EntityExtlID: oa.Org.ExternalID.String(),
+		Action:       diygoapi.CreateChangeAction,
+		After:        newOrgImage(*oa.Org),
+		Audit:        oa.SimpleAudit.Create,
+	})
+	if err != nil {
+		return errs.E(op, err)
+	}
+
 	return nil
 }
 
@@ -203,6 +245,9 @@ func newCreateOrgParams(oa *orgAudit) datastore.CreateOrgParams {
 func (s *OrgService) Update(ctx context.Context, r *diygoapi.UpdateOrgRequest, adt diygoapi.Audit) (or *diygoapi.OrgResponse, err error) {
 	const op errs.Op = "service/OrgService.Update"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -226,6 +271,9 @@ func (s *OrgService) Update(ctx context.Context, r *diygoapi.UpdateOrgRequest, a
 	// overwrite Last audit with the current audit
 	oa.SimpleAudit.Update = adt
 
+	// capture the org as it exists prior to the update for change history
+	before := newOrgImage(*oa.Org)
+
 	// override fields with data from request
 	oa.Org.Name = r.Name
 	oa.Org.Description = r.Description
@@ -251,6 +299,30 @@ func (s *OrgService) Update(ctx context.Context, r *diygoapi.UpdateOrgRequest, a
 		return nil, errs.E(op, errs.Database, fmt.Sprintf("UpdateOrg() should update 1 row, actual: %d", rowsAffected))
 	}
 
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.OrgEntityType,
+		EntityID:     oa.Org.ID,
+		EntityExtlID: oa.Org.ExternalID.String(),
+		Action:       diygoapi.UpdateChangeAction,
+		Before:       before,
+		After:        newOrgImage(*oa.Org),
+		Audit:        adt,
+	})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = emitEventTx(ctx, tx, eventParams{
+		Type:          diygoapi.OrgUpdatedEvent,
+		OrgID:         oa.Org.ID,
+		SubjectExtlID: oa.Org.ExternalID.String(),
+		Data:          newOrgImage(*oa.Org),
+		Moment:        adt.Moment,
+	})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
@@ -260,10 +332,15 @@ func (s *OrgService) Update(ctx context.Context, r *diygoapi.UpdateOrgRequest, a
 	return newOrgResponse(oa, appAudit{}), nil
 }
 
-// Delete is used to delete an Org
-func (s *OrgService) Delete(ctx context.Context, extlID string) (dr diygoapi.DeleteResponse, err error) {
+// Delete is used to delete an Org. The Org and all of its Apps are
+// marked as deleted and can be restored until the retention period
+// has passed.
+func (s *OrgService) Delete(ctx context.Context, extlID string, adt diygoapi.Audit) (dr diygoapi.DeleteResponse, err error) {
 	const op errs.Op = "service/OrgService.Delete"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -285,6 +362,19 @@ func (s *OrgService) Delete(ctx context.Context, extlID string) (dr diygoapi.Del
 		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
 	}
 
+	// emitted before the apps of the org are deleted, as webhooks of
+	// deleted apps are not sent events
+	err = emitEventTx(ctx, tx, eventParams{
+		Type:          diygoapi.OrgDeletedEvent,
+		OrgID:         o.ID,
+		SubjectExtlID: o.ExternalID.String(),
+		Data:          newOrgImage(o),
+		Moment:        adt.Moment,
+	})
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
 	var dbApps []datastore.App
 	dbApps, err = datastore.New(tx).FindAppsByOrg(ctx, o.ID.PgxUUID())
 	if err != nil {
@@ -292,15 +382,28 @@ func (s *OrgService) Delete(ctx context.Context, extlID string) (dr diygoapi.Del
 	}
 
 	for _, aa := range dbApps {
-		a := diygoapi.App{ID: aa.AppID.Bytes}
-		err = deleteAppTx(ctx, tx, a)
+		a := diygoapi.App{
+			ID:          aa.AppID.Bytes,
+			ExternalID:  secure.MustParseIdentifier(aa.AppExtlID),
+			Org:         &o,
+			Name:        aa.AppName,
+			Description: aa.AppDescription,
+		}
+		err = deleteAppTx(ctx, tx, a, adt)
 		if err != nil {
 			return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
 		}
 	}
 
+	softDeleteOrgParams := datastore.SoftDeleteOrgParams{
+		DeleteAppID:     adt.App.ID.PgxUUID(),
+		DeleteUserID:    adt.User.ID.PgxUUID(),
+		DeleteTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+		OrgID:           o.ID.PgxUUID(),
+	}
+
 	var rowsAffected int64
-	rowsAffected, err = datastore.New(tx).DeleteOrg(ctx, o.ID.PgxUUID())
+	rowsAffected, err = datastore.New(tx).SoftDeleteOrg(ctx, softDeleteOrgParams)
 	if err != nil {
 		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
 	}
@@ -309,6 +412,18 @@ func (s *OrgService) Delete(ctx context.Context, extlID string) (dr diygoapi.Del
 		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
 	}
 
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.OrgEntityType,
+		EntityID:     o.ID,
+		EntityExtlID: o.ExternalID.String(),
+		Action:       diygoapi.DeleteChangeAction,
+		Before:       newOrgImage(o),
+		Audit:        adt,
+	})
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
 	// commit db txn using pgxpool
 	err = s.Datastorer.CommitTx(ctx, tx)
 	if err != nil {
@@ -323,10 +438,143 @@ func (s *OrgService) Delete(ctx context.Context, extlID string) (dr diygoapi.Del
 	return response, nil
 }
 
+// Restore is used to restore a deleted Org, provided it was deleted
+// within the retention period. Apps which were deleted along with
+// the Org are restored as well.
+func (s *OrgService) Restore(ctx context.Context, extlID string, adt diygoapi.Audit) (or *diygoapi.OrgResponse, err error) {
+	const op errs.Op = "service/OrgService.Restore"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	// retrieve deleted Org
+	var row datastore.FindDeletedOrgByExtlIDRow
+	row, err = datastore.New(tx).FindDeletedOrgByExtlID(ctx, extlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.Validation, "No deleted org exists for the given external ID")
+		}
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if !restorable(row.DeleteTimestamp.Time, s.DeleteRetention, adt.Moment) {
+		return nil, errs.E(op, errs.Validation, "org was deleted before the retention period and can no longer be restored")
+	}
+
+	o := diygoapi.Org{
+		ID:          row.OrgID.Bytes,
+		ExternalID:  secure.MustParseIdentifier(row.OrgExtlID),
+		Name:        row.OrgName,
+		Description: row.OrgDescription,
+		Kind: &diygoapi.OrgKind{
+			ID:          row.OrgKindID.Bytes,
+			ExternalID:  row.OrgKindExtlID,
+			Description: row.OrgKindDesc,
+		},
+	}
+
+	restoreOrgParams := datastore.RestoreOrgParams{
+		UpdateAppID:     adt.App.ID.PgxUUID(),
+		UpdateUserID:    adt.User.ID.PgxUUID(),
+		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+		OrgID:           o.ID.PgxUUID(),
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).RestoreOrg(ctx, restoreOrgParams)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	err = writeChangeHistoryTx(ctx, tx, changeHistoryParams{
+		EntityType:   diygoapi.OrgEntityType,
+		EntityID:     o.ID,
+		EntityExtlID: o.ExternalID.String(),
+		Action:       diygoapi.RestoreChangeAction,
+		After:        newOrgImage(o),
+		Audit:        adt,
+	})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// only apps deleted at the same moment as the org were deleted
+	// as part of the org delete, apps deleted earlier stay deleted
+	findDeletedAppsByOrgParams := datastore.FindDeletedAppsByOrgParams{
+		OrgID:           o.ID.PgxUUID(),
+		DeleteTimestamp: row.DeleteTimestamp,
+	}
+
+	var dbApps []datastore.App
+	dbApps, err = datastore.New(tx).FindDeletedAppsByOrg(ctx, findDeletedAppsByOrgParams)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	for _, da := range dbApps {
+		a := diygoapi.App{
+			ID:          da.AppID.Bytes,
+			ExternalID:  secure.MustParseIdentifier(da.AppExtlID),
+			Org:         &o,
+			Name:        da.AppName,
+			Description: da.AppDescription,
+		}
+		err = restoreAppTx(ctx, tx, a, adt)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+	}
+
+	// emitted once the apps of the org are restored, so their
+	// webhooks are sent it
+	err = emitEventTx(ctx, tx, eventParams{
+		Type:          diygoapi.OrgRestoredEvent,
+		OrgID:         o.ID,
+		SubjectExtlID: o.ExternalID.String(),
+		Data:          newOrgImage(o),
+		Moment:        adt.Moment,
+	})
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var oa *orgAudit
+	oa, err = findOrgByExternalIDWithAudit(ctx, tx, extlID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newOrgResponse(oa, appAudit{}), nil
+}
+
 // FindAll is used to list all orgs in the datastore
 func (s *OrgService) FindAll(ctx context.Context) (responses []*diygoapi.OrgResponse, err error) {
 	const op errs.Op = "service/OrgService.FindAll"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -406,6 +654,9 @@ func (s *OrgService) FindAll(ctx context.Context) (responses []*diygoapi.OrgResp
 func (s *OrgService) FindByExternalID(ctx context.Context, extlID string) (or *diygoapi.OrgResponse, err error) {
 	const op errs.Op = "service/OrgService.FindByExternalID"
 
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
 	// start db txn using pgxpool
 	var tx pgx.Tx
 	tx, err = s.Datastorer.BeginTx(ctx)
@@ -656,3 +907,84 @@ func createStandardOrgKind(ctx context.Context, tx pgx.Tx, adt diygoapi.Audit) (
 
 	return standardParams, nil
 }
+
+// orgExportColumns is the CSV header row of an org export
+var orgExportColumns = []string{"external_id", "name", "description", "kind", "create_date_time", "update_date_time"}
+
+// Export streams all orgs to w in the given format, reading them
+// from a server-side cursor in batches
+func (s *OrgService) Export(ctx context.Context, f diygoapi.ExportFormat, w io.Writer) (err error) {
+	const op errs.Op = "service/OrgService.Export"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var ew *exportWriter
+	ew, err = newExportWriter(f, w, orgExportColumns)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	q := datastore.New(tx)
+
+	err = q.DeclareOrgExportCursor(ctx)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	for {
+		var rows []datastore.FetchOrgExportCursorRow
+		rows, err = q.FetchOrgExportCursor(ctx, exportBatchSize)
+		if err != nil {
+			return errs.E(op, errs.Database, err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		for _, row := range rows {
+			oe := diygoapi.OrgExport{
+				ExternalID:     row.OrgExtlID,
+				Name:           row.OrgName,
+				Description:    row.OrgDescription,
+				Kind:           row.OrgKindExtlID,
+				CreateDateTime: row.CreateTimestamp.Time.Format(time.RFC3339),
+				UpdateDateTime: row.UpdateTimestamp.Time.Format(time.RFC3339),
+			}
+			err = ew.Write([]string{oe.ExternalID, oe.Name, oe.Description, oe.Kind, oe.CreateDateTime, oe.UpdateDateTime}, oe)
+			if err != nil {
+				return errs.E(op, err)
+			}
+		}
+
+		err = ew.Flush()
+		if err != nil {
+			return errs.E(op, err)
+		}
+	}
+
+	// flush once more, as an empty CSV export still has its header
+	err = ew.Flush()
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	return nil
+}
@@ -315,8 +315,10 @@ func TestOrgService(t *testing.T) {
 			Datastorer: db,
 		}
 
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
 		var got diygoapi.DeleteResponse
-		got, err = s.Delete(context.Background(), testOrg.OrgExtlID)
+		got, err = s.Delete(context.Background(), testOrg.OrgExtlID, adt)
 		want := diygoapi.DeleteResponse{
 			ExternalID: testOrg.OrgExtlID,
 			Deleted:    true,
@@ -16,6 +16,9 @@ type PingService struct {
 
 // Ping method pings the database
 func (s *PingService) Ping(ctx context.Context, lgr zerolog.Logger) diygoapi.PingResponse {
+	ctx, span := tracer.Start(ctx, "service/PingService.Ping")
+	defer span.End()
+
 	// start db txn using pgxpool
 	var (
 		tx  pgx.Tx
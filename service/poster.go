@@ -0,0 +1,214 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
+)
+
+// PosterService is a service for uploading and serving movie posters.
+// Poster metadata is kept in the database and the image itself in
+// BlobStore.
+type PosterService struct {
+	Datastorer diygoapi.Datastorer
+	BlobStore  diygoapi.BlobStore
+}
+
+// newPosterFromRow initializes a Poster given a datastore.MoviePoster
+func newPosterFromRow(row datastore.MoviePoster) diygoapi.Poster {
+	return diygoapi.Poster{
+		MovieID:     row.MovieID.Bytes,
+		BlobKey:     row.BlobKey,
+		ContentType: row.ContentType,
+		Size:        row.ByteSize,
+		SHA256:      row.Sha256,
+		Width:       int(row.Width),
+		Height:      int(row.Height),
+		UpdateTime:  row.UpdateTimestamp.Time,
+	}
+}
+
+// newPosterResponse initializes PosterResponse
+func newPosterResponse(movieExtlID string, p diygoapi.Poster) *diygoapi.PosterResponse {
+	return &diygoapi.PosterResponse{
+		MovieExtlID:    movieExtlID,
+		URL:            p.URL(movieExtlID),
+		ContentType:    p.ContentType,
+		Size:           p.Size,
+		SHA256:         p.SHA256,
+		Width:          p.Width,
+		Height:         p.Height,
+		UpdateDateTime: p.UpdateTime.Format(time.RFC3339),
+	}
+}
+
+// Upload validates a poster image, stores it in the blob store and
+// records its metadata, replacing any earlier poster of the movie.
+func (s *PosterService) Upload(ctx context.Context, r *diygoapi.UploadPosterRequest, adt diygoapi.Audit) (response *diygoapi.PosterResponse, err error) {
+	const op errs.Op = "service/PosterService.Upload"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// read one byte more than allowed so an oversized image is
+	// reported by NewPoster rather than silently truncated
+	var data []byte
+	data, err = io.ReadAll(io.LimitReader(r.Data, diygoapi.MaxPosterSize+1))
+	if err != nil {
+		return nil, errs.E(op, errs.InvalidRequest, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var dbm datastore.Movie
+	dbm, err = findOrgMovie(ctx, tx, r.MovieExternalID, orgID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var p diygoapi.Poster
+	p, err = diygoapi.NewPoster(dbm.MovieID.Bytes, r.ContentType, data)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	p.UpdateTime = adt.Moment
+
+	q := datastore.New(tx)
+
+	// lock the current poster, if any, so the blob it replaces is known
+	var oldKey string
+	var old datastore.MoviePoster
+	old, err = q.FindMoviePosterByMovieIDForUpdate(ctx, dbm.MovieID)
+	switch {
+	case err == nil:
+		oldKey = old.BlobKey
+	case errors.Is(err, pgx.ErrNoRows):
+		err = nil
+	default:
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	err = s.BlobStore.Put(ctx, p.BlobKey, bytes.NewReader(data))
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// the new blob is not referenced if the metadata is not saved
+	defer func() {
+		if err != nil && p.BlobKey != oldKey {
+			_ = s.BlobStore.Delete(ctx, p.BlobKey)
+		}
+	}()
+
+	var rowsAffected int64
+	rowsAffected, err = q.UpsertMoviePoster(ctx, datastore.UpsertMoviePosterParams{
+		MovieID:         dbm.MovieID,
+		BlobKey:         p.BlobKey,
+		ContentType:     p.ContentType,
+		ByteSize:        p.Size,
+		Sha256:          p.SHA256,
+		Width:           int32(p.Width),
+		Height:          int32(p.Height),
+		CreateAppID:     adt.App.ID.PgxUUID(),
+		CreateUserID:    adt.User.ID.PgxUUID(),
+		CreateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+		UpdateAppID:     adt.App.ID.PgxUUID(),
+		UpdateUserID:    adt.User.ID.PgxUUID(),
+		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+	})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// the replaced image is no longer referenced. Failing to remove it
+	// only leaves an orphaned blob behind, so the upload still succeeds.
+	if oldKey != "" && oldKey != p.BlobKey {
+		_ = s.BlobStore.Delete(ctx, oldKey)
+	}
+
+	return newPosterResponse(r.MovieExternalID, p), nil
+}
+
+// Find returns the poster metadata of a movie and opens its image.
+// The caller must close the returned io.ReadCloser.
+func (s *PosterService) Find(ctx context.Context, movieExtlID string, adt diygoapi.Audit) (p *diygoapi.Poster, rc io.ReadCloser, err error) {
+	const op errs.Op = "service/PosterService.Find"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var dbm datastore.Movie
+	dbm, err = findOrgMovie(ctx, tx, movieExtlID, orgID)
+	if err != nil {
+		return nil, nil, errs.E(op, err)
+	}
+
+	var row datastore.MoviePoster
+	row, err = datastore.New(tx).FindMoviePosterByMovieID(ctx, dbm.MovieID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, errs.E(op, errs.NotExist, "the movie has no poster")
+		}
+		return nil, nil, errs.E(op, errs.Database, err)
+	}
+
+	poster := newPosterFromRow(row)
+
+	rc, err = s.BlobStore.Get(ctx, poster.BlobKey)
+	if err != nil {
+		return nil, nil, errs.E(op, err)
+	}
+
+	return &poster, rc, nil
+}
@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/blob"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb/sqldbtest"
+)
+
+func TestPosterService(t *testing.T) {
+	t.Run("upload and replace a poster", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		var bs *blob.FileStore
+		bs, err = blob.NewFileStore(t.TempDir())
+		c.Assert(err, qt.IsNil)
+
+		ms := service.MovieService{Datastorer: db}
+		ps := service.PosterService{Datastorer: db, BlobStore: bs}
+
+		rd, _ := time.Parse(time.RFC3339, "1979-05-25T00:00:00Z")
+		var mr *diygoapi.MovieResponse
+		mr, err = ms.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "Alien",
+			Rated:    "R",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  117,
+			Director: "Ridley Scott",
+			Writer:   "Dan O'Bannon",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(mr.PosterURL, qt.Equals, "")
+
+		_, _, err = ps.Find(ctx, mr.ExternalID, adt)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+
+		var first *diygoapi.PosterResponse
+		first, err = ps.Upload(ctx, &diygoapi.UploadPosterRequest{
+			MovieExternalID: mr.ExternalID,
+			ContentType:     "image/png",
+			Data:            bytes.NewReader(posterPNG(c, 27, 40)),
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(first.Width, qt.Equals, 27)
+		c.Assert(first.Height, qt.Equals, 40)
+
+		img := posterPNG(c, 54, 80)
+		var second *diygoapi.PosterResponse
+		second, err = ps.Upload(ctx, &diygoapi.UploadPosterRequest{
+			MovieExternalID: mr.ExternalID,
+			ContentType:     "image/png",
+			Data:            bytes.NewReader(img),
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(second.URL, qt.Not(qt.Equals), first.URL)
+
+		// the movie links to the current poster
+		mr, err = ms.FindMovieByExternalID(ctx, mr.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(mr.PosterURL, qt.Equals, second.URL)
+
+		var (
+			p  *diygoapi.Poster
+			rc io.ReadCloser
+		)
+		p, rc, err = ps.Find(ctx, mr.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+		got, err := io.ReadAll(rc)
+		c.Assert(err, qt.IsNil)
+		c.Assert(rc.Close(), qt.IsNil)
+		c.Assert(got, qt.DeepEquals, img)
+		c.Assert(p.SHA256, qt.Equals, second.SHA256)
+
+		// the replaced image is removed from the blob store
+		_, err = bs.Get(ctx, diygoapi.PosterBlobKey(p.MovieID, first.SHA256))
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+	})
+	t.Run("reject an image which is not a poster", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		var bs *blob.FileStore
+		bs, err = blob.NewFileStore(t.TempDir())
+		c.Assert(err, qt.IsNil)
+
+		ms := service.MovieService{Datastorer: db}
+		ps := service.PosterService{Datastorer: db, BlobStore: bs}
+
+		rd, _ := time.Parse(time.RFC3339, "1986-07-18T00:00:00Z")
+		var mr *diygoapi.MovieResponse
+		mr, err = ms.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "Aliens",
+			Rated:    "R",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  137,
+			Director: "James Cameron",
+			Writer:   "James Cameron",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		_, err = ps.Upload(ctx, &diygoapi.UploadPosterRequest{
+			MovieExternalID: mr.ExternalID,
+			ContentType:     "image/png",
+			Data:            strings.NewReader("not an image"),
+		}, adt)
+		c.Assert(errs.KindIs(errs.UnsupportedMediaType, err), qt.IsTrue)
+	})
+}
+
+// posterPNG returns a PNG encoded image of the given size
+func posterPNG(c *qt.C, width, height int) []byte {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height)))
+	c.Assert(err, qt.IsNil)
+	return buf.Bytes()
+}
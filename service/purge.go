@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
+)
+
+// restorable reports whether a record deleted at deletedAt can still
+// be restored at moment given the retention period. If retention is
+// not set, diygoapi.DefaultDeleteRetention is used.
+func restorable(deletedAt time.Time, retention time.Duration, moment time.Time) bool {
+	if retention <= 0 {
+		retention = diygoapi.DefaultDeleteRetention
+	}
+	return moment.Sub(deletedAt) <= retention
+}
+
+// PurgeService physically removes deleted records
+type PurgeService struct {
+	Datastorer diygoapi.Datastorer
+}
+
+// Purge physically removes all movies which were deleted before the
+// given time, as well as the movies of orgs deleted before it. Orgs
+// and apps deleted before the given time are kept, as the audit
+// columns of most records refer to them, but their names and
+// descriptions are scrubbed and the API keys of the apps removed.
+// Deleted records which are purged can no longer be restored.
+func (s *PurgeService) Purge(ctx context.Context, before time.Time) (response diygoapi.PurgeResponse, err error) {
+	const op errs.Op = "service/PurgeService.Purge"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// purging works across every org, so is exempt from row level security
+	ctx = diygoapi.NewContextWithRowSecurityBypass(ctx)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return diygoapi.PurgeResponse{}, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	ts := diygoapi.NewPgxTimestampTZ(before)
+	purged := diygoapi.NewPgxTimestampTZ(time.Now())
+	q := datastore.New(tx)
+
+	response.Before = before
+
+	response.AppAPIKeys, err = q.PurgeAppAPIKeys(ctx, ts)
+	if err != nil {
+		return diygoapi.PurgeResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	response.Apps, err = q.PurgeApps(ctx, datastore.PurgeAppsParams{DeleteTimestamp: ts, PurgeTimestamp: purged})
+	if err != nil {
+		return diygoapi.PurgeResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	response.Orgs, err = q.PurgeOrgs(ctx, datastore.PurgeOrgsParams{DeleteTimestamp: ts, PurgeTimestamp: purged})
+	if err != nil {
+		return diygoapi.PurgeResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	response.Movies, err = q.PurgeMovies(ctx, ts)
+	if err != nil {
+		return diygoapi.PurgeResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return diygoapi.PurgeResponse{}, errs.E(op, err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb/sqldbtest"
+)
+
+func TestPurgeService(t *testing.T) {
+	t.Run("purge an app with authored records", func(t *testing.T) {
+		c := qt.New(t)
+
+		eks := os.Getenv("ENCRYPT_KEY")
+		if eks == "" {
+			t.Fatal("no encryption key found")
+		}
+
+		ek, err := secure.ParseEncryptionKey(eks)
+		if err != nil {
+			t.Fatal("secure.ParseEncryptionKey() error")
+		}
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		as := service.AppService{
+			Datastorer:      db,
+			APIKeyGenerator: secure.RandomGenerator{},
+			EncryptionKey:   ek,
+		}
+		name := fmt.Sprintf("TestPurgeService_%d", time.Now().UnixNano())
+		var ar *diygoapi.AppResponse
+		ar, err = as.Create(ctx, &diygoapi.CreateAppRequest{Name: name, Description: "app to be purged"}, adt)
+		c.Assert(err, qt.IsNil)
+
+		var app *diygoapi.App
+		app, err = service.FindAppByName(ctx, tx, adt.App.Org, name)
+		c.Assert(err, qt.IsNil)
+
+		// the app authors a movie, which refers to it after it is purged
+		ms := service.MovieService{Datastorer: db}
+		rd, _ := time.Parse(time.RFC3339, "1986-10-17T00:00:00Z")
+		var mr *diygoapi.MovieResponse
+		mr, err = ms.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    name,
+			Rated:    "R",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  112,
+			Director: "Alex Cox",
+			Writer:   "Alex Cox",
+		}, diygoapi.Audit{App: app, User: adt.User, Moment: time.Now()})
+		c.Assert(err, qt.IsNil)
+
+		adt.Moment = time.Now()
+		_, err = as.Delete(ctx, ar.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+
+		ps := service.PurgeService{Datastorer: db}
+		var pr diygoapi.PurgeResponse
+		pr, err = ps.Purge(ctx, time.Now().Add(time.Second))
+		c.Assert(err, qt.IsNil)
+		c.Assert(pr.Apps >= 1, qt.IsTrue, qt.Commentf("apps purged = %d", pr.Apps))
+		c.Assert(pr.AppAPIKeys >= 1, qt.IsTrue, qt.Commentf("app API keys purged = %d", pr.AppAPIKeys))
+
+		// the movie authored by the purged app is kept
+		var got *diygoapi.MovieResponse
+		got, err = ms.FindMovieByExternalID(ctx, mr.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got.CreateAppExtlID, qt.Equals, ar.ExternalID)
+
+		// a purged app can no longer be restored
+		adt.Moment = time.Now()
+		_, err = as.Restore(ctx, ar.ExternalID, adt)
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue, qt.Commentf("%v", err))
+	})
+}
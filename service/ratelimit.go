@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+// rateLimitCacheTTL is how long the rate limits of an App are kept
+// before they are read from the datastore again
+const rateLimitCacheTTL = time.Minute
+
+// RateLimitService finds the rate limits of an App: the Defaults,
+// overridden by any set for the App in the app_rate_limit table.
+// Limits are cached, so they are not read for every request, and a
+// change to them applies within rateLimitCacheTTL.
+type RateLimitService struct {
+	Datastorer diygoapi.Datastorer
+	Defaults   diygoapi.AppRateLimits
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]cachedRateLimits
+}
+
+type cachedRateLimits struct {
+	limits  diygoapi.AppRateLimits
+	expires time.Time
+}
+
+// FindAppRateLimits returns the rate limits of the App
+func (s *RateLimitService) FindAppRateLimits(ctx context.Context, a *diygoapi.App) (limits diygoapi.AppRateLimits, err error) {
+	const op errs.Op = "service/RateLimitService.FindAppRateLimits"
+
+	if a == nil {
+		return diygoapi.AppRateLimits{}, errs.E(op, errs.Internal, "app is required")
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	cached, ok := s.cache[a.ID]
+	s.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.limits, nil
+	}
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return diygoapi.AppRateLimits{}, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	limits = s.Defaults
+	var row datastore.FindAppRateLimitRow
+	row, err = datastore.New(tx).FindAppRateLimit(ctx, a.ID.PgxUUID())
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// no limits are set for the app, the defaults apply
+	case err != nil:
+		return diygoapi.AppRateLimits{}, errs.E(op, errs.Database, err)
+	default:
+		limits.App = overrideRateLimit(limits.App, row.RequestsPerMinute, row.Burst)
+		limits.User = overrideRateLimit(limits.User, row.UserRequestsPerMinute, row.UserBurst)
+	}
+
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return diygoapi.AppRateLimits{}, errs.E(op, err)
+	}
+
+	s.mu.Lock()
+	if s.cache == nil {
+		s.cache = make(map[uuid.UUID]cachedRateLimits)
+	}
+	s.cache[a.ID] = cachedRateLimits{limits: limits, expires: now.Add(rateLimitCacheTTL)}
+	s.mu.Unlock()
+
+	return limits, nil
+}
+
+// overrideRateLimit returns rl with the requests per minute and burst
+// replaced by those which are not null
+func overrideRateLimit(rl diygoapi.RateLimit, requestsPerMinute, burst pgtype.Int4) diygoapi.RateLimit {
+	if requestsPerMinute.Valid {
+		rl.RequestsPerMinute = int(requestsPerMinute.Int32)
+	}
+	if burst.Valid {
+		rl.Burst = int(burst.Int32)
+	}
+	return rl
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
+)
+
+// findRatings retrieves the ratings of every rating system from the datastore
+func findRatings(ctx context.Context, dbtx datastore.DBTX) (diygoapi.Ratings, error) {
+	const op errs.Op = "service/findRatings"
+
+	rows, err := datastore.New(dbtx).FindRatings(ctx)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	ratings := make(diygoapi.Ratings, 0, len(rows))
+	for _, row := range rows {
+		ratings = append(ratings, diygoapi.Rating{
+			ID:          row.RatingID.Bytes,
+			System:      row.RatingSystem,
+			Country:     row.CountryCd,
+			Code:        row.RatingCd,
+			Description: row.RatingDesc,
+		})
+	}
+
+	return ratings, nil
+}
+
+// FindAllRatings lists the ratings of every rating system, ordered by
+// country and then from least to most restrictive
+func (s *MovieService) FindAllRatings(ctx context.Context) (responses []*diygoapi.RatingResponse, err error) {
+	const op errs.Op = "service/MovieService.FindAllRatings"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var ratings diygoapi.Ratings
+	ratings, err = findRatings(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	for _, r := range ratings {
+		responses = append(responses, &diygoapi.RatingResponse{
+			System:      r.System,
+			Country:     r.Country,
+			Code:        r.Code,
+			Description: r.Description,
+		})
+	}
+
+	return responses, nil
+}
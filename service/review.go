@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+// ReviewService is a service for creating, updating and reading the
+// reviews of a movie. A movie's review count and score total are
+// adjusted in the same transaction as its reviews are written.
+type ReviewService struct {
+	Datastorer diygoapi.Datastorer
+}
+
+// updateMovieReviewScoreTx adjusts the review count and score total of
+// the movie by the given amounts
+func updateMovieReviewScoreTx(ctx context.Context, tx pgx.Tx, movieID pgtype.UUID, countDelta int32, scoreDelta int64) error {
+	const op errs.Op = "service/updateMovieReviewScoreTx"
+
+	rowsAffected, err := datastore.New(tx).UpdateMovieReviewScore(ctx, datastore.UpdateMovieReviewScoreParams{
+		CountDelta: countDelta,
+		ScoreDelta: scoreDelta,
+		MovieID:    movieID,
+	})
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	return nil
+}
+
+// newReviewResponse initializes ReviewResponse for a review written
+// with the given audit
+func newReviewResponse(r diygoapi.Review, movieExtlID string, sa diygoapi.SimpleAudit) *diygoapi.ReviewResponse {
+	return &diygoapi.ReviewResponse{
+		ExternalID:      r.ExternalID.String(),
+		MovieExtlID:     movieExtlID,
+		Score:           r.Score,
+		Body:            r.Body,
+		UserExtlID:      r.User.ExternalID.String(),
+		UserFirstName:   r.User.FirstName,
+		UserLastName:    r.User.LastName,
+		CreateAppExtlID: sa.Create.App.ExternalID.String(),
+		CreateDateTime:  sa.Create.Moment.Format(time.RFC3339),
+		UpdateDateTime:  sa.Update.Moment.Format(time.RFC3339),
+	}
+}
+
+// Create reviews a movie as the user in the audit. A user can review
+// a movie only once.
+func (s *ReviewService) Create(ctx context.Context, r *diygoapi.CreateReviewRequest, adt diygoapi.Audit) (response *diygoapi.ReviewResponse, err error) {
+	const op errs.Op = "service/ReviewService.Create"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var dbm datastore.Movie
+	dbm, err = findOrgMovie(ctx, tx, r.MovieExternalID, orgID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	review := diygoapi.Review{
+		ID:         uuid.New(),
+		ExternalID: secure.NewID(),
+		MovieID:    dbm.MovieID.Bytes,
+		User:       adt.User,
+		Score:      r.Score,
+		Body:       r.Body,
+	}
+
+	err = review.IsValid()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	sa := diygoapi.SimpleAudit{
+		Create: adt,
+		Update: adt,
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).CreateReview(ctx, datastore.CreateReviewParams{
+		ReviewID:        review.ID.PgxUUID(),
+		ExtlID:          review.ExternalID.String(),
+		MovieID:         dbm.MovieID,
+		UserID:          adt.User.ID.PgxUUID(),
+		Score:           int16(review.Score),
+		Body:            diygoapi.NewPgxText(review.Body),
+		CreateAppID:     adt.App.ID.PgxUUID(),
+		CreateUserID:    adt.User.ID.PgxUUID(),
+		CreateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+		UpdateAppID:     adt.App.ID.PgxUUID(),
+		UpdateUserID:    adt.User.ID.PgxUUID(),
+		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, errs.E(op, errs.Exist, "the user has already reviewed this movie, update the existing review instead")
+		}
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	err = updateMovieReviewScoreTx(ctx, tx, dbm.MovieID, 1, int64(review.Score))
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newReviewResponse(review, dbm.ExtlID, sa), nil
+}
+
+// Update edits a review. Only the author of a review can edit it.
+func (s *ReviewService) Update(ctx context.Context, r *diygoapi.UpdateReviewRequest, adt diygoapi.Audit) (response *diygoapi.ReviewResponse, err error) {
+	const op errs.Op = "service/ReviewService.Update"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var dbm datastore.Movie
+	dbm, err = findOrgMovie(ctx, tx, r.MovieExternalID, orgID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// lock the review so the change in score is computed from the
+	// score as it is committed
+	var dbr datastore.FindReviewByExternalIDForUpdateRow
+	dbr, err = datastore.New(tx).FindReviewByExternalIDForUpdate(ctx, datastore.FindReviewByExternalIDForUpdateParams{
+		ExtlID:  r.ExternalID,
+		MovieID: dbm.MovieID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.E(op, errs.NotExist, "no review exists for the given external ID")
+		}
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if adt.User == nil || uuid.UUID(dbr.UserID.Bytes) != adt.User.ID {
+		return nil, errs.E(op, errs.Unauthorized, "only the author of a review can edit it")
+	}
+
+	review := diygoapi.Review{
+		ID:         dbr.ReviewID.Bytes,
+		ExternalID: secure.MustParseIdentifier(dbr.ExtlID),
+		MovieID:    dbm.MovieID.Bytes,
+		User:       adt.User,
+		Score:      r.Score,
+		Body:       r.Body,
+	}
+
+	err = review.IsValid()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).UpdateReview(ctx, datastore.UpdateReviewParams{
+		Score:           int16(review.Score),
+		Body:            diygoapi.NewPgxText(review.Body),
+		UpdateAppID:     adt.App.ID.PgxUUID(),
+		UpdateUserID:    adt.User.ID.PgxUUID(),
+		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+		ReviewID:        dbr.ReviewID,
+	})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	if scoreDelta := int64(review.Score) - int64(dbr.Score); scoreDelta != 0 {
+		err = updateMovieReviewScoreTx(ctx, tx, dbm.MovieID, 0, scoreDelta)
+		if err != nil {
+			return nil, errs.E(op, err)
+		}
+	}
+
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	sa := diygoapi.SimpleAudit{
+		Create: diygoapi.Audit{
+			App: &diygoapi.App{
+				ID:         dbr.CreateAppID.Bytes,
+				ExternalID: secure.MustParseIdentifier(dbr.CreateAppExtlID),
+			},
+			Moment: dbr.CreateTimestamp.Time,
+		},
+		Update: adt,
+	}
+
+	return newReviewResponse(review, dbm.ExtlID, sa), nil
+}
+
+// FindByMovie lists the reviews of a movie, oldest first
+func (s *ReviewService) FindByMovie(ctx context.Context, movieExtlID string, adt diygoapi.Audit) (responses []*diygoapi.ReviewResponse, err error) {
+	const op errs.Op = "service/ReviewService.FindByMovie"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var dbm datastore.Movie
+	dbm, err = findOrgMovie(ctx, tx, movieExtlID, orgID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var rows []datastore.FindReviewsByMovieIDRow
+	rows, err = datastore.New(tx).FindReviewsByMovieID(ctx, dbm.MovieID)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	responses = make([]*diygoapi.ReviewResponse, 0, len(rows))
+	for _, row := range rows {
+		responses = append(responses, &diygoapi.ReviewResponse{
+			ExternalID:      row.ExtlID,
+			MovieExtlID:     dbm.ExtlID,
+			Score:           int(row.Score),
+			Body:            row.Body.String,
+			UserExtlID:      row.UserExtlID,
+			UserFirstName:   row.UserFirstName,
+			UserLastName:    row.UserLastName,
+			CreateAppExtlID: row.CreateAppExtlID,
+			CreateDateTime:  row.CreateTimestamp.Time.Format(time.RFC3339),
+			UpdateDateTime:  row.UpdateTimestamp.Time.Format(time.RFC3339),
+		})
+	}
+
+	return responses, nil
+}
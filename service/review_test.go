@@ -0,0 +1,181 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb/sqldbtest"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+func TestReviewService(t *testing.T) {
+	t.Run("review a movie", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		ms := service.MovieService{Datastorer: db}
+		rs := service.ReviewService{Datastorer: db}
+
+		rd, _ := time.Parse(time.RFC3339, "1985-08-16T00:00:00Z")
+		var mr *diygoapi.MovieResponse
+		mr, err = ms.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "Re-Animator",
+			Rated:    "R",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  86,
+			Director: "Stuart Gordon",
+			Writer:   "Dennis Paoli",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(mr.ReviewCount, qt.Equals, int64(0))
+
+		var got *diygoapi.ReviewResponse
+		got, err = rs.Create(ctx, &diygoapi.CreateReviewRequest{
+			MovieExternalID: mr.ExternalID,
+			Score:           4,
+			Body:            "Gleefully gruesome.",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got.MovieExtlID, qt.Equals, mr.ExternalID)
+		c.Assert(got.Score, qt.Equals, 4)
+		c.Assert(got.UserExtlID, qt.Equals, adt.User.ExternalID.String())
+
+		// a user can review a movie only once
+		_, err = rs.Create(ctx, &diygoapi.CreateReviewRequest{
+			MovieExternalID: mr.ExternalID,
+			Score:           5,
+		}, adt)
+		c.Assert(errs.KindIs(errs.Exist, err), qt.IsTrue)
+
+		// only the author can edit a review
+		otherAdt := adt
+		otherAdt.User = &diygoapi.User{ID: uuid.New()}
+		_, err = rs.Update(ctx, &diygoapi.UpdateReviewRequest{
+			MovieExternalID: mr.ExternalID,
+			ExternalID:      got.ExternalID,
+			Score:           1,
+		}, otherAdt)
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue)
+
+		var updated *diygoapi.ReviewResponse
+		updated, err = rs.Update(ctx, &diygoapi.UpdateReviewRequest{
+			MovieExternalID: mr.ExternalID,
+			ExternalID:      got.ExternalID,
+			Score:           2,
+			Body:            "Less fun the second time.",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(updated.Score, qt.Equals, 2)
+		c.Assert(updated.CreateDateTime, qt.Equals, got.CreateDateTime)
+
+		// the movie's review count and average score follow its reviews
+		mr, err = ms.FindMovieByExternalID(ctx, mr.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(mr.ReviewCount, qt.Equals, int64(1))
+		c.Assert(mr.AverageScore, qt.Equals, float64(2))
+
+		var reviews []*diygoapi.ReviewResponse
+		reviews, err = rs.FindByMovie(ctx, mr.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(reviews, qt.HasLen, 1)
+		c.Assert(reviews[0].Body, qt.Equals, "Less fun the second time.")
+	})
+	t.Run("invalid score", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		ms := service.MovieService{Datastorer: db}
+		rs := service.ReviewService{Datastorer: db}
+
+		rd, _ := time.Parse(time.RFC3339, "1986-10-17T00:00:00Z")
+		var mr *diygoapi.MovieResponse
+		mr, err = ms.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "From Beyond",
+			Rated:    "R",
+			Released: rd.Format(time.RFC3339),
+			RunTime:  86,
+			Director: "Stuart Gordon",
+			Writer:   "Dennis Paoli",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+
+		_, err = rs.Create(ctx, &diygoapi.CreateReviewRequest{
+			MovieExternalID: mr.ExternalID,
+			Score:           diygoapi.MaxReviewScore + 1,
+		}, adt)
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+	})
+	t.Run("review a movie which does not exist", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		rs := service.ReviewService{Datastorer: db}
+
+		_, err = rs.Create(ctx, &diygoapi.CreateReviewRequest{
+			MovieExternalID: "not-a-movie",
+			Score:           3,
+		}, adt)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+	})
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+// maxSecurityEventTextLength is the most characters of an event target
+// or detail which are written, as either may be taken from request
+// headers of any length
+const maxSecurityEventTextLength = 256
+
+// truncateSecurityEventText shortens s to maxSecurityEventTextLength
+// characters, without splitting a character
+func truncateSecurityEventText(s string) string {
+	var n int
+	for i := range s {
+		if n == maxSecurityEventTextLength {
+			return s[:i]
+		}
+		n++
+	}
+	return s
+}
+
+// newCreateSecurityEventParams initializes the params needed to write
+// a security event. The request ID is taken from the context, where
+// it is added by the server request logging middleware.
+func newCreateSecurityEventParams(ctx context.Context, e diygoapi.SecurityEvent) datastore.CreateSecurityEventParams {
+	arg := datastore.CreateSecurityEventParams{
+		SecurityEventID: uuid.New().PgxUUID(),
+		EventAction:     string(e.Action),
+		EventOutcome:    string(e.Outcome),
+		Target:          diygoapi.NewPgxText(truncateSecurityEventText(e.Target)),
+		Detail:          diygoapi.NewPgxText(truncateSecurityEventText(e.Detail)),
+		EventTimestamp:  diygoapi.NewPgxTimestampTZ(e.Moment),
+	}
+	if e.Actor != nil {
+		arg.ActorExtlID = diygoapi.NewPgxText(e.Actor.ExternalID.String())
+	}
+	if e.App != nil {
+		arg.AppExtlID = diygoapi.NewPgxText(e.App.ExternalID.String())
+	}
+	if e.Org != nil {
+		arg.OrgID = e.Org.ID.PgxUUID()
+		arg.OrgExtlID = diygoapi.NewPgxText(e.Org.ExternalID.String())
+	}
+	if id, ok := hlog.IDFromCtx(ctx); ok {
+		arg.RequestID = diygoapi.NewPgxText(id.String())
+	}
+
+	return arg
+}
+
+// writeSecurityEventTx appends a security event using the same
+// transaction as the action it records, so the event is only written
+// if the action is committed.
+func writeSecurityEventTx(ctx context.Context, tx pgx.Tx, e diygoapi.SecurityEvent) (err error) {
+	const op errs.Op = "service/writeSecurityEventTx"
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).CreateSecurityEvent(ctx, newCreateSecurityEventParams(ctx, e))
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	return nil
+}
+
+// recordSecurityEvent appends a security event in a transaction of its
+// own, for events which happen as a request fails (and its transaction
+// is rolled back). The request still fails with its own error if the
+// event cannot be written, so the error is logged instead of returned.
+func recordSecurityEvent(ctx context.Context, ds diygoapi.Datastorer, e diygoapi.SecurityEvent) {
+	s := &SecurityEventService{Datastorer: ds}
+	err := s.Record(ctx, e)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("action", string(e.Action)).Msg("security event not recorded")
+	}
+}
+
+// SecurityEventService records and finds security events
+type SecurityEventService struct {
+	Datastorer diygoapi.Datastorer
+}
+
+// Record appends the security event in a transaction of its own
+func (s *SecurityEventService) Record(ctx context.Context, e diygoapi.SecurityEvent) (err error) {
+	const op errs.Op = "service/SecurityEventService.Record"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	err = writeSecurityEventTx(ctx, tx, e)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	return nil
+}
+
+// FindSecurityEvents lists security events, newest first. Only events
+// of the org of the calling app are listed, unless it is the Principal
+// org, which lists events of all orgs.
+func (s *SecurityEventService) FindSecurityEvents(ctx context.Context, r *diygoapi.FindSecurityEventsRequest) (responses []*diygoapi.SecurityEventResponse, err error) {
+	const op errs.Op = "service/SecurityEventService.FindSecurityEvents"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	limit := r.Limit
+	switch {
+	case limit == 0:
+		limit = diygoapi.DefaultSecurityEventListLimit
+	case limit < 0 || limit > diygoapi.MaxSecurityEventListLimit:
+		return nil, errs.E(op, errs.Validation, errs.Parameter("limit"),
+			fmt.Sprintf("limit must be between 1 and %d", diygoapi.MaxSecurityEventListLimit))
+	}
+	if !r.Since.IsZero() && !r.Until.IsZero() && !r.Since.Before(r.Until) {
+		return nil, errs.E(op, errs.Validation, errs.Parameter("until"), "until must be after since")
+	}
+
+	arg := datastore.FindSecurityEventsParams{
+		EventAction:  diygoapi.NewPgxText(string(r.Action)),
+		EventOutcome: diygoapi.NewPgxText(string(r.Outcome)),
+		ActorExtlID:  diygoapi.NewPgxText(r.ActorExtlID),
+		AppExtlID:    diygoapi.NewPgxText(r.AppExtlID),
+		OrgExtlID:    diygoapi.NewPgxText(r.OrgExtlID),
+		RowLimit:     int32(limit),
+	}
+	if !r.Since.IsZero() {
+		arg.Since = diygoapi.NewPgxTimestampTZ(r.Since)
+	}
+	if !r.Until.IsZero() {
+		arg.Until = diygoapi.NewPgxTimestampTZ(r.Until)
+	}
+	if r.Before > 0 {
+		arg.BeforeSeq = diygoapi.NewPgxInt8(r.Before)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rows []datastore.SecurityEvent
+	rows, err = datastore.New(tx).FindSecurityEvents(ctx, arg)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	responses = make([]*diygoapi.SecurityEventResponse, 0, len(rows))
+	for _, row := range rows {
+		responses = append(responses, newSecurityEventResponse(row))
+	}
+
+	return responses, nil
+}
+
+// newSecurityEventResponse initializes a SecurityEventResponse from a
+// security_event row
+func newSecurityEventResponse(row datastore.SecurityEvent) *diygoapi.SecurityEventResponse {
+	return &diygoapi.SecurityEventResponse{
+		Sequence:      row.EventSeq,
+		Action:        diygoapi.SecurityAction(row.EventAction),
+		Outcome:       diygoapi.SecurityOutcome(row.EventOutcome),
+		ActorExtlID:   row.ActorExtlID.String,
+		AppExtlID:     row.AppExtlID.String,
+		OrgExtlID:     row.OrgExtlID.String,
+		Target:        row.Target.String,
+		Detail:        row.Detail.String,
+		RequestID:     row.RequestID.String,
+		EventDateTime: row.EventTimestamp.Time.Format(time.RFC3339),
+	}
+}
@@ -1,3 +1,10 @@
 // Package service orchestrates components between handlers and other
 // packages (datastore, gateway, domain, etc.)
 package service
+
+import "go.opentelemetry.io/otel"
+
+// tracer starts a span for each service method, named by its errs.Op.
+// Spans are recorded by the global TracerProvider, which may be set
+// after the tracer is created.
+var tracer = otel.Tracer("github.com/gilcrest/diygoapi/service")
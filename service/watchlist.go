@@ -0,0 +1,685 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+// WatchlistService is a service for managing the watchlists of the
+// authenticated user. Watchlists belong to the org of the acting app
+// and only their owner can change them.
+type WatchlistService struct {
+	Datastorer diygoapi.Datastorer
+}
+
+// watchlistAudit is the combination of a domain Watchlist and when it
+// was created and last updated
+type watchlistAudit struct {
+	Watchlist       diygoapi.Watchlist
+	CreateTimestamp time.Time
+	UpdateTimestamp time.Time
+}
+
+// newWatchlistAuditFromRow initializes a watchlistAudit given a
+// FindWatchlistByExternalIDRow
+func newWatchlistAuditFromRow(row datastore.FindWatchlistByExternalIDRow) watchlistAudit {
+	return watchlistAudit{
+		Watchlist: diygoapi.Watchlist{
+			ID:         row.WatchlistID.Bytes,
+			ExternalID: secure.MustParseIdentifier(row.ExtlID),
+			Owner: &diygoapi.User{
+				ID:         row.UserID.Bytes,
+				ExternalID: secure.MustParseIdentifier(row.UserExtlID),
+				FirstName:  row.UserFirstName,
+				LastName:   row.UserLastName,
+			},
+			Name:   row.WatchlistName,
+			Shared: row.Shared,
+		},
+		CreateTimestamp: row.CreateTimestamp.Time,
+		UpdateTimestamp: row.UpdateTimestamp.Time,
+	}
+}
+
+// newWatchlistResponse initializes WatchlistResponse
+func newWatchlistResponse(wa watchlistAudit) *diygoapi.WatchlistResponse {
+	movies := make([]diygoapi.WatchlistMovieResponse, 0, len(wa.Watchlist.Entries))
+	for _, e := range wa.Watchlist.Entries {
+		movies = append(movies, diygoapi.WatchlistMovieResponse{
+			ExternalID: e.MovieExternalID,
+			Title:      e.Title,
+			Position:   e.Position,
+		})
+	}
+
+	return &diygoapi.WatchlistResponse{
+		ExternalID:     wa.Watchlist.ExternalID.String(),
+		Name:           wa.Watchlist.Name,
+		Shared:         wa.Watchlist.Shared,
+		OwnerExtlID:    wa.Watchlist.Owner.ExternalID.String(),
+		OwnerFirstName: wa.Watchlist.Owner.FirstName,
+		OwnerLastName:  wa.Watchlist.Owner.LastName,
+		Movies:         movies,
+		CreateDateTime: wa.CreateTimestamp.Format(time.RFC3339),
+		UpdateDateTime: wa.UpdateTimestamp.Format(time.RFC3339),
+	}
+}
+
+// findWatchlistEntries loads the entries of the given watchlists
+func findWatchlistEntries(ctx context.Context, dbtx datastore.DBTX, watchlists ...*diygoapi.Watchlist) error {
+	const op errs.Op = "service/findWatchlistEntries"
+
+	if len(watchlists) == 0 {
+		return nil
+	}
+
+	ids := make([]pgtype.UUID, 0, len(watchlists))
+	byID := make(map[uuid.UUID]*diygoapi.Watchlist, len(watchlists))
+	for _, w := range watchlists {
+		w.Entries = nil
+		ids = append(ids, w.ID.PgxUUID())
+		byID[w.ID] = w
+	}
+
+	rows, err := datastore.New(dbtx).FindWatchlistEntriesByWatchlistIDs(ctx, ids)
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+	for _, row := range rows {
+		w := byID[row.WatchlistID.Bytes]
+		w.Entries = append(w.Entries, diygoapi.WatchlistEntry{
+			MovieID:         row.MovieID.Bytes,
+			MovieExternalID: row.ExtlID,
+			Title:           row.Title,
+			Position:        int(row.Position),
+		})
+	}
+
+	return nil
+}
+
+// findWatchlistTx finds a watchlist of the acting org, with its entries
+func findWatchlistTx(ctx context.Context, tx pgx.Tx, extlID string, orgID pgtype.UUID) (watchlistAudit, error) {
+	const op errs.Op = "service/findWatchlistTx"
+
+	row, err := datastore.New(tx).FindWatchlistByExternalID(ctx, datastore.FindWatchlistByExternalIDParams{
+		ExtlID: extlID,
+		OrgID:  orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return watchlistAudit{}, errs.E(op, errs.NotExist, "no watchlist exists for the given external ID")
+		}
+		return watchlistAudit{}, errs.E(op, errs.Database, err)
+	}
+
+	wa := newWatchlistAuditFromRow(row)
+
+	err = findWatchlistEntries(ctx, tx, &wa.Watchlist)
+	if err != nil {
+		return watchlistAudit{}, errs.E(op, err)
+	}
+
+	return wa, nil
+}
+
+// findOwnedWatchlistTx finds a watchlist which the user in the audit
+// owns. Having a role which permits changing watchlists is not enough,
+// the user must also be the owner.
+func findOwnedWatchlistTx(ctx context.Context, tx pgx.Tx, extlID string, adt diygoapi.Audit) (watchlistAudit, error) {
+	const op errs.Op = "service/findOwnedWatchlistTx"
+
+	orgID, err := actingOrgID(adt)
+	if err != nil {
+		return watchlistAudit{}, errs.E(op, err)
+	}
+
+	wa, err := findWatchlistTx(ctx, tx, extlID, orgID)
+	if err != nil {
+		return watchlistAudit{}, errs.E(op, err)
+	}
+
+	err = wa.Watchlist.Authorize(adt.User)
+	if err != nil {
+		return watchlistAudit{}, errs.E(op, err)
+	}
+
+	return wa, nil
+}
+
+// reorderWatchlistTx numbers the given movies of the watchlist from 1
+// in the order given
+func reorderWatchlistTx(ctx context.Context, tx pgx.Tx, w diygoapi.Watchlist, movieIDs []uuid.UUID, adt diygoapi.Audit) error {
+	const op errs.Op = "service/reorderWatchlistTx"
+
+	if len(movieIDs) == 0 {
+		return nil
+	}
+
+	ids := make([]pgtype.UUID, 0, len(movieIDs))
+	for _, id := range movieIDs {
+		ids = append(ids, id.PgxUUID())
+	}
+
+	rowsAffected, err := datastore.New(tx).ReorderWatchlistEntries(ctx, datastore.ReorderWatchlistEntriesParams{
+		UpdateAppID:     adt.App.ID.PgxUUID(),
+		UpdateUserID:    adt.User.ID.PgxUUID(),
+		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+		MovieIds:        ids,
+		WatchlistID:     w.ID.PgxUUID(),
+	})
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != int64(len(ids)) {
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be %d, actual: %d", len(ids), rowsAffected))
+	}
+
+	return nil
+}
+
+// createWatchlistErr maps a unique violation on the owner and name of
+// a watchlist to an Exist error
+func createWatchlistErr(op errs.Op, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return errs.E(op, errs.Exist, errs.Parameter("name"), "a watchlist with this name already exists")
+	}
+	return errs.E(op, errs.Database, err)
+}
+
+// Create creates a watchlist owned by the user in the audit
+func (s *WatchlistService) Create(ctx context.Context, r *diygoapi.CreateWatchlistRequest, adt diygoapi.Audit) (response *diygoapi.WatchlistResponse, err error) {
+	const op errs.Op = "service/WatchlistService.Create"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	w := diygoapi.Watchlist{
+		ID:         uuid.New(),
+		ExternalID: secure.NewID(),
+		Owner:      adt.User,
+		Name:       strings.TrimSpace(r.Name),
+		Shared:     r.Shared,
+	}
+
+	err = w.IsValid()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).CreateWatchlist(ctx, datastore.CreateWatchlistParams{
+		WatchlistID:     w.ID.PgxUUID(),
+		ExtlID:          w.ExternalID.String(),
+		OrgID:           orgID,
+		UserID:          adt.User.ID.PgxUUID(),
+		WatchlistName:   w.Name,
+		Shared:          w.Shared,
+		CreateAppID:     adt.App.ID.PgxUUID(),
+		CreateUserID:    adt.User.ID.PgxUUID(),
+		CreateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+		UpdateAppID:     adt.App.ID.PgxUUID(),
+		UpdateUserID:    adt.User.ID.PgxUUID(),
+		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+	})
+	if err != nil {
+		return nil, createWatchlistErr(op, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newWatchlistResponse(watchlistAudit{
+		Watchlist:       w,
+		CreateTimestamp: adt.Moment,
+		UpdateTimestamp: adt.Moment,
+	}), nil
+}
+
+// Update renames a watchlist or changes whether it is shared
+func (s *WatchlistService) Update(ctx context.Context, r *diygoapi.UpdateWatchlistRequest, adt diygoapi.Audit) (response *diygoapi.WatchlistResponse, err error) {
+	const op errs.Op = "service/WatchlistService.Update"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var wa watchlistAudit
+	wa, err = findOwnedWatchlistTx(ctx, tx, r.ExternalID, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	wa.Watchlist.Name = strings.TrimSpace(r.Name)
+	wa.Watchlist.Shared = r.Shared
+
+	err = wa.Watchlist.IsValid()
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).UpdateWatchlist(ctx, datastore.UpdateWatchlistParams{
+		WatchlistName:   wa.Watchlist.Name,
+		Shared:          wa.Watchlist.Shared,
+		UpdateAppID:     adt.App.ID.PgxUUID(),
+		UpdateUserID:    adt.User.ID.PgxUUID(),
+		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+		WatchlistID:     wa.Watchlist.ID.PgxUUID(),
+	})
+	if err != nil {
+		return nil, createWatchlistErr(op, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	wa.UpdateTimestamp = adt.Moment
+
+	return newWatchlistResponse(wa), nil
+}
+
+// Delete removes a watchlist and its entries
+func (s *WatchlistService) Delete(ctx context.Context, extlID string, adt diygoapi.Audit) (dr diygoapi.DeleteResponse, err error) {
+	const op errs.Op = "service/WatchlistService.Delete"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var wa watchlistAudit
+	wa, err = findOwnedWatchlistTx(ctx, tx, extlID, adt)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).DeleteWatchlist(ctx, wa.Watchlist.ID.PgxUUID())
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	return diygoapi.DeleteResponse{
+		ExternalID: extlID,
+		Deleted:    true,
+	}, nil
+}
+
+// AddMovie adds a movie to the end of a watchlist
+func (s *WatchlistService) AddMovie(ctx context.Context, r *diygoapi.AddWatchlistMovieRequest, adt diygoapi.Audit) (response *diygoapi.WatchlistResponse, err error) {
+	const op errs.Op = "service/WatchlistService.AddMovie"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var wa watchlistAudit
+	wa, err = findOwnedWatchlistTx(ctx, tx, r.ExternalID, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var dbm datastore.Movie
+	dbm, err = findOrgMovie(ctx, tx, r.MovieExternalID, orgID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	position := 1
+	if n := len(wa.Watchlist.Entries); n > 0 {
+		position = wa.Watchlist.Entries[n-1].Position + 1
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).CreateWatchlistEntry(ctx, datastore.CreateWatchlistEntryParams{
+		WatchlistID:     wa.Watchlist.ID.PgxUUID(),
+		MovieID:         dbm.MovieID,
+		Position:        int32(position),
+		CreateAppID:     adt.App.ID.PgxUUID(),
+		CreateUserID:    adt.User.ID.PgxUUID(),
+		CreateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+		UpdateAppID:     adt.App.ID.PgxUUID(),
+		UpdateUserID:    adt.User.ID.PgxUUID(),
+		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(adt.Moment),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, errs.E(op, errs.Exist, errs.Parameter("movie_extl_id"), "the movie is already on the watchlist")
+		}
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	err = findWatchlistEntries(ctx, tx, &wa.Watchlist)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newWatchlistResponse(wa), nil
+}
+
+// RemoveMovie removes a movie from a watchlist. The movies after it
+// move up a position.
+func (s *WatchlistService) RemoveMovie(ctx context.Context, extlID, movieExtlID string, adt diygoapi.Audit) (response *diygoapi.WatchlistResponse, err error) {
+	const op errs.Op = "service/WatchlistService.RemoveMovie"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var wa watchlistAudit
+	wa, err = findOwnedWatchlistTx(ctx, tx, extlID, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var (
+		removed   *diygoapi.WatchlistEntry
+		remaining []uuid.UUID
+	)
+	for i, e := range wa.Watchlist.Entries {
+		if e.MovieExternalID == movieExtlID {
+			removed = &wa.Watchlist.Entries[i]
+			continue
+		}
+		remaining = append(remaining, e.MovieID)
+	}
+	if removed == nil {
+		return nil, errs.E(op, errs.NotExist, "the movie is not on the watchlist")
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).DeleteWatchlistEntry(ctx, datastore.DeleteWatchlistEntryParams{
+		WatchlistID: wa.Watchlist.ID.PgxUUID(),
+		MovieID:     removed.MovieID.PgxUUID(),
+	})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	err = reorderWatchlistTx(ctx, tx, wa.Watchlist, remaining, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = findWatchlistEntries(ctx, tx, &wa.Watchlist)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newWatchlistResponse(wa), nil
+}
+
+// Reorder puts the movies of a watchlist in the order given
+func (s *WatchlistService) Reorder(ctx context.Context, r *diygoapi.ReorderWatchlistRequest, adt diygoapi.Audit) (response *diygoapi.WatchlistResponse, err error) {
+	const op errs.Op = "service/WatchlistService.Reorder"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var wa watchlistAudit
+	wa, err = findOwnedWatchlistTx(ctx, tx, r.ExternalID, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// the new order must be a permutation of the movies on the watchlist
+	byExtlID := make(map[string]uuid.UUID, len(wa.Watchlist.Entries))
+	for _, e := range wa.Watchlist.Entries {
+		byExtlID[e.MovieExternalID] = e.MovieID
+	}
+	if len(r.MovieExternalIDs) != len(byExtlID) {
+		return nil, errs.E(op, errs.Validation, errs.Parameter("movie_extl_ids"),
+			fmt.Sprintf("movie_extl_ids must list each of the %d movies on the watchlist once", len(byExtlID)))
+	}
+	movieIDs := make([]uuid.UUID, 0, len(r.MovieExternalIDs))
+	for _, extlID := range r.MovieExternalIDs {
+		id, ok := byExtlID[extlID]
+		if !ok {
+			return nil, errs.E(op, errs.Validation, errs.Parameter("movie_extl_ids"),
+				fmt.Sprintf("movie %s is not on the watchlist or is listed more than once", extlID))
+		}
+		delete(byExtlID, extlID)
+		movieIDs = append(movieIDs, id)
+	}
+
+	err = reorderWatchlistTx(ctx, tx, wa.Watchlist, movieIDs, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = findWatchlistEntries(ctx, tx, &wa.Watchlist)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	return newWatchlistResponse(wa), nil
+}
+
+// FindMine lists the watchlists owned by the user in the audit
+func (s *WatchlistService) FindMine(ctx context.Context, adt diygoapi.Audit) (responses []*diygoapi.WatchlistResponse, err error) {
+	const op errs.Op = "service/WatchlistService.FindMine"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rows []datastore.FindWatchlistsByUserIDRow
+	rows, err = datastore.New(tx).FindWatchlistsByUserID(ctx, datastore.FindWatchlistsByUserIDParams{
+		UserID: adt.User.ID.PgxUUID(),
+		OrgID:  orgID,
+	})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	was := make([]watchlistAudit, 0, len(rows))
+	watchlists := make([]*diygoapi.Watchlist, 0, len(rows))
+	for _, row := range rows {
+		was = append(was, newWatchlistAuditFromRow(datastore.FindWatchlistByExternalIDRow(row)))
+	}
+	for i := range was {
+		watchlists = append(watchlists, &was[i].Watchlist)
+	}
+
+	err = findWatchlistEntries(ctx, tx, watchlists...)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	responses = make([]*diygoapi.WatchlistResponse, 0, len(was))
+	for _, wa := range was {
+		responses = append(responses, newWatchlistResponse(wa))
+	}
+
+	return responses, nil
+}
+
+// FindByExternalID finds a watchlist the user in the audit owns, or
+// one shared by another user of the acting org
+func (s *WatchlistService) FindByExternalID(ctx context.Context, extlID string, adt diygoapi.Audit) (response *diygoapi.WatchlistResponse, err error) {
+	const op errs.Op = "service/WatchlistService.FindByExternalID"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	var orgID pgtype.UUID
+	orgID, err = actingOrgID(adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var wa watchlistAudit
+	wa, err = findWatchlistTx(ctx, tx, extlID, orgID)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	// a private watchlist of another user is not disclosed
+	if !wa.Watchlist.CanRead(adt.User) {
+		return nil, errs.E(op, errs.NotExist, "no watchlist exists for the given external ID")
+	}
+
+	return newWatchlistResponse(wa), nil
+}
@@ -0,0 +1,180 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb/sqldbtest"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+func TestWatchlistService(t *testing.T) {
+	t.Run("manage a watchlist", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		ms := service.MovieService{Datastorer: db}
+		ws := service.WatchlistService{Datastorer: db}
+
+		var movies []*diygoapi.MovieResponse
+		for _, title := range []string{"Suspiria", "Inferno", "Tenebrae"} {
+			rd, _ := time.Parse(time.RFC3339, "1977-02-01T00:00:00Z")
+			var mr *diygoapi.MovieResponse
+			mr, err = ms.Create(ctx, &diygoapi.CreateMovieRequest{
+				Title:    title,
+				Rated:    "R",
+				Released: rd.Format(time.RFC3339),
+				RunTime:  98,
+				Director: "Dario Argento",
+				Writer:   "Dario Argento",
+			}, adt)
+			c.Assert(err, qt.IsNil)
+			movies = append(movies, mr)
+		}
+
+		var wl *diygoapi.WatchlistResponse
+		wl, err = ws.Create(ctx, &diygoapi.CreateWatchlistRequest{Name: "Giallo"}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(wl.OwnerExtlID, qt.Equals, adt.User.ExternalID.String())
+		c.Assert(wl.Movies, qt.HasLen, 0)
+
+		// watchlist names are unique per user
+		_, err = ws.Create(ctx, &diygoapi.CreateWatchlistRequest{Name: "Giallo"}, adt)
+		c.Assert(errs.KindIs(errs.Exist, err), qt.IsTrue)
+
+		for _, mr := range movies {
+			wl, err = ws.AddMovie(ctx, &diygoapi.AddWatchlistMovieRequest{
+				ExternalID:      wl.ExternalID,
+				MovieExternalID: mr.ExternalID,
+			}, adt)
+			c.Assert(err, qt.IsNil)
+		}
+		c.Assert(wl.Movies, qt.HasLen, 3)
+		c.Assert(wl.Movies[2].Title, qt.Equals, "Tenebrae")
+		c.Assert(wl.Movies[2].Position, qt.Equals, 3)
+
+		// a movie can be on a watchlist only once
+		_, err = ws.AddMovie(ctx, &diygoapi.AddWatchlistMovieRequest{
+			ExternalID:      wl.ExternalID,
+			MovieExternalID: movies[0].ExternalID,
+		}, adt)
+		c.Assert(errs.KindIs(errs.Exist, err), qt.IsTrue)
+
+		// a reorder must list every movie on the watchlist
+		_, err = ws.Reorder(ctx, &diygoapi.ReorderWatchlistRequest{
+			ExternalID:       wl.ExternalID,
+			MovieExternalIDs: []string{movies[2].ExternalID, movies[0].ExternalID},
+		}, adt)
+		c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+
+		wl, err = ws.Reorder(ctx, &diygoapi.ReorderWatchlistRequest{
+			ExternalID:       wl.ExternalID,
+			MovieExternalIDs: []string{movies[2].ExternalID, movies[0].ExternalID, movies[1].ExternalID},
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(wl.Movies[0].Title, qt.Equals, "Tenebrae")
+		c.Assert(wl.Movies[1].Title, qt.Equals, "Suspiria")
+
+		// removing a movie moves the ones after it up
+		wl, err = ws.RemoveMovie(ctx, wl.ExternalID, movies[2].ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(wl.Movies, qt.HasLen, 2)
+		c.Assert(wl.Movies[0].Title, qt.Equals, "Suspiria")
+		c.Assert(wl.Movies[0].Position, qt.Equals, 1)
+
+		var mine []*diygoapi.WatchlistResponse
+		mine, err = ws.FindMine(ctx, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(mine, qt.HasLen, 1)
+		c.Assert(mine[0].Movies, qt.HasLen, 2)
+
+		var dr diygoapi.DeleteResponse
+		dr, err = ws.Delete(ctx, wl.ExternalID, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(dr.Deleted, qt.IsTrue)
+
+		_, err = ws.FindByExternalID(ctx, wl.ExternalID, adt)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+	})
+	t.Run("only the owner can change a watchlist", func(t *testing.T) {
+		c := qt.New(t)
+
+		var err error
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		// start db txn using pgxpool
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("db.BeginTx error: %v", err)
+		}
+		// defer transaction rollback and handle error, if any
+		defer func() {
+			err = db.RollbackTx(ctx, tx, err)
+		}()
+
+		adt := findPrincipalTestAudit(ctx, c, tx)
+
+		ws := service.WatchlistService{Datastorer: db}
+
+		var private, shared *diygoapi.WatchlistResponse
+		private, err = ws.Create(ctx, &diygoapi.CreateWatchlistRequest{Name: "Guilty pleasures"}, adt)
+		c.Assert(err, qt.IsNil)
+		shared, err = ws.Create(ctx, &diygoapi.CreateWatchlistRequest{Name: "Club picks", Shared: true}, adt)
+		c.Assert(err, qt.IsNil)
+
+		// another user of the same org, with the same role
+		otherAdt := adt
+		otherAdt.User = &diygoapi.User{ID: uuid.New()}
+
+		// a shared watchlist can be read, but not changed
+		var got *diygoapi.WatchlistResponse
+		got, err = ws.FindByExternalID(ctx, shared.ExternalID, otherAdt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got.Name, qt.Equals, "Club picks")
+
+		_, err = ws.Update(ctx, &diygoapi.UpdateWatchlistRequest{
+			ExternalID: shared.ExternalID,
+			Name:       "Mine now",
+		}, otherAdt)
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue)
+
+		_, err = ws.Delete(ctx, shared.ExternalID, otherAdt)
+		c.Assert(errs.KindIs(errs.Unauthorized, err), qt.IsTrue)
+
+		// a private watchlist is not disclosed at all
+		_, err = ws.FindByExternalID(ctx, private.ExternalID, otherAdt)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+
+		_, err = ws.Delete(ctx, private.ExternalID, otherAdt)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+	})
+}
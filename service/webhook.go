@@ -0,0 +1,602 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
+	"github.com/gilcrest/diygoapi/tracing"
+)
+
+// DefaultWebhookTimeout is how long a webhook endpoint is given to
+// respond to a delivery
+const DefaultWebhookTimeout = 10 * time.Second
+
+// errWebhookAddrNotAllowed is the error of an attempt to a webhook
+// whose host resolves to an address webhooks cannot be sent to. The
+// address itself is left out, as the error is kept in the delivery
+// history the app can read.
+var errWebhookAddrNotAllowed = errors.New("webhook endpoint address is not allowed")
+
+// newWebhookClient returns a client which gives endpoints
+// DefaultWebhookTimeout to respond and treats a redirect as a failed
+// attempt rather than following it, so the endpoint registered is the
+// one sent to. Each address is checked with diygoapi.CheckWebhookAddr
+// as it is dialed, after the host is resolved, so a host name cannot
+// be pointed at a private address once the webhook is registered.
+// Proxies are not used, as the proxy would be dialed instead.
+func newWebhookClient(allowed []netip.Prefix) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return errWebhookAddrNotAllowed
+			}
+			if diygoapi.CheckWebhookAddr(ap.Addr(), allowed) != nil {
+				return errWebhookAddrNotAllowed
+			}
+			return nil
+		},
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   DefaultWebhookTimeout,
+		Transport: otelhttp.NewTransport(t),
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// webhookResponseLimit is the most of a webhook response body read
+// before the connection is released. The body itself is not kept.
+const webhookResponseLimit = 64 << 10
+
+// WebhookService is a service for registering the webhooks of an App
+// and inspecting their deliveries
+type WebhookService struct {
+	Datastorer      diygoapi.Datastorer
+	APIKeyGenerator diygoapi.APIKeyGenerator
+	EncryptionKey   *[32]byte
+	// AllowedNetworks are the otherwise private networks webhooks can
+	// be registered for, see diygoapi.CheckWebhookAddr
+	AllowedNetworks []netip.Prefix
+}
+
+// newWebhookResponse initializes WebhookResponse given a datastore.Webhook
+func newWebhookResponse(row datastore.Webhook) *diygoapi.WebhookResponse {
+	eventTypes := make([]diygoapi.EventType, 0, len(row.EventTypes))
+	for _, et := range row.EventTypes {
+		eventTypes = append(eventTypes, diygoapi.EventType(et))
+	}
+
+	return &diygoapi.WebhookResponse{
+		ExternalID:     row.ExtlID,
+		URL:            row.EndpointUrl,
+		EventTypes:     eventTypes,
+		Active:         row.Active,
+		CreateDateTime: row.CreateTimestamp.Time.Format(time.RFC3339),
+		UpdateDateTime: row.UpdateTimestamp.Time.Format(time.RFC3339),
+	}
+}
+
+// Create registers a webhook for the App in the Audit. The secret
+// deliveries are signed with is only returned here.
+func (s *WebhookService) Create(ctx context.Context, r *diygoapi.CreateWebhookRequest, adt diygoapi.Audit) (response *diygoapi.WebhookResponse, err error) {
+	const op errs.Op = "service/WebhookService.Create"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	if r == nil {
+		return nil, errs.E(op, errs.Validation, "CreateWebhookRequest must have a value when creating a Webhook")
+	}
+
+	var wh diygoapi.Webhook
+	wh, err = diygoapi.NewWebhook(adt.App, r, s.APIKeyGenerator, s.AllowedNetworks)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	var ciphertext []byte
+	ciphertext, err = secure.Encrypt([]byte(wh.Secret), s.EncryptionKey)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	eventTypes := make([]string, 0, len(wh.EventTypes))
+	for _, et := range wh.EventTypes {
+		eventTypes = append(eventTypes, string(et))
+	}
+
+	params := datastore.CreateWebhookParams{
+		WebhookID:        wh.ID.PgxUUID(),
+		ExtlID:           wh.ExternalID.String(),
+		OrgID:            wh.App.Org.ID.PgxUUID(),
+		AppID:            wh.App.ID.PgxUUID(),
+		EndpointUrl:      wh.URL,
+		SecretCiphertext: hex.EncodeToString(ciphertext),
+		EventTypes:       eventTypes,
+		Active:           wh.Active,
+		CreateAppID:      adt.App.ID.PgxUUID(),
+		CreateUserID:     adt.User.ID.PgxUUID(),
+		CreateTimestamp:  diygoapi.NewPgxTimestampTZ(adt.Moment),
+		UpdateAppID:      adt.App.ID.PgxUUID(),
+		UpdateUserID:     adt.User.ID.PgxUUID(),
+		UpdateTimestamp:  diygoapi.NewPgxTimestampTZ(adt.Moment),
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).CreateWebhook(ctx, params)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return nil, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	response = newWebhookResponse(datastore.Webhook(params))
+	response.Secret = wh.Secret
+
+	return response, nil
+}
+
+// findWebhookTx finds a webhook of the App in the Audit given its
+// external ID. The webhooks of other apps do not exist to it.
+func findWebhookTx(ctx context.Context, tx pgx.Tx, extlID string, adt diygoapi.Audit) (datastore.Webhook, error) {
+	const op errs.Op = "service/findWebhookTx"
+
+	row, err := datastore.New(tx).FindWebhookByExternalID(ctx, datastore.FindWebhookByExternalIDParams{
+		ExtlID: extlID,
+		AppID:  adt.App.ID.PgxUUID(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return datastore.Webhook{}, errs.E(op, errs.NotExist, "no webhook exists for the given external ID")
+		}
+		return datastore.Webhook{}, errs.E(op, errs.Database, err)
+	}
+
+	return row, nil
+}
+
+// FindAll lists the webhooks of the App in the Audit
+func (s *WebhookService) FindAll(ctx context.Context, adt diygoapi.Audit) (responses []*diygoapi.WebhookResponse, err error) {
+	const op errs.Op = "service/WebhookService.FindAll"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var rows []datastore.Webhook
+	rows, err = datastore.New(tx).FindWebhooksByAppID(ctx, adt.App.ID.PgxUUID())
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	responses = make([]*diygoapi.WebhookResponse, 0, len(rows))
+	for _, row := range rows {
+		responses = append(responses, newWebhookResponse(row))
+	}
+
+	return responses, nil
+}
+
+// Delete deletes a webhook of the App in the Audit, along with its
+// delivery history. Deliveries still pending are not sent.
+func (s *WebhookService) Delete(ctx context.Context, extlID string, adt diygoapi.Audit) (dr diygoapi.DeleteResponse, err error) {
+	const op errs.Op = "service/WebhookService.Delete"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var row datastore.Webhook
+	row, err = findWebhookTx(ctx, tx, extlID, adt)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = datastore.New(tx).DeleteWebhook(ctx, row.WebhookID)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return diygoapi.DeleteResponse{}, errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	// commit db txn using pgxpool
+	err = s.Datastorer.CommitTx(ctx, tx)
+	if err != nil {
+		return diygoapi.DeleteResponse{}, errs.E(op, err)
+	}
+
+	return diygoapi.DeleteResponse{ExternalID: extlID, Deleted: true}, nil
+}
+
+// FindDeliveries lists the most recent deliveries to a webhook of the
+// App in the Audit, newest first, each with the history of its attempts
+func (s *WebhookService) FindDeliveries(ctx context.Context, extlID string, adt diygoapi.Audit) (responses []*diygoapi.WebhookDeliveryResponse, err error) {
+	const op errs.Op = "service/WebhookService.FindDeliveries"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer tracing.EndSpan(span, &err)
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = s.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = s.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	var wh datastore.Webhook
+	wh, err = findWebhookTx(ctx, tx, extlID, adt)
+	if err != nil {
+		return nil, errs.E(op, err)
+	}
+
+	q := datastore.New(tx)
+
+	var rows []datastore.FindWebhookDeliveriesRow
+	rows, err = q.FindWebhookDeliveries(ctx, datastore.FindWebhookDeliveriesParams{
+		WebhookID: wh.WebhookID,
+		RowLimit:  diygoapi.WebhookDeliveryListLimit,
+	})
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	ids := make([]pgtype.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.DeliveryID)
+	}
+
+	var attempts []datastore.WebhookDeliveryAttempt
+	attempts, err = q.FindWebhookDeliveryAttempts(ctx, ids)
+	if err != nil {
+		return nil, errs.E(op, errs.Database, err)
+	}
+
+	history := make(map[[16]byte][]*diygoapi.WebhookDeliveryAttemptResponse, len(rows))
+	for _, a := range attempts {
+		history[a.DeliveryID.Bytes] = append(history[a.DeliveryID.Bytes], &diygoapi.WebhookDeliveryAttemptResponse{
+			Attempt:         int(a.Attempt),
+			StatusCode:      int(a.StatusCode.Int32),
+			Error:           a.ErrorMessage.String,
+			DurationMillis:  int(a.DurationMs),
+			AttemptDateTime: a.AttemptTimestamp.Time.Format(time.RFC3339),
+		})
+	}
+
+	responses = make([]*diygoapi.WebhookDeliveryResponse, 0, len(rows))
+	for _, row := range rows {
+		attemptHistory := history[row.DeliveryID.Bytes]
+		if attemptHistory == nil {
+			attemptHistory = []*diygoapi.WebhookDeliveryAttemptResponse{}
+		}
+		responses = append(responses, &diygoapi.WebhookDeliveryResponse{
+			ExternalID:     row.ExtlID,
+			EventExtlID:    row.EventExtlID,
+			EventType:      diygoapi.EventType(row.EventType),
+			State:          diygoapi.WebhookDeliveryState(row.DeliveryState),
+			Attempts:       int(row.Attempts),
+			LastStatusCode: int(row.LastStatusCode.Int32),
+			LastError:      row.LastError.String,
+			CreateDateTime: row.CreateTimestamp.Time.Format(time.RFC3339),
+			UpdateDateTime: row.UpdateTimestamp.Time.Format(time.RFC3339),
+			AttemptHistory: attemptHistory,
+		})
+	}
+
+	return responses, nil
+}
+
+// WebhookDeliverer is the JobHandler which sends events to webhooks.
+// Each attempt is recorded in the delivery history. A failed attempt
+// fails the job, which retries it with backoff, and the delivery
+// fails once the job runs out of attempts.
+//
+// A delivery whose webhook has been deleted or deactivated since is
+// dropped without being sent.
+type WebhookDeliverer struct {
+	Datastorer    diygoapi.Datastorer
+	EncryptionKey *[32]byte
+
+	// Client sends the deliveries. If nil, a client which gives
+	// endpoints DefaultWebhookTimeout to respond, does not follow
+	// redirects and only dials public addresses, or those in
+	// AllowedNetworks, is used.
+	Client *http.Client
+	// AllowedNetworks are the otherwise private networks deliveries
+	// can be sent to, see diygoapi.CheckWebhookAddr
+	AllowedNetworks []netip.Prefix
+
+	clientOnce    sync.Once
+	defaultClient *http.Client
+}
+
+// HandleJob sends the delivery in the job payload
+func (d *WebhookDeliverer) HandleJob(ctx context.Context, j diygoapi.Job) error {
+	const op errs.Op = "service/WebhookDeliverer.HandleJob"
+
+	ctx, span := tracer.Start(ctx, string(op))
+	defer span.End()
+
+	var p webhookDeliveryJob
+	err := json.Unmarshal(j.Payload, &p)
+	if err != nil {
+		return errs.E(op, errs.Internal, err)
+	}
+
+	var row datastore.FindWebhookDeliveryForSendRow
+	row, err = d.findDelivery(ctx, p.DeliveryExtlID)
+	if err != nil {
+		if errs.KindIs(errs.NotExist, err) {
+			return nil
+		}
+		return errs.E(op, err)
+	}
+
+	if !row.Active || diygoapi.WebhookDeliveryState(row.DeliveryState) != diygoapi.WebhookDeliveryPending {
+		return nil
+	}
+
+	var body []byte
+	body, err = json.Marshal(diygoapi.EventResponse{
+		ExternalID:    row.EventExtlID,
+		Type:          diygoapi.EventType(row.EventType),
+		SubjectExtlID: row.SubjectExtlID,
+		CreateTime:    row.EventTimestamp.Time.Format(time.RFC3339),
+		Data:          row.Payload,
+	})
+	if err != nil {
+		return errs.E(op, errs.Internal, err)
+	}
+
+	var secret string
+	secret, err = d.decryptSecret(row.SecretCiphertext)
+	if err != nil {
+		return errs.E(op, err)
+	}
+
+	start := time.Now()
+	statusCode, sendErr := d.send(ctx, row, secret, body)
+	elapsed := time.Since(start)
+
+	state := diygoapi.WebhookDeliverySucceeded
+	if sendErr != nil {
+		state = diygoapi.WebhookDeliveryPending
+		if j.Attempts >= j.MaxAttempts {
+			state = diygoapi.WebhookDeliveryFailed
+		}
+	}
+
+	// the attempt is recorded even if the worker is being stopped,
+	// as it was made
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jobBookkeepingTimeout)
+	defer cancel()
+
+	err = d.recordAttempt(recordCtx, row.DeliveryID, state, statusCode, sendErr, start, elapsed)
+	if sendErr != nil {
+		return errs.E(op, sendErr)
+	}
+	if err != nil {
+		// the event will be sent again, which receivers are expected to
+		// cope with given the delivery header
+		return errs.E(op, err)
+	}
+
+	return nil
+}
+
+// findDelivery finds a delivery along with what is needed to send it
+func (d *WebhookDeliverer) findDelivery(ctx context.Context, extlID string) (row datastore.FindWebhookDeliveryForSendRow, err error) {
+	const op errs.Op = "service/WebhookDeliverer.findDelivery"
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = d.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return row, errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = d.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	row, err = datastore.New(tx).FindWebhookDeliveryForSend(ctx, extlID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return row, errs.E(op, errs.NotExist, "no webhook delivery exists for the given external ID")
+		}
+		return row, errs.E(op, errs.Database, err)
+	}
+
+	return row, nil
+}
+
+// decryptSecret decrypts the hex encoded webhook secret ciphertext
+func (d *WebhookDeliverer) decryptSecret(ciphertext string) (string, error) {
+	const op errs.Op = "service/WebhookDeliverer.decryptSecret"
+
+	b, err := hex.DecodeString(ciphertext)
+	if err != nil {
+		return "", errs.E(op, errs.Internal, err)
+	}
+
+	var secret []byte
+	secret, err = secure.Decrypt(b, d.EncryptionKey)
+	if err != nil {
+		return "", errs.E(op, err)
+	}
+
+	return string(secret), nil
+}
+
+// send posts the signed event to the webhook endpoint, returning the
+// status code of the response, or zero if there was none
+func (d *WebhookDeliverer) send(ctx context.Context, row datastore.FindWebhookDeliveryForSendRow, secret string, body []byte) (int, error) {
+	const op errs.Op = "service/WebhookDeliverer.send"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, row.EndpointUrl, bytes.NewReader(body))
+	if err != nil {
+		return 0, errs.E(op, errs.Internal, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(diygoapi.WebhookEventHeader, row.EventType)
+	req.Header.Set(diygoapi.WebhookDeliveryHeader, row.ExtlID)
+	req.Header.Set(diygoapi.WebhookSignatureHeader, diygoapi.SignWebhook(secret, time.Now(), body))
+
+	client := d.Client
+	if client == nil {
+		d.clientOnce.Do(func() { d.defaultClient = newWebhookClient(d.AllowedNetworks) })
+		client = d.defaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(err, errWebhookAddrNotAllowed) {
+			return 0, errs.E(op, errs.IO, errWebhookAddrNotAllowed)
+		}
+		return 0, errs.E(op, errs.IO, err)
+	}
+	defer resp.Body.Close()
+
+	// drain some of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseLimit))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, errs.E(op, errs.IO, fmt.Sprintf("webhook endpoint responded with %s", resp.Status))
+	}
+
+	return resp.StatusCode, nil
+}
+
+// recordAttempt counts an attempt against the delivery and adds it to
+// the delivery history
+func (d *WebhookDeliverer) recordAttempt(ctx context.Context, deliveryID pgtype.UUID, state diygoapi.WebhookDeliveryState, statusCode int, sendErr error, start time.Time, elapsed time.Duration) (err error) {
+	const op errs.Op = "service/WebhookDeliverer.recordAttempt"
+
+	var (
+		status  pgtype.Int4
+		message pgtype.Text
+	)
+	if statusCode != 0 {
+		status = pgtype.Int4{Int32: int32(statusCode), Valid: true}
+	}
+	if sendErr != nil {
+		message = diygoapi.NewPgxText(sendErr.Error())
+	}
+
+	// start db txn using pgxpool
+	var tx pgx.Tx
+	tx, err = d.Datastorer.BeginTx(ctx)
+	if err != nil {
+		return errs.E(op, err)
+	}
+	// defer transaction rollback and handle error, if any
+	defer func() {
+		err = d.Datastorer.RollbackTx(ctx, tx, err)
+	}()
+
+	q := datastore.New(tx)
+
+	var attempt int32
+	attempt, err = q.RecordWebhookDeliveryAttempt(ctx, datastore.RecordWebhookDeliveryAttemptParams{
+		DeliveryState:   string(state),
+		LastStatusCode:  status,
+		LastError:       message,
+		UpdateTimestamp: diygoapi.NewPgxTimestampTZ(time.Now()),
+		DeliveryID:      deliveryID,
+	})
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	var rowsAffected int64
+	rowsAffected, err = q.CreateWebhookDeliveryAttempt(ctx, datastore.CreateWebhookDeliveryAttemptParams{
+		DeliveryID:       deliveryID,
+		Attempt:          attempt,
+		StatusCode:       status,
+		ErrorMessage:     message,
+		DurationMs:       int32(elapsed.Milliseconds()),
+		AttemptTimestamp: diygoapi.NewPgxTimestampTZ(start),
+	})
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	if rowsAffected != 1 {
+		return errs.E(op, errs.Database, fmt.Sprintf("rows affected should be 1, actual: %d", rowsAffected))
+	}
+
+	return d.Datastorer.CommitTx(ctx, tx)
+}
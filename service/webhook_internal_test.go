@@ -0,0 +1,31 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func Test_newWebhookClient(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(receiver.Close)
+
+	t.Run("private address", func(t *testing.T) {
+		c := qt.New(t)
+
+		_, err := newWebhookClient(nil).Post(receiver.URL, "application/json", nil)
+		c.Assert(errors.Is(err, errWebhookAddrNotAllowed), qt.IsTrue, qt.Commentf("%v", err))
+	})
+	t.Run("allowed network", func(t *testing.T) {
+		c := qt.New(t)
+
+		resp, err := newWebhookClient([]netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}).Post(receiver.URL, "application/json", nil)
+		c.Assert(err, qt.IsNil)
+		c.Cleanup(func() { _ = resp.Body.Close() })
+		c.Assert(resp.StatusCode, qt.Equals, http.StatusOK)
+	})
+}
@@ -0,0 +1,194 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/service"
+	"github.com/gilcrest/diygoapi/sqldb/sqldbtest"
+)
+
+func TestWebhookService(t *testing.T) {
+	t.Run("deliver signed events with attempt history", func(t *testing.T) {
+		c := qt.New(t)
+
+		eks := os.Getenv("ENCRYPT_KEY")
+		if eks == "" {
+			t.Fatal("no encryption key found")
+		}
+		ek, err := secure.ParseEncryptionKey(eks)
+		if err != nil {
+			t.Fatal("secure.ParseEncryptionKey() error")
+		}
+
+		db, cleanup := sqldbtest.NewDB(t)
+		c.Cleanup(cleanup)
+
+		ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+
+		var tx pgx.Tx
+		tx, err = db.BeginTx(ctx)
+		c.Assert(err, qt.IsNil)
+		adt := findPrincipalTestAudit(ctx, c, tx)
+		c.Assert(db.RollbackTx(ctx, tx, nil), qt.IsNil)
+
+		// the receiver verifies each delivery against the secret of the
+		// webhook, which is only known once the webhook is created
+		var (
+			mu       sync.Mutex
+			secret   string
+			received = make(chan diygoapi.EventResponse, 10)
+		)
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+
+			mu.Lock()
+			s := secret
+			mu.Unlock()
+
+			if diygoapi.VerifyWebhookSignature(s, r.Header.Get(diygoapi.WebhookSignatureHeader), body, time.Minute, time.Now()) != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var e diygoapi.EventResponse
+			if json.Unmarshal(body, &e) != nil || r.Header.Get(diygoapi.WebhookEventHeader) != string(e.Type) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			received <- e
+		}))
+		c.Cleanup(receiver.Close)
+
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		c.Cleanup(failing.Close)
+
+		// the receivers are local, which webhooks are only allowed to
+		// be sent to if configured
+		localhost := []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}
+
+		s := service.WebhookService{Datastorer: db, APIKeyGenerator: secure.RandomGenerator{}, EncryptionKey: ek, AllowedNetworks: localhost}
+
+		var wh *diygoapi.WebhookResponse
+		wh, err = s.Create(ctx, &diygoapi.CreateWebhookRequest{
+			URL:        receiver.URL,
+			EventTypes: []string{string(diygoapi.MovieCreatedEvent)},
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Assert(wh.Secret, qt.Not(qt.Equals), "")
+		c.Cleanup(func() { _, _ = s.Delete(ctx, wh.ExternalID, adt) })
+
+		mu.Lock()
+		secret = wh.Secret
+		mu.Unlock()
+
+		var failingWh *diygoapi.WebhookResponse
+		failingWh, err = s.Create(ctx, &diygoapi.CreateWebhookRequest{
+			URL:        failing.URL,
+			EventTypes: []string{string(diygoapi.MovieCreatedEvent)},
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Cleanup(func() { _, _ = s.Delete(ctx, failingWh.ExternalID, adt) })
+
+		// the secret is not returned once the webhook is created
+		var webhooks []*diygoapi.WebhookResponse
+		webhooks, err = s.FindAll(ctx, adt)
+		c.Assert(err, qt.IsNil)
+		for _, w := range webhooks {
+			c.Assert(w.Secret, qt.Equals, "")
+		}
+
+		w := &service.JobWorkers{
+			Datastorer: db,
+			Logger:     zerolog.Nop(),
+			Handlers: map[string]diygoapi.JobHandler{
+				diygoapi.WebhookDeliveryJobKind: &service.WebhookDeliverer{Datastorer: db, EncryptionKey: ek, AllowedNetworks: localhost},
+			},
+			Concurrency:  2,
+			PollInterval: 50 * time.Millisecond,
+		}
+		w.Start(ctx)
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			c.Assert(w.Shutdown(shutdownCtx), qt.IsNil)
+		}()
+
+		ms := service.MovieService{Datastorer: db}
+		var m *diygoapi.MovieResponse
+		m, err = ms.Create(ctx, &diygoapi.CreateMovieRequest{
+			Title:    "Repo Man",
+			Rated:    "R",
+			Released: "1984-03-02T00:00:00Z",
+			RunTime:  92,
+			Director: "Alex Cox",
+			Writer:   "Alex Cox",
+		}, adt)
+		c.Assert(err, qt.IsNil)
+		c.Cleanup(func() { _, _ = ms.Delete(ctx, m.ExternalID, adt) })
+
+		select {
+		case e := <-received:
+			c.Assert(e.Type, qt.Equals, diygoapi.MovieCreatedEvent)
+			c.Assert(e.SubjectExtlID, qt.Equals, m.ExternalID)
+		case <-time.After(10 * time.Second):
+			c.Fatal("no delivery received")
+		}
+
+		delivered := waitForDelivery(c, s, wh.ExternalID, adt, func(d *diygoapi.WebhookDeliveryResponse) bool {
+			return d.State == diygoapi.WebhookDeliverySucceeded
+		})
+		c.Assert(delivered.Attempts, qt.Equals, 1)
+		c.Assert(delivered.AttemptHistory, qt.HasLen, 1)
+		c.Assert(delivered.AttemptHistory[0].StatusCode, qt.Equals, http.StatusOK)
+
+		// a failed attempt is recorded and the delivery is left pending
+		// to be retried
+		undelivered := waitForDelivery(c, s, failingWh.ExternalID, adt, func(d *diygoapi.WebhookDeliveryResponse) bool {
+			return d.Attempts > 0
+		})
+		c.Assert(undelivered.State, qt.Equals, diygoapi.WebhookDeliveryPending)
+		c.Assert(undelivered.LastStatusCode, qt.Equals, http.StatusInternalServerError)
+		c.Assert(undelivered.AttemptHistory[0].StatusCode, qt.Equals, http.StatusInternalServerError)
+		c.Assert(undelivered.AttemptHistory[0].Error, qt.Not(qt.Equals), "")
+
+		_, err = s.FindDeliveries(ctx, secure.NewID().String(), adt)
+		c.Assert(errs.KindIs(errs.NotExist, err), qt.IsTrue)
+	})
+}
+
+// waitForDelivery polls until the latest delivery to the webhook
+// satisfies done
+func waitForDelivery(c *qt.C, s service.WebhookService, extlID string, adt diygoapi.Audit, done func(*diygoapi.WebhookDeliveryResponse) bool) *diygoapi.WebhookDeliveryResponse {
+	c.Helper()
+
+	ctx := diygoapi.NewContextWithRowSecurityBypass(context.Background())
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		deliveries, err := s.FindDeliveries(ctx, extlID, adt)
+		c.Assert(err, qt.IsNil)
+		if len(deliveries) > 0 && done(deliveries[0]) {
+			return deliveries[0]
+		}
+		if time.Now().After(deadline) {
+			c.Fatalf("delivery to webhook %s is not done", extlID)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
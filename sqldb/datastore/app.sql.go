@@ -95,20 +95,6 @@ func (q *Queries) CreateAppAPIKey(ctx context.Context, arg CreateAppAPIKeyParams
 	return result.RowsAffected(), nil
 }
 
-const deleteApp = `-- name: DeleteApp :execrows
-DELETE FROM app
-WHERE app_id = $1
-`
-
-// DeleteApp deletes an app given its app_id.
-func (q *Queries) DeleteApp(ctx context.Context, appID pgtype.UUID) (int64, error) {
-	result, err := q.db.Exec(ctx, deleteApp, appID)
-	if err != nil {
-		return 0, err
-	}
-	return result.RowsAffected(), nil
-}
-
 const deleteAppAPIKey = `-- name: DeleteAppAPIKey :execrows
 DELETE FROM app_api_key
 WHERE api_key = $1
@@ -188,6 +174,7 @@ from app a
          inner join org o on o.org_id = a.org_id
          inner join app_api_key aak on a.app_id = aak.app_id
 where a.app_extl_id = $1
+  and a.delete_timestamp is null
 `
 
 type FindAppAPIKeysByAppExtlIDRow struct {
@@ -251,6 +238,7 @@ FROM app a
          INNER JOIN org o on o.org_id = a.org_id
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE a.app_extl_id = $1
+  AND a.delete_timestamp IS NULL
 `
 
 type FindAppByExternalIDRow struct {
@@ -305,8 +293,8 @@ SELECT a.org_id,
        ca.app_name        create_app_name,
        ca.app_description create_app_description,
        a.create_user_id,
-       cu.first_name     create_user_first_name,
-       cu.last_name      create_user_last_name,
+       cu.first_name      create_user_first_name,
+       cu.last_name       create_user_last_name,
        a.create_timestamp,
        a.update_app_id,
        ua.org_id          update_app_org_id,
@@ -314,8 +302,8 @@ SELECT a.org_id,
        ua.app_name        update_app_name,
        ua.app_description update_app_description,
        a.update_user_id,
-       uu.first_name     update_user_first_name,
-       uu.last_name      update_user_last_name,
+       uu.first_name      update_user_first_name,
+       uu.last_name       update_user_last_name,
        a.update_timestamp
 FROM app a
          INNER JOIN org o on o.org_id = a.org_id
@@ -325,6 +313,7 @@ FROM app a
          LEFT JOIN users cu on cu.user_id = a.create_user_id
          LEFT JOIN users uu on uu.user_id = a.update_user_id
 WHERE a.app_extl_id = $1
+  AND a.delete_timestamp IS NULL
 `
 
 type FindAppByExternalIDWithAuditRow struct {
@@ -414,6 +403,7 @@ FROM app a
          INNER JOIN org o on o.org_id = a.org_id
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE a.app_id = $1
+  AND a.delete_timestamp IS NULL
 `
 
 type FindAppByIDRow struct {
@@ -468,8 +458,8 @@ SELECT a.org_id,
        ca.app_name        create_app_name,
        ca.app_description create_app_description,
        a.create_user_id,
-       cu.first_name     create_user_first_name,
-       cu.last_name      create_user_last_name,
+       cu.first_name      create_user_first_name,
+       cu.last_name       create_user_last_name,
        a.create_timestamp,
        a.update_app_id,
        ua.org_id          update_app_org_id,
@@ -477,8 +467,8 @@ SELECT a.org_id,
        ua.app_name        update_app_name,
        ua.app_description update_app_description,
        a.update_user_id,
-       uu.first_name     update_user_first_name,
-       uu.last_name      update_user_last_name,
+       uu.first_name      update_user_first_name,
+       uu.last_name       update_user_last_name,
        a.update_timestamp
 FROM app a
          INNER JOIN org o on o.org_id = a.org_id
@@ -488,6 +478,7 @@ FROM app a
          LEFT JOIN users cu on cu.user_id = a.create_user_id
          LEFT JOIN users uu on uu.user_id = a.update_user_id
 WHERE a.app_id = $1
+  AND a.delete_timestamp IS NULL
 `
 
 type FindAppByIDWithAuditRow struct {
@@ -578,6 +569,7 @@ FROM app a
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE o.org_id = $1
   AND a.app_name = $2
+  AND a.delete_timestamp IS NULL
 `
 
 type FindAppByNameParams struct {
@@ -635,6 +627,7 @@ FROM app a
          INNER JOIN org o on o.org_id = a.org_id
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE a.auth_provider_client_id = $1
+  AND a.delete_timestamp IS NULL
 `
 
 type FindAppByProviderClientIDRow struct {
@@ -672,7 +665,8 @@ func (q *Queries) FindAppByProviderClientID(ctx context.Context, authProviderCli
 }
 
 const findApps = `-- name: FindApps :many
-SELECT app_id, app_extl_id, org_id, app_name, app_description, auth_provider_id, auth_provider_client_id, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp FROM app
+SELECT app_id, app_extl_id, org_id, app_name, app_description, auth_provider_id, auth_provider_client_id, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp, delete_app_id, delete_user_id, delete_timestamp, purge_timestamp FROM app
+WHERE delete_timestamp IS NULL
 ORDER BY app_name
 `
 
@@ -700,6 +694,10 @@ func (q *Queries) FindApps(ctx context.Context) ([]App, error) {
 			&i.UpdateAppID,
 			&i.UpdateUserID,
 			&i.UpdateTimestamp,
+			&i.DeleteAppID,
+			&i.DeleteUserID,
+			&i.DeleteTimestamp,
+			&i.PurgeTimestamp,
 		); err != nil {
 			return nil, err
 		}
@@ -712,8 +710,9 @@ func (q *Queries) FindApps(ctx context.Context) ([]App, error) {
 }
 
 const findAppsByOrg = `-- name: FindAppsByOrg :many
-SELECT app_id, app_extl_id, org_id, app_name, app_description, auth_provider_id, auth_provider_client_id, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp FROM app
+SELECT app_id, app_extl_id, org_id, app_name, app_description, auth_provider_id, auth_provider_client_id, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp, delete_app_id, delete_user_id, delete_timestamp, purge_timestamp FROM app
 WHERE org_id = $1
+  AND delete_timestamp IS NULL
 `
 
 // FindAppsByOrg returns all apps for a given Organization.
@@ -740,6 +739,10 @@ func (q *Queries) FindAppsByOrg(ctx context.Context, orgID pgtype.UUID) ([]App,
 			&i.UpdateAppID,
 			&i.UpdateUserID,
 			&i.UpdateTimestamp,
+			&i.DeleteAppID,
+			&i.DeleteUserID,
+			&i.DeleteTimestamp,
+			&i.PurgeTimestamp,
 		); err != nil {
 			return nil, err
 		}
@@ -769,8 +772,8 @@ SELECT a.org_id,
        ca.app_name        create_app_name,
        ca.app_description create_app_description,
        a.create_user_id,
-       cu.first_name     create_user_first_name,
-       cu.last_name      create_user_last_name,
+       cu.first_name      create_user_first_name,
+       cu.last_name       create_user_last_name,
        a.create_timestamp,
        a.update_app_id,
        ua.org_id          update_app_org_id,
@@ -778,8 +781,8 @@ SELECT a.org_id,
        ua.app_name        update_app_name,
        ua.app_description update_app_description,
        a.update_user_id,
-       uu.first_name     update_user_first_name,
-       uu.last_name      update_user_last_name,
+       uu.first_name      update_user_first_name,
+       uu.last_name       update_user_last_name,
        a.update_timestamp
 FROM app a
          INNER JOIN org o on o.org_id = a.org_id
@@ -788,6 +791,7 @@ FROM app a
          INNER JOIN app ua on ua.app_id = a.update_app_id
          LEFT JOIN users cu on cu.user_id = a.create_user_id
          LEFT JOIN users uu on uu.user_id = a.update_user_id
+WHERE a.delete_timestamp IS NULL
 `
 
 type FindAppsWithAuditRow struct {
@@ -874,6 +878,223 @@ func (q *Queries) FindAppsWithAudit(ctx context.Context) ([]FindAppsWithAuditRow
 	return items, nil
 }
 
+const findDeletedAppByExternalID = `-- name: FindDeletedAppByExternalID :one
+SELECT a.app_id,
+       a.org_id,
+       o.org_extl_id,
+       o.org_name,
+       o.org_description,
+       ok.org_kind_id,
+       ok.org_kind_extl_id,
+       ok.org_kind_desc,
+       a.app_extl_id,
+       a.app_name,
+       a.app_description,
+       a.delete_timestamp,
+       o.delete_timestamp org_delete_timestamp
+FROM app a
+         INNER JOIN org o on o.org_id = a.org_id
+         INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+WHERE a.app_extl_id = $1
+  AND a.delete_timestamp IS NOT NULL
+  AND a.purge_timestamp IS NULL
+`
+
+type FindDeletedAppByExternalIDRow struct {
+	AppID              pgtype.UUID
+	OrgID              pgtype.UUID
+	OrgExtlID          string
+	OrgName            string
+	OrgDescription     string
+	OrgKindID          pgtype.UUID
+	OrgKindExtlID      string
+	OrgKindDesc        string
+	AppExtlID          string
+	AppName            string
+	AppDescription     string
+	DeleteTimestamp    pgtype.Timestamptz
+	OrgDeleteTimestamp pgtype.Timestamptz
+}
+
+// FindDeletedAppByExternalID selects a deleted app given its app_extl_id.
+func (q *Queries) FindDeletedAppByExternalID(ctx context.Context, appExtlID string) (FindDeletedAppByExternalIDRow, error) {
+	row := q.db.QueryRow(ctx, findDeletedAppByExternalID, appExtlID)
+	var i FindDeletedAppByExternalIDRow
+	err := row.Scan(
+		&i.AppID,
+		&i.OrgID,
+		&i.OrgExtlID,
+		&i.OrgName,
+		&i.OrgDescription,
+		&i.OrgKindID,
+		&i.OrgKindExtlID,
+		&i.OrgKindDesc,
+		&i.AppExtlID,
+		&i.AppName,
+		&i.AppDescription,
+		&i.DeleteTimestamp,
+		&i.OrgDeleteTimestamp,
+	)
+	return i, err
+}
+
+const findDeletedAppsByOrg = `-- name: FindDeletedAppsByOrg :many
+SELECT app_id, app_extl_id, org_id, app_name, app_description, auth_provider_id, auth_provider_client_id, create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp, delete_app_id, delete_user_id, delete_timestamp, purge_timestamp FROM app
+WHERE org_id = $1
+  AND delete_timestamp = $2
+`
+
+type FindDeletedAppsByOrgParams struct {
+	OrgID           pgtype.UUID
+	DeleteTimestamp pgtype.Timestamptz
+}
+
+// FindDeletedAppsByOrg returns the apps for a given Organization
+// which were deleted at the given timestamp.
+func (q *Queries) FindDeletedAppsByOrg(ctx context.Context, arg FindDeletedAppsByOrgParams) ([]App, error) {
+	rows, err := q.db.Query(ctx, findDeletedAppsByOrg, arg.OrgID, arg.DeleteTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []App
+	for rows.Next() {
+		var i App
+		if err := rows.Scan(
+			&i.AppID,
+			&i.AppExtlID,
+			&i.OrgID,
+			&i.AppName,
+			&i.AppDescription,
+			&i.AuthProviderID,
+			&i.AuthProviderClientID,
+			&i.CreateAppID,
+			&i.CreateUserID,
+			&i.CreateTimestamp,
+			&i.UpdateAppID,
+			&i.UpdateUserID,
+			&i.UpdateTimestamp,
+			&i.DeleteAppID,
+			&i.DeleteUserID,
+			&i.DeleteTimestamp,
+			&i.PurgeTimestamp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeAppAPIKeys = `-- name: PurgeAppAPIKeys :execrows
+DELETE
+FROM app_api_key
+WHERE app_id IN (SELECT app_id
+                 FROM app
+                 WHERE delete_timestamp < $1)
+`
+
+// PurgeAppAPIKeys deletes all API keys for apps which were deleted before the given timestamp.
+func (q *Queries) PurgeAppAPIKeys(ctx context.Context, deleteTimestamp pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeAppAPIKeys, deleteTimestamp)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeApps = `-- name: PurgeApps :execrows
+UPDATE app
+SET app_name                = 'purged-' || app_extl_id,
+    app_description         = 'purged',
+    auth_provider_id        = NULL,
+    auth_provider_client_id = NULL,
+    purge_timestamp         = $2
+WHERE delete_timestamp < $1
+  AND purge_timestamp IS NULL
+`
+
+type PurgeAppsParams struct {
+	DeleteTimestamp pgtype.Timestamptz
+	PurgeTimestamp  pgtype.Timestamptz
+}
+
+// PurgeApps scrubs apps which were deleted before the given timestamp and marks them as purged.
+// Purged apps are not removed, as the audit columns of other records refer to them.
+func (q *Queries) PurgeApps(ctx context.Context, arg PurgeAppsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeApps, arg.DeleteTimestamp, arg.PurgeTimestamp)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreApp = `-- name: RestoreApp :execrows
+UPDATE app
+SET delete_app_id    = NULL,
+    delete_user_id   = NULL,
+    delete_timestamp = NULL,
+    update_app_id    = $1,
+    update_user_id   = $2,
+    update_timestamp = $3
+WHERE app_id = $4
+  AND delete_timestamp IS NOT NULL
+`
+
+type RestoreAppParams struct {
+	UpdateAppID     pgtype.UUID
+	UpdateUserID    pgtype.UUID
+	UpdateTimestamp pgtype.Timestamptz
+	AppID           pgtype.UUID
+}
+
+// RestoreApp clears the deleted mark of an app given its app_id.
+func (q *Queries) RestoreApp(ctx context.Context, arg RestoreAppParams) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreApp,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.AppID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteApp = `-- name: SoftDeleteApp :execrows
+UPDATE app
+SET delete_app_id    = $1,
+    delete_user_id   = $2,
+    delete_timestamp = $3
+WHERE app_id = $4
+  AND delete_timestamp IS NULL
+`
+
+type SoftDeleteAppParams struct {
+	DeleteAppID     pgtype.UUID
+	DeleteUserID    pgtype.UUID
+	DeleteTimestamp pgtype.Timestamptz
+	AppID           pgtype.UUID
+}
+
+// SoftDeleteApp marks an app as deleted given its app_id.
+func (q *Queries) SoftDeleteApp(ctx context.Context, arg SoftDeleteAppParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteApp,
+		arg.DeleteAppID,
+		arg.DeleteUserID,
+		arg.DeleteTimestamp,
+		arg.AppID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateApp = `-- name: UpdateApp :execrows
 UPDATE app
 SET app_name        = $1,
@@ -0,0 +1,127 @@
+package datastore
+
+// This file is not generated. sqlc cannot generate code for
+// server-side cursors, so the export cursors are written by hand
+// following the conventions of the generated code. A cursor only
+// lives as long as the transaction it was declared in.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const declareMovieExportCursor = `DECLARE movie_export_cursor NO SCROLL CURSOR FOR
+SELECT m.extl_id, m.title, m.rated, m.released, m.run_time, m.director, m.writer, m.create_timestamp, m.update_timestamp
+FROM movie m
+WHERE m.org_id = $1
+  AND m.delete_timestamp IS NULL
+ORDER BY m.create_timestamp, m.extl_id
+`
+
+// DeclareMovieExportCursor declares a cursor over all movies owned by
+// the org which have not been deleted, oldest first
+func (q *Queries) DeclareMovieExportCursor(ctx context.Context, orgID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, declareMovieExportCursor, orgID)
+	return err
+}
+
+type FetchMovieExportCursorRow struct {
+	ExtlID          string
+	Title           string
+	Rated           pgtype.Text
+	Released        pgtype.Date
+	RunTime         pgtype.Int8
+	Director        pgtype.Text
+	Writer          pgtype.Text
+	CreateTimestamp pgtype.Timestamptz
+	UpdateTimestamp pgtype.Timestamptz
+}
+
+// FetchMovieExportCursor fetches up to count rows from the movie
+// export cursor. An empty result means the cursor is exhausted.
+func (q *Queries) FetchMovieExportCursor(ctx context.Context, count int) ([]FetchMovieExportCursorRow, error) {
+	// FETCH does not accept a bind parameter for the row count
+	rows, err := q.db.Query(ctx, fmt.Sprintf("FETCH FORWARD %d FROM movie_export_cursor", count))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchMovieExportCursorRow
+	for rows.Next() {
+		var i FetchMovieExportCursorRow
+		if err := rows.Scan(
+			&i.ExtlID,
+			&i.Title,
+			&i.Rated,
+			&i.Released,
+			&i.RunTime,
+			&i.Director,
+			&i.Writer,
+			&i.CreateTimestamp,
+			&i.UpdateTimestamp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const declareOrgExportCursor = `DECLARE org_export_cursor NO SCROLL CURSOR FOR
+SELECT o.org_extl_id, o.org_name, o.org_description, ok.org_kind_extl_id, o.create_timestamp, o.update_timestamp
+FROM org o
+         INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+WHERE o.delete_timestamp IS NULL
+ORDER BY o.create_timestamp, o.org_extl_id
+`
+
+// DeclareOrgExportCursor declares a cursor over all orgs which
+// have not been deleted, oldest first
+func (q *Queries) DeclareOrgExportCursor(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, declareOrgExportCursor)
+	return err
+}
+
+type FetchOrgExportCursorRow struct {
+	OrgExtlID       string
+	OrgName         string
+	OrgDescription  string
+	OrgKindExtlID   string
+	CreateTimestamp pgtype.Timestamptz
+	UpdateTimestamp pgtype.Timestamptz
+}
+
+// FetchOrgExportCursor fetches up to count rows from the org
+// export cursor. An empty result means the cursor is exhausted.
+func (q *Queries) FetchOrgExportCursor(ctx context.Context, count int) ([]FetchOrgExportCursorRow, error) {
+	// FETCH does not accept a bind parameter for the row count
+	rows, err := q.db.Query(ctx, fmt.Sprintf("FETCH FORWARD %d FROM org_export_cursor", count))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchOrgExportCursorRow
+	for rows.Next() {
+		var i FetchOrgExportCursorRow
+		if err := rows.Scan(
+			&i.OrgExtlID,
+			&i.OrgName,
+			&i.OrgDescription,
+			&i.OrgKindExtlID,
+			&i.CreateTimestamp,
+			&i.UpdateTimestamp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -36,6 +36,22 @@ type App struct {
 	UpdateUserID pgtype.UUID
 	// The timestamp when the record was updated most recently.
 	UpdateTimestamp pgtype.Timestamptz
+	// The application which deleted this record. Null unless the record is deleted.
+	DeleteAppID pgtype.UUID
+	// The user which deleted this record. Null unless the record is deleted.
+	DeleteUserID pgtype.UUID
+	// The timestamp when this record was deleted. A record with a delete_timestamp is hidden from callers and is purged by the purge command once it is older than the retention period.
+	DeleteTimestamp pgtype.Timestamptz
+	// The timestamp when this deleted record was purged. A purged app is kept, as other records refer to it, but its name, description and API keys are removed and it can no longer be restored.
+	PurgeTimestamp pgtype.Timestamptz
+}
+
+// The app_allowed_origin table holds the origins browsers may call the API from for an app, in place of the configured CORS allowed origins. An app with no rows uses the configured origins.
+type AppAllowedOrigin struct {
+	// The app which allows the origin.
+	AppID pgtype.UUID
+	// The scheme and host of the origin, e.g. https://app.example.com, or * for any origin.
+	Origin string
 }
 
 type AppApiKey struct {
@@ -52,6 +68,20 @@ type AppApiKey struct {
 	UpdateTimestamp pgtype.Timestamptz
 }
 
+// The app_rate_limit table overrides the default rate limits for an app. A null column leaves the default in place.
+type AppRateLimit struct {
+	// The app the rate limits apply to.
+	AppID pgtype.UUID
+	// The number of requests a minute allowed for all of the app's requests together. Zero means the app's requests are not limited.
+	RequestsPerMinute pgtype.Int4
+	// The number of the app's requests allowed at once.
+	Burst pgtype.Int4
+	// The number of requests a minute allowed for each user of the app. Zero means users' requests are not limited.
+	UserRequestsPerMinute pgtype.Int4
+	// The number of requests allowed at once for each user of the app.
+	UserBurst pgtype.Int4
+}
+
 // The auth table stores which user has authenticated through an Oauth2 provider.
 type Auth struct {
 	// The unique id given to the authorization.
@@ -107,6 +137,92 @@ type AuthProvider struct {
 	UpdateTimestamp pgtype.Timestamptz
 }
 
+// The change_history table is an append-only log of every change made to a domain entity (movie, org, app, role, permission). There are intentionally no foreign keys so that history outlives the entities and apps/users it references.
+type ChangeHistory struct {
+	// The unique ID given to the change history entry.
+	ChangeHistoryID pgtype.UUID
+	// A monotonically increasing sequence used to order changes written within the same moment.
+	ChangeSeq int64
+	// The type of domain entity which was changed (movie, org, app, role, permission).
+	EntityType string
+	// The unique ID of the entity which was changed.
+	EntityID pgtype.UUID
+	// The external ID of the entity which was changed.
+	EntityExtlID string
+	// The action taken against the entity (create, update, delete).
+	ChangeAction string
+	// The JSON representation of the entity before the change. Null for create.
+	BeforeImage []byte
+	// The JSON representation of the entity after the change. Null for delete.
+	AfterImage []byte
+	// The application which performed the change.
+	ChangeAppID pgtype.UUID
+	// The user which performed the change.
+	ChangeUserID pgtype.UUID
+	// The timestamp when the change was made.
+	ChangeTimestamp pgtype.Timestamptz
+}
+
+// The event_outbox table stores domain events, e.g. movie.created, written in the same transaction as the change they describe. Webhook deliveries are created from it in that transaction too, so an event is only published if the change is committed.
+type EventOutbox struct {
+	// The unique ID given to the event.
+	EventID pgtype.UUID
+	// The order in which events were written.
+	EventSeq int64
+	// A unique ID given to the event which can be used externally.
+	ExtlID string
+	// The type of event, e.g. movie.created or org.deleted.
+	EventType string
+	// The organization the event belongs to. Only webhooks of this organization receive it.
+	OrgID pgtype.UUID
+	// The external ID of the entity the event is about.
+	SubjectExtlID string
+	// The entity the event is about as JSON, as it was after the change, or before it for a delete.
+	Payload []byte
+	// The timestamp when the change the event describes was made.
+	CreateTimestamp pgtype.Timestamptz
+	// The ID of the transaction which wrote the event. Event streams only read events of transactions older than any still running, so events committed out of sequence order are not skipped.
+	TransactionID interface{}
+}
+
+// The genre table is the taxonomy of genres a movie can be categorized in.
+type Genre struct {
+	// The unique ID given to the genre.
+	GenreID pgtype.UUID
+	// The unique code of the genre, e.g. horror. Callers refer to a genre by its code.
+	GenreCd string
+	// The display name of the genre.
+	GenreName string
+}
+
+// The job table is the queue of background jobs. Workers claim queued jobs with select ... for update skip locked, so each job is run by one worker at a time.
+type Job struct {
+	// The unique ID given to the job.
+	JobID pgtype.UUID
+	// A unique ID given to the job which can be used externally.
+	ExtlID string
+	// The kind of job, which determines the handler that runs it.
+	JobKind string
+	// The input of the job, as JSON.
+	Payload []byte
+	// The state of the job: queued, running, succeeded or dead. A dead job failed on every attempt and is only run again if retried.
+	JobState string
+	// The number of times the job has been claimed by a worker.
+	Attempts int32
+	// The number of attempts after which a failing job is dead-lettered.
+	MaxAttempts int32
+	// The earliest time the job may next be run. Pushed back after each failed attempt.
+	RunAt pgtype.Timestamptz
+	// When the job was claimed by a worker. A running job whose lease has expired is claimed again.
+	LockedAt pgtype.Timestamptz
+	// The error from the most recent failed attempt.
+	LastError pgtype.Text
+	// The timestamp when this record was created.
+	CreateTimestamp pgtype.Timestamptz
+	// The timestamp when the record was updated most recently.
+	UpdateTimestamp pgtype.Timestamptz
+}
+
 // The movie table stores details about a movie.
 type Movie struct {
 	// The unique ID given to the movie.
@@ -137,6 +253,97 @@ type Movie struct {
 	UpdateUserID pgtype.UUID
 	// The timestamp when the record was updated most recently.
 	UpdateTimestamp pgtype.Timestamptz
+	// The application which deleted this record. Null unless the record is deleted.
+	DeleteAppID pgtype.UUID
+	// The user which deleted this record. Null unless the record is deleted.
+	DeleteUserID pgtype.UUID
+	// The timestamp when this record was deleted. A record with a delete_timestamp is hidden from callers and is physically removed by the purge command once it is older than the retention period.
+	DeleteTimestamp pgtype.Timestamptz
+	// The organization which owns the movie. Movies are only visible to apps of the owning organization.
+	OrgID pgtype.UUID
+	// The country of the rating system the movie is rated in. The rated column must be a rating of this country.
+	RatingCountry string
+	// The number of reviews of the movie. Maintained in the same transaction as the reviews.
+	ReviewCount int32
+	// The sum of the scores of the reviews of the movie. The average score is review_score_total / review_count.
+	ReviewScoreTotal int64
+}
+
+// The movie_credit table stores the cast and crew credited on a movie.
+type MovieCredit struct {
+	// The unique ID given to the credit.
+	MovieCreditID pgtype.UUID
+	// The movie the person is credited on.
+	MovieID pgtype.UUID
+	// The part the person had in making the movie: director, writer, actor or producer.
+	CreditRole string
+	// The name of the credited person.
+	PersonName string
+	// The character played, for actors.
+	CharacterName pgtype.Text
+	// The billing order of the credit among the credits of the movie with the same role, starting at 1.
+	BillingOrder int32
+	// The application which created this record.
+	CreateAppID pgtype.UUID
+	// The user which created this record.
+	CreateUserID pgtype.UUID
+	// The timestamp when this record was created.
+	CreateTimestamp pgtype.Timestamptz
+	// The application which performed the most recent update to this record.
+	UpdateAppID pgtype.UUID
+	// The user which performed the most recent update to this record.
+	UpdateUserID pgtype.UUID
+	// The timestamp when the record was updated most recently.
+	UpdateTimestamp pgtype.Timestamptz
+}
+
+// The movie_genre table stores which genres a movie is categorized in.
+type MovieGenre struct {
+	// The movie which is categorized in the genre.
+	MovieID pgtype.UUID
+	// The genre the movie is categorized in.
+	GenreID pgtype.UUID
+	// The application which created this record.
+	CreateAppID pgtype.UUID
+	// The user which created this record.
+	CreateUserID pgtype.UUID
+	// The timestamp when this record was created.
+	CreateTimestamp pgtype.Timestamptz
+	// The application which performed the most recent update to this record.
+	UpdateAppID pgtype.UUID
+	// The user which performed the most recent update to this record.
+	UpdateUserID pgtype.UUID
+	// The timestamp when the record was updated most recently.
+	UpdateTimestamp pgtype.Timestamptz
+}
+
+type MoviePoster struct {
+	// The movie the poster is for. A movie has at most one poster.
+	MovieID pgtype.UUID
+	// The key of the image in the blob store.
+	BlobKey string
+	// The media type of the image, e.g. image/png.
+	ContentType string
+	// The size of the image in bytes.
+	ByteSize int64
+	// The hex encoded SHA-256 hash of the image. Used as the ETag when serving the image.
+	Sha256 string
+	// The width of the image in pixels.
+	Width int32
+	// The height of the image in pixels.
+	Height int32
+	// The application which created this record.
+	CreateAppID pgtype.UUID
+	// The user which created this record.
+	CreateUserID pgtype.UUID
+	// The timestamp when this record was created.
+	CreateTimestamp pgtype.Timestamptz
+	// The application which performed the most recent update to this record.
+	UpdateAppID pgtype.UUID
+	// The user which performed the most recent update to this record.
+	UpdateUserID pgtype.UUID
+	// The timestamp when the record was updated most recently.
+	UpdateTimestamp pgtype.Timestamptz
 }
 
 type Org struct {
@@ -162,6 +369,14 @@ type Org struct {
 	UpdateUserID pgtype.UUID
 	// The timestamp when the record was updated most recently.
 	UpdateTimestamp pgtype.Timestamptz
+	// The application which deleted this record. Null unless the record is deleted.
+	DeleteAppID pgtype.UUID
+	// The user which deleted this record. Null unless the record is deleted.
+	DeleteUserID pgtype.UUID
+	// The timestamp when this record was deleted. A record with a delete_timestamp is hidden from callers and is purged by the purge command once it is older than the retention period.
+	DeleteTimestamp pgtype.Timestamptz
+	// The timestamp when this deleted record was purged. A purged org is kept, as other records refer to it, but its name and description are scrubbed and it can no longer be restored.
+	PurgeTimestamp pgtype.Timestamptz
 }
 
 // Organization Kind is a reference table denoting an organization's (org) classification. Examples are Genesis, Test, Standard
@@ -233,6 +448,50 @@ type Person struct {
 	UpdateTimestamp pgtype.Timestamptz
 }
 
+// The rating table is the controlled vocabulary of ratings a movie can be given, for each rating system (MPAA, BBFC, etc.).
+type Rating struct {
+	// The unique ID given to the rating.
+	RatingID pgtype.UUID
+	// The rating system the rating belongs to, e.g. MPAA.
+	RatingSystem string
+	// The ISO 3166-1 alpha-2 code of the country the rating system is used in. Each country has a single rating system.
+	CountryCd string
+	// The rating as it is written by the rating system, e.g. PG-13.
+	RatingCd string
+	// A description of the rating.
+	RatingDesc string
+	// The order of the rating within its rating system, from least to most restrictive.
+	DisplayOrder int32
+}
+
+// The review table stores the score and review a user has given a movie. A user can review a movie once.
+type Review struct {
+	// The unique ID given to the review.
+	ReviewID pgtype.UUID
+	// A unique ID given to the review which can be used externally.
+	ExtlID string
+	// The movie being reviewed.
+	MovieID pgtype.UUID
+	// The user who wrote the review. Only the author can edit a review.
+	UserID pgtype.UUID
+	// The score given to the movie, from 1 to 5.
+	Score int16
+	// The text of the review.
+	Body pgtype.Text
+	// The application which created this record.
+	CreateAppID pgtype.UUID
+	// The user which created this record.
+	CreateUserID pgtype.UUID
+	// The timestamp when this record was created.
+	CreateTimestamp pgtype.Timestamptz
+	// The application which performed the most recent update to this record.
+	UpdateAppID pgtype.UUID
+	// The user which performed the most recent update to this record.
+	UpdateUserID pgtype.UUID
+	// The timestamp when the record was updated most recently.
+	UpdateTimestamp pgtype.Timestamptz
+}
+
 // The role table stores a job function or title which defines an authority level.
 type Role struct {
 	// The unique ID for the table.
@@ -279,6 +538,44 @@ type RolePermission struct {
 	UpdateTimestamp pgtype.Timestamptz
 }
 
+// The schema_version table has a single row holding the number of the latest up migration file run against the database. It is stamped after all up migrations have run, and checked by the readiness endpoint.
+type SchemaVersion struct {
+	// Always true, to allow only a single row.
+	SchemaVersionID bool
+	// The number of the latest up migration file run, e.g. 28 for 028-schema_version.sql.
+	Version int32
+	// The timestamp when the migrations were last run.
+	UpdateTimestamp pgtype.Timestamptz
+}
+
+// The security_event table is an append-only audit log of security relevant events: authentication failures, authorization denials, genesis, API key creation, role creation and grants and logger changes. There are intentionally no foreign keys so that events outlive the apps, orgs and users they reference.
+type SecurityEvent struct {
+	// The unique ID given to the security event.
+	SecurityEventID pgtype.UUID
+	// A monotonically increasing sequence used to order events and page through them.
+	EventSeq int64
+	// The action taken (authenticate_app, authenticate_user, authorize, genesis, create_api_key, create_role, grant_role, update_logger).
+	EventAction string
+	// The outcome of the action (success, failure, denied).
+	EventOutcome string
+	// The external ID of the user who took the action. Null when the user is not known, e.g. for a failed authentication.
+	ActorExtlID pgtype.Text
+	// The external ID of the app the action was taken through. Null when the app is not known.
+	AppExtlID pgtype.Text
+	// The org the event belongs to, which row level security limits reads by. Null when the org is not known.
+	OrgID pgtype.UUID
+	// The external ID of the org the event belongs to.
+	OrgExtlID pgtype.Text
+	// What the action was taken on, e.g. the route of an authorization or the user granted a role.
+	Target pgtype.Text
+	// Further detail of the event, e.g. why an authentication failed.
+	Detail pgtype.Text
+	// The ID of the request the event happened in, as sent in the Request-Id response header.
+	RequestID pgtype.Text
+	// The timestamp when the event happened.
+	EventTimestamp pgtype.Timestamptz
+}
+
 // users stores data about users that interact with the system. A user is a person who utilizes a computer or network service." In the context of this project, given that we allow Persons to authenticate with multiple providers, a User is akin to a user (Wikipedia - "The word persona derives from Latin, where it originally referred to a theatrical mask. On the social web, users develop virtual personas as online identities.") and as such, a Person can have one to many Users (for instance, I can have a GitHub user and a Google user, but I am just one Person). As a general, practical matter, most operations are considered at the User level. For instance, roles are assigned at the user level instead of the Person level, which allows for more fine-grained access control. Architecture note: All tables are to be singular, however, because user is a reserved word, the rules are broken here. It is unfortunate, but the alternatives are no better.
 type User struct {
 	UserID     pgtype.UUID
@@ -369,3 +666,125 @@ type UsersRole struct {
 	// The timestamp when the record was updated most recently.
 	UpdateTimestamp pgtype.Timestamptz
 }
+
+// The watchlist table stores the personal lists of movies kept by a user. Only the owner can change a watchlist.
+type Watchlist struct {
+	// The unique ID given to the watchlist.
+	WatchlistID pgtype.UUID
+	// A unique ID given to the watchlist which can be used externally.
+	ExtlID string
+	// The organization the watchlist belongs to. A shared watchlist can be read by the users of this organization.
+	OrgID pgtype.UUID
+	// The user who owns the watchlist.
+	UserID pgtype.UUID
+	// The name of the watchlist, unique for the owner.
+	WatchlistName string
+	// Whether the watchlist can be read by the other users of the organization.
+	Shared bool
+	// The application which created this record.
+	CreateAppID pgtype.UUID
+	// The user which created this record.
+	CreateUserID pgtype.UUID
+	// The timestamp when this record was created.
+	CreateTimestamp pgtype.Timestamptz
+	// The application which performed the most recent update to this record.
+	UpdateAppID pgtype.UUID
+	// The user which performed the most recent update to this record.
+	UpdateUserID pgtype.UUID
+	// The timestamp when the record was updated most recently.
+	UpdateTimestamp pgtype.Timestamptz
+}
+
+// The watchlist_entry table stores the movies on a watchlist.
+type WatchlistEntry struct {
+	// The watchlist the movie is on.
+	WatchlistID pgtype.UUID
+	// The movie on the watchlist.
+	MovieID pgtype.UUID
+	// The position of the movie on the watchlist, starting at 1.
+	Position int32
+	// The application which created this record.
+	CreateAppID pgtype.UUID
+	// The user which created this record.
+	CreateUserID pgtype.UUID
+	// The timestamp when this record was created.
+	CreateTimestamp pgtype.Timestamptz
+	// The application which performed the most recent update to this record.
+	UpdateAppID pgtype.UUID
+	// The user which performed the most recent update to this record.
+	UpdateUserID pgtype.UUID
+	// The timestamp when the record was updated most recently.
+	UpdateTimestamp pgtype.Timestamptz
+}
+
+// The webhook table stores the endpoints an app has registered to be sent the events of its organization.
+type Webhook struct {
+	// The unique ID given to the webhook.
+	WebhookID pgtype.UUID
+	// A unique ID given to the webhook which can be used externally.
+	ExtlID string
+	// The organization whose events are sent to the webhook.
+	OrgID pgtype.UUID
+	// The app which registered, and alone can manage, the webhook.
+	AppID pgtype.UUID
+	// The URL events are posted to.
+	EndpointUrl string
+	// The secret deliveries are signed with, encrypted with the encryption key and hex encoded.
+	SecretCiphertext string
+	// The types of event sent to the webhook.
+	EventTypes []string
+	// Whether events are sent to the webhook.
+	Active bool
+	// The application which created this record.
+	CreateAppID pgtype.UUID
+	// The user which created this record.
+	CreateUserID pgtype.UUID
+	// The timestamp when this record was created.
+	CreateTimestamp pgtype.Timestamptz
+	// The application which performed the most recent update to this record.
+	UpdateAppID pgtype.UUID
+	// The user which performed the most recent update to this record.
+	UpdateUserID pgtype.UUID
+	// The timestamp when the record was updated most recently.
+	UpdateTimestamp pgtype.Timestamptz
+}
+
+// The webhook_delivery table stores the sending of an event to a webhook. Each delivery is run as a background job, which retries it with backoff.
+type WebhookDelivery struct {
+	// The unique ID given to the delivery.
+	DeliveryID pgtype.UUID
+	// A unique ID given to the delivery which can be used externally. Sent with each attempt, so a receiver can ignore a delivery it has already seen.
+	ExtlID string
+	// The webhook the event is sent to.
+	WebhookID pgtype.UUID
+	// The event being sent.
+	EventID pgtype.UUID
+	// The state of the delivery: pending, succeeded or failed. A failed delivery ran out of attempts.
+	DeliveryState string
+	// The number of times sending the event has been attempted.
+	Attempts int32
+	// The HTTP status code of the most recent attempt. Null if no response was received.
+	LastStatusCode pgtype.Int4
+	// The error from the most recent failed attempt.
+	LastError pgtype.Text
+	// The timestamp when this record was created.
+	CreateTimestamp pgtype.Timestamptz
+	// The timestamp when the record was updated most recently.
+	UpdateTimestamp pgtype.Timestamptz
+}
+
+// The webhook_delivery_attempt table stores the history of attempts to send an event to a webhook.
+type WebhookDeliveryAttempt struct {
+	// The delivery the attempt was made for.
+	DeliveryID pgtype.UUID
+	// The number of the attempt, starting at 1.
+	Attempt int32
+	// The HTTP status code of the response. Null if no response was received.
+	StatusCode pgtype.Int4
+	// Why the attempt failed. Null if it succeeded.
+	ErrorMessage pgtype.Text
+	// How long the attempt took, in milliseconds.
+	DurationMs int32
+	// The timestamp when the attempt was made.
+	AttemptTimestamp pgtype.Timestamptz
+}
@@ -13,20 +13,22 @@ import (
 )
 
 const createMovie = `-- name: CreateMovie :execresult
-INSERT INTO movie (movie_id, extl_id, title, rated, released, run_time, director, writer,
+INSERT INTO movie (movie_id, extl_id, org_id, title, rated, released, run_time, director, writer, rating_country,
                    create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 `
 
 type CreateMovieParams struct {
 	MovieID         pgtype.UUID
 	ExtlID          string
+	OrgID           pgtype.UUID
 	Title           string
 	Rated           pgtype.Text
 	Released        pgtype.Date
 	RunTime         pgtype.Int8
 	Director        pgtype.Text
 	Writer          pgtype.Text
+	RatingCountry   string
 	CreateAppID     pgtype.UUID
 	CreateUserID    pgtype.UUID
 	CreateTimestamp pgtype.Timestamptz
@@ -39,12 +41,14 @@ func (q *Queries) CreateMovie(ctx context.Context, arg CreateMovieParams) (pgcon
 	return q.db.Exec(ctx, createMovie,
 		arg.MovieID,
 		arg.ExtlID,
+		arg.OrgID,
 		arg.Title,
 		arg.Rated,
 		arg.Released,
 		arg.RunTime,
 		arg.Director,
 		arg.Writer,
+		arg.RatingCountry,
 		arg.CreateAppID,
 		arg.CreateUserID,
 		arg.CreateTimestamp,
@@ -54,27 +58,63 @@ func (q *Queries) CreateMovie(ctx context.Context, arg CreateMovieParams) (pgcon
 	)
 }
 
-const deleteMovie = `-- name: DeleteMovie :execrows
-DELETE FROM movie
-WHERE movie_id = $1
+const findDeletedMovieByExternalID = `-- name: FindDeletedMovieByExternalID :one
+SELECT m.movie_id, m.extl_id, m.title, m.rated, m.released, m.run_time, m.director, m.writer, m.create_app_id, m.create_user_id, m.create_timestamp, m.update_app_id, m.update_user_id, m.update_timestamp, m.delete_app_id, m.delete_user_id, m.delete_timestamp, m.org_id, m.rating_country, m.review_count, m.review_score_total
+FROM movie m
+WHERE m.extl_id = $1
+  AND m.org_id = $2
+  AND m.delete_timestamp IS NOT NULL
 `
 
-func (q *Queries) DeleteMovie(ctx context.Context, movieID pgtype.UUID) (int64, error) {
-	result, err := q.db.Exec(ctx, deleteMovie, movieID)
-	if err != nil {
-		return 0, err
-	}
-	return result.RowsAffected(), nil
+type FindDeletedMovieByExternalIDParams struct {
+	ExtlID string
+	OrgID  pgtype.UUID
+}
+
+func (q *Queries) FindDeletedMovieByExternalID(ctx context.Context, arg FindDeletedMovieByExternalIDParams) (Movie, error) {
+	row := q.db.QueryRow(ctx, findDeletedMovieByExternalID, arg.ExtlID, arg.OrgID)
+	var i Movie
+	err := row.Scan(
+		&i.MovieID,
+		&i.ExtlID,
+		&i.Title,
+		&i.Rated,
+		&i.Released,
+		&i.RunTime,
+		&i.Director,
+		&i.Writer,
+		&i.CreateAppID,
+		&i.CreateUserID,
+		&i.CreateTimestamp,
+		&i.UpdateAppID,
+		&i.UpdateUserID,
+		&i.UpdateTimestamp,
+		&i.DeleteAppID,
+		&i.DeleteUserID,
+		&i.DeleteTimestamp,
+		&i.OrgID,
+		&i.RatingCountry,
+		&i.ReviewCount,
+		&i.ReviewScoreTotal,
+	)
+	return i, err
 }
 
 const findMovieByExternalID = `-- name: FindMovieByExternalID :one
-SELECT m.movie_id, m.extl_id, m.title, m.rated, m.released, m.run_time, m.director, m.writer, m.create_app_id, m.create_user_id, m.create_timestamp, m.update_app_id, m.update_user_id, m.update_timestamp
+SELECT m.movie_id, m.extl_id, m.title, m.rated, m.released, m.run_time, m.director, m.writer, m.create_app_id, m.create_user_id, m.create_timestamp, m.update_app_id, m.update_user_id, m.update_timestamp, m.delete_app_id, m.delete_user_id, m.delete_timestamp, m.org_id, m.rating_country, m.review_count, m.review_score_total
 FROM movie m
 WHERE m.extl_id = $1
+  AND m.org_id = $2
+  AND m.delete_timestamp IS NULL
 `
 
-func (q *Queries) FindMovieByExternalID(ctx context.Context, extlID string) (Movie, error) {
-	row := q.db.QueryRow(ctx, findMovieByExternalID, extlID)
+type FindMovieByExternalIDParams struct {
+	ExtlID string
+	OrgID  pgtype.UUID
+}
+
+func (q *Queries) FindMovieByExternalID(ctx context.Context, arg FindMovieByExternalIDParams) (Movie, error) {
+	row := q.db.QueryRow(ctx, findMovieByExternalID, arg.ExtlID, arg.OrgID)
 	var i Movie
 	err := row.Scan(
 		&i.MovieID,
@@ -91,6 +131,13 @@ func (q *Queries) FindMovieByExternalID(ctx context.Context, extlID string) (Mov
 		&i.UpdateAppID,
 		&i.UpdateUserID,
 		&i.UpdateTimestamp,
+		&i.DeleteAppID,
+		&i.DeleteUserID,
+		&i.DeleteTimestamp,
+		&i.OrgID,
+		&i.RatingCountry,
+		&i.ReviewCount,
+		&i.ReviewScoreTotal,
 	)
 	return i, err
 }
@@ -104,6 +151,9 @@ SELECT m.movie_id,
        m.run_time,
        m.director,
        m.writer,
+       m.rating_country,
+       m.review_count,
+       m.review_score_total,
        m.create_app_id,
        ca.org_id          create_app_org_id,
        ca.app_extl_id     create_app_extl_id,
@@ -128,6 +178,8 @@ FROM movie m
          LEFT JOIN users cu on cu.user_id = m.create_user_id
          LEFT JOIN users uu on uu.user_id = m.update_user_id
 WHERE m.extl_id = $1
+  AND m.org_id = $2
+  AND m.delete_timestamp IS NULL
 `
 
 type FindMovieByExternalIDWithAuditRow struct {
@@ -139,6 +191,9 @@ type FindMovieByExternalIDWithAuditRow struct {
 	RunTime              pgtype.Int8
 	Director             pgtype.Text
 	Writer               pgtype.Text
+	RatingCountry        string
+	ReviewCount          int32
+	ReviewScoreTotal     int64
 	CreateAppID          pgtype.UUID
 	CreateAppOrgID       pgtype.UUID
 	CreateAppExtlID      string
@@ -159,8 +214,13 @@ type FindMovieByExternalIDWithAuditRow struct {
 	UpdateTimestamp      pgtype.Timestamptz
 }
 
-func (q *Queries) FindMovieByExternalIDWithAudit(ctx context.Context, extlID string) (FindMovieByExternalIDWithAuditRow, error) {
-	row := q.db.QueryRow(ctx, findMovieByExternalIDWithAudit, extlID)
+type FindMovieByExternalIDWithAuditParams struct {
+	ExtlID string
+	OrgID  pgtype.UUID
+}
+
+func (q *Queries) FindMovieByExternalIDWithAudit(ctx context.Context, arg FindMovieByExternalIDWithAuditParams) (FindMovieByExternalIDWithAuditRow, error) {
+	row := q.db.QueryRow(ctx, findMovieByExternalIDWithAudit, arg.ExtlID, arg.OrgID)
 	var i FindMovieByExternalIDWithAuditRow
 	err := row.Scan(
 		&i.MovieID,
@@ -171,6 +231,9 @@ func (q *Queries) FindMovieByExternalIDWithAudit(ctx context.Context, extlID str
 		&i.RunTime,
 		&i.Director,
 		&i.Writer,
+		&i.RatingCountry,
+		&i.ReviewCount,
+		&i.ReviewScoreTotal,
 		&i.CreateAppID,
 		&i.CreateAppOrgID,
 		&i.CreateAppExtlID,
@@ -202,6 +265,9 @@ SELECT m.movie_id,
        m.run_time,
        m.director,
        m.writer,
+       m.rating_country,
+       m.review_count,
+       m.review_score_total,
        m.create_app_id,
        ca.org_id          create_app_org_id,
        ca.app_extl_id     create_app_extl_id,
@@ -225,6 +291,8 @@ FROM movie m
          INNER JOIN app ua on ua.app_id = m.update_app_id
          LEFT JOIN users cu on cu.user_id = m.create_user_id
          LEFT JOIN users uu on uu.user_id = m.update_user_id
+WHERE m.org_id = $1
+  AND m.delete_timestamp IS NULL
 `
 
 type FindMoviesRow struct {
@@ -236,6 +304,9 @@ type FindMoviesRow struct {
 	RunTime              pgtype.Int8
 	Director             pgtype.Text
 	Writer               pgtype.Text
+	RatingCountry        string
+	ReviewCount          int32
+	ReviewScoreTotal     int64
 	CreateAppID          pgtype.UUID
 	CreateAppOrgID       pgtype.UUID
 	CreateAppExtlID      string
@@ -256,8 +327,8 @@ type FindMoviesRow struct {
 	UpdateTimestamp      pgtype.Timestamptz
 }
 
-func (q *Queries) FindMovies(ctx context.Context) ([]FindMoviesRow, error) {
-	rows, err := q.db.Query(ctx, findMovies)
+func (q *Queries) FindMovies(ctx context.Context, orgID pgtype.UUID) ([]FindMoviesRow, error) {
+	rows, err := q.db.Query(ctx, findMovies, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -274,6 +345,280 @@ func (q *Queries) FindMovies(ctx context.Context) ([]FindMoviesRow, error) {
 			&i.RunTime,
 			&i.Director,
 			&i.Writer,
+			&i.RatingCountry,
+			&i.ReviewCount,
+			&i.ReviewScoreTotal,
+			&i.RatingCountry,
+			&i.CreateAppID,
+			&i.CreateAppOrgID,
+			&i.CreateAppExtlID,
+			&i.CreateAppName,
+			&i.CreateAppDescription,
+			&i.CreateUserID,
+			&i.CreateUserFirstName,
+			&i.CreateUserLastName,
+			&i.CreateTimestamp,
+			&i.UpdateAppID,
+			&i.UpdateAppOrgID,
+			&i.UpdateAppExtlID,
+			&i.UpdateAppName,
+			&i.UpdateAppDescription,
+			&i.UpdateUserID,
+			&i.UpdateUserFirstName,
+			&i.UpdateUserLastName,
+			&i.UpdateTimestamp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findMoviesByCredit = `-- name: FindMoviesByCredit :many
+SELECT m.movie_id,
+       m.extl_id,
+       m.title,
+       m.rated,
+       m.released,
+       m.run_time,
+       m.director,
+       m.writer,
+       m.rating_country,
+       m.review_count,
+       m.review_score_total,
+       m.create_app_id,
+       ca.org_id          create_app_org_id,
+       ca.app_extl_id     create_app_extl_id,
+       ca.app_name        create_app_name,
+       ca.app_description create_app_description,
+       m.create_user_id,
+       cu.first_name     create_user_first_name,
+       cu.last_name      create_user_last_name,
+       m.create_timestamp,
+       m.update_app_id,
+       ua.org_id          update_app_org_id,
+       ua.app_extl_id     update_app_extl_id,
+       ua.app_name        update_app_name,
+       ua.app_description update_app_description,
+       m.update_user_id,
+       uu.first_name     update_user_first_name,
+       uu.last_name      update_user_last_name,
+       m.update_timestamp
+FROM movie m
+         INNER JOIN app ca on ca.app_id = m.create_app_id
+         INNER JOIN app ua on ua.app_id = m.update_app_id
+         LEFT JOIN users cu on cu.user_id = m.create_user_id
+         LEFT JOIN users uu on uu.user_id = m.update_user_id
+WHERE m.org_id = $1
+  AND m.delete_timestamp IS NULL
+  AND EXISTS(SELECT 1
+             FROM movie_credit mc
+             WHERE mc.movie_id = m.movie_id
+               AND lower(mc.person_name) = lower($2)
+               AND ($3::varchar IS NULL OR mc.credit_role = $3))
+ORDER BY m.released, m.title
+`
+
+type FindMoviesByCreditParams struct {
+	OrgID      pgtype.UUID
+	PersonName string
+	CreditRole pgtype.Text
+}
+
+type FindMoviesByCreditRow struct {
+	MovieID              pgtype.UUID
+	ExtlID               string
+	Title                string
+	Rated                pgtype.Text
+	Released             pgtype.Date
+	RunTime              pgtype.Int8
+	Director             pgtype.Text
+	Writer               pgtype.Text
+	RatingCountry        string
+	ReviewCount          int32
+	ReviewScoreTotal     int64
+	CreateAppID          pgtype.UUID
+	CreateAppOrgID       pgtype.UUID
+	CreateAppExtlID      string
+	CreateAppName        string
+	CreateAppDescription string
+	CreateUserID         pgtype.UUID
+	CreateUserFirstName  pgtype.Text
+	CreateUserLastName   pgtype.Text
+	CreateTimestamp      pgtype.Timestamptz
+	UpdateAppID          pgtype.UUID
+	UpdateAppOrgID       pgtype.UUID
+	UpdateAppExtlID      string
+	UpdateAppName        string
+	UpdateAppDescription string
+	UpdateUserID         pgtype.UUID
+	UpdateUserFirstName  pgtype.Text
+	UpdateUserLastName   pgtype.Text
+	UpdateTimestamp      pgtype.Timestamptz
+}
+
+// FindMoviesByCredit finds the movies a person is credited on, matching
+// the name case-insensitively. A null credit_role matches any role.
+func (q *Queries) FindMoviesByCredit(ctx context.Context, arg FindMoviesByCreditParams) ([]FindMoviesByCreditRow, error) {
+	rows, err := q.db.Query(ctx, findMoviesByCredit, arg.OrgID, arg.PersonName, arg.CreditRole)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindMoviesByCreditRow
+	for rows.Next() {
+		var i FindMoviesByCreditRow
+		if err := rows.Scan(
+			&i.MovieID,
+			&i.ExtlID,
+			&i.Title,
+			&i.Rated,
+			&i.Released,
+			&i.RunTime,
+			&i.Director,
+			&i.Writer,
+			&i.RatingCountry,
+			&i.ReviewCount,
+			&i.ReviewScoreTotal,
+			&i.RatingCountry,
+			&i.CreateAppID,
+			&i.CreateAppOrgID,
+			&i.CreateAppExtlID,
+			&i.CreateAppName,
+			&i.CreateAppDescription,
+			&i.CreateUserID,
+			&i.CreateUserFirstName,
+			&i.CreateUserLastName,
+			&i.CreateTimestamp,
+			&i.UpdateAppID,
+			&i.UpdateAppOrgID,
+			&i.UpdateAppExtlID,
+			&i.UpdateAppName,
+			&i.UpdateAppDescription,
+			&i.UpdateUserID,
+			&i.UpdateUserFirstName,
+			&i.UpdateUserLastName,
+			&i.UpdateTimestamp,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findMoviesByGenre = `-- name: FindMoviesByGenre :many
+SELECT m.movie_id,
+       m.extl_id,
+       m.title,
+       m.rated,
+       m.released,
+       m.run_time,
+       m.director,
+       m.writer,
+       m.rating_country,
+       m.review_count,
+       m.review_score_total,
+       m.create_app_id,
+       ca.org_id          create_app_org_id,
+       ca.app_extl_id     create_app_extl_id,
+       ca.app_name        create_app_name,
+       ca.app_description create_app_description,
+       m.create_user_id,
+       cu.first_name     create_user_first_name,
+       cu.last_name      create_user_last_name,
+       m.create_timestamp,
+       m.update_app_id,
+       ua.org_id          update_app_org_id,
+       ua.app_extl_id     update_app_extl_id,
+       ua.app_name        update_app_name,
+       ua.app_description update_app_description,
+       m.update_user_id,
+       uu.first_name     update_user_first_name,
+       uu.last_name      update_user_last_name,
+       m.update_timestamp
+FROM movie m
+         INNER JOIN app ca on ca.app_id = m.create_app_id
+         INNER JOIN app ua on ua.app_id = m.update_app_id
+         LEFT JOIN users cu on cu.user_id = m.create_user_id
+         LEFT JOIN users uu on uu.user_id = m.update_user_id
+WHERE m.org_id = $1
+  AND m.delete_timestamp IS NULL
+  AND EXISTS(SELECT 1
+             FROM movie_genre mg
+                      INNER JOIN genre g on g.genre_id = mg.genre_id
+             WHERE mg.movie_id = m.movie_id
+               AND g.genre_cd = $2)
+ORDER BY m.title
+`
+
+type FindMoviesByGenreParams struct {
+	OrgID   pgtype.UUID
+	GenreCd string
+}
+
+type FindMoviesByGenreRow struct {
+	MovieID              pgtype.UUID
+	ExtlID               string
+	Title                string
+	Rated                pgtype.Text
+	Released             pgtype.Date
+	RunTime              pgtype.Int8
+	Director             pgtype.Text
+	Writer               pgtype.Text
+	RatingCountry        string
+	ReviewCount          int32
+	ReviewScoreTotal     int64
+	CreateAppID          pgtype.UUID
+	CreateAppOrgID       pgtype.UUID
+	CreateAppExtlID      string
+	CreateAppName        string
+	CreateAppDescription string
+	CreateUserID         pgtype.UUID
+	CreateUserFirstName  pgtype.Text
+	CreateUserLastName   pgtype.Text
+	CreateTimestamp      pgtype.Timestamptz
+	UpdateAppID          pgtype.UUID
+	UpdateAppOrgID       pgtype.UUID
+	UpdateAppExtlID      string
+	UpdateAppName        string
+	UpdateAppDescription string
+	UpdateUserID         pgtype.UUID
+	UpdateUserFirstName  pgtype.Text
+	UpdateUserLastName   pgtype.Text
+	UpdateTimestamp      pgtype.Timestamptz
+}
+
+// FindMoviesByGenre finds the movies categorized in the given genre.
+func (q *Queries) FindMoviesByGenre(ctx context.Context, arg FindMoviesByGenreParams) ([]FindMoviesByGenreRow, error) {
+	rows, err := q.db.Query(ctx, findMoviesByGenre, arg.OrgID, arg.GenreCd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindMoviesByGenreRow
+	for rows.Next() {
+		var i FindMoviesByGenreRow
+		if err := rows.Scan(
+			&i.MovieID,
+			&i.ExtlID,
+			&i.Title,
+			&i.Rated,
+			&i.Released,
+			&i.RunTime,
+			&i.Director,
+			&i.Writer,
+			&i.RatingCountry,
+			&i.ReviewCount,
+			&i.ReviewScoreTotal,
+			&i.RatingCountry,
 			&i.CreateAppID,
 			&i.CreateAppOrgID,
 			&i.CreateAppExtlID,
@@ -304,13 +649,20 @@ func (q *Queries) FindMovies(ctx context.Context) ([]FindMoviesRow, error) {
 }
 
 const findMoviesByTitle = `-- name: FindMoviesByTitle :many
-SELECT m.movie_id, m.extl_id, m.title, m.rated, m.released, m.run_time, m.director, m.writer, m.create_app_id, m.create_user_id, m.create_timestamp, m.update_app_id, m.update_user_id, m.update_timestamp
+SELECT m.movie_id, m.extl_id, m.title, m.rated, m.released, m.run_time, m.director, m.writer, m.create_app_id, m.create_user_id, m.create_timestamp, m.update_app_id, m.update_user_id, m.update_timestamp, m.delete_app_id, m.delete_user_id, m.delete_timestamp, m.org_id, m.rating_country, m.review_count, m.review_score_total
 FROM movie m
 WHERE m.title = $1
+  AND m.org_id = $2
+  AND m.delete_timestamp IS NULL
 `
 
-func (q *Queries) FindMoviesByTitle(ctx context.Context, title string) ([]Movie, error) {
-	rows, err := q.db.Query(ctx, findMoviesByTitle, title)
+type FindMoviesByTitleParams struct {
+	Title string
+	OrgID pgtype.UUID
+}
+
+func (q *Queries) FindMoviesByTitle(ctx context.Context, arg FindMoviesByTitleParams) ([]Movie, error) {
+	rows, err := q.db.Query(ctx, findMoviesByTitle, arg.Title, arg.OrgID)
 	if err != nil {
 		return nil, err
 	}
@@ -333,6 +685,153 @@ func (q *Queries) FindMoviesByTitle(ctx context.Context, title string) ([]Movie,
 			&i.UpdateAppID,
 			&i.UpdateUserID,
 			&i.UpdateTimestamp,
+			&i.DeleteAppID,
+			&i.DeleteUserID,
+			&i.DeleteTimestamp,
+			&i.OrgID,
+			&i.RatingCountry,
+			&i.ReviewCount,
+			&i.ReviewScoreTotal,
+			&i.RatingCountry,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeMovies = `-- name: PurgeMovies :execrows
+DELETE
+FROM movie
+WHERE delete_timestamp < $1
+   OR org_id IN (SELECT org_id
+                 FROM org
+                 WHERE delete_timestamp < $1)
+`
+
+// PurgeMovies physically removes movies which were deleted before the given timestamp,
+// along with the movies of orgs which were deleted before it.
+func (q *Queries) PurgeMovies(ctx context.Context, deleteTimestamp pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeMovies, deleteTimestamp)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreMovie = `-- name: RestoreMovie :execrows
+UPDATE movie
+SET delete_app_id    = NULL,
+    delete_user_id   = NULL,
+    delete_timestamp = NULL,
+    update_app_id    = $1,
+    update_user_id   = $2,
+    update_timestamp = $3
+WHERE movie_id = $4
+  AND org_id = $5
+  AND delete_timestamp IS NOT NULL
+`
+
+type RestoreMovieParams struct {
+	UpdateAppID     pgtype.UUID
+	UpdateUserID    pgtype.UUID
+	UpdateTimestamp pgtype.Timestamptz
+	MovieID         pgtype.UUID
+	OrgID           pgtype.UUID
+}
+
+func (q *Queries) RestoreMovie(ctx context.Context, arg RestoreMovieParams) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreMovie,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.MovieID,
+		arg.OrgID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const searchMovies = `-- name: SearchMovies :many
+SELECT m.extl_id,
+       m.title,
+       m.rated,
+       m.released,
+       m.run_time,
+       m.director,
+       m.writer,
+       (ts_rank(movie_search_document(m.title, m.director, m.writer), q.query) +
+        greatest(word_similarity($1::text, m.title),
+                 word_similarity($1::text, coalesce(m.director, '')),
+                 word_similarity($1::text, coalesce(m.writer, ''))))::real rank,
+       concat_ws(' / ',
+                 ts_headline('english', e.title, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+                 ts_headline('simple', e.director, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+                 ts_headline('simple', e.writer, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'))::text snippet
+FROM movie m,
+     websearch_to_tsquery('english', $1::text) q(query),
+     LATERAL (SELECT replace(replace(replace(replace(m.title, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'),
+                     replace(replace(replace(replace(m.director, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'),
+                     replace(replace(replace(replace(m.writer, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')) e(title, director, writer)
+WHERE m.org_id = $2
+  AND m.delete_timestamp IS NULL
+  AND (movie_search_document(m.title, m.director, m.writer) @@ q.query
+    OR $1::text <% m.title
+    OR $1::text <% m.director
+    OR $1::text <% m.writer)
+ORDER BY rank DESC, m.title
+LIMIT $3::int
+`
+
+type SearchMoviesParams struct {
+	Search   string
+	OrgID    pgtype.UUID
+	RowLimit int32
+}
+
+type SearchMoviesRow struct {
+	ExtlID   string
+	Title    string
+	Rated    pgtype.Text
+	Released pgtype.Date
+	RunTime  pgtype.Int8
+	Director pgtype.Text
+	Writer   pgtype.Text
+	Rank     float32
+	Snippet  string
+}
+
+// SearchMovies ranks movies against a full-text query over title,
+// director and writer. Trigram word similarity also matches partial
+// words and misspellings, which full-text search alone would miss.
+// The snippet is HTML: the title, director and writer are escaped
+// before their matched words are wrapped in <mark> tags, each
+// highlighted with the text search config it is indexed with.
+func (q *Queries) SearchMovies(ctx context.Context, arg SearchMoviesParams) ([]SearchMoviesRow, error) {
+	rows, err := q.db.Query(ctx, searchMovies, arg.Search, arg.OrgID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchMoviesRow
+	for rows.Next() {
+		var i SearchMoviesRow
+		if err := rows.Scan(
+			&i.ExtlID,
+			&i.Title,
+			&i.Rated,
+			&i.Released,
+			&i.RunTime,
+			&i.Director,
+			&i.Writer,
+			&i.Rank,
+			&i.Snippet,
 		); err != nil {
 			return nil, err
 		}
@@ -344,6 +843,38 @@ func (q *Queries) FindMoviesByTitle(ctx context.Context, title string) ([]Movie,
 	return items, nil
 }
 
+const softDeleteMovie = `-- name: SoftDeleteMovie :execrows
+UPDATE movie
+SET delete_app_id    = $1,
+    delete_user_id   = $2,
+    delete_timestamp = $3
+WHERE movie_id = $4
+  AND org_id = $5
+  AND delete_timestamp IS NULL
+`
+
+type SoftDeleteMovieParams struct {
+	DeleteAppID     pgtype.UUID
+	DeleteUserID    pgtype.UUID
+	DeleteTimestamp pgtype.Timestamptz
+	MovieID         pgtype.UUID
+	OrgID           pgtype.UUID
+}
+
+func (q *Queries) SoftDeleteMovie(ctx context.Context, arg SoftDeleteMovieParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteMovie,
+		arg.DeleteAppID,
+		arg.DeleteUserID,
+		arg.DeleteTimestamp,
+		arg.MovieID,
+		arg.OrgID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateMovie = `-- name: UpdateMovie :exec
 UPDATE movie
 SET title            = $1,
@@ -352,10 +883,12 @@ SET title            = $1,
     run_time         = $4,
     director         = $5,
     writer           = $6,
-    update_app_id    = $7,
-    update_user_id   = $8,
-    update_timestamp = $9
-WHERE movie_id = $10
+    rating_country   = $7,
+    update_app_id    = $8,
+    update_user_id   = $9,
+    update_timestamp = $10
+WHERE movie_id = $11
+  AND org_id = $12
 `
 
 type UpdateMovieParams struct {
@@ -365,10 +898,12 @@ type UpdateMovieParams struct {
 	RunTime         pgtype.Int8
 	Director        pgtype.Text
 	Writer          pgtype.Text
+	RatingCountry   string
 	UpdateAppID     pgtype.UUID
 	UpdateUserID    pgtype.UUID
 	UpdateTimestamp pgtype.Timestamptz
 	MovieID         pgtype.UUID
+	OrgID           pgtype.UUID
 }
 
 func (q *Queries) UpdateMovie(ctx context.Context, arg UpdateMovieParams) error {
@@ -379,10 +914,36 @@ func (q *Queries) UpdateMovie(ctx context.Context, arg UpdateMovieParams) error
 		arg.RunTime,
 		arg.Director,
 		arg.Writer,
+		arg.RatingCountry,
 		arg.UpdateAppID,
 		arg.UpdateUserID,
 		arg.UpdateTimestamp,
 		arg.MovieID,
+		arg.OrgID,
 	)
 	return err
 }
+
+const updateMovieReviewScore = `-- name: UpdateMovieReviewScore :execrows
+UPDATE movie
+SET review_count       = review_count + $1::int,
+    review_score_total = review_score_total + $2::bigint
+WHERE movie_id = $3
+`
+
+type UpdateMovieReviewScoreParams struct {
+	CountDelta int32
+	ScoreDelta int64
+	MovieID    pgtype.UUID
+}
+
+// UpdateMovieReviewScore adjusts the review count and score total of a
+// movie by the given amounts. Adjusting rather than recounting means
+// concurrent reviews of the same movie are not lost.
+func (q *Queries) UpdateMovieReviewScore(ctx context.Context, arg UpdateMovieReviewScoreParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateMovieReviewScore, arg.CountDelta, arg.ScoreDelta, arg.MovieID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -87,18 +87,47 @@ func (q *Queries) CreateOrgKind(ctx context.Context, arg CreateOrgKindParams) (i
 	return result.RowsAffected(), nil
 }
 
-const deleteOrg = `-- name: DeleteOrg :execrows
-DELETE
-FROM org
-WHERE org_id = $1
+const findDeletedOrgByExtlID = `-- name: FindDeletedOrgByExtlID :one
+SELECT o.org_id,
+       o.org_extl_id,
+       o.org_name,
+       o.org_description,
+       o.org_kind_id,
+       ok.org_kind_extl_id,
+       ok.org_kind_desc,
+       o.delete_timestamp
+FROM org o
+         INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+WHERE o.org_extl_id = $1
+  AND o.delete_timestamp IS NOT NULL
+  AND o.purge_timestamp IS NULL
 `
 
-func (q *Queries) DeleteOrg(ctx context.Context, orgID pgtype.UUID) (int64, error) {
-	result, err := q.db.Exec(ctx, deleteOrg, orgID)
-	if err != nil {
-		return 0, err
-	}
-	return result.RowsAffected(), nil
+type FindDeletedOrgByExtlIDRow struct {
+	OrgID           pgtype.UUID
+	OrgExtlID       string
+	OrgName         string
+	OrgDescription  string
+	OrgKindID       pgtype.UUID
+	OrgKindExtlID   string
+	OrgKindDesc     string
+	DeleteTimestamp pgtype.Timestamptz
+}
+
+func (q *Queries) FindDeletedOrgByExtlID(ctx context.Context, orgExtlID string) (FindDeletedOrgByExtlIDRow, error) {
+	row := q.db.QueryRow(ctx, findDeletedOrgByExtlID, orgExtlID)
+	var i FindDeletedOrgByExtlIDRow
+	err := row.Scan(
+		&i.OrgID,
+		&i.OrgExtlID,
+		&i.OrgName,
+		&i.OrgDescription,
+		&i.OrgKindID,
+		&i.OrgKindExtlID,
+		&i.OrgKindDesc,
+		&i.DeleteTimestamp,
+	)
+	return i, err
 }
 
 const findOrgByExtlID = `-- name: FindOrgByExtlID :one
@@ -112,6 +141,7 @@ SELECT o.org_id,
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE org_extl_id = $1
+  AND o.delete_timestamp IS NULL
 `
 
 type FindOrgByExtlIDRow struct {
@@ -172,6 +202,7 @@ FROM org o
          INNER JOIN users cu on cu.user_id = o.create_user_id
          INNER JOIN users uu on uu.user_id = o.update_user_id
 WHERE o.org_extl_id = $1
+  AND o.delete_timestamp IS NULL
 `
 
 type FindOrgByExtlIDWithAuditRow struct {
@@ -246,6 +277,7 @@ SELECT o.org_id,
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE o.org_id = $1
+  AND o.delete_timestamp IS NULL
 `
 
 type FindOrgByIDRow struct {
@@ -306,6 +338,7 @@ FROM org o
          INNER JOIN users cu on cu.user_id = o.create_user_id
          INNER JOIN users uu on uu.user_id = o.update_user_id
 WHERE o.org_id = $1
+  AND o.delete_timestamp IS NULL
 `
 
 type FindOrgByIDWithAuditRow struct {
@@ -380,6 +413,7 @@ SELECT o.org_id,
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE o.org_name = $1
+  AND o.delete_timestamp IS NULL
 `
 
 type FindOrgByNameRow struct {
@@ -440,6 +474,7 @@ FROM org o
          INNER JOIN users cu on cu.user_id = o.create_user_id
          INNER JOIN users uu on uu.user_id = o.update_user_id
 WHERE o.org_name = $1
+  AND o.delete_timestamp IS NULL
 `
 
 type FindOrgByNameWithAuditRow struct {
@@ -575,6 +610,7 @@ SELECT o.org_id,
        ok.org_kind_desc
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+WHERE o.delete_timestamp IS NULL
 ORDER BY org_name
 `
 
@@ -626,6 +662,7 @@ SELECT o.org_id,
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE ok.org_kind_extl_id = $1
+  AND o.delete_timestamp IS NULL
 `
 
 type FindOrgsByKindExtlIDRow struct {
@@ -696,6 +733,7 @@ FROM org o
          INNER JOIN app a2 on a2.app_id = o.update_app_id
          INNER JOIN users cu on cu.user_id = o.create_user_id
          INNER JOIN users uu on uu.user_id = o.update_user_id
+WHERE o.delete_timestamp IS NULL
 `
 
 type FindOrgsWithAuditRow struct {
@@ -772,6 +810,91 @@ func (q *Queries) FindOrgsWithAudit(ctx context.Context) ([]FindOrgsWithAuditRow
 	return items, nil
 }
 
+const purgeOrgs = `-- name: PurgeOrgs :execrows
+UPDATE org
+SET org_name        = 'purged-' || org_extl_id,
+    org_description = 'purged',
+    purge_timestamp = $2
+WHERE delete_timestamp < $1
+  AND purge_timestamp IS NULL
+`
+
+type PurgeOrgsParams struct {
+	DeleteTimestamp pgtype.Timestamptz
+	PurgeTimestamp  pgtype.Timestamptz
+}
+
+// PurgeOrgs scrubs orgs which were deleted before the given timestamp and marks them as purged.
+// Purged orgs are not removed, as the users, roles and apps of the org refer to them.
+func (q *Queries) PurgeOrgs(ctx context.Context, arg PurgeOrgsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeOrgs, arg.DeleteTimestamp, arg.PurgeTimestamp)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreOrg = `-- name: RestoreOrg :execrows
+UPDATE org
+SET delete_app_id    = NULL,
+    delete_user_id   = NULL,
+    delete_timestamp = NULL,
+    update_app_id    = $1,
+    update_user_id   = $2,
+    update_timestamp = $3
+WHERE org_id = $4
+  AND delete_timestamp IS NOT NULL
+`
+
+type RestoreOrgParams struct {
+	UpdateAppID     pgtype.UUID
+	UpdateUserID    pgtype.UUID
+	UpdateTimestamp pgtype.Timestamptz
+	OrgID           pgtype.UUID
+}
+
+func (q *Queries) RestoreOrg(ctx context.Context, arg RestoreOrgParams) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreOrg,
+		arg.UpdateAppID,
+		arg.UpdateUserID,
+		arg.UpdateTimestamp,
+		arg.OrgID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteOrg = `-- name: SoftDeleteOrg :execrows
+UPDATE org
+SET delete_app_id    = $1,
+    delete_user_id   = $2,
+    delete_timestamp = $3
+WHERE org_id = $4
+  AND delete_timestamp IS NULL
+`
+
+type SoftDeleteOrgParams struct {
+	DeleteAppID     pgtype.UUID
+	DeleteUserID    pgtype.UUID
+	DeleteTimestamp pgtype.Timestamptz
+	OrgID           pgtype.UUID
+}
+
+func (q *Queries) SoftDeleteOrg(ctx context.Context, arg SoftDeleteOrgParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteOrg,
+		arg.DeleteAppID,
+		arg.DeleteUserID,
+		arg.DeleteTimestamp,
+		arg.OrgID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateOrg = `-- name: UpdateOrg :execrows
 UPDATE org
 SET org_name         = $1,
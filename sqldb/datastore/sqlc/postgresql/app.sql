@@ -14,7 +14,8 @@ SELECT a.org_id,
 FROM app a
          INNER JOIN org o on o.org_id = a.org_id
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
-WHERE a.app_id = $1;
+WHERE a.app_id = $1
+  AND a.delete_timestamp IS NULL;
 
 -- name: FindAppByIDWithAudit :one
 -- FindAppByID selects an app given its app_id.
@@ -55,7 +56,8 @@ FROM app a
          INNER JOIN app ua on ua.app_id = a.update_app_id
          LEFT JOIN users cu on cu.user_id = a.create_user_id
          LEFT JOIN users uu on uu.user_id = a.update_user_id
-WHERE a.app_id = $1;
+WHERE a.app_id = $1
+  AND a.delete_timestamp IS NULL;
 
 -- name: FindAppByExternalID :one
 -- FindAppByExternalID selects an app given its app_extl_id.
@@ -73,7 +75,8 @@ SELECT a.app_id,
 FROM app a
          INNER JOIN org o on o.org_id = a.org_id
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
-WHERE a.app_extl_id = $1;
+WHERE a.app_extl_id = $1
+  AND a.delete_timestamp IS NULL;
 
 -- name: FindAppByExternalIDWithAudit :one
 -- FindAppByExternalID selects an app given its app_extl_id.
@@ -114,7 +117,8 @@ FROM app a
          INNER JOIN app ua on ua.app_id = a.update_app_id
          LEFT JOIN users cu on cu.user_id = a.create_user_id
          LEFT JOIN users uu on uu.user_id = a.update_user_id
-WHERE a.app_extl_id = $1;
+WHERE a.app_extl_id = $1
+  AND a.delete_timestamp IS NULL;
 
 -- name: FindAppByName :one
 -- FindAppByName selects an app given its app_name.
@@ -133,7 +137,8 @@ FROM app a
          INNER JOIN org o on o.org_id = a.org_id
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
 WHERE o.org_id = $1
-  AND a.app_name = $2;
+  AND a.app_name = $2
+  AND a.delete_timestamp IS NULL;
 
 -- name: FindAppByProviderClientID :one
 -- FindAppByProviderClientID selects an app given an external auth provider client ID.
@@ -151,19 +156,22 @@ SELECT a.app_id,
 FROM app a
          INNER JOIN org o on o.org_id = a.org_id
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
-WHERE a.auth_provider_client_id = $1;
+WHERE a.auth_provider_client_id = $1
+  AND a.delete_timestamp IS NULL;
 
 -- name: FindApps :many
 -- FindApps returns every app.
 SELECT *
 FROM app
+WHERE delete_timestamp IS NULL
 ORDER BY app_name;
 
 -- name: FindAppsByOrg :many
 -- FindAppsByOrg returns all apps for a given Organization.
 SELECT *
 FROM app
-WHERE org_id = $1;
+WHERE org_id = $1
+  AND delete_timestamp IS NULL;
 
 -- name: FindAppsWithAudit :many
 -- FindAppsWithAudit returns every app.
@@ -203,7 +211,8 @@ FROM app a
          INNER JOIN app ca on ca.app_id = a.create_app_id
          INNER JOIN app ua on ua.app_id = a.update_app_id
          LEFT JOIN users cu on cu.user_id = a.create_user_id
-         LEFT JOIN users uu on uu.user_id = a.update_user_id;
+         LEFT JOIN users uu on uu.user_id = a.update_user_id
+WHERE a.delete_timestamp IS NULL;
 
 -- name: CreateApp :execrows
 -- CreateApp inserts a new app into the app table.
@@ -224,11 +233,76 @@ SET app_name         = $1,
 WHERE app_id = $6;
 
 
--- name: DeleteApp :execrows
--- DeleteApp deletes an app given its app_id.
-DELETE
+-- name: SoftDeleteApp :execrows
+-- SoftDeleteApp marks an app as deleted given its app_id.
+UPDATE app
+SET delete_app_id    = $1,
+    delete_user_id   = $2,
+    delete_timestamp = $3
+WHERE app_id = $4
+  AND delete_timestamp IS NULL;
+
+-- name: FindDeletedAppByExternalID :one
+-- FindDeletedAppByExternalID selects a deleted app given its app_extl_id.
+SELECT a.app_id,
+       a.org_id,
+       o.org_extl_id,
+       o.org_name,
+       o.org_description,
+       ok.org_kind_id,
+       ok.org_kind_extl_id,
+       ok.org_kind_desc,
+       a.app_extl_id,
+       a.app_name,
+       a.app_description,
+       a.delete_timestamp,
+       o.delete_timestamp org_delete_timestamp
+FROM app a
+         INNER JOIN org o on o.org_id = a.org_id
+         INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+WHERE a.app_extl_id = $1
+  AND a.delete_timestamp IS NOT NULL
+  AND a.purge_timestamp IS NULL;
+
+-- name: FindDeletedAppsByOrg :many
+-- FindDeletedAppsByOrg returns the apps for a given Organization
+-- which were deleted at the given timestamp.
+SELECT *
 FROM app
-WHERE app_id = $1;
+WHERE org_id = $1
+  AND delete_timestamp = $2;
+
+-- name: RestoreApp :execrows
+-- RestoreApp clears the deleted mark of an app given its app_id.
+UPDATE app
+SET delete_app_id    = NULL,
+    delete_user_id   = NULL,
+    delete_timestamp = NULL,
+    update_app_id    = $1,
+    update_user_id   = $2,
+    update_timestamp = $3
+WHERE app_id = $4
+  AND delete_timestamp IS NOT NULL;
+
+-- name: PurgeApps :execrows
+-- PurgeApps scrubs apps which were deleted before the given timestamp and marks them as purged.
+-- Purged apps are not removed, as the audit columns of other records refer to them.
+UPDATE app
+SET app_name                = 'purged-' || app_extl_id,
+    app_description         = 'purged',
+    auth_provider_id        = NULL,
+    auth_provider_client_id = NULL,
+    purge_timestamp         = $2
+WHERE delete_timestamp < $1
+  AND purge_timestamp IS NULL;
+
+-- name: PurgeAppAPIKeys :execrows
+-- PurgeAppAPIKeys deletes all API keys for apps which were deleted before the given timestamp.
+DELETE
+FROM app_api_key
+WHERE app_id IN (SELECT app_id
+                 FROM app
+                 WHERE delete_timestamp < $1);
 
 -- name: DeleteAppAPIKey :execrows
 -- DeleteAppAPIKey deletes an app API key given the key.
@@ -269,4 +343,5 @@ select a.app_id,
 from app a
          inner join org o on o.org_id = a.org_id
          inner join app_api_key aak on a.app_id = aak.app_id
-where a.app_extl_id = $1;
\ No newline at end of file
+where a.app_extl_id = $1
+  and a.delete_timestamp is null;
\ No newline at end of file
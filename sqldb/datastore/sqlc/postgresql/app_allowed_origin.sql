@@ -0,0 +1,9 @@
+-- name: FindAppAllowedOrigins :many
+-- FindAppAllowedOrigins selects the allowed origins of every app
+-- which has not been deleted.
+SELECT aao.app_id,
+       aao.origin
+FROM app_allowed_origin aao
+         INNER JOIN app a on a.app_id = aao.app_id
+WHERE a.delete_timestamp IS NULL
+ORDER BY aao.app_id, aao.origin;
@@ -0,0 +1,8 @@
+-- name: FindAppRateLimit :one
+-- FindAppRateLimit selects the rate limits set for an app, if any.
+SELECT requests_per_minute,
+       burst,
+       user_requests_per_minute,
+       user_burst
+FROM app_rate_limit
+WHERE app_id = $1;
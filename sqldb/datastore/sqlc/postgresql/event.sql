@@ -0,0 +1,25 @@
+-- name: CreateEvents :batchexec
+INSERT INTO event_outbox (event_id, extl_id, event_type, org_id, subject_extl_id, payload, create_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: FindEventsAfter :many
+-- FindEventsAfter returns the events of an org of the given types written
+-- after the given sequence, in sequence order. Events written by a
+-- transaction which began before the oldest one still running are held
+-- back, as an event with a lower sequence may yet be committed.
+SELECT event_id, event_seq, extl_id, event_type, org_id, subject_extl_id, payload, create_timestamp
+FROM event_outbox
+WHERE org_id = sqlc.arg(org_id)
+  AND event_seq > sqlc.arg(after_seq)
+  AND event_type = ANY (sqlc.arg(event_types)::varchar[])
+  AND transaction_id < pg_snapshot_xmin(pg_current_snapshot())
+ORDER BY event_seq
+LIMIT sqlc.arg(row_limit);
+
+-- name: FindLatestEventSeq :one
+-- FindLatestEventSeq returns the sequence of the latest event of an org
+-- which can be read, or zero if there is none.
+SELECT coalesce(max(event_seq), 0)::bigint AS event_seq
+FROM event_outbox
+WHERE org_id = $1
+  AND transaction_id < pg_snapshot_xmin(pg_current_snapshot());
@@ -0,0 +1,21 @@
+-- name: FindGenres :many
+SELECT g.*
+FROM genre g
+ORDER BY g.genre_name;
+
+-- name: CreateMovieGenres :batchexec
+INSERT INTO movie_genre (movie_id, genre_id, create_app_id, create_user_id, create_timestamp,
+                         update_app_id, update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: DeleteMovieGenres :execrows
+DELETE
+FROM movie_genre
+WHERE movie_id = $1;
+
+-- name: FindMovieGenresByMovieIDs :many
+SELECT mg.movie_id, g.genre_id, g.genre_cd, g.genre_name
+FROM movie_genre mg
+         INNER JOIN genre g on g.genre_id = mg.genre_id
+WHERE mg.movie_id = ANY (sqlc.arg(movie_ids)::uuid[])
+ORDER BY mg.movie_id, g.genre_name;
@@ -0,0 +1,30 @@
+-- name: CreateChangeHistory :execrows
+INSERT INTO change_history (change_history_id, entity_type, entity_id, entity_extl_id, change_action,
+                            before_image, after_image, change_app_id, change_user_id, change_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: CreateChangeHistories :copyfrom
+INSERT INTO change_history (change_history_id, entity_type, entity_id, entity_extl_id, change_action,
+                            before_image, after_image, change_app_id, change_user_id, change_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: FindChangeHistoryByEntityExtlID :many
+SELECT ch.change_history_id,
+       ch.entity_type,
+       ch.entity_id,
+       ch.entity_extl_id,
+       ch.change_action,
+       ch.before_image,
+       ch.after_image,
+       ch.change_app_id,
+       a.app_extl_id change_app_extl_id,
+       ch.change_user_id,
+       u.first_name  change_user_first_name,
+       u.last_name   change_user_last_name,
+       ch.change_timestamp
+FROM change_history ch
+         LEFT JOIN app a on a.app_id = ch.change_app_id
+         LEFT JOIN users u on u.user_id = ch.change_user_id
+WHERE ch.entity_type = $1
+  AND ch.entity_extl_id = $2
+ORDER BY ch.change_seq;
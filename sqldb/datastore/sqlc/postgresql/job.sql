@@ -0,0 +1,85 @@
+-- name: ClaimJob :one
+-- ClaimJob marks the next job due to run as running and returns it.
+-- A running job whose lease has expired is claimed again, as its
+-- worker is assumed to have died, if it has attempts left. Jobs locked
+-- by other workers are skipped rather than waited on.
+UPDATE job
+SET job_state        = 'running',
+    attempts         = attempts + 1,
+    locked_at        = sqlc.arg(now),
+    update_timestamp = sqlc.arg(now)
+WHERE job_id = (SELECT j.job_id
+                FROM job j
+                WHERE (j.job_state = 'queued' AND j.run_at <= sqlc.arg(now))
+                   OR (j.job_state = 'running' AND j.locked_at < sqlc.arg(lease_expired_before)
+                    AND j.attempts < j.max_attempts)
+                ORDER BY j.run_at, j.create_timestamp
+                LIMIT 1 FOR UPDATE SKIP LOCKED)
+RETURNING *;
+
+-- name: CompleteJob :execrows
+-- CompleteJob marks a running job as succeeded. The attempt is checked
+-- so a worker whose lease expired cannot complete a job claimed again
+-- by another worker.
+UPDATE job
+SET job_state        = 'succeeded',
+    locked_at        = NULL,
+    update_timestamp = sqlc.arg(update_timestamp)
+WHERE job_id = sqlc.arg(job_id)
+  AND job_state = 'running'
+  AND attempts = sqlc.arg(attempts);
+
+-- name: CreateJob :execrows
+INSERT INTO job (job_id, extl_id, job_kind, payload, job_state, max_attempts, run_at,
+                 create_timestamp, update_timestamp)
+VALUES ($1, $2, $3, $4, 'queued', $5, $6, $7, $8);
+
+-- name: DeadLetterExpiredJobs :execrows
+-- DeadLetterExpiredJobs dead-letters running jobs whose lease expired
+-- on their last attempt, which ClaimJob would otherwise leave running.
+UPDATE job
+SET job_state        = 'dead',
+    last_error       = sqlc.arg(last_error),
+    locked_at        = NULL,
+    update_timestamp = sqlc.arg(now)
+WHERE job_id IN (SELECT j.job_id
+                 FROM job j
+                 WHERE j.job_state = 'running'
+                   AND j.locked_at < sqlc.arg(lease_expired_before)
+                   AND j.attempts >= j.max_attempts
+                 FOR UPDATE SKIP LOCKED);
+
+-- name: FailJob :execrows
+-- FailJob records a failed attempt of a running job. The job is either
+-- queued again to run later or dead-lettered.
+UPDATE job
+SET job_state        = sqlc.arg(job_state),
+    run_at           = sqlc.arg(run_at),
+    last_error       = sqlc.arg(last_error),
+    locked_at        = NULL,
+    update_timestamp = sqlc.arg(update_timestamp)
+WHERE job_id = sqlc.arg(job_id)
+  AND job_state = 'running'
+  AND attempts = sqlc.arg(attempts);
+
+-- name: FindJobByExternalID :one
+SELECT * FROM job
+WHERE extl_id = $1;
+
+-- name: FindJobs :many
+-- FindJobs lists the most recently created jobs, optionally only those
+-- in the given state.
+SELECT * FROM job
+WHERE (sqlc.narg(job_state)::varchar IS NULL OR job_state = sqlc.narg(job_state))
+ORDER BY create_timestamp DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: RetryJob :execrows
+-- RetryJob queues a dead job to run again with a fresh set of attempts.
+UPDATE job
+SET job_state        = 'queued',
+    attempts         = 0,
+    run_at           = sqlc.arg(now),
+    update_timestamp = sqlc.arg(now)
+WHERE job_id = sqlc.arg(job_id)
+  AND job_state = 'dead';
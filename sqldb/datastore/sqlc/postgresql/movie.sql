@@ -1,12 +1,19 @@
 -- name: CreateMovie :execresult
-INSERT INTO movie (movie_id, extl_id, title, rated, released, run_time, director, writer,
+INSERT INTO movie (movie_id, extl_id, org_id, title, rated, released, run_time, director, writer, rating_country,
                    create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14);
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16);
+
+-- name: CreateMovies :batchexec
+INSERT INTO movie (movie_id, extl_id, org_id, title, rated, released, run_time, director, writer, rating_country,
+                   create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16);
 
 -- name: FindMovieByExternalID :one
 SELECT m.*
 FROM movie m
-WHERE m.extl_id = $1;
+WHERE m.extl_id = $1
+  AND m.org_id = $2
+  AND m.delete_timestamp IS NULL;
 
 -- name: FindMovieByExternalIDWithAudit :one
 SELECT m.movie_id,
@@ -17,6 +24,9 @@ SELECT m.movie_id,
        m.run_time,
        m.director,
        m.writer,
+       m.rating_country,
+       m.review_count,
+       m.review_score_total,
        m.create_app_id,
        ca.org_id          create_app_org_id,
        ca.app_extl_id     create_app_extl_id,
@@ -40,12 +50,16 @@ FROM movie m
          INNER JOIN app ua on ua.app_id = m.update_app_id
          LEFT JOIN users cu on cu.user_id = m.create_user_id
          LEFT JOIN users uu on uu.user_id = m.update_user_id
-WHERE m.extl_id = $1;
+WHERE m.extl_id = $1
+  AND m.org_id = $2
+  AND m.delete_timestamp IS NULL;
 
 -- name: FindMoviesByTitle :many
 SELECT m.*
 FROM movie m
-WHERE m.title = $1;
+WHERE m.title = $1
+  AND m.org_id = $2
+  AND m.delete_timestamp IS NULL;
 
 -- name: FindMovies :many
 SELECT m.movie_id,
@@ -56,6 +70,49 @@ SELECT m.movie_id,
        m.run_time,
        m.director,
        m.writer,
+       m.rating_country,
+       m.review_count,
+       m.review_score_total,
+       m.create_app_id,
+       ca.org_id          create_app_org_id,
+       ca.app_extl_id     create_app_extl_id,
+       ca.app_name        create_app_name,
+       ca.app_description create_app_description,
+       m.create_user_id,
+       cu.first_name     create_user_first_name,
+       cu.last_name      create_user_last_name,
+       m.create_timestamp,
+       m.update_app_id,
+       ua.org_id          update_app_org_id,
+       ua.app_extl_id     update_app_extl_id,
+       ua.app_name        update_app_name,
+       ua.app_description update_app_description,
+       m.update_user_id,
+       uu.first_name     update_user_first_name,
+       uu.last_name      update_user_last_name,
+       m.update_timestamp
+FROM movie m
+         INNER JOIN app ca on ca.app_id = m.create_app_id
+         INNER JOIN app ua on ua.app_id = m.update_app_id
+         LEFT JOIN users cu on cu.user_id = m.create_user_id
+         LEFT JOIN users uu on uu.user_id = m.update_user_id
+WHERE m.org_id = $1
+  AND m.delete_timestamp IS NULL;
+
+-- name: FindMoviesByCredit :many
+-- FindMoviesByCredit finds the movies a person is credited on, matching
+-- the name case-insensitively. A null credit_role matches any role.
+SELECT m.movie_id,
+       m.extl_id,
+       m.title,
+       m.rated,
+       m.released,
+       m.run_time,
+       m.director,
+       m.writer,
+       m.rating_country,
+       m.review_count,
+       m.review_score_total,
        m.create_app_id,
        ca.org_id          create_app_org_id,
        ca.app_extl_id     create_app_extl_id,
@@ -78,7 +135,96 @@ FROM movie m
          INNER JOIN app ca on ca.app_id = m.create_app_id
          INNER JOIN app ua on ua.app_id = m.update_app_id
          LEFT JOIN users cu on cu.user_id = m.create_user_id
-         LEFT JOIN users uu on uu.user_id = m.update_user_id;
+         LEFT JOIN users uu on uu.user_id = m.update_user_id
+WHERE m.org_id = sqlc.arg(org_id)
+  AND m.delete_timestamp IS NULL
+  AND EXISTS(SELECT 1
+             FROM movie_credit mc
+             WHERE mc.movie_id = m.movie_id
+               AND lower(mc.person_name) = lower(sqlc.arg(person_name))
+               AND (sqlc.narg(credit_role)::varchar IS NULL OR mc.credit_role = sqlc.narg(credit_role)))
+ORDER BY m.released, m.title;
+
+-- name: FindMoviesByGenre :many
+-- FindMoviesByGenre finds the movies categorized in the given genre.
+SELECT m.movie_id,
+       m.extl_id,
+       m.title,
+       m.rated,
+       m.released,
+       m.run_time,
+       m.director,
+       m.writer,
+       m.rating_country,
+       m.review_count,
+       m.review_score_total,
+       m.create_app_id,
+       ca.org_id          create_app_org_id,
+       ca.app_extl_id     create_app_extl_id,
+       ca.app_name        create_app_name,
+       ca.app_description create_app_description,
+       m.create_user_id,
+       cu.first_name     create_user_first_name,
+       cu.last_name      create_user_last_name,
+       m.create_timestamp,
+       m.update_app_id,
+       ua.org_id          update_app_org_id,
+       ua.app_extl_id     update_app_extl_id,
+       ua.app_name        update_app_name,
+       ua.app_description update_app_description,
+       m.update_user_id,
+       uu.first_name     update_user_first_name,
+       uu.last_name      update_user_last_name,
+       m.update_timestamp
+FROM movie m
+         INNER JOIN app ca on ca.app_id = m.create_app_id
+         INNER JOIN app ua on ua.app_id = m.update_app_id
+         LEFT JOIN users cu on cu.user_id = m.create_user_id
+         LEFT JOIN users uu on uu.user_id = m.update_user_id
+WHERE m.org_id = sqlc.arg(org_id)
+  AND m.delete_timestamp IS NULL
+  AND EXISTS(SELECT 1
+             FROM movie_genre mg
+                      INNER JOIN genre g on g.genre_id = mg.genre_id
+             WHERE mg.movie_id = m.movie_id
+               AND g.genre_cd = sqlc.arg(genre_cd))
+ORDER BY m.title;
+
+-- name: SearchMovies :many
+-- SearchMovies ranks movies against a full-text query over title,
+-- director and writer. Trigram word similarity also matches partial
+-- words and misspellings, which full-text search alone would miss.
+-- The snippet is HTML: the title, director and writer are escaped
+-- before their matched words are wrapped in <mark> tags, each
+-- highlighted with the text search config it is indexed with.
+SELECT m.extl_id,
+       m.title,
+       m.rated,
+       m.released,
+       m.run_time,
+       m.director,
+       m.writer,
+       (ts_rank(movie_search_document(m.title, m.director, m.writer), q.query) +
+        greatest(word_similarity(sqlc.arg(search)::text, m.title),
+                 word_similarity(sqlc.arg(search)::text, coalesce(m.director, '')),
+                 word_similarity(sqlc.arg(search)::text, coalesce(m.writer, ''))))::real rank,
+       concat_ws(' / ',
+                 ts_headline('english', e.title, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+                 ts_headline('simple', e.director, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+                 ts_headline('simple', e.writer, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'))::text snippet
+FROM movie m,
+     websearch_to_tsquery('english', sqlc.arg(search)::text) q(query),
+     LATERAL (SELECT replace(replace(replace(replace(m.title, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'),
+                     replace(replace(replace(replace(m.director, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'),
+                     replace(replace(replace(replace(m.writer, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')) e(title, director, writer)
+WHERE m.org_id = sqlc.arg(org_id)
+  AND m.delete_timestamp IS NULL
+  AND (movie_search_document(m.title, m.director, m.writer) @@ q.query
+    OR sqlc.arg(search)::text <% m.title
+    OR sqlc.arg(search)::text <% m.director
+    OR sqlc.arg(search)::text <% m.writer)
+ORDER BY rank DESC, m.title
+LIMIT sqlc.arg(row_limit)::int;
 
 -- name: UpdateMovie :exec
 UPDATE movie
@@ -88,11 +234,56 @@ SET title            = $1,
     run_time         = $4,
     director         = $5,
     writer           = $6,
-    update_app_id    = $7,
-    update_user_id   = $8,
-    update_timestamp = $9
-WHERE movie_id = $10;
+    rating_country   = $7,
+    update_app_id    = $8,
+    update_user_id   = $9,
+    update_timestamp = $10
+WHERE movie_id = $11
+  AND org_id = $12;
+
+-- name: UpdateMovieReviewScore :execrows
+-- UpdateMovieReviewScore adjusts the review count and score total of a
+-- movie by the given amounts. Adjusting rather than recounting means
+-- concurrent reviews of the same movie are not lost.
+UPDATE movie
+SET review_count       = review_count + sqlc.arg(count_delta)::int,
+    review_score_total = review_score_total + sqlc.arg(score_delta)::bigint
+WHERE movie_id = sqlc.arg(movie_id);
+
+-- name: SoftDeleteMovie :execrows
+UPDATE movie
+SET delete_app_id    = $1,
+    delete_user_id   = $2,
+    delete_timestamp = $3
+WHERE movie_id = $4
+  AND org_id = $5
+  AND delete_timestamp IS NULL;
+
+-- name: FindDeletedMovieByExternalID :one
+SELECT m.*
+FROM movie m
+WHERE m.extl_id = $1
+  AND m.org_id = $2
+  AND m.delete_timestamp IS NOT NULL;
+
+-- name: RestoreMovie :execrows
+UPDATE movie
+SET delete_app_id    = NULL,
+    delete_user_id   = NULL,
+    delete_timestamp = NULL,
+    update_app_id    = $1,
+    update_user_id   = $2,
+    update_timestamp = $3
+WHERE movie_id = $4
+  AND org_id = $5
+  AND delete_timestamp IS NOT NULL;
 
--- name: DeleteMovie :execrows
-DELETE FROM movie
-WHERE movie_id = $1;
+-- name: PurgeMovies :execrows
+-- PurgeMovies physically removes movies which were deleted before the given timestamp,
+-- along with the movies of orgs which were deleted before it.
+DELETE
+FROM movie
+WHERE delete_timestamp < $1
+   OR org_id IN (SELECT org_id
+                 FROM org
+                 WHERE delete_timestamp < $1);
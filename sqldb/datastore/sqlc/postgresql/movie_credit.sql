@@ -0,0 +1,16 @@
+-- name: CreateMovieCredits :batchexec
+INSERT INTO movie_credit (movie_credit_id, movie_id, credit_role, person_name, character_name, billing_order,
+                          create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id,
+                          update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: DeleteMovieCredits :execrows
+DELETE
+FROM movie_credit
+WHERE movie_id = $1;
+
+-- name: FindMovieCreditsByMovieIDs :many
+SELECT mc.movie_id, mc.credit_role, mc.person_name, mc.character_name, mc.billing_order
+FROM movie_credit mc
+WHERE mc.movie_id = ANY (sqlc.arg(movie_ids)::uuid[])
+ORDER BY mc.movie_id, mc.credit_role, mc.billing_order;
@@ -0,0 +1,29 @@
+-- name: FindMoviePosterByMovieID :one
+SELECT * FROM movie_poster
+WHERE movie_id = $1;
+
+-- name: FindMoviePosterByMovieIDForUpdate :one
+-- FindMoviePosterByMovieIDForUpdate finds the poster of a movie and
+-- locks it until the end of the transaction, so the blob it replaces
+-- is known.
+SELECT * FROM movie_poster
+WHERE movie_id = $1
+FOR UPDATE;
+
+-- name: FindMoviePostersByMovieIDs :many
+SELECT * FROM movie_poster
+WHERE movie_id = ANY (sqlc.arg(movie_ids)::uuid[]);
+
+-- name: UpsertMoviePoster :execrows
+INSERT INTO movie_poster (movie_id, blob_key, content_type, byte_size, sha256, width, height,
+                          create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+ON CONFLICT (movie_id) DO UPDATE SET blob_key         = excluded.blob_key,
+                                     content_type     = excluded.content_type,
+                                     byte_size        = excluded.byte_size,
+                                     sha256           = excluded.sha256,
+                                     width            = excluded.width,
+                                     height           = excluded.height,
+                                     update_app_id    = excluded.update_app_id,
+                                     update_user_id   = excluded.update_user_id,
+                                     update_timestamp = excluded.update_timestamp;
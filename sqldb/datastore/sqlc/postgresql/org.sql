@@ -8,7 +8,8 @@ SELECT o.org_id,
        ok.org_kind_desc
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
-WHERE o.org_id = $1;
+WHERE o.org_id = $1
+  AND o.delete_timestamp IS NULL;
 
 -- name: FindOrgByIDWithAudit :one
 SELECT o.org_id,
@@ -42,7 +43,8 @@ FROM org o
          INNER JOIN app a2 on a2.app_id = o.update_app_id
          INNER JOIN users cu on cu.user_id = o.create_user_id
          INNER JOIN users uu on uu.user_id = o.update_user_id
-WHERE o.org_id = $1;
+WHERE o.org_id = $1
+  AND o.delete_timestamp IS NULL;
 
 -- name: FindOrgByExtlID :one
 SELECT o.org_id,
@@ -54,7 +56,8 @@ SELECT o.org_id,
        ok.org_kind_desc
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
-WHERE org_extl_id = $1;
+WHERE org_extl_id = $1
+  AND o.delete_timestamp IS NULL;
 
 -- name: FindOrgByExtlIDWithAudit :one
 SELECT o.org_id,
@@ -88,7 +91,8 @@ FROM org o
          INNER JOIN app a2 on a2.app_id = o.update_app_id
          INNER JOIN users cu on cu.user_id = o.create_user_id
          INNER JOIN users uu on uu.user_id = o.update_user_id
-WHERE o.org_extl_id = $1;
+WHERE o.org_extl_id = $1
+  AND o.delete_timestamp IS NULL;
 
 -- name: FindOrgByName :one
 SELECT o.org_id,
@@ -100,7 +104,8 @@ SELECT o.org_id,
        ok.org_kind_desc
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
-WHERE o.org_name = $1;
+WHERE o.org_name = $1
+  AND o.delete_timestamp IS NULL;
 
 -- name: FindOrgByNameWithAudit :one
 SELECT o.org_id,
@@ -134,7 +139,8 @@ FROM org o
          INNER JOIN app a2 on a2.app_id = o.update_app_id
          INNER JOIN users cu on cu.user_id = o.create_user_id
          INNER JOIN users uu on uu.user_id = o.update_user_id
-WHERE o.org_name = $1;
+WHERE o.org_name = $1
+  AND o.delete_timestamp IS NULL;
 
 -- name: FindOrgs :many
 SELECT o.org_id,
@@ -146,6 +152,7 @@ SELECT o.org_id,
        ok.org_kind_desc
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+WHERE o.delete_timestamp IS NULL
 ORDER BY org_name;
 
 -- name: FindOrgsWithAudit :many
@@ -179,7 +186,8 @@ FROM org o
          INNER JOIN app a on a.app_id = o.create_app_id
          INNER JOIN app a2 on a2.app_id = o.update_app_id
          INNER JOIN users cu on cu.user_id = o.create_user_id
-         INNER JOIN users uu on uu.user_id = o.update_user_id;
+         INNER JOIN users uu on uu.user_id = o.update_user_id
+WHERE o.delete_timestamp IS NULL;
 
 -- name: FindOrgsByKindExtlID :many
 SELECT o.org_id,
@@ -190,7 +198,8 @@ SELECT o.org_id,
        ok.org_kind_desc
 FROM org o
          INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
-WHERE ok.org_kind_extl_id = $1;
+WHERE ok.org_kind_extl_id = $1
+  AND o.delete_timestamp IS NULL;
 
 
 -- name: CreateOrg :execrows
@@ -207,10 +216,49 @@ SET org_name         = $1,
     update_timestamp = $5
 WHERE org_id = $6;
 
--- name: DeleteOrg :execrows
-DELETE
-FROM org
-WHERE org_id = $1;
+-- name: SoftDeleteOrg :execrows
+UPDATE org
+SET delete_app_id    = $1,
+    delete_user_id   = $2,
+    delete_timestamp = $3
+WHERE org_id = $4
+  AND delete_timestamp IS NULL;
+
+-- name: FindDeletedOrgByExtlID :one
+SELECT o.org_id,
+       o.org_extl_id,
+       o.org_name,
+       o.org_description,
+       o.org_kind_id,
+       ok.org_kind_extl_id,
+       ok.org_kind_desc,
+       o.delete_timestamp
+FROM org o
+         INNER JOIN org_kind ok on ok.org_kind_id = o.org_kind_id
+WHERE o.org_extl_id = $1
+  AND o.delete_timestamp IS NOT NULL
+  AND o.purge_timestamp IS NULL;
+
+-- name: RestoreOrg :execrows
+UPDATE org
+SET delete_app_id    = NULL,
+    delete_user_id   = NULL,
+    delete_timestamp = NULL,
+    update_app_id    = $1,
+    update_user_id   = $2,
+    update_timestamp = $3
+WHERE org_id = $4
+  AND delete_timestamp IS NOT NULL;
+
+-- name: PurgeOrgs :execrows
+-- PurgeOrgs scrubs orgs which were deleted before the given timestamp and marks them as purged.
+-- Purged orgs are not removed, as the users, roles and apps of the org refer to them.
+UPDATE org
+SET org_name        = 'purged-' || org_extl_id,
+    org_description = 'purged',
+    purge_timestamp = $2
+WHERE delete_timestamp < $1
+  AND purge_timestamp IS NULL;
 
 -- ---------------------------------------------------------------------------------------------------------------------
 -- Org Kind
@@ -0,0 +1,4 @@
+-- name: FindRatings :many
+SELECT r.*
+FROM rating r
+ORDER BY r.country_cd, r.display_order;
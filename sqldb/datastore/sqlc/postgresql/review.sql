@@ -0,0 +1,41 @@
+-- name: CreateReview :execrows
+INSERT INTO review (review_id, extl_id, movie_id, user_id, score, body,
+                    create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: FindReviewByExternalIDForUpdate :one
+-- FindReviewByExternalIDForUpdate finds a review of the movie and locks
+-- it until the end of the transaction.
+SELECT r.*,
+       ca.app_extl_id create_app_extl_id
+FROM review r
+         INNER JOIN app ca on ca.app_id = r.create_app_id
+WHERE r.extl_id = $1
+  AND r.movie_id = $2
+FOR UPDATE OF r;
+
+-- name: FindReviewsByMovieID :many
+SELECT r.review_id,
+       r.extl_id,
+       r.score,
+       r.body,
+       u.user_extl_id,
+       u.first_name       user_first_name,
+       u.last_name        user_last_name,
+       ca.app_extl_id     create_app_extl_id,
+       r.create_timestamp,
+       r.update_timestamp
+FROM review r
+         INNER JOIN users u on u.user_id = r.user_id
+         INNER JOIN app ca on ca.app_id = r.create_app_id
+WHERE r.movie_id = $1
+ORDER BY r.create_timestamp, r.extl_id;
+
+-- name: UpdateReview :execrows
+UPDATE review
+SET score            = $1,
+    body             = $2,
+    update_app_id    = $3,
+    update_user_id   = $4,
+    update_timestamp = $5
+WHERE review_id = $6;
@@ -0,0 +1,5 @@
+-- name: FindSchemaVersion :one
+-- FindSchemaVersion returns the number of the latest up migration
+-- file run against the database.
+SELECT version
+FROM schema_version;
@@ -0,0 +1,19 @@
+-- name: CreateSecurityEvent :execrows
+INSERT INTO security_event (security_event_id, event_action, event_outcome, actor_extl_id, app_extl_id, org_id,
+                            org_extl_id, target, detail, request_id, event_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+
+-- name: FindSecurityEvents :many
+-- FindSecurityEvents lists security events, newest first, optionally
+-- filtered. Events before a sequence number are listed to page back.
+SELECT * FROM security_event
+WHERE (sqlc.narg(event_action)::varchar IS NULL OR event_action = sqlc.narg(event_action))
+  AND (sqlc.narg(event_outcome)::varchar IS NULL OR event_outcome = sqlc.narg(event_outcome))
+  AND (sqlc.narg(actor_extl_id)::varchar IS NULL OR actor_extl_id = sqlc.narg(actor_extl_id))
+  AND (sqlc.narg(app_extl_id)::varchar IS NULL OR app_extl_id = sqlc.narg(app_extl_id))
+  AND (sqlc.narg(org_extl_id)::varchar IS NULL OR org_extl_id = sqlc.narg(org_extl_id))
+  AND (sqlc.narg(since)::timestamptz IS NULL OR event_timestamp >= sqlc.narg(since))
+  AND (sqlc.narg(until)::timestamptz IS NULL OR event_timestamp < sqlc.narg(until))
+  AND (sqlc.narg(before_seq)::bigint IS NULL OR event_seq < sqlc.narg(before_seq))
+ORDER BY event_seq DESC
+LIMIT sqlc.arg(row_limit);
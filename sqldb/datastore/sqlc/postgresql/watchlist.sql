@@ -0,0 +1,88 @@
+-- name: CreateWatchlist :execrows
+INSERT INTO watchlist (watchlist_id, extl_id, org_id, user_id, watchlist_name, shared,
+                       create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: FindWatchlistByExternalID :one
+SELECT w.watchlist_id,
+       w.extl_id,
+       w.user_id,
+       w.watchlist_name,
+       w.shared,
+       u.user_extl_id,
+       u.first_name     user_first_name,
+       u.last_name      user_last_name,
+       w.create_timestamp,
+       w.update_timestamp
+FROM watchlist w
+         INNER JOIN users u on u.user_id = w.user_id
+WHERE w.extl_id = $1
+  AND w.org_id = $2;
+
+-- name: FindWatchlistsByUserID :many
+SELECT w.watchlist_id,
+       w.extl_id,
+       w.user_id,
+       w.watchlist_name,
+       w.shared,
+       u.user_extl_id,
+       u.first_name     user_first_name,
+       u.last_name      user_last_name,
+       w.create_timestamp,
+       w.update_timestamp
+FROM watchlist w
+         INNER JOIN users u on u.user_id = w.user_id
+WHERE w.user_id = $1
+  AND w.org_id = $2
+ORDER BY w.watchlist_name;
+
+-- name: UpdateWatchlist :execrows
+UPDATE watchlist
+SET watchlist_name   = $1,
+    shared           = $2,
+    update_app_id    = $3,
+    update_user_id   = $4,
+    update_timestamp = $5
+WHERE watchlist_id = $6;
+
+-- name: DeleteWatchlist :execrows
+DELETE
+FROM watchlist
+WHERE watchlist_id = $1;
+
+-- name: CreateWatchlistEntry :execrows
+INSERT INTO watchlist_entry (watchlist_id, movie_id, position,
+                             create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: DeleteWatchlistEntry :execrows
+DELETE
+FROM watchlist_entry
+WHERE watchlist_id = $1
+  AND movie_id = $2;
+
+-- name: FindWatchlistEntriesByWatchlistIDs :many
+-- FindWatchlistEntriesByWatchlistIDs finds the movies on the given
+-- watchlists in position order. Deleted movies are left out.
+SELECT we.watchlist_id,
+       we.movie_id,
+       m.extl_id,
+       m.title,
+       we.position
+FROM watchlist_entry we
+         INNER JOIN movie m on m.movie_id = we.movie_id
+WHERE we.watchlist_id = ANY (sqlc.arg(watchlist_ids)::uuid[])
+  AND m.delete_timestamp IS NULL
+ORDER BY we.watchlist_id, we.position, m.title;
+
+-- name: ReorderWatchlistEntries :execrows
+-- ReorderWatchlistEntries moves each of the given movies to its
+-- position in the array, starting at 1.
+UPDATE watchlist_entry we
+SET position         = o.position,
+    update_app_id    = sqlc.arg(update_app_id),
+    update_user_id   = sqlc.arg(update_user_id),
+    update_timestamp = sqlc.arg(update_timestamp)
+FROM unnest(sqlc.arg(movie_ids)::uuid[]) WITH ORDINALITY AS o(movie_id, position)
+WHERE we.watchlist_id = sqlc.arg(watchlist_id)
+  AND we.movie_id = o.movie_id;
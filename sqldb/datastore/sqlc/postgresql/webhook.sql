@@ -0,0 +1,91 @@
+-- name: CreateWebhook :execrows
+INSERT INTO webhook (webhook_id, extl_id, org_id, app_id, endpoint_url, secret_ciphertext, event_types, active,
+                     create_app_id, create_user_id, create_timestamp, update_app_id, update_user_id, update_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14);
+
+-- name: FindWebhookByExternalID :one
+SELECT * FROM webhook
+WHERE extl_id = $1
+  AND app_id = $2;
+
+-- name: FindWebhooksByAppID :many
+SELECT * FROM webhook
+WHERE app_id = $1
+ORDER BY create_timestamp;
+
+-- name: FindWebhooksForEvent :many
+-- FindWebhooksForEvent finds the active webhooks of an org which are
+-- sent events of the given type. Webhooks of deleted apps are skipped.
+SELECT w.* FROM webhook w
+         INNER JOIN app a on a.app_id = w.app_id
+WHERE w.org_id = sqlc.arg(org_id)
+  AND w.active
+  AND sqlc.arg(event_type)::varchar = ANY (w.event_types)
+  AND a.delete_timestamp IS NULL;
+
+-- name: DeleteWebhook :execrows
+DELETE
+FROM webhook
+WHERE webhook_id = $1;
+
+-- name: CreateWebhookDelivery :execrows
+INSERT INTO webhook_delivery (delivery_id, extl_id, webhook_id, event_id, delivery_state, create_timestamp, update_timestamp)
+VALUES ($1, $2, $3, $4, 'pending', $5, $6);
+
+-- name: FindWebhookDeliveryForSend :one
+-- FindWebhookDeliveryForSend finds a delivery along with the webhook
+-- and event needed to send it.
+SELECT d.delivery_id,
+       d.extl_id,
+       d.delivery_state,
+       w.endpoint_url,
+       w.secret_ciphertext,
+       w.active,
+       e.extl_id          event_extl_id,
+       e.event_type,
+       e.subject_extl_id,
+       e.payload,
+       e.create_timestamp event_timestamp
+FROM webhook_delivery d
+         INNER JOIN webhook w on w.webhook_id = d.webhook_id
+         INNER JOIN event_outbox e on e.event_id = d.event_id
+WHERE d.extl_id = $1;
+
+-- name: RecordWebhookDeliveryAttempt :one
+-- RecordWebhookDeliveryAttempt counts an attempt against a delivery
+-- and returns the number of the attempt.
+UPDATE webhook_delivery
+SET delivery_state   = $1,
+    attempts         = attempts + 1,
+    last_status_code = $2,
+    last_error       = $3,
+    update_timestamp = $4
+WHERE delivery_id = $5
+RETURNING attempts;
+
+-- name: CreateWebhookDeliveryAttempt :execrows
+INSERT INTO webhook_delivery_attempt (delivery_id, attempt, status_code, error_message, duration_ms, attempt_timestamp)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: FindWebhookDeliveries :many
+-- FindWebhookDeliveries lists the most recent deliveries to a webhook.
+SELECT d.delivery_id,
+       d.extl_id,
+       e.extl_id event_extl_id,
+       e.event_type,
+       d.delivery_state,
+       d.attempts,
+       d.last_status_code,
+       d.last_error,
+       d.create_timestamp,
+       d.update_timestamp
+FROM webhook_delivery d
+         INNER JOIN event_outbox e on e.event_id = d.event_id
+WHERE d.webhook_id = sqlc.arg(webhook_id)
+ORDER BY d.create_timestamp DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: FindWebhookDeliveryAttempts :many
+SELECT * FROM webhook_delivery_attempt
+WHERE delivery_id = ANY (sqlc.arg(delivery_ids)::uuid[])
+ORDER BY delivery_id, attempt;
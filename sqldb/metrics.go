@@ -0,0 +1,59 @@
+package sqldb
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// PoolCollector is a prometheus.Collector which reports the stats of
+// the DB pool each time it is scraped
+type PoolCollector struct {
+	db *DB
+
+	acquiredConns    *prometheus.Desc
+	idleConns        *prometheus.Desc
+	totalConns       *prometheus.Desc
+	maxConns         *prometheus.Desc
+	acquireCount     *prometheus.Desc
+	emptyAcquires    *prometheus.Desc
+	acquireWaitTotal *prometheus.Desc
+}
+
+// NewPoolCollector initializes a PoolCollector for the pool of db
+func NewPoolCollector(db *DB) *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("diygoapi", "db_pool", name), help, nil, nil)
+	}
+
+	return &PoolCollector{
+		db:               db,
+		acquiredConns:    desc("acquired_connections", "Number of connections currently acquired from the pool."),
+		idleConns:        desc("idle_connections", "Number of idle connections in the pool."),
+		totalConns:       desc("total_connections", "Total number of connections in the pool, including those being opened."),
+		maxConns:         desc("max_connections", "Maximum size of the pool."),
+		acquireCount:     desc("acquires_total", "Number of successful connection acquires from the pool."),
+		emptyAcquires:    desc("empty_acquires_total", "Number of acquires which had to wait for a connection as the pool was empty."),
+		acquireWaitTotal: desc("acquire_wait_seconds_total", "Total time spent acquiring connections from the pool."),
+	}
+}
+
+// Describe sends the descriptors of the pool metrics to ch
+func (pc *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pc.acquiredConns
+	ch <- pc.idleConns
+	ch <- pc.totalConns
+	ch <- pc.maxConns
+	ch <- pc.acquireCount
+	ch <- pc.emptyAcquires
+	ch <- pc.acquireWaitTotal
+}
+
+// Collect sends the current pool stats to ch
+func (pc *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := pc.db.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(pc.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(pc.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(pc.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(pc.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(pc.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(pc.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(pc.acquireWaitTotal, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
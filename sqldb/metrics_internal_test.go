@@ -0,0 +1,34 @@
+package sqldb
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPoolCollector(t *testing.T) {
+	c := qt.New(t)
+
+	// the pool does not connect until a connection is acquired, so
+	// no database is needed to report its stats
+	cfg, err := pgxpool.ParseConfig("postgres://localhost:1/none?pool_max_conns=7")
+	c.Assert(err, qt.IsNil)
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	c.Assert(err, qt.IsNil)
+	c.Cleanup(pool.Close)
+
+	pc := NewPoolCollector(NewDB(pool))
+	c.Assert(testutil.CollectAndCount(pc), qt.Equals, 7)
+
+	want := `
+# HELP diygoapi_db_pool_max_connections Maximum size of the pool.
+# TYPE diygoapi_db_pool_max_connections gauge
+diygoapi_db_pool_max_connections 7
+`
+	err = testutil.CollectAndCompare(pc, strings.NewReader(want), "diygoapi_db_pool_max_connections")
+	c.Assert(err, qt.IsNil)
+}
@@ -13,10 +13,13 @@ import (
 
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
 
+	"github.com/gilcrest/diygoapi"
 	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/sqldb/datastore"
 )
 
 const (
@@ -34,6 +37,11 @@ const (
 	DBSearchPathEnv string = "DB_SEARCH_PATH"
 )
 
+// SchemaVersion is the number of the latest up migration file in
+// scripts/db/migrations/up, which the database must be at for the
+// server to be ready. Bump it when adding a migration.
+const SchemaVersion int32 = 31
+
 // PostgreSQLDSN is a PostgreSQL datasource name
 type PostgreSQLDSN struct {
 	Host       string
@@ -127,9 +135,16 @@ func NewPgxPool(ctx context.Context, lgr zerolog.Logger, dsn PostgreSQLDSN) (dbp
 
 	f := func() {}
 
-	// Open the postgres database using the pgxpool driver (pq)
-	// func Open(driverName, dataSourceName string) (*DB, error)
-	dbpool, err = pgxpool.New(ctx, dsn.KeywordValueConnectionString())
+	var cfg *pgxpool.Config
+	cfg, err = pgxpool.ParseConfig(dsn.KeywordValueConnectionString())
+	if err != nil {
+		return nil, f, errs.E(op, errs.Database, err)
+	}
+	// trace each query run on the pool
+	cfg.ConnConfig.Tracer = newQueryTracer()
+
+	// Open the postgres database using the pgxpool driver
+	dbpool, err = pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		return nil, f, errs.E(op, errs.Database, err)
 	}
@@ -164,6 +179,24 @@ func (db *DB) Ping(ctx context.Context) error {
 	return err
 }
 
+// CheckSchemaVersion returns an error unless the migrations run against
+// the database are at SchemaVersion.
+func (db *DB) CheckSchemaVersion(ctx context.Context) error {
+	const op errs.Op = "sqldb/DB.CheckSchemaVersion"
+
+	v, err := datastore.New(db.pool).FindSchemaVersion(ctx)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return errs.E(op, errs.Database, "schema version not found, migrations have not been run")
+	case err != nil:
+		return errs.E(op, errs.Database, err)
+	case v != SchemaVersion:
+		return errs.E(op, errs.Database, fmt.Sprintf("schema version is %d, want %d", v, SchemaVersion))
+	}
+
+	return nil
+}
+
 // ValidatePool pings the database and logs the current user and database.
 // ValidatePool is used for logging db status on startup.
 func (db *DB) ValidatePool(ctx context.Context, log zerolog.Logger) error {
@@ -211,7 +244,10 @@ func (db *DB) ValidatePool(ctx context.Context, log zerolog.Logger) error {
 }
 
 // BeginTx returns an acquired transaction from the db pool and
-// adds app specific error handling
+// adds app specific error handling.
+//
+// The row level security settings for the transaction are set from
+// the App in the context, if any (see setRowSecurity).
 func (db *DB) BeginTx(ctx context.Context) (pgx.Tx, error) {
 	const op errs.Op = "sqldb/DB.BeginTx"
 
@@ -224,9 +260,57 @@ func (db *DB) BeginTx(ctx context.Context) (pgx.Tx, error) {
 		return nil, errs.E(op, errs.Database, err)
 	}
 
+	err = setRowSecurity(ctx, tx)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, errs.E(op, errs.Database, err)
+	}
+
 	return tx, nil
 }
 
+// setRowSecurity sets the transaction local settings read by the row
+// level security policies on org owned tables:
+//
+//   - app.org_id is the ID of the org of the App in the context.
+//     Policies only expose rows owned by this org.
+//   - app.rls_bypass is "on" when the App belongs to the Principal
+//     org, or the context was marked with
+//     diygoapi.NewContextWithRowSecurityBypass. Policies expose all
+//     rows when it is on.
+//
+// When the context has neither, nothing is set and the policies
+// expose no rows, so a query which is missing its org filter fails
+// closed rather than returning another org's data. set_config is
+// used rather than SET LOCAL as SET does not accept bind parameters.
+func setRowSecurity(ctx context.Context, tx pgx.Tx) error {
+	var (
+		orgID  pgtype.UUID
+		bypass = diygoapi.RowSecurityBypassFromContext(ctx)
+	)
+
+	if a, err := diygoapi.AppFromContext(ctx); err == nil && a != nil && a.Org != nil {
+		orgID = a.Org.ID.PgxUUID()
+		if a.Org.Kind != nil && a.Org.Kind.ExternalID == diygoapi.PrincipalOrgKind {
+			bypass = true
+		}
+	}
+
+	if !orgID.Valid && !bypass {
+		return nil
+	}
+
+	rlsBypass := "off"
+	if bypass {
+		rlsBypass = "on"
+	}
+
+	_, err := tx.Exec(ctx, `select set_config('app.org_id', coalesce($1::uuid::text, ''), true),
+       set_config('app.rls_bypass', $2::text, true)`, orgID, rlsBypass)
+
+	return err
+}
+
 // RollbackTx is a wrapper for sql.Tx.Rollback in order to expose from
 // the Datastore interface. Proper error handling is also considered.
 func (db *DB) RollbackTx(ctx context.Context, tx pgx.Tx, err error) error {
@@ -276,3 +360,47 @@ func (db *DB) CommitTx(ctx context.Context, tx pgx.Tx) error {
 
 	return nil
 }
+
+// Listen listens for notifications on a PostgreSQL channel using a
+// connection of its own, calling notify with the payload of each.
+// It blocks until the context is done, returning nil, or the
+// connection fails.
+//
+// The connection is closed when Listen returns rather than released
+// back to the pool, as it would otherwise go on listening.
+func (db *DB) Listen(ctx context.Context, channel string, notify func(payload string)) error {
+	const op errs.Op = "sqldb/DB.Listen"
+
+	if db.pool == nil {
+		return errs.E(op, errs.Database, "db pool cannot be nil")
+	}
+
+	pc, err := db.pool.Acquire(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return errs.E(op, errs.Database, err)
+	}
+	conn := pc.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	_, err = conn.Exec(ctx, "listen "+pgx.Identifier{channel}.Sanitize())
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return errs.E(op, errs.Database, err)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errs.E(op, errs.Database, err)
+		}
+		notify(n.Payload)
+	}
+}
@@ -0,0 +1,113 @@
+package sqldb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryTracer is a pgx tracer which starts a span for each query,
+// batch and copy run on the pool. Spans are named by the sqlc query
+// name, e.g. FindMovieByExternalID, or else the SQL command.
+type queryTracer struct {
+	tracer trace.Tracer
+}
+
+func newQueryTracer() queryTracer {
+	return queryTracer{tracer: otel.Tracer("github.com/gilcrest/diygoapi/sqldb")}
+}
+
+// TraceQueryStart implements pgx.QueryTracer
+func (qt queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = qt.tracer.Start(ctx, querySpanName(data.SQL),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "postgresql"),
+			attribute.String("db.query.text", data.SQL)))
+
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer
+func (qt queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	endQuerySpan(trace.SpanFromContext(ctx), data.CommandTag.RowsAffected(), data.Err)
+}
+
+// TraceBatchStart implements pgx.BatchTracer
+func (qt queryTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	ctx, _ = qt.tracer.Start(ctx, "batch",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "postgresql"),
+			attribute.Int("db.operation.batch.size", data.Batch.Len())))
+
+	return ctx
+}
+
+// TraceBatchQuery implements pgx.BatchTracer. Queries of a batch are
+// sent together, so each is an event of the batch span rather than a
+// span of its own.
+func (qt queryTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	attrs := []attribute.KeyValue{attribute.String("db.query.text", data.SQL)}
+	if data.Err != nil {
+		attrs = append(attrs, attribute.String("error", data.Err.Error()))
+	}
+	trace.SpanFromContext(ctx).AddEvent(querySpanName(data.SQL), trace.WithAttributes(attrs...))
+}
+
+// TraceBatchEnd implements pgx.BatchTracer
+func (qt queryTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	endQuerySpan(trace.SpanFromContext(ctx), -1, data.Err)
+}
+
+// TraceCopyFromStart implements pgx.CopyFromTracer
+func (qt queryTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	ctx, _ = qt.tracer.Start(ctx, "copy "+data.TableName.Sanitize(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "postgresql"),
+			attribute.String("db.collection.name", data.TableName.Sanitize())))
+
+	return ctx
+}
+
+// TraceCopyFromEnd implements pgx.CopyFromTracer
+func (qt queryTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	endQuerySpan(trace.SpanFromContext(ctx), data.CommandTag.RowsAffected(), data.Err)
+}
+
+// endQuerySpan records the rows affected, if known, and error, if
+// any, on the span and ends it
+func endQuerySpan(span trace.Span, rows int64, err error) {
+	if rows >= 0 {
+		span.SetAttributes(attribute.Int64("db.response.rows_affected", rows))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// querySpanName returns the sqlc name of the query, taken from its
+// "-- name: FindMovieByExternalID :one" comment, or else the SQL
+// command, e.g. BEGIN
+func querySpanName(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if name, ok := strings.CutPrefix(sql, "-- name: "); ok {
+		name, _, _ = strings.Cut(name, " ")
+		return name
+	}
+
+	command, _, _ := strings.Cut(sql, " ")
+	command, _, _ = strings.Cut(command, "\n")
+	if command == "" {
+		return "query"
+	}
+	return strings.ToUpper(command)
+}
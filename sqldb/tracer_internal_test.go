@@ -0,0 +1,54 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func Test_querySpanName(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{"sqlc", "-- name: FindMovieByExternalID :one\nSELECT * FROM movie WHERE extl_id = $1", "FindMovieByExternalID"},
+		{"leading space", "\n  -- name: DeleteMovie :execrows\nDELETE FROM movie", "DeleteMovie"},
+		{"command", "begin", "BEGIN"},
+		{"multiline command", "select\n1", "SELECT"},
+		{"empty", "", "query"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.New(t).Assert(querySpanName(tt.sql), qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestQueryTracer(t *testing.T) {
+	c := qt.New(t)
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	tr := queryTracer{tracer: tp.Tracer("test")}
+
+	ctx := tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "-- name: CreateMovie :exec\nINSERT INTO movie"})
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("INSERT 0 1")})
+
+	ctx = tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "commit"})
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("conn closed")})
+
+	spans := sr.Ended()
+	c.Assert(spans, qt.HasLen, 2)
+	c.Assert(spans[0].Name(), qt.Equals, "CreateMovie")
+	c.Assert(spans[0].Status().Code, qt.Equals, codes.Unset)
+	c.Assert(spans[1].Name(), qt.Equals, "COMMIT")
+	c.Assert(spans[1].Status().Code, qt.Equals, codes.Error)
+}
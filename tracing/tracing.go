@@ -0,0 +1,110 @@
+// Package tracing has helpers to set up OpenTelemetry tracing
+//
+//	https://opentelemetry.io/docs/languages/go/
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gilcrest/diygoapi/errs"
+)
+
+// ServiceName is the name spans are reported under
+const ServiceName = "diygoapi"
+
+// Exporters spans can be sent to
+const (
+	// NoExporter records no spans, though trace context is still
+	// propagated
+	NoExporter = "none"
+	// StdoutExporter writes spans as JSON, for local use
+	StdoutExporter = "stdout"
+	// OTLPExporter sends spans to an OpenTelemetry collector over HTTP
+	OTLPExporter = "otlp"
+)
+
+// Config configures the TracerProvider
+type Config struct {
+	// Exporter is one of NoExporter, StdoutExporter or OTLPExporter
+	Exporter string
+
+	// OTLPEndpoint is the URL of the collector spans are sent to by
+	// the OTLPExporter, e.g. http://localhost:4318. If empty, the
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variable or the
+	// OpenTelemetry default is used.
+	OTLPEndpoint string
+
+	// SampleRatio is the fraction of traces started here which are
+	// sampled, from 0 to 1. Traces started by a caller follow the
+	// caller's sampling decision.
+	SampleRatio float64
+
+	// Stdout is where the StdoutExporter writes spans
+	Stdout io.Writer
+}
+
+// Start sets the global TracerProvider and W3C trace context
+// propagator. The returned function flushes any spans not yet exported
+// and stops the TracerProvider.
+func Start(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	const op errs.Op = "tracing/Start"
+
+	// trace context is propagated whether or not spans are exported,
+	// so traces are not broken by passing through this service
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case NoExporter, "":
+		return func(context.Context) error { return nil }, nil
+	case StdoutExporter:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(cfg.Stdout))
+	case OTLPExporter:
+		var opts []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, errs.E(op, errs.Validation, "unknown trace exporter: "+cfg.Exporter)
+	}
+	if err != nil {
+		return nil, errs.E(op, errs.Internal, err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// EndSpan records err, if any, on the span and ends it. It is meant to
+// be deferred with a pointer to a named error result.
+func EndSpan(span trace.Span, err *error) {
+	if err != nil && *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+		var e *errs.Error
+		if errors.As(*err, &e) {
+			span.SetAttributes(attribute.String("error.kind", e.Kind.String()))
+		}
+	}
+	span.End()
+}
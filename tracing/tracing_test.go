@@ -0,0 +1,72 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/tracing"
+)
+
+func TestStart(t *testing.T) {
+	c := qt.New(t)
+	ctx := context.Background()
+
+	c.Run("none", func(c *qt.C) {
+		shutdown, err := tracing.Start(ctx, tracing.Config{Exporter: tracing.NoExporter})
+		c.Assert(err, qt.IsNil)
+		c.Assert(shutdown(ctx), qt.IsNil)
+		c.Assert(otel.GetTextMapPropagator().Fields(), qt.Contains, "traceparent")
+	})
+
+	c.Run("stdout", func(c *qt.C) {
+		var buf bytes.Buffer
+		shutdown, err := tracing.Start(ctx, tracing.Config{Exporter: tracing.StdoutExporter, SampleRatio: 1, Stdout: &buf})
+		c.Assert(err, qt.IsNil)
+
+		_, span := otel.Tracer("test").Start(ctx, "tested")
+		span.End()
+
+		// shutdown flushes the span to the exporter
+		c.Assert(shutdown(ctx), qt.IsNil)
+		c.Assert(buf.String(), qt.Contains, `"Name":"tested"`)
+		c.Assert(buf.String(), qt.Contains, tracing.ServiceName)
+	})
+
+	c.Run("unknown", func(c *qt.C) {
+		_, err := tracing.Start(ctx, tracing.Config{Exporter: "carrier-pigeon"})
+		wantErr := errs.E(errs.Validation, "unknown trace exporter: carrier-pigeon")
+		c.Assert(err, qt.CmpEquals(cmp.Comparer(errs.Match)), wantErr)
+	})
+}
+
+func TestEndSpan(t *testing.T) {
+	c := qt.New(t)
+
+	sr := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)).Tracer("test")
+
+	var err error
+	_, span := tracer.Start(context.Background(), "ok")
+	tracing.EndSpan(span, &err)
+
+	err = errs.E(errs.NotExist, errors.New("no rows"))
+	_, span = tracer.Start(context.Background(), "failed")
+	tracing.EndSpan(span, &err)
+
+	spans := sr.Ended()
+	c.Assert(spans, qt.HasLen, 2)
+	c.Assert(spans[0].Status().Code, qt.Equals, codes.Unset)
+	c.Assert(spans[1].Status().Code, qt.Equals, codes.Error)
+	c.Assert(spans[1].Attributes(), qt.Contains, attribute.String("error.kind", errs.NotExist.String()))
+}
@@ -0,0 +1,140 @@
+package diygoapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+// MaxWatchlistNameLength is the maximum length of a watchlist name
+const MaxWatchlistNameLength = 200
+
+// WatchlistServicer is used to manage the personal watchlists of the
+// authenticated user and to read the watchlists shared within their org.
+type WatchlistServicer interface {
+	Create(ctx context.Context, r *CreateWatchlistRequest, adt Audit) (*WatchlistResponse, error)
+	Update(ctx context.Context, r *UpdateWatchlistRequest, adt Audit) (*WatchlistResponse, error)
+	Delete(ctx context.Context, extlID string, adt Audit) (DeleteResponse, error)
+	AddMovie(ctx context.Context, r *AddWatchlistMovieRequest, adt Audit) (*WatchlistResponse, error)
+	RemoveMovie(ctx context.Context, extlID, movieExtlID string, adt Audit) (*WatchlistResponse, error)
+	Reorder(ctx context.Context, r *ReorderWatchlistRequest, adt Audit) (*WatchlistResponse, error)
+	FindMine(ctx context.Context, adt Audit) ([]*WatchlistResponse, error)
+	FindByExternalID(ctx context.Context, extlID string, adt Audit) (*WatchlistResponse, error)
+}
+
+// Watchlist is a personal list of movies kept by its Owner. Only the
+// owner can change a watchlist. A Shared watchlist can also be read by
+// the other users of the owner's org.
+type Watchlist struct {
+	ID         uuid.UUID
+	ExternalID secure.Identifier
+	Owner      *User
+	Name       string
+	Shared     bool
+	Entries    []WatchlistEntry
+}
+
+// WatchlistEntry is a movie on a watchlist. Position starts at 1.
+type WatchlistEntry struct {
+	MovieID         uuid.UUID
+	MovieExternalID string
+	Title           string
+	Position        int
+}
+
+// IsValid performs validation of the struct
+func (w *Watchlist) IsValid() error {
+	const op errs.Op = "diygoapi/Watchlist.IsValid"
+
+	switch {
+	case w.ExternalID.String() == "":
+		return errs.E(op, errs.Validation, errs.Parameter("extlID"), errs.MissingField("extlID"))
+	case w.Owner == nil:
+		return errs.E(op, errs.Validation, errs.Parameter("owner"), "a watchlist must have an owner")
+	case strings.TrimSpace(w.Name) == "":
+		return errs.E(op, errs.Validation, errs.Parameter("name"), errs.MissingField("name"))
+	case len(w.Name) > MaxWatchlistNameLength:
+		return errs.E(op, errs.Validation, errs.Parameter("name"), "name must be 200 characters or less")
+	}
+
+	return nil
+}
+
+// IsOwnedBy reports whether the user owns the watchlist
+func (w *Watchlist) IsOwnedBy(u *User) bool {
+	return u != nil && w.Owner != nil && w.Owner.ID == u.ID
+}
+
+// CanRead reports whether the user can read the watchlist. The
+// watchlist must already be known to belong to the user's org.
+func (w *Watchlist) CanRead(u *User) bool {
+	return w.Shared || w.IsOwnedBy(u)
+}
+
+// Authorize returns an error unless the user owns the watchlist. A
+// watchlist the user cannot read is reported as not existing rather
+// than unauthorized, so private watchlists are not disclosed.
+func (w *Watchlist) Authorize(u *User) error {
+	const op errs.Op = "diygoapi/Watchlist.Authorize"
+
+	switch {
+	case w.IsOwnedBy(u):
+		return nil
+	case w.CanRead(u):
+		return errs.E(op, errs.Unauthorized, "only the owner can change a watchlist")
+	}
+
+	return errs.E(op, errs.NotExist, "no watchlist exists for the given external ID")
+}
+
+// CreateWatchlistRequest is the request struct for creating a watchlist
+type CreateWatchlistRequest struct {
+	Name   string `json:"name"`
+	Shared bool   `json:"shared"`
+}
+
+// UpdateWatchlistRequest is the request struct for renaming a
+// watchlist or changing whether it is shared
+type UpdateWatchlistRequest struct {
+	ExternalID string
+	Name       string `json:"name"`
+	Shared     bool   `json:"shared"`
+}
+
+// AddWatchlistMovieRequest is the request struct for adding a movie to
+// the end of a watchlist
+type AddWatchlistMovieRequest struct {
+	ExternalID      string
+	MovieExternalID string `json:"movie_extl_id"`
+}
+
+// ReorderWatchlistRequest is the request struct for reordering a
+// watchlist. MovieExternalIDs must list every movie on the watchlist
+// exactly once, in the new order.
+type ReorderWatchlistRequest struct {
+	ExternalID       string
+	MovieExternalIDs []string `json:"movie_extl_ids"`
+}
+
+// WatchlistResponse is the response struct for a Watchlist
+type WatchlistResponse struct {
+	ExternalID     string                   `json:"external_id"`
+	Name           string                   `json:"name"`
+	Shared         bool                     `json:"shared"`
+	OwnerExtlID    string                   `json:"owner_extl_id"`
+	OwnerFirstName string                   `json:"owner_first_name"`
+	OwnerLastName  string                   `json:"owner_last_name"`
+	Movies         []WatchlistMovieResponse `json:"movies"`
+	CreateDateTime string                   `json:"create_date_time"`
+	UpdateDateTime string                   `json:"update_date_time"`
+}
+
+// WatchlistMovieResponse is the response struct for a movie on a watchlist
+type WatchlistMovieResponse struct {
+	ExternalID string `json:"external_id"`
+	Title      string `json:"title"`
+	Position   int    `json:"position"`
+}
@@ -0,0 +1,83 @@
+package diygoapi
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+func TestWatchlist_IsValid(t *testing.T) {
+	c := qt.New(t)
+
+	watchlistFunc := func() *Watchlist {
+		return &Watchlist{
+			ID:         uuid.New(),
+			ExternalID: secure.NewID(),
+			Owner:      &User{ID: uuid.New()},
+			Name:       "Friday night",
+		}
+	}
+
+	w1 := watchlistFunc()
+	w2 := watchlistFunc()
+	w2.ExternalID = nil
+	w3 := watchlistFunc()
+	w3.Owner = nil
+	w4 := watchlistFunc()
+	w4.Name = "  "
+	w5 := watchlistFunc()
+	w5.Name = strings.Repeat("a", MaxWatchlistNameLength+1)
+
+	tests := []struct {
+		name    string
+		w       *Watchlist
+		wantErr error
+	}{
+		{"typical no error", w1, nil},
+		{"nil ExternalID", w2, errs.E(errs.Validation, errs.Parameter("extlID"), errs.MissingField("extlID"))},
+		{"nil Owner", w3, errs.E(errs.Validation, errs.Parameter("owner"), "a watchlist must have an owner")},
+		{"blank name", w4, errs.E(errs.Validation, errs.Parameter("name"), errs.MissingField("name"))},
+		{"name too long", w5, errs.E(errs.Validation, errs.Parameter("name"), "name must be 200 characters or less")},
+	}
+	for _, tt := range tests {
+		c.Run(tt.name, func(c *qt.C) {
+			c.Assert(tt.w.IsValid(), qt.CmpEquals(cmp.Comparer(errs.Match)), tt.wantErr)
+		})
+	}
+}
+
+func TestWatchlist_Authorize(t *testing.T) {
+	c := qt.New(t)
+
+	owner := &User{ID: uuid.New()}
+	other := &User{ID: uuid.New()}
+
+	private := &Watchlist{Owner: owner}
+	shared := &Watchlist{Owner: owner, Shared: true}
+
+	tests := []struct {
+		name        string
+		w           *Watchlist
+		u           *User
+		wantCanRead bool
+		wantErr     error
+	}{
+		{"owner of private watchlist", private, owner, true, nil},
+		{"owner of shared watchlist", shared, owner, true, nil},
+		{"other user of shared watchlist", shared, other, true, errs.E(errs.Unauthorized, "only the owner can change a watchlist")},
+		{"other user of private watchlist", private, other, false, errs.E(errs.NotExist, "no watchlist exists for the given external ID")},
+		{"nil user", private, nil, false, errs.E(errs.NotExist, "no watchlist exists for the given external ID")},
+	}
+	for _, tt := range tests {
+		c.Run(tt.name, func(c *qt.C) {
+			c.Assert(tt.w.CanRead(tt.u), qt.Equals, tt.wantCanRead)
+			c.Assert(tt.w.Authorize(tt.u), qt.CmpEquals(cmp.Comparer(errs.Match)), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package diygoapi
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+	"github.com/gilcrest/diygoapi/uuid"
+)
+
+// Headers sent with each webhook delivery. The delivery ID stays the
+// same across attempts, so a receiver can ignore a delivery it has
+// already processed.
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookDeliveryJobKind is the kind of the background job which sends
+// an event to a webhook
+const WebhookDeliveryJobKind = "webhook.deliver"
+
+// WebhookDeliveryMaxAttempts is the number of times sending an event to
+// a webhook is attempted before the delivery is given up on
+const WebhookDeliveryMaxAttempts = 8
+
+// WebhookDeliveryListLimit is the number of most recent deliveries
+// listed for a webhook
+const WebhookDeliveryListLimit = 50
+
+// webhookSecretLength is the number of random bytes in a webhook secret
+const webhookSecretLength = 32
+
+// WebhookDeliveryState is the state of the delivery of an event to a webhook
+type WebhookDeliveryState string
+
+// Webhook delivery states. A delivery is pending until an attempt
+// succeeds or it runs out of attempts and fails.
+const (
+	WebhookDeliveryPending   WebhookDeliveryState = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryState = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryState = "failed"
+)
+
+// WebhookServicer is used to register and inspect the webhooks of an App
+type WebhookServicer interface {
+	Create(ctx context.Context, r *CreateWebhookRequest, adt Audit) (*WebhookResponse, error)
+	FindAll(ctx context.Context, adt Audit) ([]*WebhookResponse, error)
+	Delete(ctx context.Context, extlID string, adt Audit) (DeleteResponse, error)
+	FindDeliveries(ctx context.Context, extlID string, adt Audit) ([]*WebhookDeliveryResponse, error)
+}
+
+// Webhook is an endpoint registered by an App to be sent the events of
+// its Org. Each event is posted to URL as an EventResponse, signed with
+// Secret.
+type Webhook struct {
+	ID         uuid.UUID
+	ExternalID secure.Identifier
+	App        *App
+	URL        string
+	Secret     string
+	EventTypes []EventType
+	Active     bool
+}
+
+// NewWebhook initializes and validates a Webhook for the App given a
+// CreateWebhookRequest. A random secret is generated for it. A URL
+// with an IP address host is checked with CheckWebhookAddr, given the
+// allowed networks.
+func NewWebhook(a *App, r *CreateWebhookRequest, g APIKeyGenerator, allowed []netip.Prefix) (Webhook, error) {
+	const op errs.Op = "diygoapi/NewWebhook"
+
+	if a == nil || a.Org == nil {
+		return Webhook{}, errs.E(op, errs.Internal, "app and its org are required to create a webhook")
+	}
+
+	err := validateWebhookURL(r.URL, allowed)
+	if err != nil {
+		return Webhook{}, errs.E(op, err)
+	}
+
+	if len(r.EventTypes) == 0 {
+		return Webhook{}, errs.E(op, errs.Validation, errs.Parameter("event_types"), errs.MissingField("event_types"))
+	}
+	var (
+		eventTypes []EventType
+		seen       = make(map[EventType]bool)
+	)
+	for _, s := range r.EventTypes {
+		var et EventType
+		et, err = ParseEventType(s)
+		if err != nil {
+			return Webhook{}, errs.E(op, err)
+		}
+		if seen[et] {
+			continue
+		}
+		seen[et] = true
+		eventTypes = append(eventTypes, et)
+	}
+
+	var secret string
+	secret, err = g.RandomString(webhookSecretLength)
+	if err != nil {
+		return Webhook{}, errs.E(op, err)
+	}
+
+	return Webhook{
+		ID:         uuid.New(),
+		ExternalID: secure.NewID(),
+		App:        a,
+		URL:        r.URL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		Active:     true,
+	}, nil
+}
+
+// validateWebhookURL checks the URL is an absolute http or https URL
+// and, if its host is an IP address or localhost, that webhooks may be
+// sent to it. Other host names are checked as they are dialed.
+func validateWebhookURL(s string, allowed []netip.Prefix) error {
+	const op errs.Op = "diygoapi/validateWebhookURL"
+
+	switch {
+	case s == "":
+		return errs.E(op, errs.Validation, errs.Parameter("url"), errs.MissingField("url"))
+	case len(s) > 2000:
+		return errs.E(op, errs.Validation, errs.Parameter("url"), "url must be 2000 characters or less")
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errs.E(op, errs.Validation, errs.Parameter("url"), "url must be an absolute http or https URL")
+	}
+	if u.User != nil {
+		return errs.E(op, errs.Validation, errs.Parameter("url"), "url cannot include credentials")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	addr, err := netip.ParseAddr(host)
+	switch {
+	case err == nil:
+	case host == "localhost" || strings.HasSuffix(host, ".localhost"):
+		addr = netip.AddrFrom4([4]byte{127, 0, 0, 1})
+	default:
+		return nil
+	}
+	err = CheckWebhookAddr(addr, allowed)
+	if err != nil {
+		return errs.E(op, errs.Validation, errs.Parameter("url"), err)
+	}
+
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which
+// is not routable on the internet
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// CheckWebhookAddr returns an error if webhooks cannot be sent to the
+// IP address. Only public unicast addresses are allowed, so an app
+// cannot have the server post to itself or to the private network it
+// runs in, unless the address is in one of the allowed networks, as
+// configured for local development and tests.
+func CheckWebhookAddr(addr netip.Addr, allowed []netip.Prefix) error {
+	const op errs.Op = "diygoapi/CheckWebhookAddr"
+
+	addr = addr.Unmap()
+	for _, p := range allowed {
+		if p.Contains(addr) {
+			return nil
+		}
+	}
+
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || sharedAddressSpace.Contains(addr) ||
+		(addr.Is4() && addr.As4()[0] == 0) {
+		return errs.E(op, errs.Validation, "webhooks can only be sent to public addresses")
+	}
+
+	return nil
+}
+
+// SignWebhook returns the signature header value of a webhook delivery
+// body sent at time t, in the form t=<unix time>,v1=<signature>. The
+// signature is the hex encoded HMAC-SHA256 of the Unix time, a period
+// and the body, keyed with the webhook secret. Including the time in
+// what is signed lets a receiver reject replays.
+func SignWebhook(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + webhookMAC(secret, ts, body)
+}
+
+// VerifyWebhookSignature checks a signature header made by SignWebhook
+// matches the body, and that it was made within tolerance of now
+func VerifyWebhookSignature(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	const op errs.Op = "diygoapi/VerifyWebhookSignature"
+
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return errs.E(op, errs.Unauthenticated, "malformed webhook signature")
+	}
+
+	if d := now.Sub(time.Unix(unix, 0)); d > tolerance || d < -tolerance {
+		return errs.E(op, errs.Unauthenticated, fmt.Sprintf("webhook signature is outside the tolerance of %s", tolerance))
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(webhookMAC(secret, ts, body))) {
+		return errs.E(op, errs.Unauthenticated, "webhook signature does not match")
+	}
+
+	return nil
+}
+
+func webhookMAC(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreateWebhookRequest is the request struct for registering a webhook
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+}
+
+// WebhookResponse is the response struct for a Webhook. The secret is
+// only returned when the webhook is created.
+type WebhookResponse struct {
+	ExternalID     string      `json:"external_id"`
+	URL            string      `json:"url"`
+	EventTypes     []EventType `json:"event_types"`
+	Active         bool        `json:"active"`
+	Secret         string      `json:"secret,omitempty"`
+	CreateDateTime string      `json:"create_date_time"`
+	UpdateDateTime string      `json:"update_date_time"`
+}
+
+// WebhookDeliveryResponse is the response struct for the delivery of
+// an event to a webhook, including the history of its attempts
+type WebhookDeliveryResponse struct {
+	ExternalID     string                            `json:"external_id"`
+	EventExtlID    string                            `json:"event_extl_id"`
+	EventType      EventType                         `json:"event_type"`
+	State          WebhookDeliveryState              `json:"state"`
+	Attempts       int                               `json:"attempts"`
+	LastStatusCode int                               `json:"last_status_code,omitempty"`
+	LastError      string                            `json:"last_error,omitempty"`
+	CreateDateTime string                            `json:"create_date_time"`
+	UpdateDateTime string                            `json:"update_date_time"`
+	AttemptHistory []*WebhookDeliveryAttemptResponse `json:"attempt_history"`
+}
+
+// WebhookDeliveryAttemptResponse is the response struct for one
+// attempt at a webhook delivery. StatusCode is zero if no response
+// was received.
+type WebhookDeliveryAttemptResponse struct {
+	Attempt         int    `json:"attempt"`
+	StatusCode      int    `json:"status_code,omitempty"`
+	Error           string `json:"error,omitempty"`
+	DurationMillis  int    `json:"duration_ms"`
+	AttemptDateTime string `json:"attempt_date_time"`
+}
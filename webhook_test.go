@@ -0,0 +1,116 @@
+package diygoapi_test
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/gilcrest/diygoapi"
+	"github.com/gilcrest/diygoapi/errs"
+	"github.com/gilcrest/diygoapi/secure"
+)
+
+func TestParseEventType(t *testing.T) {
+	c := qt.New(t)
+
+	et, err := diygoapi.ParseEventType("movie.created")
+	c.Assert(err, qt.IsNil)
+	c.Assert(et, qt.Equals, diygoapi.MovieCreatedEvent)
+
+	_, err = diygoapi.ParseEventType("movie.watched")
+	c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+}
+
+func TestNewWebhook(t *testing.T) {
+	a := &diygoapi.App{Org: &diygoapi.Org{}}
+
+	t.Run("valid", func(t *testing.T) {
+		c := qt.New(t)
+
+		wh, err := diygoapi.NewWebhook(a, &diygoapi.CreateWebhookRequest{
+			URL:        "https://example.com/hooks",
+			EventTypes: []string{"movie.created", "org.updated", "movie.created"},
+		}, secure.RandomGenerator{}, nil)
+		c.Assert(err, qt.IsNil)
+		c.Assert(wh.Active, qt.IsTrue)
+		c.Assert(wh.Secret, qt.Not(qt.Equals), "")
+		c.Assert(wh.EventTypes, qt.DeepEquals, []diygoapi.EventType{diygoapi.MovieCreatedEvent, diygoapi.OrgUpdatedEvent})
+	})
+
+	tests := []struct {
+		name string
+		r    *diygoapi.CreateWebhookRequest
+	}{
+		{"missing url", &diygoapi.CreateWebhookRequest{EventTypes: []string{"movie.created"}}},
+		{"relative url", &diygoapi.CreateWebhookRequest{URL: "/hooks", EventTypes: []string{"movie.created"}}},
+		{"ftp url", &diygoapi.CreateWebhookRequest{URL: "ftp://example.com", EventTypes: []string{"movie.created"}}},
+		{"url with credentials", &diygoapi.CreateWebhookRequest{URL: "https://otto:pw@example.com", EventTypes: []string{"movie.created"}}},
+		{"no event types", &diygoapi.CreateWebhookRequest{URL: "https://example.com/hooks"}},
+		{"unknown event type", &diygoapi.CreateWebhookRequest{URL: "https://example.com/hooks", EventTypes: []string{"movie.watched"}}},
+		{"loopback address", &diygoapi.CreateWebhookRequest{URL: "http://127.0.0.1:8080/hooks", EventTypes: []string{"movie.created"}}},
+		{"localhost", &diygoapi.CreateWebhookRequest{URL: "http://localhost/hooks", EventTypes: []string{"movie.created"}}},
+		{"link-local address", &diygoapi.CreateWebhookRequest{URL: "http://169.254.169.254/latest/meta-data", EventTypes: []string{"movie.created"}}},
+		{"private address", &diygoapi.CreateWebhookRequest{URL: "https://10.1.2.3/hooks", EventTypes: []string{"movie.created"}}},
+		{"unspecified address", &diygoapi.CreateWebhookRequest{URL: "http://0.0.0.0/hooks", EventTypes: []string{"movie.created"}}},
+		{"mapped loopback address", &diygoapi.CreateWebhookRequest{URL: "http://[::ffff:127.0.0.1]/hooks", EventTypes: []string{"movie.created"}}},
+		{"private IPv6 address", &diygoapi.CreateWebhookRequest{URL: "http://[fd00::1]/hooks", EventTypes: []string{"movie.created"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+
+			_, err := diygoapi.NewWebhook(a, tt.r, secure.RandomGenerator{}, nil)
+			c.Assert(errs.KindIs(errs.Validation, err), qt.IsTrue)
+		})
+	}
+
+	t.Run("allowed network", func(t *testing.T) {
+		c := qt.New(t)
+
+		_, err := diygoapi.NewWebhook(a, &diygoapi.CreateWebhookRequest{
+			URL:        "http://127.0.0.1:8080/hooks",
+			EventTypes: []string{"movie.created"},
+		}, secure.RandomGenerator{}, []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")})
+		c.Assert(err, qt.IsNil)
+	})
+}
+
+func TestWebhookSignature(t *testing.T) {
+	const secret = "shh"
+	body := []byte(`{"type":"movie.created"}`)
+	sentAt := time.Unix(1700000000, 0)
+	sig := diygoapi.SignWebhook(secret, sentAt, body)
+
+	t.Run("valid", func(t *testing.T) {
+		c := qt.New(t)
+
+		err := diygoapi.VerifyWebhookSignature(secret, sig, body, 5*time.Minute, sentAt.Add(time.Minute))
+		c.Assert(err, qt.IsNil)
+	})
+	t.Run("tampered body", func(t *testing.T) {
+		c := qt.New(t)
+
+		err := diygoapi.VerifyWebhookSignature(secret, sig, []byte(`{"type":"movie.deleted"}`), 5*time.Minute, sentAt)
+		c.Assert(errs.KindIs(errs.Unauthenticated, err), qt.IsTrue)
+	})
+	t.Run("wrong secret", func(t *testing.T) {
+		c := qt.New(t)
+
+		err := diygoapi.VerifyWebhookSignature("other", sig, body, 5*time.Minute, sentAt)
+		c.Assert(errs.KindIs(errs.Unauthenticated, err), qt.IsTrue)
+	})
+	t.Run("outside tolerance", func(t *testing.T) {
+		c := qt.New(t)
+
+		err := diygoapi.VerifyWebhookSignature(secret, sig, body, 5*time.Minute, sentAt.Add(10*time.Minute))
+		c.Assert(errs.KindIs(errs.Unauthenticated, err), qt.IsTrue)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		c := qt.New(t)
+
+		err := diygoapi.VerifyWebhookSignature(secret, "v1=abc", body, 5*time.Minute, sentAt)
+		c.Assert(errs.KindIs(errs.Unauthenticated, err), qt.IsTrue)
+	})
+}